@@ -159,6 +159,73 @@ static void run_cmd(char *const argv[]) {
     }
 }
 
+/* runs argv like run_cmd and returns its stdout in out */
+static void capture_cmd(char *const argv[], char *out, size_t sz) {
+    int fds[2];
+    if (pipe(fds) < 0)
+        die("pipe");
+
+    pid_t pid = fork();
+    if (pid < 0)
+        die("fork");
+
+    if (pid == 0) {
+        close(fds[0]);
+        dup2(fds[1], STDOUT_FILENO);
+        close(fds[1]);
+        execvp(argv[0], argv);
+        perror(argv[0]);
+        _exit(127);
+    }
+
+    close(fds[1]);
+    size_t n = 0;
+    ssize_t r;
+    while (n + 1 < sz && (r = read(fds[0], out + n, sz - n - 1)) > 0)
+        n += r;
+    out[n] = '\0';
+    close(fds[0]);
+
+    int status;
+    if (waitpid(pid, &status, 0) < 0)
+        die("waitpid");
+
+    if (!WIFEXITED(status) || WEXITSTATUS(status) != 0) {
+        log(LOG_ERROR, "command failed: %s", argv[0]);
+        exit(1);
+    }
+}
+
+/* the libs are searched after the include paths of the manifest; a project
+   without manifest is laid out the legacy way only if this is set */
+static void set_picasso_include(const char *toolRoot) {
+    if (getenv("PICASSO_INCLUDE") != NULL)
+        return;
+    char picassoInclude[PATH_MAX];
+    snprintf(picassoInclude, sizeof(picassoInclude), "%s/libs", toolRoot);
+    if (setenv("PICASSO_INCLUDE", picassoInclude, 0) != 0) {
+        log(LOG_WARN, "Failed to set PICASSO_INCLUDE");
+    } else {
+        log(LOG_INFO, "PICASSO_INCLUDE set to: %s", picassoInclude);
+    }
+}
+
+/* reads key of the manifest of the project in dir with irgen manifest,
+   one value per line */
+static void manifest_get(const char *irgen, const char *dir, const char *key, char *out, size_t sz) {
+    capture_cmd((char *[]){(char *)irgen, "manifest", (char *)dir, "--get", (char *)key, NULL}, out, sz);
+}
+
+/* reads the single value of key, e.g. output */
+static void manifest_value(const char *irgen, const char *dir, const char *key, char *out, size_t sz) {
+    manifest_get(irgen, dir, key, out, sz);
+    out[strcspn(out, "\n")] = '\0';
+    if (out[0] == '\0') {
+        log(LOG_ERROR, "no %s in the manifest of %s", key, dir);
+        exit(1);
+    }
+}
+
 static const char* find_clang(void) {
     static char clang_path[PATH_MAX] = {0};
     if (clang_path[0] != '\0') return clang_path;
@@ -342,10 +409,8 @@ static void generate_ffi_irs_from_root( const char *ffiRoot, const char *tmpDir,
     closedir(d);
 }
 
-static void generate_ffi_irs(const char *dir, const char *buildDir) {
-    char ffiRoot[PATH_MAX];
-    snprintf(ffiRoot, sizeof(ffiRoot), "%s/c/ffi", dir);
-
+/* ffiRoots are the C module roots of the manifest, one per line */
+static void generate_ffi_irs(char *ffiRoots, const char *buildDir) {
     char tmpDir[PATH_MAX];
     snprintf(tmpDir, sizeof(tmpDir), "%s/tmp", buildDir);
 
@@ -355,21 +420,119 @@ static void generate_ffi_irs(const char *dir, const char *buildDir) {
     run_cmd((char *[]){"mkdir", "-p", tmpDir, NULL});
     run_cmd((char *[]){"mkdir", "-p", ffiObjDir, NULL});
 
+    for (char *ffiRoot = strtok(ffiRoots, "\n"); ffiRoot; ffiRoot = strtok(NULL, "\n")) {
+        generate_ffi_irs_from_root(
+            ffiRoot,
+            tmpDir,
+            ffiObjDir,
+            NULL,
+            1
+        );
+    }
+}
+
+/* generates the IR of the stdlib FFI modules into buildDir/tmp, which irgen
+   reads the builtin modules from */
+static void generate_stdlib_irs(const char *buildDir, const char *toolRoot) {
+    char libsDir[PATH_MAX];
+    snprintf(libsDir, sizeof(libsDir), "%s/libs", toolRoot);
+
+    char runtimeHdrs[PATH_MAX];
+    snprintf(runtimeHdrs, sizeof(runtimeHdrs), "%s/runtime/headers", toolRoot);
+
+    char tmpDir[PATH_MAX];
+    snprintf(tmpDir, sizeof(tmpDir), "%s/tmp", buildDir);
+    run_cmd((char *[]){"mkdir", "-p", tmpDir, NULL});
+
+    log(LOG_INFO, "Generating stdlib FFI IRs");
     generate_ffi_irs_from_root(
-        ffiRoot,
+        libsDir,
         tmpDir,
-        ffiObjDir,
         NULL,
-        1
+        runtimeHdrs,
+        0
     );
 }
 
+/* compiles the IR in buildDir, of the project and of its FFI modules in
+   buildDir/tmp, and links it with the runtime into buildDir/a.out */
+static void link_project(const char *buildDir) {
+    /* .ll → .bc */
+    log(LOG_INFO, "Converting .ll to .bc");
+    char *ll_to_bc[] = {
+        "sh", "-c",
+        "set -e; for f in \"$1\"/*.ll; do "
+        "b=$(basename \"$f\" .ll); "
+        "llvm-as \"$f\" -o \"$1/$b.bc\"; "
+        "done",
+        "sh",
+        buildDir,
+        NULL
+    };
+    run_cmd(ll_to_bc);
+
+    /* .bc → .o */
+    log(LOG_INFO, "Compiling .bc to .o");
+    
+    /* Use llc to compile .bc to .o (handles LLVM version compatibility) */
+    char bc_to_o_cmd[PATH_MAX * 2];
+    snprintf(bc_to_o_cmd, sizeof(bc_to_o_cmd),
+        "set -e; for f in \"$1\"/*.bc; do "
+        "b=$(basename \"$f\" .bc); "
+        "llc -filetype=obj \"$f\" -o \"$1/$b.o\"; "
+        "done");
+    
+    char *bc_to_o[] = {
+        "sh", "-c",
+        bc_to_o_cmd,
+        "sh",
+        buildDir,
+        NULL
+    };
+    run_cmd(bc_to_o);
+
+    /* final link */
+    log(LOG_INFO, "Linking final executable");
+    
+    const char *sdk = get_sdk_path();
+    char ffi_include[PATH_MAX];
+    snprintf(ffi_include, sizeof(ffi_include), "%s/usr/include/ffi", sdk);
+    
+    char link_cmd[PATH_MAX * 3];
+    char runtimeLib[PATH_MAX];
+    get_runtimelib(runtimeLib, sizeof(runtimeLib));
+    snprintf(link_cmd, sizeof(link_cmd),
+        "set -e; "
+        "OBJS=$1/*.o; "
+        "FFI_OBJS=\"\"; "
+        "if [ -d \"$1/tmp/ffi-obj\" ] && ls \"$1/tmp/ffi-obj\"/*.o >/dev/null 2>&1; then "
+        "  FFI_OBJS=$1/tmp/ffi-obj/*.o; "
+        "fi; "
+        "cc $OBJS $FFI_OBJS "
+        "-isysroot %s "
+        "%s"
+        " -o $1/a.out "
+        " -rdynamic "
+        " -I%s "
+        " -lffi -lpthread -lm",
+        sdk, runtimeLib, ffi_include);
+    
+    char *link[] = {
+        "sh", "-c",
+        link_cmd,
+        "sh",
+        buildDir,
+        NULL
+    };
+    run_cmd(link);
+}
+
 /* main */
 int main(int argc, char **argv) {
     init_logging();
 
     if (argc < 2) {
-        log(LOG_ERROR, "usage: picasso <build|exec|clean> <project-dir>");
+        log(LOG_ERROR, "usage: picasso <build|ffi|link|exec|clean> <project-dir>");
         return 1;
     }
 
@@ -382,129 +545,65 @@ int main(int argc, char **argv) {
         const char *dir = argv[2];
         log(LOG_INFO, "Starting build for project: %s", dir);
 
+        char toolRoot[PATH_MAX];
+        get_tool_root(toolRoot, sizeof(toolRoot));
+        set_picasso_include(toolRoot);
+
+        char irgenPath[PATH_MAX];
+        get_irgen(irgenPath, sizeof(irgenPath));
+
+        /* output and C modules as declared by picasso.toml / picasso.ini */
         char buildDir[PATH_MAX];
-        snprintf(buildDir, sizeof(buildDir), "%s/build", dir);
+        manifest_value(irgenPath, dir, "output", buildDir, sizeof(buildDir));
+        char ffiRoots[PATH_MAX * 4];
+        manifest_get(irgenPath, dir, "ffi", ffiRoots, sizeof(ffiRoots));
 
         run_cmd((char *[]){"mkdir", "-p", buildDir, NULL});
 
         /* project FFI IR + objects */
         log(LOG_INFO, "Generating FFI IRs for project");
-        generate_ffi_irs(dir, buildDir);
+        generate_ffi_irs(ffiRoots, buildDir);
 
         /* stdlib FFI IRs */
-        char toolRoot[PATH_MAX];
-        get_tool_root(toolRoot, sizeof(toolRoot));
-
-        char libsDir[PATH_MAX];
-        snprintf(libsDir, sizeof(libsDir), "%s/libs", toolRoot);
-
-        char runtimeHdrs[PATH_MAX];
-        snprintf(runtimeHdrs, sizeof(runtimeHdrs), "%s/runtime/headers", toolRoot);
-
-        char tmpDir[PATH_MAX];
-        snprintf(tmpDir, sizeof(tmpDir), "%s/build/tmp", dir);
-
-        log(LOG_INFO, "Generating stdlib FFI IRs");
-        generate_ffi_irs_from_root(
-            libsDir,
-            tmpDir,
-            NULL,
-            runtimeHdrs,
-            0
-        );
+        generate_stdlib_irs(buildDir, toolRoot);
 
         /* language IR generation */
         log(LOG_INFO, "Running IR generation");
-        
-        /* Set PICASSO_INCLUDE environment variable if not already set */
-        if (getenv("PICASSO_INCLUDE") == NULL) {
-            char picassoInclude[PATH_MAX];
-            snprintf(picassoInclude, sizeof(picassoInclude), "%s/libs", toolRoot);
-            if (setenv("PICASSO_INCLUDE", picassoInclude, 0) != 0) {
-                log(LOG_WARN, "Failed to set PICASSO_INCLUDE");
-            } else {
-                log(LOG_INFO, "PICASSO_INCLUDE set to: %s", picassoInclude);
-            }
-        }
-        
-        char irgenPath[PATH_MAX];
-        get_irgen(irgenPath, sizeof(irgenPath));
         char *irgen[] = { irgenPath, "gen", (char *)dir, NULL };
         run_cmd(irgen);
 
-        /* .ll → .bc */
-        log(LOG_INFO, "Converting .ll to .bc");
-        char *ll_to_bc[] = {
-            "sh", "-c",
-            "set -e; for f in \"$1\"/*.ll; do "
-            "b=$(basename \"$f\" .ll); "
-            "llvm-as \"$f\" -o \"$1/$b.bc\"; "
-            "done",
-            "sh",
-            buildDir,
-            NULL
-        };
-        run_cmd(ll_to_bc);
-
-        /* .bc → .o */
-        log(LOG_INFO, "Compiling .bc to .o");
-        
-        /* Use llc to compile .bc to .o (handles LLVM version compatibility) */
-        char bc_to_o_cmd[PATH_MAX * 2];
-        snprintf(bc_to_o_cmd, sizeof(bc_to_o_cmd),
-            "set -e; for f in \"$1\"/*.bc; do "
-            "b=$(basename \"$f\" .bc); "
-            "llc -filetype=obj \"$f\" -o \"$1/$b.o\"; "
-            "done");
-        
-        char *bc_to_o[] = {
-            "sh", "-c",
-            bc_to_o_cmd,
-            "sh",
-            buildDir,
-            NULL
-        };
-        run_cmd(bc_to_o);
-
-        /* final link */
-        log(LOG_INFO, "Linking final executable");
-        
-        const char *sdk = get_sdk_path();
-        char ffi_include[PATH_MAX];
-        snprintf(ffi_include, sizeof(ffi_include), "%s/usr/include/ffi", sdk);
-        
-        char link_cmd[PATH_MAX * 3];
-        char runtimeLib[PATH_MAX];
-        get_runtimelib(runtimeLib, sizeof(runtimeLib));
-        snprintf(link_cmd, sizeof(link_cmd),
-            "set -e; "
-            "OBJS=$1/*.o; "
-            "FFI_OBJS=\"\"; "
-            "if [ -d \"$1/tmp/ffi-obj\" ] && ls \"$1/tmp/ffi-obj\"/*.o >/dev/null 2>&1; then "
-            "  FFI_OBJS=$1/tmp/ffi-obj/*.o; "
-            "fi; "
-            "cc $OBJS $FFI_OBJS "
-            "-isysroot %s "
-            "%s"
-            " -o $1/a.out "
-            " -rdynamic "
-            " -I%s "
-            " -lffi -lpthread -lm",
-            sdk, runtimeLib, ffi_include);
-        
-        char *link[] = {
-            "sh", "-c",
-            link_cmd,
-            "sh",
-            buildDir,
-            NULL
-        };
-        run_cmd(link);
+        link_project(buildDir);
 
         log(LOG_INFO, "Build completed successfully");
         return 0;
     }
 
+    /* the steps around irgen, for builds irgen drives, e.g. irgen run */
+    if (!strcmp(argv[1], "ffi")) {
+        if (argc != 3) {
+            log(LOG_ERROR, "picasso ffi <build dir>");
+            return 1;
+        }
+
+        log(LOG_INFO, "Generating stdlib FFI IRs into: %s", argv[2]);
+        char toolRoot[PATH_MAX];
+        get_tool_root(toolRoot, sizeof(toolRoot));
+        generate_stdlib_irs(argv[2], toolRoot);
+        return 0;
+    }
+
+    if (!strcmp(argv[1], "link")) {
+        if (argc != 3) {
+            log(LOG_ERROR, "picasso link <build dir>");
+            return 1;
+        }
+
+        log(LOG_INFO, "Linking build directory: %s", argv[2]);
+        link_project(argv[2]);
+        log(LOG_INFO, "Link completed successfully");
+        return 0;
+    }
+
     if (!strcmp(argv[1], "exec")) {
         if (argc != 3) {
             log(LOG_ERROR, "picasso exec <project root dir>");
@@ -512,8 +611,15 @@ int main(int argc, char **argv) {
         }
 
         log(LOG_INFO, "Executing project: %s", argv[2]);
+        char toolRoot[PATH_MAX];
+        get_tool_root(toolRoot, sizeof(toolRoot));
+        set_picasso_include(toolRoot);
+        char irgenPath[PATH_MAX];
+        get_irgen(irgenPath, sizeof(irgenPath));
+        char buildDir[PATH_MAX];
+        manifest_value(irgenPath, argv[2], "output", buildDir, sizeof(buildDir));
         char exe[PATH_MAX];
-        snprintf(exe, sizeof(exe), "%s/build/a.out", argv[2]);
+        snprintf(exe, sizeof(exe), "%s/a.out", buildDir);
         execl(exe, exe, (char *)NULL);
         die("exec");
     }
@@ -525,12 +631,14 @@ int main(int argc, char **argv) {
         }
 
         log(LOG_INFO, "Cleaning project: %s", argv[2]);
+        char toolRoot[PATH_MAX];
+        get_tool_root(toolRoot, sizeof(toolRoot));
+        set_picasso_include(toolRoot);
+        char irgenPath[PATH_MAX];
+        get_irgen(irgenPath, sizeof(irgenPath));
         char buildDir[PATH_MAX];
-        snprintf(buildDir, sizeof(buildDir), "%s/build", argv[2]);
-        char libDir[PATH_MAX];
-        snprintf(libDir, sizeof(libDir), "%s/picasso", argv[2]);
+        manifest_value(irgenPath, argv[2], "output", buildDir, sizeof(buildDir));
         run_cmd((char*[]){"rm", "-rf", buildDir,  NULL});
-        run_cmd((char*[]){"rm", "-rf", libDir,  NULL});
         log(LOG_INFO, "Clean completed successfully");
         return 0;
     }
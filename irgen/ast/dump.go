@@ -0,0 +1,145 @@
+package ast
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/nagarajRPoojari/picasso/irgen/lexer"
+)
+
+var (
+	sourceLocType = reflect.TypeOf(SourceLoc{})
+	tokenType     = reflect.TypeOf(lexer.Token{})
+)
+
+// locString returns loc as path:line:col. SourceLoc has no String method,
+// which every node embedding it would take as its own.
+func locString(loc SourceLoc) string {
+	return fmt.Sprintf("%s:%d:%d", loc.FilePath, loc.Line, loc.Col)
+}
+
+// Fprint writes node, e.g. a BlockStatement or any Statement or Expression,
+// to w as indented text, a field per line. Nodes are named by their type,
+// followed by their SourceLoc, and zero fields are left out, e.g.
+//
+//	ReturnStatement @ a.pic:4:5
+//	  Value: ExpressionStatement @ a.pic:4:12
+//	    Expression: SymbolExpression @ a.pic:4:12
+//	      Value: "x"
+func Fprint(w io.Writer, node any) error {
+	var b strings.Builder
+	fprint(&b, reflect.ValueOf(node), 0)
+	b.WriteString("\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func fprint(b *strings.Builder, v reflect.Value, depth int) {
+	indent := strings.Repeat("  ", depth+1)
+	switch v.Kind() {
+	case reflect.Invalid:
+		b.WriteString("nil")
+	case reflect.Interface, reflect.Pointer:
+		if v.IsNil() {
+			b.WriteString("nil")
+			return
+		}
+		fprint(b, v.Elem(), depth)
+	case reflect.Slice:
+		fmt.Fprintf(b, "[%d]", v.Len())
+		for i := 0; i < v.Len(); i++ {
+			fmt.Fprintf(b, "\n%s%d: ", indent, i)
+			fprint(b, v.Index(i), depth+1)
+		}
+	case reflect.String:
+		fmt.Fprintf(b, "%q", v.String())
+	case reflect.Struct:
+		switch v.Type() {
+		case tokenType:
+			b.WriteString(v.Interface().(lexer.Token).String())
+			return
+		case sourceLocType:
+			b.WriteString(locString(v.Interface().(SourceLoc)))
+			return
+		}
+		b.WriteString(v.Type().Name())
+		if loc, ok := nodeLoc(v); ok {
+			fmt.Fprintf(b, " @ %s", locString(loc))
+		}
+		forFields(v, func(name string, f reflect.Value) {
+			fmt.Fprintf(b, "\n%s%s: ", indent, name)
+			fprint(b, f, depth+1)
+		})
+	default:
+		fmt.Fprintf(b, "%v", v.Interface())
+	}
+}
+
+// JSON returns node as maps, slices and values to be encoded as JSON. Every
+// node has its type in "node" and its SourceLoc, if any, in "loc"; zero
+// fields are left out, as in Fprint.
+func JSON(node any) any {
+	return jsonValue(reflect.ValueOf(node))
+}
+
+func jsonValue(v reflect.Value) any {
+	switch v.Kind() {
+	case reflect.Invalid:
+		return nil
+	case reflect.Interface, reflect.Pointer:
+		if v.IsNil() {
+			return nil
+		}
+		return jsonValue(v.Elem())
+	case reflect.Slice:
+		out := make([]any, v.Len())
+		for i := range out {
+			out[i] = jsonValue(v.Index(i))
+		}
+		return out
+	case reflect.Struct:
+		switch v.Type() {
+		case tokenType:
+			return v.Interface().(lexer.Token).String()
+		case sourceLocType:
+			loc := v.Interface().(SourceLoc)
+			return map[string]any{"file": loc.FilePath, "line": loc.Line, "col": loc.Col}
+		}
+		out := map[string]any{"node": v.Type().Name()}
+		if loc, ok := nodeLoc(v); ok {
+			out["loc"] = jsonValue(reflect.ValueOf(loc))
+		}
+		forFields(v, func(name string, f reflect.Value) {
+			out[name] = jsonValue(f)
+		})
+		return out
+	default:
+		return v.Interface()
+	}
+}
+
+// nodeLoc returns the SourceLoc struct v embeds, if set.
+func nodeLoc(v reflect.Value) (SourceLoc, bool) {
+	f := v.FieldByName(sourceLocType.Name())
+	if !f.IsValid() || f.Type() != sourceLocType || f.IsZero() {
+		return SourceLoc{}, false
+	}
+	return f.Interface().(SourceLoc), true
+}
+
+// forFields calls f for the exported, non-zero fields of struct v in order,
+// but its embedded SourceLoc.
+func forFields(v reflect.Value, f func(name string, field reflect.Value)) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() || field.Anonymous && field.Type == sourceLocType {
+			continue
+		}
+		if fv := v.Field(i); !fv.IsZero() {
+			f(field.Name, fv)
+		}
+	}
+}
@@ -62,6 +62,8 @@ func (t BinaryExpression) GetSrc() SourceLoc {
 // For multiple assignments: a, b = 100, 200;
 type AssignmentExpression struct {
 	SourceLoc
+	// Operator is one of =, += and -=.
+	Operator      lexer.Token
 	Assignee      Expression
 	AssignedValue Expression
 
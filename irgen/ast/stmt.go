@@ -22,7 +22,9 @@ func (t BlockStatement) GetSrc() SourceLoc {
 // For multiple declarations: say a: int, b: int = 100, 200;
 type VariableDeclarationStatement struct {
 	SourceLoc
-	Identifier    string
+	Identifier string
+	// NameLoc is where Identifier is written.
+	NameLoc       SourceLoc
 	Constant      bool
 	AssignedValue Expression
 	ExplicitType  Type
@@ -32,6 +34,7 @@ type VariableDeclarationStatement struct {
 
 	// For multiple variable declarations
 	Identifiers    []string
+	NameLocs       []SourceLoc
 	ExplicitTypes  []Type
 	AssignedValues []Expression
 }
@@ -58,6 +61,8 @@ func (t ExpressionStatement) GetSrc() SourceLoc {
 type Parameter struct {
 	Name string
 	Type Type
+	// NameLoc is where Name is written.
+	NameLoc SourceLoc
 }
 
 // FunctionDefinitionStatement represents a full function implementation,
@@ -72,6 +77,9 @@ type FunctionDefinitionStatement struct {
 	ReturnType Type
 	IsStatic   bool
 	IsInternal bool
+	// IsTest marks a test function, test fn name() { ... }, run by irgen
+	// test.
+	IsTest bool
 }
 
 func (FunctionDefinitionStatement) stmt() {}
@@ -129,6 +137,8 @@ type ImportStatement struct {
 	SourceLoc
 	Name  string
 	Alias string
+	// NameLoc is where the alias is written, or the path if there is none.
+	NameLoc SourceLoc
 }
 
 func (ImportStatement) stmt() {}
@@ -150,13 +160,14 @@ func (t ImportStatement) EndName() string {
 }
 
 // ForeachStatement represents a collection-based loop. If Index is true,
-// the iteration provides the current offset or key.
+// the iteration provides the current offset or key, named IndexName.
 type ForeachStatement struct {
 	SourceLoc
-	Value    string
-	Index    bool
-	Iterable Expression
-	Body     []Statement
+	Value     string
+	Index     bool
+	IndexName string
+	Iterable  Expression
+	Body      []Statement
 }
 
 func (n ForeachStatement) stmt() {}
@@ -227,3 +238,15 @@ func (AtomicBlockStatement) stmt() {}
 func (t AtomicBlockStatement) GetSrc() SourceLoc {
 	return t.SourceLoc
 }
+
+// BadStatement stands in for a statement that failed to parse. The parser
+// records the syntax error and skips to the next statement, so that the
+// rest of the file is still checked.
+type BadStatement struct {
+	SourceLoc
+}
+
+func (BadStatement) stmt() {}
+func (t BadStatement) GetSrc() SourceLoc {
+	return t.SourceLoc
+}
@@ -1,9 +1,6 @@
 package ast
 
-import (
-	"encoding/gob"
-	"fmt"
-)
+import "fmt"
 
 // SymbolType represents a named type in the Picasso type system.
 // It can represent either a primitive/built-in type (Atomic) or
@@ -112,38 +109,3 @@ func (t *TupleType) GetUnderlyingType() string {
 func (t *TupleType) Get() string {
 	return "tuple"
 }
-
-func init() {
-	gob.Register(&SymbolType{})
-	gob.Register(&ListType{})
-	gob.Register(&TupleType{})
-
-	gob.Register(NumberExpression{})
-	gob.Register(SymbolExpression{})
-	gob.Register(StringExpression{})
-	gob.Register(BinaryExpression{})
-	gob.Register(AssignmentExpression{})
-	gob.Register(MemberExpression{})
-	gob.Register(PrefixExpression{})
-	gob.Register(ComputedExpression{})
-	gob.Register(RangeExpression{})
-	gob.Register(CallExpression{})
-	gob.Register(FunctionExpression{})
-	gob.Register(ListExpression{})
-	gob.Register(NewExpression{})
-	gob.Register(NullExpression{})
-
-	gob.Register(BlockStatement{})
-	gob.Register(VariableDeclarationStatement{})
-	gob.Register(ExpressionStatement{})
-	gob.Register(FunctionDeclarationStatement{})
-	gob.Register(FunctionDefinitionStatement{})
-	gob.Register(ReturnStatement{})
-	gob.Register(IfStatement{})
-	gob.Register(ImportStatement{})
-	gob.Register(ForeachStatement{})
-	gob.Register(WhileStatement{})
-	gob.Register(ClassDeclarationStatement{})
-	gob.Register(InterfaceDeclarationStatement{})
-	gob.Register(BreakStatement{})
-}
@@ -0,0 +1,100 @@
+package ast
+
+// Node is implemented by every Statement and Expression.
+type Node interface {
+	GetSrc() SourceLoc
+}
+
+// Inspect traverses the tree rooted at node in depth-first order, calling f
+// for each node. If f returns false, the children of that node are skipped.
+// Nil nodes are ignored.
+func Inspect(node Node, f func(Node) bool) {
+	if node == nil || !f(node) {
+		return
+	}
+
+	switch n := node.(type) {
+	// statements
+	case BlockStatement:
+		inspectStmts(n.Body, f)
+	case VariableDeclarationStatement:
+		inspectExpr(n.AssignedValue, f)
+		inspectExprs(n.AssignedValues, f)
+	case ExpressionStatement:
+		inspectExpr(n.Expression, f)
+	case FunctionDefinitionStatement:
+		inspectStmts(n.Body, f)
+	case ReturnStatement:
+		inspectExpr(n.Value.Expression, f)
+		inspectExprs(n.Values, f)
+	case IfStatement:
+		inspectExpr(n.Condition, f)
+		inspectStmt(n.Consequent, f)
+		inspectStmt(n.Alternate, f)
+	case ForeachStatement:
+		inspectExpr(n.Iterable, f)
+		inspectStmts(n.Body, f)
+	case WhileStatement:
+		inspectExpr(n.Condition, f)
+		inspectStmts(n.Body, f)
+	case ClassDeclarationStatement:
+		inspectStmts(n.Body, f)
+	case InterfaceDeclarationStatement:
+		inspectStmts(n.Body, f)
+	case AtomicBlockStatement:
+		inspectStmts(n.Body, f)
+
+	// expressions
+	case BinaryExpression:
+		inspectExpr(n.Left, f)
+		inspectExpr(n.Right, f)
+	case AssignmentExpression:
+		inspectExpr(n.Assignee, f)
+		inspectExpr(n.AssignedValue, f)
+		inspectExprs(n.Assignees, f)
+		inspectExprs(n.AssignedValues, f)
+	case PrefixExpression:
+		inspectExpr(n.Operand, f)
+	case MemberExpression:
+		inspectExpr(n.Member, f)
+	case CallExpression:
+		inspectExpr(n.Method, f)
+		inspectExprs(n.Arguments, f)
+	case ComputedExpression:
+		inspectExpr(n.Member, f)
+		inspectExprs(n.Indices, f)
+	case RangeExpression:
+		inspectExpr(n.Lower, f)
+		inspectExpr(n.Upper, f)
+	case FunctionExpression:
+		inspectStmts(n.Body, f)
+	case ListExpression:
+		inspectExprs(n.Constants, f)
+	case NewExpression:
+		Inspect(n.Instantiation, f)
+	}
+}
+
+func inspectStmt(s Statement, f func(Node) bool) {
+	if s != nil {
+		Inspect(s, f)
+	}
+}
+
+func inspectExpr(e Expression, f func(Node) bool) {
+	if e != nil {
+		Inspect(e, f)
+	}
+}
+
+func inspectStmts(list []Statement, f func(Node) bool) {
+	for _, s := range list {
+		inspectStmt(s, f)
+	}
+}
+
+func inspectExprs(list []Expression, f func(Node) bool) {
+	for _, e := range list {
+		inspectExpr(e, f)
+	}
+}
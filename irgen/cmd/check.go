@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"fmt"
+
+	generator "github.com/nagarajRPoojari/picasso/irgen/codegen"
+	errorsx "github.com/nagarajRPoojari/picasso/irgen/error"
+	"github.com/nagarajRPoojari/picasso/irgen/manifest"
+	"github.com/nagarajRPoojari/picasso/irgen/sema"
+	"github.com/spf13/cobra"
+)
+
+var checkCmd = &cobra.Command{
+	Use:   "check [source dir]",
+	Short: "Type checks Picasso source code without generating IR",
+	Long: `check parses and type checks every package of given project directory
+and reports all errors found, without writing any build output
+Example:
+    picasso check projectDir`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// args[0] is project directory
+		m, err := manifest.Load(args[0])
+		exitOnError(err)
+		pkgs, err := generator.ParseProject(errorsx.Diagnostics, m)
+		exitOnError(err)
+		errorsx.Diagnostics.ExitOnErrors()
+
+		sema.Check(errorsx.Diagnostics, pkgs)
+		errorsx.Diagnostics.ExitOnErrors()
+
+		// warnings, if any, and an empty document for structured formats
+		errorsx.Diagnostics.Print()
+		if errorsx.Diagnostics.Format() == errorsx.FormatText {
+			fmt.Printf("%d package(s) ok\n", len(pkgs))
+		}
+	},
+}
+
+func init() {
+	addDiagnosticsFlags(checkCmd)
+	rootCmd.AddCommand(checkCmd)
+}
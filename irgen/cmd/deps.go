@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/nagarajRPoojari/picasso/irgen/deps"
+	"github.com/nagarajRPoojari/picasso/irgen/manifest"
+	"github.com/spf13/cobra"
+)
+
+var depsCmd = &cobra.Command{
+	Use:   "deps [project dir]",
+	Short: "Vendors the dependencies of a Picasso project",
+	Long: `deps copies the packages of the dependencies declared in the manifest of
+given project directory, which defaults to the current directory, into its
+vendor directory and records their checksums in picasso.lock. Tarballs are
+downloaded into $PICASSO_CACHE, the user cache directory by default, once.
+A tarball whose checksum differs from the one of the lockfile at the same
+version is an error. The packages of a dependency are imported with its
+name, e.g. using "vendor/shared/geo/shape".
+With --verify, only checks the vendor directory against the lockfile, as
+builds do.
+Example:
+    picasso deps projectDir
+    picasso deps --verify`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		dir := "."
+		if len(args) > 0 {
+			dir = args[0]
+		}
+		m, err := manifest.Load(dir)
+		exitOnError(err)
+
+		if verify, _ := cmd.Flags().GetBool("verify"); verify {
+			exitOnError(deps.Verify(m))
+			return
+		}
+		locked, err := deps.Vendor(m)
+		exitOnError(err)
+		for _, l := range locked {
+			fmt.Printf("%s %s %s %s\n", l.Name, l.Version, l.Source, l.Sum)
+		}
+	},
+}
+
+func init() {
+	depsCmd.Flags().Bool("verify", false, "check the vendor directory against picasso.lock without vendoring")
+	rootCmd.AddCommand(depsCmd)
+}
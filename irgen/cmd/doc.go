@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	generator "github.com/nagarajRPoojari/picasso/irgen/codegen"
+	"github.com/nagarajRPoojari/picasso/irgen/doc"
+	errorsx "github.com/nagarajRPoojari/picasso/irgen/error"
+	"github.com/spf13/cobra"
+)
+
+var docCmd = &cobra.Command{
+	Use:   "doc <source dir> [-o out dir]",
+	Short: "Generates API documentation of Picasso packages",
+	Long: `doc extracts the doc comments, the // comments right above them, of the
+classes, interfaces, fields and methods of every package under given
+directory, but its build output, and writes a Markdown and an HTML page
+per package with an index of each. Internal declarations are left out.
+Packages are named like in imports, relative to the directory.
+Example:
+    picasso doc $PICASSO_INCLUDE -o docs
+    picasso doc projectDir`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		out, _ := cmd.Flags().GetString("out")
+
+		var pkgs []*doc.Package
+		err := filepath.WalkDir(args[0], func(path string, d fs.DirEntry, err error) error {
+			switch {
+			case err != nil:
+				return err
+			case d.IsDir():
+				if path != args[0] && d.Name() == generator.BUILD {
+					return filepath.SkipDir
+				}
+				return nil
+			case !strings.HasSuffix(path, ".pic"):
+				return nil
+			}
+
+			rel, err := filepath.Rel(args[0], path)
+			if err != nil {
+				return err
+			}
+			src, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			name := strings.ReplaceAll(filepath.ToSlash(strings.TrimSuffix(rel, ".pic")), "/", ".")
+			p, err := doc.Parse(errorsx.Diagnostics, name, path, src)
+			if errors.Is(err, doc.ErrSyntax) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			pkgs = append(pkgs, p)
+			return nil
+		})
+		exitOnError(err)
+		errorsx.Diagnostics.ExitOnErrors()
+
+		doc.Link(pkgs)
+		exitOnError(doc.Write(out, pkgs))
+		fmt.Printf("%d package(s) documented in %s\n", len(pkgs), out)
+	},
+}
+
+func init() {
+	docCmd.Flags().StringP("out", "o", "doc", "directory to write the documentation to")
+	addDiagnosticsFlags(docCmd)
+	rootCmd.AddCommand(docCmd)
+}
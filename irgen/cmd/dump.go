@@ -0,0 +1,182 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/nagarajRPoojari/picasso/irgen/ast"
+	generator "github.com/nagarajRPoojari/picasso/irgen/codegen"
+	"github.com/nagarajRPoojari/picasso/irgen/codegen/exports"
+	"github.com/nagarajRPoojari/picasso/irgen/codegen/pipeline"
+	errorsx "github.com/nagarajRPoojari/picasso/irgen/error"
+	"github.com/nagarajRPoojari/picasso/irgen/lexer"
+	"github.com/nagarajRPoojari/picasso/irgen/manifest"
+	"github.com/nagarajRPoojari/picasso/irgen/parser"
+	"github.com/spf13/cobra"
+)
+
+var dumpCmd = &cobra.Command{
+	Use:   "dump",
+	Short: "Prints what the compiler makes of the sources, phase by phase",
+	Long: `dump prints the output of a phase of the compiler for a source file or
+a package of the project directory given with --dir, the current directory by
+default: its tokens, its AST, its exports or its IR. Packages are named like
+in imports, with dots, e.g. geo.shape for geo/shape.pic
+Example:
+    picasso dump tokens start.pic
+    picasso dump ast --json geo.shape --dir projectDir
+    picasso dump ir start --dir projectDir --stop-after declare`,
+}
+
+var dumpTokensCmd = &cobra.Command{
+	Use:   "tokens <file or package>",
+	Short: "Prints the tokens of a source file with their positions",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		path, _ := dumpSource(cmd, args[0])
+		f, err := os.Open(path)
+		exitOnError(err)
+		defer f.Close()
+
+		tokens := lexer.TokenizeReader(errorsx.Diagnostics, path, f)
+		errorsx.Diagnostics.ExitOnErrors()
+		for _, tk := range tokens {
+			tk.Debug()
+		}
+	},
+}
+
+var dumpASTCmd = &cobra.Command{
+	Use:   "ast <file or package>",
+	Short: "Prints the AST of a source file as indented text or JSON",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		path, _ := dumpSource(cmd, args[0])
+		tree := parseSource(path)
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			printJSON(ast.JSON(tree))
+			return
+		}
+		exitOnError(ast.Fprint(os.Stdout, tree))
+	},
+}
+
+var dumpExportsCmd = &cobra.Command{
+	Use:   "exports <file or package>",
+	Short: "Prints the declarations a source file would export, without building it",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		path, pkg := dumpSource(cmd, args[0])
+		p := exports.FromAST(pkg, parseSource(path))
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			printJSON(p)
+			return
+		}
+		exitOnError(exports.Fprint(os.Stdout, p))
+	},
+}
+
+var dumpIRCmd = &cobra.Command{
+	Use:   "ir [file or package]",
+	Short: "Prints the IR of the packages of the project, without writing it",
+	Long: `ir builds the project given with --dir and prints the IR of given
+package, or of every package built, before it is written to disk. Nothing is
+written to the build directory, from which C modules are still read. With
+--stop-after, the pipeline of every package stops after given step, leaving
+its IR incomplete.
+Example:
+    picasso dump ir geo.shape --dir projectDir --stop-after declare`,
+	Args: cobra.RangeArgs(0, 1),
+	Run: func(cmd *cobra.Command, args []string) {
+		dir, _ := cmd.Flags().GetString("dir")
+		stop, _ := cmd.Flags().GetString("stop-after")
+		checked, _ := cmd.Flags().GetBool("checked-arith")
+		last, err := pipeline.ParseStep(stop)
+		exitOnError(err)
+
+		m, err := manifest.Load(dir)
+		exitOnError(err)
+		pkgs, err := generator.ParseProject(errorsx.Diagnostics, m)
+		exitOnError(err)
+		errorsx.Diagnostics.ExitOnErrors()
+
+		opts := generator.Options{CheckedArith: checked, StopAfter: last, DryRun: true}
+		g := generator.NewGeneratorFor(pkgs, m.Output, opts)
+		err = g.BuildAll()
+		errorsx.Diagnostics.Print()
+		exitOnError(err)
+
+		mods := g.Modules()
+		if len(args) > 0 {
+			_, pkg := dumpSource(cmd, args[0])
+			m, ok := mods[pkg]
+			if !ok {
+				exitOnError(fmt.Errorf("package %s is not built, as start does not import it", pkg))
+			}
+			fmt.Print(m)
+			return
+		}
+		for _, name := range slices.Sorted(maps.Keys(mods)) {
+			fmt.Printf("; package %s\n%s\n", name, mods[name])
+		}
+	},
+}
+
+// dumpSource returns the path and the package name of the source named by
+// arg, a .pic file or a package of the project directory.
+func dumpSource(cmd *cobra.Command, arg string) (path, pkg string) {
+	dir, _ := cmd.Flags().GetString("dir")
+	if filepath.Ext(arg) != ".pic" {
+		return filepath.Join(dir, filepath.FromSlash(strings.ReplaceAll(arg, ".", "/"))+".pic"), arg
+	}
+
+	pkg = strings.TrimSuffix(filepath.Base(arg), ".pic")
+	if rel, err := filepath.Rel(dir, arg); err == nil && !strings.HasPrefix(rel, "..") {
+		pkg = strings.ReplaceAll(filepath.ToSlash(strings.TrimSuffix(rel, ".pic")), "/", ".")
+	}
+	return arg, pkg
+}
+
+// parseSource parses the file at path, exiting on syntax errors.
+func parseSource(path string) ast.BlockStatement {
+	f, err := os.Open(path)
+	exitOnError(err)
+	defer f.Close()
+
+	tree := parser.ParseAllFrom(errorsx.Diagnostics, path, f)
+	errorsx.Diagnostics.ExitOnErrors()
+	return tree
+}
+
+func printJSON(v any) {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	exitOnError(enc.Encode(v))
+}
+
+func init() {
+	dumpCmd.PersistentFlags().String("dir", ".", "project directory packages are looked up in")
+	dumpASTCmd.Flags().Bool("json", false, "print the AST as JSON")
+	dumpExportsCmd.Flags().Bool("json", false, "print the exports as JSON")
+	dumpIRCmd.Flags().String("stop-after", "", "last pipeline step to run: "+strings.Join(stepNames(), ", "))
+	addCheckedArithFlag(dumpIRCmd)
+
+	for _, cmd := range []*cobra.Command{dumpTokensCmd, dumpASTCmd, dumpExportsCmd, dumpIRCmd} {
+		addDiagnosticsFlags(cmd)
+		dumpCmd.AddCommand(cmd)
+	}
+	rootCmd.AddCommand(dumpCmd)
+}
+
+func stepNames() []string {
+	names := make([]string, len(pipeline.Steps))
+	for i, step := range pipeline.Steps {
+		names[i] = string(step)
+	}
+	return names
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/nagarajRPoojari/picasso/irgen/codegen/exports"
+	"github.com/nagarajRPoojari/picasso/irgen/manifest"
+	"github.com/spf13/cobra"
+)
+
+var exportsCmd = &cobra.Command{
+	Use:   "exports [package] [source dir]",
+	Short: "Prints the declarations a built package exports to its importers",
+	Long: `exports prints the .exports file written for given package by the last
+build of the project directory, which defaults to the current directory, in
+the output directory of its manifest.
+Packages are named like in imports, with dots, e.g. picasso.os
+Example:
+    picasso exports picasso.os projectDir
+    picasso exports --json start`,
+	Args: cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		dir := "."
+		if len(args) > 1 {
+			dir = args[1]
+		}
+		m, err := manifest.Load(dir)
+		exitOnError(err)
+		p, err := exports.Read(exports.Path(m.Output, args[0]))
+		exitOnError(err)
+
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			exitOnError(enc.Encode(p))
+			return
+		}
+		exitOnError(exports.Fprint(os.Stdout, p))
+	},
+}
+
+func init() {
+	exportsCmd.Flags().Bool("json", false, "print the .exports file as JSON")
+	rootCmd.AddCommand(exportsCmd)
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	generator "github.com/nagarajRPoojari/picasso/irgen/codegen"
+	errorsx "github.com/nagarajRPoojari/picasso/irgen/error"
+	"github.com/nagarajRPoojari/picasso/irgen/format"
+	"github.com/spf13/cobra"
+)
+
+var fmtCmd = &cobra.Command{
+	Use:   "fmt [-w] [-d] <paths>",
+	Short: "Formats Picasso source code in the canonical style",
+	Long: `fmt formats the given .pic files, and those under the given directories
+but their build output, and prints the result. Comments are kept.
+Files with syntax errors are reported and left alone.
+Example:
+    picasso fmt -w projectDir
+    picasso fmt -d start.pic`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		write, _ := cmd.Flags().GetBool("write")
+		diff, _ := cmd.Flags().GetBool("diff")
+
+		failed := false
+		for _, arg := range args {
+			err := filepath.WalkDir(arg, func(path string, d fs.DirEntry, err error) error {
+				switch {
+				case err != nil:
+					return err
+				case d.IsDir():
+					if path != arg && d.Name() == generator.BUILD {
+						return filepath.SkipDir
+					}
+					return nil
+				case path != arg && !strings.HasSuffix(path, ".pic"):
+					return nil
+				}
+				if err := formatFile(path, write, diff); err != nil {
+					if !errors.Is(err, format.ErrSyntax) {
+						fmt.Fprintln(os.Stderr, "error:", err)
+					}
+					failed = true
+				}
+				return nil
+			})
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "error:", err)
+				failed = true
+			}
+		}
+
+		errorsx.Diagnostics.Print()
+		if failed {
+			os.Exit(1)
+		}
+	},
+}
+
+// formatFile formats the file at path, printing the result unless write or
+// diff says otherwise.
+func formatFile(path string, write, diff bool) error {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	res, err := format.Source(errorsx.Diagnostics, path, src)
+	if err != nil {
+		return err
+	}
+
+	if diff {
+		os.Stdout.Write(format.Diff(path, src, res))
+	}
+	if write && !bytes.Equal(src, res) {
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(path, res, info.Mode().Perm())
+	}
+	if !write && !diff {
+		os.Stdout.Write(res)
+	}
+	return nil
+}
+
+func init() {
+	fmtCmd.Flags().BoolP("write", "w", false, "write the result to the file instead of printing it")
+	fmtCmd.Flags().BoolP("diff", "d", false, "print a diff of the changes instead of the result")
+	addDiagnosticsFlags(fmtCmd)
+	rootCmd.AddCommand(fmtCmd)
+}
@@ -1,7 +1,10 @@
 package cmd
 
 import (
+	"runtime"
+
 	generator "github.com/nagarajRPoojari/picasso/irgen/codegen"
+	errorsx "github.com/nagarajRPoojari/picasso/irgen/error"
 	"github.com/spf13/cobra"
 )
 
@@ -14,11 +17,21 @@ Example:
 	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		// args[0] is project directory
-		c := generator.NewGenerator(args[0])
-		c.BuildAll()
+		checked, _ := cmd.Flags().GetBool("checked-arith")
+		jobs, _ := cmd.Flags().GetInt("jobs")
+		c, err := generator.NewGenerator(args[0], generator.Options{CheckedArith: checked, Jobs: jobs})
+		if err == nil {
+			err = c.BuildAll()
+		}
+		// structured formats report success too
+		errorsx.Diagnostics.Print()
+		exitOnError(err)
 	},
 }
 
 func init() {
+	addCheckedArithFlag(genCmd)
+	genCmd.Flags().IntP("jobs", "j", runtime.NumCPU(), "number of packages built at once")
+	addDiagnosticsFlags(genCmd)
 	rootCmd.AddCommand(genCmd)
 }
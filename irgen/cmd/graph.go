@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	generator "github.com/nagarajRPoojari/picasso/irgen/codegen"
+	"github.com/nagarajRPoojari/picasso/irgen/codegen/tools"
+	errorsx "github.com/nagarajRPoojari/picasso/irgen/error"
+	"github.com/nagarajRPoojari/picasso/irgen/manifest"
+	"github.com/spf13/cobra"
+)
+
+var graphCmd = &cobra.Command{
+	Use:   "graph <project dir>",
+	Short: "Prints the import graph of a Picasso project",
+	Long: `graph prints the packages of given project directory and their imports,
+with the builtin and C modules they import as nodes of their own kinds, as a
+Graphviz digraph or as JSON. It also reports the packages the entry package
+does not import, the packages with the largest transitive fan-in and an
+import cycle, if any.
+Example:
+    picasso graph projectDir | dot -Tsvg > imports.svg
+    picasso graph projectDir --format json --top 10`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		format, _ := cmd.Flags().GetString("format")
+		top, _ := cmd.Flags().GetInt("top")
+
+		m, err := manifest.Load(args[0])
+		exitOnError(err)
+		pkgs, err := generator.ParseProject(errorsx.Diagnostics, m)
+		exitOnError(err)
+		errorsx.Diagnostics.ExitOnErrors()
+
+		r := tools.NewImportGraph(pkgs).Report(m.Entry, top)
+		switch format {
+		case "dot":
+			exitOnError(r.WriteDOT(os.Stdout))
+		case "json":
+			exitOnError(r.WriteJSON(os.Stdout))
+		default:
+			exitOnError(fmt.Errorf("unknown graph format %q, want dot or json", format))
+		}
+	},
+}
+
+func init() {
+	graphCmd.Flags().String("format", "dot", "output format: dot or json")
+	graphCmd.Flags().Int("top", 5, "number of packages reported by fan-in")
+	addDiagnosticsFlags(graphCmd)
+	rootCmd.AddCommand(graphCmd)
+}
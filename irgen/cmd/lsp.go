@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/nagarajRPoojari/picasso/irgen/lsp"
+	"github.com/spf13/cobra"
+)
+
+var lspCmd = &cobra.Command{
+	Use:   "lsp",
+	Short: "Runs a language server for Picasso over stdin and stdout",
+	Long: `lsp runs a Language Server Protocol server reading requests from stdin
+and writing responses to stdout, for editors to start. It reports parse and
+type errors as the sources change, and supports go to definition, hover
+and completion after a dot. Packages are looked up in the roots of the
+manifest of the project, then in $PICASSO_INCLUDE.
+Example:
+    picasso lsp`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := lsp.NewServer(os.Getenv("PICASSO_INCLUDE")).Serve(os.Stdin, os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, "error:", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(lspCmd)
+}
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/nagarajRPoojari/picasso/irgen/manifest"
+	"github.com/spf13/cobra"
+)
+
+var manifestCmd = &cobra.Command{
+	Use:   "manifest [project dir]",
+	Short: "Prints the manifest of a Picasso project as the compiler reads it",
+	Long: `manifest reads the picasso.toml or picasso.ini of given project directory,
+which defaults to the current directory, and prints it with its defaults
+filled in and its paths relative to the working directory, or the values of
+a single key, one per line, with --get. A project without manifest is laid
+out in the legacy way if $PICASSO_INCLUDE is set. Exits with status 1 if the
+manifest is missing or invalid.
+Example:
+    picasso manifest projectDir
+    picasso manifest projectDir --get output`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		dir := "."
+		if len(args) > 0 {
+			dir = args[0]
+		}
+		m, err := manifest.Load(dir)
+		exitOnError(err)
+
+		fields := []struct {
+			key    string
+			values []string
+			list   bool
+		}{
+			{"module", []string{m.Module}, false},
+			{"entry", []string{m.Entry}, false},
+			{"sources", m.Sources, true},
+			{"include", m.Include, true},
+			{"ffi", m.FFI, true},
+			{"output", []string{m.Output}, false},
+		}
+
+		if key, _ := cmd.Flags().GetString("get"); key != "" {
+			for _, f := range fields {
+				if f.key == key {
+					for _, v := range f.values {
+						fmt.Println(v)
+					}
+					return
+				}
+			}
+			exitOnError(fmt.Errorf("unknown manifest key %s", key))
+		}
+
+		if m.Path == "" {
+			fmt.Printf("# no manifest in %s, legacy layout\n", dir)
+		} else {
+			fmt.Printf("# %s\n", m.Path)
+		}
+		for _, f := range fields {
+			quoted := make([]string, len(f.values))
+			for i, v := range f.values {
+				quoted[i] = strconv.Quote(v)
+			}
+			if f.list {
+				fmt.Printf("%s = [%s]\n", f.key, strings.Join(quoted, ", "))
+			} else {
+				fmt.Printf("%s = %s\n", f.key, quoted[0])
+			}
+		}
+		for _, d := range m.Deps {
+			fmt.Printf("\n[dependencies.%s]\n", d.Name)
+			if d.URL != "" {
+				fmt.Printf("url = %q\n", d.URL)
+			} else {
+				fmt.Printf("path = %q\n", d.Path)
+			}
+			if d.Version != "" {
+				fmt.Printf("version = %q\n", d.Version)
+			}
+		}
+	},
+}
+
+func init() {
+	manifestCmd.Flags().String("get", "", "print the values of this key only: module, entry, sources, include, ffi or output")
+	rootCmd.AddCommand(manifestCmd)
+}
@@ -1,8 +1,13 @@
 package cmd
 
 import (
+	"errors"
+	"fmt"
 	"os"
+	"strings"
 
+	generator "github.com/nagarajRPoojari/picasso/irgen/codegen"
+	errorsx "github.com/nagarajRPoojari/picasso/irgen/error"
 	"github.com/spf13/cobra"
 )
 
@@ -23,3 +28,47 @@ func Execute() {
 func init() {
 	rootCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
 }
+
+// addDiagnosticsFlags adds --diagnostics-format and the -W warning flags to
+// a command reporting compile errors.
+func addDiagnosticsFlags(cmd *cobra.Command) {
+	cmd.Flags().String("diagnostics-format", string(errorsx.FormatText), "output format of errors: text, json or sarif")
+	cmd.Flags().StringArrayP("warn", "W", nil, "configure warnings, e.g. -Wall, -Wno-unused-import, -Werror=shadow (known: "+
+		strings.Join(errorsx.WarningNames(), ", ")+")")
+	cmd.PreRunE = setupDiagnostics
+}
+
+// addCheckedArithFlag adds --checked-arith to a command generating code.
+func addCheckedArithFlag(cmd *cobra.Command) {
+	cmd.Flags().Bool("checked-arith", false, "trap on integer overflow and division by zero; "+
+		"arithmetic is done on 64-bit integers, so narrower results only trap when stored back")
+}
+
+func setupDiagnostics(cmd *cobra.Command, _ []string) error {
+	value, _ := cmd.Flags().GetString("diagnostics-format")
+	format, err := errorsx.ParseFormat(value)
+	if err != nil {
+		return err
+	}
+	errorsx.Diagnostics.SetFormat(format)
+
+	flags, _ := cmd.Flags().GetStringArray("warn")
+	for _, flag := range flags {
+		if err := errorsx.Diagnostics.SetWarningFlag(flag); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// exitOnError ends a failed command with status 1. Compile errors are
+// printed with the diagnostics, any other error is printed here.
+func exitOnError(err error) {
+	if err == nil {
+		return
+	}
+	if !errors.Is(err, generator.ErrCompile) {
+		fmt.Fprintln(os.Stderr, "error:", err)
+	}
+	os.Exit(1)
+}
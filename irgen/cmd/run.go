@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os"
+
+	generator "github.com/nagarajRPoojari/picasso/irgen/codegen"
+	errorsx "github.com/nagarajRPoojari/picasso/irgen/error"
+	"github.com/nagarajRPoojari/picasso/irgen/script"
+	"github.com/spf13/cobra"
+)
+
+var runCmd = &cobra.Command{
+	Use:   "run <file.pic> [args...]",
+	Short: "Builds and runs a single Picasso file",
+	Long: `run compiles a standalone .pic file, which defines fn start and imports
+builtin packages only, in a temporary directory, links it with the picasso
+CLI, $PICASSO if set, and runs it. The arguments after the file are passed
+to start(args), after the path of the file, and run exits with the exit
+status of the program.
+Example:
+    picasso run hello.pic
+    picasso run --checked-arith wc.pic -l input.txt`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		checked, _ := cmd.Flags().GetBool("checked-arith")
+		work, _ := cmd.Flags().GetString("work")
+
+		code, err := script.Run(context.Background(), args[0], args[1:], script.Options{
+			Generator: generator.Options{CheckedArith: checked},
+			Work:      work,
+		})
+		if errors.Is(err, generator.ErrCompile) {
+			errorsx.Diagnostics.ExitOnErrors()
+		}
+		exitOnError(err)
+		os.Exit(code)
+	},
+}
+
+func init() {
+	// flags after the file are the program's
+	runCmd.Flags().SetInterspersed(false)
+	addCheckedArithFlag(runCmd)
+	runCmd.Flags().String("work", "", "directory to build the file in, kept afterwards; a temporary one by default")
+	addDiagnosticsFlags(runCmd)
+	rootCmd.AddCommand(runCmd)
+}
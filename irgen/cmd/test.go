@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	errorsx "github.com/nagarajRPoojari/picasso/irgen/error"
+	"github.com/nagarajRPoojari/picasso/irgen/testrunner"
+	"github.com/spf13/cobra"
+)
+
+var testCmd = &cobra.Command{
+	Use:   "test <project dir>",
+	Short: "Runs the tests of a Picasso project",
+	Long: `test runs the test functions of the *_test.pic files of given project
+directory, declared test fn name() { ... } and checking results with
+builtin/assert. It builds a runner with picasso build, $PICASSO if set, and
+runs every test in a process of its own. A test fails if an assertion
+fails, it crashes or it exceeds the timeout. Tests are named by package and
+function, e.g. geo.shape_test.area. Exits with status 1 if any test fails.
+Example:
+    picasso test projectDir
+    picasso test projectDir --run 'shape_test\.' --format junit -o report.xml`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		run, _ := cmd.Flags().GetString("run")
+		format, _ := cmd.Flags().GetString("format")
+		out, _ := cmd.Flags().GetString("out")
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+		work, _ := cmd.Flags().GetString("work")
+
+		r := &testrunner.Runner{Timeout: timeout, Work: work}
+		if run != "" {
+			re, err := regexp.Compile(run)
+			exitOnError(err)
+			r.Filter = re
+		}
+
+		results, err := r.Run(context.Background(), args[0])
+		if errors.Is(err, testrunner.ErrSyntax) {
+			errorsx.Diagnostics.ExitOnErrors()
+		}
+		exitOnError(err)
+		if len(results) == 0 {
+			fmt.Fprintln(os.Stderr, "no tests to run")
+			return
+		}
+
+		var w io.WriteCloser = os.Stdout
+		if out != "" {
+			w, err = os.Create(out)
+			exitOnError(err)
+		}
+		exitOnError(testrunner.Write(w, format, results))
+		if out != "" {
+			exitOnError(w.Close())
+		}
+		if testrunner.Failed(results) > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	testCmd.Flags().String("run", "", "run only the tests whose full name matches this regular expression")
+	testCmd.Flags().String("format", testrunner.FormatText, "report format: "+strings.Join(testrunner.Formats, ", "))
+	testCmd.Flags().StringP("out", "o", "", "file to write the report to instead of stdout")
+	testCmd.Flags().Duration("timeout", 0, "time limit of each test, e.g. 30s; 0 means none")
+	testCmd.Flags().String("work", "", "directory to generate the runner in, kept afterwards; a temporary one by default")
+	addDiagnosticsFlags(testCmd)
+	rootCmd.AddCommand(testCmd)
+}
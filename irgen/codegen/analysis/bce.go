@@ -0,0 +1,272 @@
+// Package analysis holds AST-level analyses consulted by the codegen handlers.
+package analysis
+
+import (
+	"math"
+
+	"github.com/nagarajRPoojari/picasso/irgen/ast"
+)
+
+const (
+	ARRAY_MODULE = "array"
+	ARRAY_LEN    = "len"
+	ARRAY_SHAPE  = "shape"
+	ARRAY_CREATE = "create"
+)
+
+// LoopBound records what is statically known about a foreach index variable.
+// One entry is pushed per foreach, even when nothing is proven, so that an
+// inner loop reusing a name shadows the facts of an outer one.
+type LoopBound struct {
+	Index string
+
+	// Proven is false when the range could not be analysed.
+	Proven bool
+	// Array is the array whose length bounds the loop, "" if none.
+	Array string
+	// Upper is the constant exclusive upper bound, -1 if not constant.
+	Upper int64
+}
+
+// IsArrayLib reports whether alias refers to the builtin array module.
+type IsArrayLib func(alias string) bool
+
+// ForeachBound analyses 'foreach i in lo..hi' and reports whether i stays
+// within [0, hi) for the whole body. lo must be a non-negative constant and
+// hi either a constant or array.len(a) / array.shape(a)[0] for a local a.
+// Neither i nor a may be redeclared or reassigned inside the body. Array
+// lengths never shrink, so appends inside the body keep the proof valid.
+func ForeachBound(st ast.ForeachStatement, isArrayLib IsArrayLib) LoopBound {
+	b := LoopBound{Index: st.Value, Upper: -1}
+
+	r, ok := st.Iterable.(ast.RangeExpression)
+	if !ok {
+		return b
+	}
+	if lo, ok := constIndex(r.Lower); !ok || lo < 0 {
+		return b
+	}
+
+	if hi, ok := constIndex(r.Upper); ok {
+		b.Upper = hi
+	} else if arr, ok := lengthOf(r.Upper, isArrayLib); ok {
+		b.Array = arr
+	} else {
+		return b
+	}
+
+	if Rebinds(st.Body, st.Value) || (b.Array != "" && Rebinds(st.Body, b.Array)) {
+		return b
+	}
+
+	b.Proven = true
+	return b
+}
+
+// FixedLengths returns array variables of a function body created with a
+// constant first dimension, e.g. 'say a: []int = array.create(int, 8)',
+// mapped to that dimension. Variables declared more than once, reassigned,
+// or shadowing a parameter are left out.
+func FixedLengths(body []ast.Statement, params []ast.Parameter, isArrayLib IsArrayLib) map[string]int64 {
+	decls := make(map[string]int)
+	lens := make(map[string]int64)
+
+	for _, s := range body {
+		ast.Inspect(s, func(n ast.Node) bool {
+			d, ok := n.(ast.VariableDeclarationStatement)
+			if !ok {
+				return true
+			}
+			for _, id := range d.Identifiers {
+				decls[id]++
+			}
+			if d.Identifier == "" {
+				return true
+			}
+			decls[d.Identifier]++
+			if l, ok := createdLength(d.AssignedValue, isArrayLib); ok {
+				lens[d.Identifier] = l
+			}
+			return true
+		})
+	}
+
+	for name := range lens {
+		if decls[name] != 1 || isParam(params, name) || reassigns(body, name) {
+			delete(lens, name)
+		}
+	}
+	return lens
+}
+
+// InBounds reports whether idx, used as the first index into the local array
+// named array, is proven to lie within its first dimension.
+func InBounds(bounds []LoopBound, fixed map[string]int64, array string, idx ast.Expression) bool {
+	if v, ok := constIndex(idx); ok {
+		l, ok := fixed[array]
+		return ok && v >= 0 && v < l
+	}
+
+	sym, ok := idx.(ast.SymbolExpression)
+	if !ok {
+		return false
+	}
+
+	// innermost loop binding the name wins
+	for i := len(bounds) - 1; i >= 0; i-- {
+		b := bounds[i]
+		if b.Index != sym.Value {
+			continue
+		}
+		if !b.Proven {
+			return false
+		}
+		if b.Array == array {
+			return true
+		}
+		l, ok := fixed[array]
+		return ok && b.Upper >= 0 && b.Upper <= l
+	}
+	return false
+}
+
+// Rebinds reports whether name is declared or assigned anywhere in body,
+// including nested blocks, loops and function literals.
+func Rebinds(body []ast.Statement, name string) bool {
+	found := false
+	for _, s := range body {
+		ast.Inspect(s, func(n ast.Node) bool {
+			switch x := n.(type) {
+			case ast.VariableDeclarationStatement:
+				if x.Identifier == name || contains(x.Identifiers, name) {
+					found = true
+				}
+			case ast.ForeachStatement:
+				if x.Value == name {
+					found = true
+				}
+			case ast.FunctionExpression:
+				if isParam(x.Parameters, name) {
+					found = true
+				}
+			case ast.AssignmentExpression:
+				if assignsTo(x, name) {
+					found = true
+				}
+			}
+			return !found
+		})
+	}
+	return found
+}
+
+func reassigns(body []ast.Statement, name string) bool {
+	found := false
+	for _, s := range body {
+		ast.Inspect(s, func(n ast.Node) bool {
+			switch x := n.(type) {
+			case ast.ForeachStatement:
+				if x.Value == name {
+					found = true
+				}
+			case ast.FunctionExpression:
+				if isParam(x.Parameters, name) {
+					found = true
+				}
+			case ast.AssignmentExpression:
+				if assignsTo(x, name) {
+					found = true
+				}
+			}
+			return !found
+		})
+	}
+	return found
+}
+
+func assignsTo(a ast.AssignmentExpression, name string) bool {
+	if s, ok := a.Assignee.(ast.SymbolExpression); ok && s.Value == name {
+		return true
+	}
+	for _, e := range a.Assignees {
+		if s, ok := e.(ast.SymbolExpression); ok && s.Value == name {
+			return true
+		}
+	}
+	return false
+}
+
+// lengthOf matches array.len(a) and array.shape(a)[0], returning a.
+func lengthOf(e ast.Expression, isArrayLib IsArrayLib) (string, bool) {
+	if c, ok := e.(ast.ComputedExpression); ok {
+		if len(c.Indices) != 1 {
+			return "", false
+		}
+		if v, ok := constIndex(c.Indices[0]); !ok || v != 0 {
+			return "", false
+		}
+		return arrayCallArg(c.Member, ARRAY_SHAPE, isArrayLib)
+	}
+	return arrayCallArg(e, ARRAY_LEN, isArrayLib)
+}
+
+func arrayCallArg(e ast.Expression, fn string, isArrayLib IsArrayLib) (string, bool) {
+	call, ok := e.(ast.CallExpression)
+	if !ok || !isArrayCall(call, fn, isArrayLib) || len(call.Arguments) != 1 {
+		return "", false
+	}
+	sym, ok := call.Arguments[0].(ast.SymbolExpression)
+	if !ok {
+		return "", false
+	}
+	return sym.Value, true
+}
+
+// createdLength matches array.create(T, n, ...) with a constant n > 0.
+func createdLength(e ast.Expression, isArrayLib IsArrayLib) (int64, bool) {
+	call, ok := e.(ast.CallExpression)
+	if !ok || !isArrayCall(call, ARRAY_CREATE, isArrayLib) || len(call.Arguments) < 2 {
+		return 0, false
+	}
+	n, ok := constIndex(call.Arguments[1])
+	if !ok || n <= 0 {
+		return 0, false
+	}
+	return n, true
+}
+
+func isArrayCall(call ast.CallExpression, fn string, isArrayLib IsArrayLib) bool {
+	m, ok := call.Method.(ast.MemberExpression)
+	if !ok || m.Property != fn {
+		return false
+	}
+	mod, ok := m.Member.(ast.SymbolExpression)
+	return ok && isArrayLib(mod.Value)
+}
+
+// constIndex returns the value of an integral numeric literal.
+func constIndex(e ast.Expression) (int64, bool) {
+	n, ok := e.(ast.NumberExpression)
+	if !ok || n.Value != math.Trunc(n.Value) || math.Abs(n.Value) > math.MaxInt32 {
+		return 0, false
+	}
+	return int64(n.Value), true
+}
+
+func isParam(params []ast.Parameter, name string) bool {
+	for _, p := range params {
+		if p.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -10,6 +10,8 @@ Notes:
 package c
 
 import (
+	"sync"
+
 	"github.com/llir/llvm/ir"
 	"github.com/llir/llvm/ir/types"
 )
@@ -44,6 +46,8 @@ const (
 
 	FUNC_GET_SUBARRAY = "__public__get_subarray"
 	FUNC_SET_SUBARRAY = "__public__set_subarray"
+	FUNC_SLICE_ARRAY  = "__public__slice_array"
+	FUNC_COPY_ARRAY   = "__public__copy_array"
 
 	FUNC_EXTEND_ARRAY     = "__public__extend_array"
 	FUNC_STRING_SUBSTRING = "__public__strings_substring"
@@ -89,8 +93,9 @@ type Interface struct {
 	Constants map[string]*ir.Global
 }
 
-// Instance is a global singleton providing access to the C runtime interface.
-var Instance *Interface
+// interfaces holds the Interface of every module being generated, keyed by
+// *ir.Module, so that several modules can be generated at once.
+var interfaces sync.Map
 
 // NewInterface initializes a new runtime registry for the given LLVM module.
 // It populates the internal maps by registering all required external
@@ -104,6 +109,22 @@ func InitInterface(mod *ir.Module) *Interface {
 	t.registerTypes(mod)
 	t.registerFuncs(mod)
 
-	Instance = t
-	return Instance
+	interfaces.Store(mod, t)
+	return t
+}
+
+// Of returns the interface registered for mod by InitInterface.
+func Of(mod *ir.Module) *Interface {
+	t, _ := interfaces.Load(mod)
+	return t.(*Interface)
+}
+
+// For returns the interface of the module containing block.
+func For(block *ir.Block) *Interface {
+	return Of(block.Parent.Parent)
+}
+
+// Release forgets the interface of mod once it has been generated.
+func Release(mod *ir.Module) {
+	interfaces.Delete(mod)
 }
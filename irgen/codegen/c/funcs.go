@@ -60,6 +60,16 @@ func (t *Interface) initRuntime(mod *ir.Module) {
 		ir.NewParam("index", types.I64),
 		ir.NewParam("sub_arr", types.NewPointer(t.Types[TYPE_ARRAY])))
 
+	// @slice_array
+	t.Funcs[FUNC_SLICE_ARRAY] = mod.NewFunc(FUNC_SLICE_ARRAY, types.NewPointer(t.Types[TYPE_ARRAY]),
+		ir.NewParam("arr", types.NewPointer(t.Types[TYPE_ARRAY])),
+		ir.NewParam("lo", types.I64),
+		ir.NewParam("hi", types.I64))
+
+	// @copy_array
+	t.Funcs[FUNC_COPY_ARRAY] = mod.NewFunc(FUNC_COPY_ARRAY, types.NewPointer(t.Types[TYPE_ARRAY]),
+		ir.NewParam("arr", types.NewPointer(t.Types[TYPE_ARRAY])))
+
 	// @string_alloc
 	t.Funcs[FUNC_STRING_ALLOC] = mod.NewFunc(FUNC_STRING_ALLOC, types.NewPointer(t.Types[TYPE_STRING]), ir.NewParam("", types.I32))
 	t.Funcs[FUNC_STRING_SUBSTRING] = mod.NewFunc(FUNC_STRING_SUBSTRING, types.NewPointer(t.Types[TYPE_STRING]), ir.NewParam("", types.NewPointer(t.Types[TYPE_STRING])), ir.NewParam("", types.I64), ir.NewParam("", types.I64))
@@ -25,6 +25,7 @@ func (t *Interface) initArrayTypes(_ *ir.Module) {
 		types.I64,                   // rank
 		types.I64,                   // capacity
 		types.I32,                   // elesize
+		types.NewPointer(types.I8),  // base (owning array for slice views)
 	)
 
 	t.Types[TYPE_STRING] = types.NewStruct(
@@ -1,25 +1,37 @@
 package generator
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io/fs"
+	"maps"
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
-	"time"
+	"sync"
 
 	"github.com/llir/llvm/asm"
 	"github.com/llir/llvm/ir"
 	"github.com/nagarajRPoojari/picasso/irgen/ast"
+	"github.com/nagarajRPoojari/picasso/irgen/codegen/c"
 	errorutils "github.com/nagarajRPoojari/picasso/irgen/codegen/error"
+	"github.com/nagarajRPoojari/picasso/irgen/codegen/exports"
 	"github.com/nagarajRPoojari/picasso/irgen/codegen/handlers/state"
 	"github.com/nagarajRPoojari/picasso/irgen/codegen/libs"
 	function "github.com/nagarajRPoojari/picasso/irgen/codegen/libs/func"
+	rterr "github.com/nagarajRPoojari/picasso/irgen/codegen/libs/private/runtime"
 	"github.com/nagarajRPoojari/picasso/irgen/codegen/pipeline"
 	"github.com/nagarajRPoojari/picasso/irgen/codegen/tools"
-	"github.com/nagarajRPoojari/picasso/irgen/codegen/utils"
+	"github.com/nagarajRPoojari/picasso/irgen/deps"
+	errorsx "github.com/nagarajRPoojari/picasso/irgen/error"
+	"github.com/nagarajRPoojari/picasso/irgen/manifest"
 	"github.com/nagarajRPoojari/picasso/irgen/parser"
+	"github.com/nagarajRPoojari/picasso/irgen/sema"
 	"github.com/nagarajRPoojari/picasso/irgen/utils/logger"
 )
 
@@ -34,121 +46,252 @@ type generator struct {
 
 	ffiModules map[string]*ir.Module
 
-	// outputDir is directory where IR & info files will be dumped.
+	// outputDir is directory where IR & info files will be dumped. Nothing
+	// is written if empty.
 	outputDir string
 
-	// cached pkgs
-	cachedPkgs map[string]struct{}
+	// cache tells which packages were rebuilt, nil when not consulted.
+	cache *tools.BuildCache
 
-	// builtPkgs tracks packages that are completely built and compiled
-	// key should be fully qualified name of package. e.g, os.io
-	builtPkgs map[string]struct{}
+	// mu guards llvms, which packages built concurrently add to.
+	mu sync.Mutex
 
-	// visitingPkgs tracks packages currently in the recursion stack (for cycle detection)
-	// key should be fully qualified name of package. e.g, os.io
-	visitingPkgs map[string]struct{}
+	// entry is the package defining fn start, built with all it imports.
+	entry string
+
+	// info is the result of the semantic pass over all packages.
+	info *sema.Info
+
+	// diags receives the errors of every phase.
+	diags *errorsx.Collector
+
+	opts Options
+	ctx  context.Context
 }
 
-func NewGenerator(projectDir string) *generator {
-	outputDir := filepath.Join(projectDir, BUILD)
+// ErrCompile is returned when compilation fails with errors recorded in
+// the collector of the build, see Options.Diagnostics.
+var ErrCompile = errors.New("compilation failed")
 
-	modifiedPkgs, allPkgs := LoadPackages(projectDir)
-	return &generator{
-		packages:     modifiedPkgs,
-		allPkgs:      allPkgs,
-		llvms:        make(map[string]*LLVM),
-		ffiModules:   make(map[string]*ir.Module),
-		outputDir:    outputDir,
-		builtPkgs:    make(map[string]struct{}),
-		visitingPkgs: make(map[string]struct{}),
+// buildError carries a failure that is not a compile error, e.g. a module
+// that cannot be read, out of BuildAll.
+type buildError struct {
+	err error
+}
+
+func (t *generator) fail(err error) {
+	panic(buildError{err: err})
+}
+
+// Options controls code generation behaviour across all packages.
+type Options struct {
+	// CheckedArith emits overflow and division-by-zero traps for integer arithmetic.
+	CheckedArith bool
+	// Jobs is the number of packages built at once, at least 1.
+	Jobs int
+	// StopAfter ends the pipeline of every package after given step,
+	// leaving its IR incomplete; "" runs all steps.
+	StopAfter pipeline.Step
+	// DryRun builds IR in memory only: nothing is written to the output
+	// directory, which C modules are still read from.
+	DryRun bool
+	// Diagnostics receives the errors and warnings of the build; nil for
+	// errorsx.Diagnostics, the collector of the command line tools.
+	Diagnostics *errorsx.Collector
+}
+
+func (o Options) diagnostics() *errorsx.Collector {
+	if o.Diagnostics == nil {
+		return errorsx.Diagnostics
 	}
+	return o.Diagnostics
 }
 
-func (t *generator) BuildAll() {
-	// main file is expected to be named as start.pic.
-	// @todo: main.pic would be a good choise, why did I even replace
-	// all 'main' with 'start'?
-	t.buildPackage(state.PackageEntry{Name: MAIN, Alias: MAIN})
+// Hash returns the hash of the options changing the IR generated, which the
+// build cache is keyed by. Options that don't, e.g. Jobs, are left out.
+func (o Options) Hash() string {
+	sum := sha256.Sum256(fmt.Appendf(nil, "checked-arith=%t\nstop-after=%s\n", o.CheckedArith, o.StopAfter))
+	return hex.EncodeToString(sum[:])
+}
 
-	// for all modified packages, generate .exports
-	for pkgName := range t.packages {
-		t.generateExports(pkgName)
+// NewGenerator loads the project at projectDir as its manifest describes,
+// parsing the packages modified since the last build.
+func NewGenerator(projectDir string, opts Options) (*generator, error) {
+	m, err := manifest.Load(projectDir)
+	if err != nil {
+		return nil, err
 	}
+	return NewGeneratorFromManifest(m, opts)
 }
 
-func (t *generator) generateExports(pkgName string) {
-	outputPath := filepath.Join(t.outputDir, fmt.Sprintf("%s.exports", pkgName))
-
-	// clear definitions in AST
-	for _, stmt := range t.packages[pkgName].Body {
-		if cls, ok := stmt.(ast.ClassDeclarationStatement); ok {
-			for i, stmt := range cls.Body {
-				if funcStmt, ok := stmt.(ast.FunctionDefinitionStatement); ok {
-					cls.Body[i] = ast.FunctionDefinitionStatement{
-						Parameters: funcStmt.Parameters,
-						Name:       funcStmt.Name,
-						Body:       []ast.Statement{},
-						Hash:       funcStmt.Hash,
-						ReturnType: funcStmt.ReturnType,
-						IsStatic:   funcStmt.IsStatic,
-					}
-				}
-			}
-		}
+// NewGeneratorFromManifest is NewGenerator for the project m describes,
+// building its entry package into its output directory.
+func NewGeneratorFromManifest(m *manifest.Manifest, opts Options) (*generator, error) {
+	modifiedPkgs, allPkgs, cache, err := LoadPackages(m, opts)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := allPkgs[m.Entry]; !ok {
+		return nil, fmt.Errorf("entry package %s not found in %s", m.Entry, strings.Join(m.Sources, ", "))
 	}
+	g := newGenerator(modifiedPkgs, allPkgs, m.Output, opts)
+	g.cache = cache
+	g.entry = m.Entry
+	return g, nil
+}
 
-	utils.SaveToFile(outputPath, t.packages[pkgName])
+// NewGeneratorFor returns a generator building the given packages, keyed by
+// package name, into outputDir. Every package is built; no build cache is
+// consulted.
+func NewGeneratorFor(pkgs map[string]ast.BlockStatement, outputDir string, opts Options) *generator {
+	allPkgs := make(map[string]struct{}, len(pkgs))
+	for name := range pkgs {
+		allPkgs[name] = struct{}{}
+	}
+	return newGenerator(pkgs, allPkgs, outputDir, opts)
 }
 
-// buildPackage implements Post-Order Traversal (Bottom-Up) to ensure global state integrity.
-// LLVM maintains some global state unique to that module, traversing bottom up avoids
-// such global val overriding.
-// maintaining global is a design limitation that need to be fixed in future @todo.
-func (t *generator) buildPackage(pkg state.PackageEntry) {
-	pkgName := pkg.Name
-	if _, ok := t.builtPkgs[pkgName]; ok {
-		return
+func newGenerator(pkgs map[string]ast.BlockStatement, allPkgs map[string]struct{}, outputDir string, opts Options) *generator {
+	return &generator{
+		packages:   pkgs,
+		allPkgs:    allPkgs,
+		llvms:      make(map[string]*LLVM),
+		ffiModules: make(map[string]*ir.Module),
+		outputDir:  outputDir,
+		entry:      MAIN,
+		diags:      opts.diagnostics(),
+		opts:       opts,
+		ctx:        context.Background(),
 	}
+}
 
-	if _, ok := t.visitingPkgs[pkgName]; ok {
-		logger.Fatal(pkgName, "%s", fmt.Sprintf("Cyclic dependency detected involving package: %s", pkgName))
+// BuildAll generates IR for the start package and the packages it imports.
+// Compile errors are recorded in the collector of the build and reported
+// as ErrCompile.
+func (t *generator) BuildAll() error {
+	return t.BuildAllContext(context.Background())
+}
+
+// BuildAllContext is BuildAll, stopping between packages once ctx is done.
+func (t *generator) BuildAllContext(ctx context.Context) (err error) {
+	t.ctx = ctx
+
+	// errors are collected as compilation goes on; an error that could not be
+	// recovered from locally ends the build, reporting everything seen so far.
+	defer func() {
+		if r := recover(); r != nil {
+			if b, ok := r.(buildError); ok {
+				err = b.err
+				return
+			}
+			if !t.diags.Caught(r) && t.diags.Errors() == 0 {
+				panic(r)
+			}
+			err = ErrCompile
+		}
+	}()
+
+	// resolve and type check modified packages before generating any IR,
+	// so that a broken project fails fast with all its errors.
+	t.info = t.check()
+	if t.diags.Errors() > 0 {
+		return ErrCompile
 	}
-	t.visitingPkgs[pkgName] = struct{}{}
 
-	_, ok := t.allPkgs[pkgName]
-	if !ok {
-		errorutils.Abort(errorutils.UnknownModule, pkgName)
-		return
+	if t.outputDir != "" && !t.opts.DryRun {
+		if err := os.MkdirAll(t.outputDir, 0o755); err != nil {
+			return err
+		}
+	}
+	// the entry package is start.pic unless the manifest says otherwise
+	t.buildAllPackages(t.plan(t.entry))
+	if t.diags.Errors() > 0 {
+		return ErrCompile
 	}
 
-	tree, ok := t.packages[pkgName]
-	if !ok {
-		// package exists but not modified, so no rebuild needed
-		logger.Warn(pkgName, "[skip] %s", pkgName)
+	// for all modified packages, generate .exports
+	if t.outputDir != "" && !t.opts.DryRun {
+		for pkgName := range t.packages {
+			t.generateExports(pkgName)
+		}
+	}
 
-		delete(t.visitingPkgs, pkgName)
-		t.builtPkgs[pkgName] = struct{}{}
-		return
+	// only now, so that a failed build is retried in full
+	if t.cache != nil && !t.opts.DryRun {
+		built := make(map[string]struct{}, len(t.llvms))
+		for pkgName := range t.llvms {
+			built[pkgName] = struct{}{}
+		}
+		if err := t.cache.Save(filepath.Join(t.outputDir, MANIFEST), built); err != nil {
+			t.fail(err)
+		}
 	}
+	return nil
+}
 
-	directUserImports := t.extractUserImports(tree)
-	for _, imp := range directUserImports {
-		t.buildPackage(imp)
+// Modules returns the IR of the packages built, keyed by package name.
+func (t *generator) Modules() map[string]*ir.Module {
+	mods := make(map[string]*ir.Module, len(t.llvms))
+	for name, llvm := range t.llvms {
+		mods[name] = llvm.GetModule()
 	}
+	return mods
+}
 
-	ffiImports := t.extractFFIimports(tree)
-	for _, imp := range ffiImports {
-		t.buildFFIPackage(imp)
+// check runs the semantic pass over the modified packages. Unmodified
+// packages contribute their declarations from .exports.
+func (t *generator) check() *sema.Info {
+	pkgs := make(map[string]ast.BlockStatement, len(t.allPkgs))
+	targets := make([]string, 0, len(t.packages))
+	for pkgName := range t.allPkgs {
+		if tree, ok := t.packages[pkgName]; ok {
+			pkgs[pkgName] = tree
+			targets = append(targets, pkgName)
+			continue
+		}
+		if tree, err := t.loadExports(pkgName); err == nil {
+			pkgs[pkgName] = tree
+		}
 	}
+	return sema.Check(t.diags, pkgs, targets...)
+}
 
-	stdlibImports := t.extractStdLibImports(tree)
-	for _, imp := range stdlibImports {
-		t.buildStdLib(imp)
+func (t *generator) generateExports(pkgName string) {
+	outputPath := exports.Path(t.outputDir, pkgName)
+	if err := exports.Write(outputPath, exports.FromAST(pkgName, t.packages[pkgName])); err != nil {
+		t.fail(err)
+	}
+}
+
+// loadExports returns the declarations of pkgName from the .exports written
+// when it was last built.
+func (t *generator) loadExports(pkgName string) (ast.BlockStatement, error) {
+	p, err := exports.Read(exports.Path(t.outputDir, pkgName))
+	if err != nil {
+		return ast.BlockStatement{}, err
+	}
+	return p.AST()
+}
+
+// buildPackage generates IR for pkgName, whose user imports have been built
+// and whose C modules have been loaded, see plan.
+func (t *generator) buildPackage(pkgName string) {
+	if err := t.ctx.Err(); err != nil {
+		t.fail(err)
 	}
+	tree := t.packages[pkgName]
+	directUserImports := t.extractUserImports(tree)
+	stdlibImports := t.extractStdLibImports(tree)
 
 	// Create new LLVM context for this package (Safe, as children are finished)
 	llvm := NewLLVM(pkgName, t.outputDir)
+	llvm.st.CheckedArith = t.opts.CheckedArith
+	llvm.st.Diagnostics = t.diags
+	llvm.st.Info = t.info
+
+	t.mu.Lock()
 	t.llvms[pkgName] = llvm
+	t.mu.Unlock()
 
 	// Resolve Imports: Declare symbols from direct and transitive dependencies (B and C)
 	t.resolveUserImports(tree, directUserImports, llvm)
@@ -160,11 +303,13 @@ func (t *generator) buildPackage(pkg state.PackageEntry) {
 	t.compile(tree, llvm)
 
 	// Dump
-	llvm.Dump(t.outputDir, pkgName)
-
-	// Mark as finished
-	delete(t.visitingPkgs, pkgName)
-	t.builtPkgs[pkgName] = struct{}{}
+	if t.outputDir != "" && !t.opts.DryRun {
+		if err := llvm.Dump(t.outputDir, pkgName); err != nil {
+			t.fail(err)
+		}
+	}
+	c.Release(llvm.GetModule())
+	rterr.Release(llvm.GetModule())
 }
 
 func (t *generator) buildFFIPackage(pkg state.PackageEntry) {
@@ -173,18 +318,15 @@ func (t *generator) buildFFIPackage(pkg state.PackageEntry) {
 	}
 
 	split := strings.Split(pkg.Name, ".")
-	path := filepath.Join(t.outputDir, "tmp", fmt.Sprintf("%s.ll", split[len(split)-1]))
-
-	// Read original .ll file
-	input, err := os.ReadFile(path)
+	path, input, err := t.readModule(split[len(split)-1])
 	if err != nil {
-		logger.Fatal(pkg.Alias, "%s", err.Error())
+		t.fail(fmt.Errorf("loading FFI module %s: %w", pkg.Name, err))
 	}
 
 	// Parse LLVM IR
 	mod, err := asm.ParseBytes(path, input)
 	if err != nil {
-		logger.Fatal(pkg.Alias, "%s", err.Error())
+		t.fail(fmt.Errorf("loading FFI module %s: %w", pkg.Name, err))
 	}
 
 	t.ffiModules[pkg.Name] = mod
@@ -205,10 +347,7 @@ func (t *generator) buildStdLib(pkg state.PackageEntry) {
 		return
 	}
 
-	path := filepath.Join(t.outputDir, "tmp", fmt.Sprintf("%s.ll", modName))
-
-	// Read original .ll file
-	input, err := os.ReadFile(path)
+	path, input, err := t.readModule(modName)
 	if err != nil {
 		_, ok := libs.ModuleList[modName]
 		if ok {
@@ -216,19 +355,30 @@ func (t *generator) buildStdLib(pkg state.PackageEntry) {
 			return
 		}
 
-		logger.Fatal(pkg.Alias, "%s", err.Error())
+		t.fail(fmt.Errorf("loading module %s: %w", pkg.Name, err))
 	}
 
 	// Parse LLVM IR
 	data := normalizeOpaquePointers(input)
 	mod, err := asm.ParseBytes(path, data)
 	if err != nil {
-		logger.Fatal(pkg.Alias, "%s", err.Error())
+		t.fail(fmt.Errorf("loading module %s: %w", pkg.Name, err))
 	}
 
 	t.ffiModules[pkg.Name] = mod
 }
 
+// readModule reads the IR of the C module name, which the build places in
+// the tmp directory of the output directory.
+func (t *generator) readModule(name string) (string, []byte, error) {
+	path := filepath.Join(t.outputDir, "tmp", fmt.Sprintf("%s.ll", name))
+	if t.outputDir == "" {
+		return path, nil, fs.ErrNotExist
+	}
+	input, err := os.ReadFile(path)
+	return path, input, err
+}
+
 // extractUserImports returns only the names of non-builtin imported packages.
 func (t *generator) extractUserImports(tree ast.BlockStatement) []state.PackageEntry {
 	var imports []state.PackageEntry
@@ -319,16 +469,14 @@ func (t *generator) recursiveTransitiveDeclaration(pkg state.PackageEntry, llvm
 	}
 	declared[pkgFullName] = struct{}{}
 
-	packageAST := ast.BlockStatement{}
-
-	if _, ok := t.packages[pkgFullName]; !ok {
+	packageAST, ok := t.packages[pkgFullName]
+	if !ok {
 		// load from exports
-		err := utils.LoadFromFile(filepath.Join(t.outputDir, fmt.Sprintf("%s.exports", pkgFullName)), &packageAST)
+		var err error
+		packageAST, err = t.loadExports(pkgFullName)
 		if err != nil {
-			logger.Fatal(pkg.Alias, "%s", fmt.Errorf("error while loading exports: %v", err).Error())
+			t.fail(fmt.Errorf("loading exports of %s: %w", pkgFullName, err))
 		}
-	} else {
-		packageAST = t.packages[pkgFullName]
 	}
 
 	subImports := t.extractUserImports(packageAST)
@@ -362,164 +510,173 @@ func (t *generator) importBasePackages(methodMap map[string]function.Func, modul
 
 // compile passes tree through all steps to output IR
 func (t *generator) compile(tree ast.BlockStatement, llvm *LLVM) {
-	llvm.ParseAST(tree)
+	llvm.ParseAST(tree, t.opts.StopAfter)
 }
 
-func isRootOf(a, b string) (bool, error) {
-	aAbs, err := filepath.Abs(a)
+// LoadPackages loads the packages of the project m describes, keyed by
+// their path in their root with dots, e.g. os.io for os/io.pic.
+//
+// Only the packages the build cache finds dirty for a build with opts are
+// parsed in full; the cache is returned to record the build once it
+// succeeds.
+func LoadPackages(m *manifest.Manifest, opts Options) (map[string]ast.BlockStatement, map[string]struct{}, *tools.BuildCache, error) {
+	paths, err := sourceFiles(m)
 	if err != nil {
-		return false, err
+		return nil, nil, nil, err
+	}
+	diags := opts.diagnostics()
+	allPkgImports := make(map[string]ast.BlockStatement, len(paths))
+	for pkgName, path := range paths {
+		allPkgImports[pkgName] = resolveVendored(pkgName, parser.ParseImports(diags, path), paths)
 	}
-	bAbs, err := filepath.Abs(b)
+
+	cache := tools.NewBuildCache(allPkgImports, filepath.Join(m.Output, MANIFEST), opts.Hash())
+	changed, err := cache.Changed(paths)
 	if err != nil {
-		return false, err
+		return nil, nil, nil, err
+	}
+	// importers of an unchanged package are built against its .exports,
+	// which must be there and of the current version
+	for pkgName := range paths {
+		if _, ok := changed[pkgName]; ok {
+			continue
+		}
+		if _, err := exports.Read(exports.Path(m.Output, pkgName)); err != nil {
+			changed[pkgName] = struct{}{}
+		}
 	}
 
-	aClean := filepath.Clean(aAbs)
-	bClean := filepath.Clean(bAbs)
+	// the interface of a changed package decides whether its importers
+	// are rebuilt too
+	modifiedPkgAST := make(map[string]ast.BlockStatement)
+	interfaces := make(map[string]string, len(changed))
+	for _, pkgName := range slices.Sorted(maps.Keys(changed)) {
+		tree := resolveVendored(pkgName, parser.ParseAll(diags, paths[pkgName]), paths)
+		modifiedPkgAST[pkgName] = tree
+		interfaces[pkgName] = exports.FromAST(pkgName, tree).Hash()
+	}
+	for _, pkgName := range slices.Sorted(maps.Keys(cache.Dirty(interfaces))) {
+		if _, ok := modifiedPkgAST[pkgName]; !ok {
+			modifiedPkgAST[pkgName] = resolveVendored(pkgName, parser.ParseAll(diags, paths[pkgName]), paths)
+		}
+	}
 
-	rel, err := filepath.Rel(aClean, bClean)
-	if err != nil {
-		return false, err
+	if diags.Errors() > 0 {
+		return nil, nil, nil, ErrCompile
 	}
 
-	// If rel starts with "..", b is outside a
-	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)), nil
+	allPkgs := make(map[string]struct{}, len(paths))
+	for pkgName := range paths {
+		allPkgs[pkgName] = struct{}{}
+	}
+	return modifiedPkgAST, allPkgs, cache, nil
 }
 
-func rel(root string, path string) (string, error) {
-	if ok, _ := isRootOf(root, path); ok {
-		return filepath.Rel(root, path)
+// ParseProject parses every package of the project m describes without
+// consulting the build cache, e.g. to check it without generating code.
+// Packages are keyed like in LoadPackages; syntax errors go to diags.
+func ParseProject(diags *errorsx.Collector, m *manifest.Manifest) (map[string]ast.BlockStatement, error) {
+	paths, err := sourceFiles(m)
+	if err != nil {
+		return nil, err
+	}
+	pkgs := make(map[string]ast.BlockStatement, len(paths))
+	for _, pkgName := range slices.Sorted(maps.Keys(paths)) {
+		pkgs[pkgName] = resolveVendored(pkgName, parser.ParseAll(diags, paths[pkgName]), paths)
 	}
-	return filepath.Rel(os.Getenv("PICASSO_INCLUDE"), path)
+	return pkgs, nil
 }
 
-func walk(root string, fn fs.WalkDirFunc) error {
-	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			return err
-		}
+// sourceFiles returns the .pic file of every package of the project, keyed
+// by package name, looking in its source roots, then in its include paths
+// and then in the vendor directory, which must match the lockfile. A
+// package found twice is an error. Links are not followed, so the link to
+// the libs older builds made in the project is left out.
+func sourceFiles(m *manifest.Manifest) (map[string]string, error) {
+	if err := deps.Verify(m); err != nil {
+		return nil, err
+	}
 
-		// If it's a symlink
-		if d.Type()&os.ModeSymlink != 0 {
-			target, err := os.Readlink(path)
+	roots := m.Roots()
+	paths := make(map[string]string)
+	walk := func(dir, root string) error {
+		return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
 			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				// roots nested in one another are walked once
+				if path == m.Output || path != dir && slices.Contains(roots, path) ||
+					len(m.Deps) > 0 && path == m.Vendor() {
+					return filepath.SkipDir
+				}
 				return nil
 			}
-
-			// Make target absolute if needed
-			if !filepath.IsAbs(target) {
-				target = filepath.Join(filepath.Dir(path), target)
+			if filepath.Ext(path) != ".pic" {
+				return nil
 			}
 
-			info, err := os.Stat(target)
-			if err == nil && info.IsDir() {
-				// Walk the symlink target
-				return walk(target, fn)
+			pkgName, err := packageName(root, path)
+			if err != nil {
+				return err
 			}
-		}
-
-		return fn(path, d, err)
-	})
-}
-
-// LoadPackages loads package with their AST by going through project.ini file
-// Packages will be named will modified by replacing '/' with '.' resulting
-// in something like os.io instead of os/io.
-func LoadPackages(projectDir string) (map[string]ast.BlockStatement, map[string]struct{}) {
-	rootDir := projectDir
-
-	if os.Getenv("PICASSO_INCLUDE") == "" {
-		logger.Fatal("", "PICASSO_INCLUDE is unset")
-	}
-
-	err := os.Symlink(filepath.Join(os.Getenv("PICASSO_INCLUDE"), "picasso"), filepath.Join(rootDir, "picasso"))
-	if err != nil {
-		logger.Warn("", " assuming symlink to libs")
-	}
-
-	modifiedPkgAST := make(map[string]ast.BlockStatement)
-	allPkgImports := make(map[string]ast.BlockStatement)
-
-	err = walk(rootDir, func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			return err
-		}
-
-		if d.IsDir() {
-			return nil
-		}
-
-		if filepath.Ext(path) != ".pic" {
+			if prev, ok := paths[pkgName]; ok {
+				return fmt.Errorf("package %s is defined twice, in %s and %s", pkgName, prev, path)
+			}
+			paths[pkgName] = path
 			return nil
-		}
-
-		tree := parser.ParseImports(path)
-
-		// relative path from root
-		rel, err := rel(rootDir, path)
-		if err != nil {
-			return err
-		}
-
-		// remove extension
-		rel = strings.TrimSuffix(rel, ".pic")
-
-		// normalize to forward slashes for package name
-		pkgName := strings.ReplaceAll(filepath.ToSlash(rel), "/", ".")
-
-		allPkgImports[pkgName] = tree
-		return nil
-	})
-
-	if err != nil {
-		logger.Fatal("%s", err.Error())
+		})
 	}
-
-	cachedBuilder := tools.NewBuildCache(allPkgImports, projectDir)
-	modifiedPkgs, err := cachedBuilder.CheckBuildCache()
-
-	allPkgs := make(map[string]struct{})
-
-	err = walk(rootDir, func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			return err
+	for _, root := range roots {
+		if err := walk(root, root); err != nil {
+			return nil, err
 		}
-
-		if d.IsDir() {
-			return nil
+	}
+	// named by their path in the project directory, e.g. vendor.geo.shape
+	for _, d := range m.Deps {
+		dir := filepath.Join(m.Vendor(), d.Name)
+		if err := walk(dir, filepath.Dir(m.Vendor())); err != nil {
+			return nil, err
 		}
+	}
+	return paths, nil
+}
 
-		if filepath.Ext(path) != ".pic" {
-			return nil
+// resolveVendored points the imports of a vendored package, written as in
+// its own project, at the packages of its dependency when it has them, e.g.
+// "geo/point" in vendor/shared at vendor.shared.geo.point. Other packages
+// are returned as they are.
+func resolveVendored(pkgName string, tree ast.BlockStatement, paths map[string]string) ast.BlockStatement {
+	rest, ok := strings.CutPrefix(pkgName, manifest.VendorDir+".")
+	if !ok {
+		return tree
+	}
+	dep, _, _ := strings.Cut(rest, ".")
+	prefix := manifest.VendorDir + "." + dep + "."
+	for i, st := range tree.Body {
+		imp, ok := st.(ast.ImportStatement)
+		if !ok || imp.IsBuiltIn() || imp.IsFFI() {
+			continue
 		}
-		// relative path from root
-		rel, err := rel(rootDir, path)
-		if err != nil {
-			return err
+		if _, ok := paths[prefix+imp.Name]; ok {
+			imp.Name = prefix + imp.Name
+			tree.Body[i] = imp
 		}
+	}
+	return tree
+}
 
-		// remove extension
-		rel = strings.TrimSuffix(rel, ".pic")
-
-		// normalize to forward slashes for package name
-		pkgName := strings.ReplaceAll(filepath.ToSlash(rel), string(os.PathSeparator), ".")
-
-		allPkgs[pkgName] = struct{}{}
-		// ignore unmodified packages
-		if _, ok := modifiedPkgs[pkgName]; !ok {
-			return nil
-		}
-		tree := parser.ParseAll(path)
-
-		modifiedPkgAST[pkgName] = tree
-		return nil
-	})
-
+// packageName names the package of the source file at path, e.g. os.io for
+// os/io.pic in root.
+func packageName(root string, path string) (string, error) {
+	rel, err := filepath.Rel(root, path)
 	if err != nil {
-		logger.Fatal("%s", err.Error())
+		return "", err
 	}
 
-	utils.SaveToFile(filepath.Join(projectDir, BUILD, "build.meta"), tools.LastBuildTime{Time: time.Now()})
-	return modifiedPkgAST, allPkgs
+	// remove extension
+	rel = strings.TrimSuffix(rel, ".pic")
+
+	// normalize to forward slashes for package name
+	return strings.ReplaceAll(filepath.ToSlash(rel), "/", "."), nil
 }
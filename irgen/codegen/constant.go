@@ -6,4 +6,7 @@ const (
 	THIS    = "this"
 	BUILTIN = "builtin"
 	BUILD   = "build"
+
+	// MANIFEST is the build cache manifest in the build directory.
+	MANIFEST = "build.meta"
 )
@@ -4,10 +4,10 @@ package errorutils
 
 import (
 	"fmt"
-	"os"
 	"strings"
 
-	"github.com/fatih/color"
+	"github.com/nagarajRPoojari/picasso/irgen/ast"
+	errorsx "github.com/nagarajRPoojari/picasso/irgen/error"
 )
 
 const (
@@ -45,6 +45,12 @@ const (
 	ClassNotAccessible              = "class %s is not accessible for instantiation"
 	TupleUnpackFailed               = "failed to unpack tuple: %s"
 	TuplePackFailed                 = "failed to pack tuple: %s"
+	SliceAssignmentError            = "cannot assign to an array slice, assign to its elements instead"
+	PreviousDeclaration             = "%s first declared here"
+	UnknownType                     = "unknown type %s"
+	MissingReturn                   = "missing return at end of %s"
+	CyclicImport                    = "cyclic dependency detected: %s"
+	FormatMismatch                  = "format mismatch in %s: %s"
 )
 
 const (
@@ -61,57 +67,120 @@ const (
 	InternalTypeError          = "type error"
 )
 
+// codes gives every template a stable error code, reported with the error
+// in machine readable output. New templates get the next free code; codes
+// are never reused.
+var codes = map[string]string{
+	InternalError:                   "E0001",
+	InvalidStatement:                "E0002",
+	InvalidExpression:               "E0003",
+	InvalidBinaryExpressionOperand:  "E0004",
+	InvalidBinaryExpressionOperator: "E0005",
+	BinaryOperationError:            "E0006",
+	PrefixOperationError:            "E0007",
+	ImplicitTypeCastError:           "E0008",
+	ExplicitTypeCastError:           "E0009",
+	MemberExpressionError:           "E0010",
+	UnknownMethod:                   "E0011",
+	TypeRedeclaration:               "E0012",
+	VariableRedeclaration:           "E0013",
+	MethodRedeclaration:             "E0014",
+	FunctionSignatureMisMatch:       "E0015",
+	UnknownClassField:               "E0016",
+	UnknownClass:                    "E0017",
+	UnknownVariable:                 "E0018",
+	UnknownModule:                   "E0019",
+	InvalidModulerSource:            "E0020",
+	InvalidMainMethodSignature:      "E0021",
+	TypeError:                       "E0022",
+	ParamsError:                     "E0023",
+	GlobalVarsNotAllowedError:       "E0024",
+	InvalidBreakStatement:           "E0025",
+	InterfaceInstantiationError:     "E0026",
+	UnknownInterfaceError:           "E0027",
+	VarsNotAllowedInInterfaceError:  "E0028",
+	UnImplementedInterfaceMethod:    "E0029",
+	InvalidConstructorSignature:     "E0030",
+	FieldNotAccessible:              "E0031",
+	ClassNotAccessible:              "E0032",
+	TupleUnpackFailed:               "E0033",
+	TuplePackFailed:                 "E0034",
+	SliceAssignmentError:            "E0035",
+	UnknownType:                     "E0036",
+	MissingReturn:                   "E0037",
+	CyclicImport:                    "E0038",
+	FormatMismatch:                  "E0039",
+
+	InvalidNativeType: "E0100",
+	InvalidLLVMType:   "E0101",
+	InvalidTargetType: "E0102",
+
+	InternalFuncCallError:      "E0200",
+	InternalUDTDefinitionError: "E0201",
+	InternalMemberExprError:    "E0202",
+	InternalInstantiationError: "E0203",
+	InternalTypeError:          "E0204",
+}
+
+// Code returns the error code of template msg, "" if msg is not one of the
+// templates above.
+func Code(msg string) string {
+	return codes[msg]
+}
+
 func Assert(cond bool, msg string) {
 	if !cond {
 		panic("assertion failed: " + msg)
 	}
 }
 
+// Abort records a compilation error built from msg, substituting each %s
+// placeholder with the next arg, and unwinds to the nearest recovery point
+// so that compilation can continue with the next statement or function.
+// The error is attributed to the AST node being compiled.
 func Abort(msg string, args ...any) {
-	redBold := color.New(color.FgRed, color.Bold).SprintFunc()
-	mainColor := color.New(color.FgYellow).SprintFunc()
-	argColor := color.New(color.FgRed).SprintFunc()
+	AbortWithNotes(nil, msg, args...)
+}
 
-	formattedMsg := msg
-	argPositions := []struct {
-		start int
-		end   int
-	}{}
+// AbortWithNotes is Abort with notes pointing at related source, e.g. where
+// a conflicting symbol was first declared.
+func AbortWithNotes(notes []errorsx.Note, msg string, args ...any) {
+	errorsx.Raise(errorsx.Diagnostic{
+		Phase:   errorsx.PhaseCompilation,
+		Code:    Code(msg),
+		Message: format(msg, args...),
+		Notes:   notes,
+	})
+}
 
+// NoteAt builds a note for loc from msg, substituting args as in Abort.
+func NoteAt(loc ast.SourceLoc, msg string, args ...any) errorsx.Note {
+	return errorsx.Note{Message: format(msg, args...), Path: loc.FilePath, Line: loc.Line, Col: loc.Col}
+}
+
+func format(msg string, args ...any) string {
+	// substitute args one at a time, resuming after each inserted arg so
+	// that args containing "%s" themselves (e.g. LLVM type names) stay intact
+	formattedMsg := msg
+	from := 0
 	for _, arg := range args {
-		placeholder := "%s"
-		idx := strings.Index(formattedMsg, placeholder)
+		idx := placeholder(formattedMsg[from:])
 		if idx == -1 {
 			break
 		}
+		idx += from
 		argStr := fmt.Sprint(arg)
-		formattedMsg = formattedMsg[:idx] + argStr + formattedMsg[idx+len(placeholder):]
-		argPositions = append(argPositions, struct{ start, end int }{idx, idx + len(argStr)})
+		formattedMsg = formattedMsg[:idx] + argStr + formattedMsg[idx+2:]
+		from = idx + len(argStr)
 	}
+	return formattedMsg
+}
 
-	coloredMsg := ""
-	lastIndex := 0
-	for _, pos := range argPositions {
-		coloredMsg += mainColor(formattedMsg[lastIndex:pos.start]) // normal text
-		coloredMsg += argColor(formattedMsg[pos.start:pos.end])    // red arg
-		lastIndex = pos.end
-	}
-	coloredMsg += mainColor(formattedMsg[lastIndex:])
-
-	fmt.Print(redBold("ERROR: "))
-	fmt.Println(coloredMsg)
-
-	lineLen := len("ERROR: ") + len([]rune(formattedMsg))
-	squiggleRunes := make([]rune, lineLen)
-	for i := 0; i < lineLen; i++ {
-		squiggleRunes[i] = ' '
-	}
-	for _, pos := range argPositions {
-		for i := pos.start + len("ERROR: "); i < pos.end+len("ERROR: "); i++ {
-			squiggleRunes[i] = '~'
-		}
+// placeholder returns the index of the first %s or %v in s, -1 if none.
+func placeholder(s string) int {
+	idx := strings.Index(s, "%s")
+	if v := strings.Index(s, "%v"); v != -1 && (idx == -1 || v < idx) {
+		idx = v
 	}
-	fmt.Println(argColor(string(squiggleRunes)))
-
-	os.Exit(1)
+	return idx
 }
@@ -0,0 +1,218 @@
+package exports
+
+import (
+	"fmt"
+
+	"github.com/nagarajRPoojari/picasso/irgen/ast"
+)
+
+// FromAST returns the exports of the package name parsed as tree.
+func FromAST(name string, tree ast.BlockStatement) *Package {
+	p := &Package{Version: Version, Name: name}
+	for _, stI := range tree.Body {
+		switch st := stI.(type) {
+		case ast.ImportStatement:
+			p.Imports = append(p.Imports, Import{Pos: posOf(st.SourceLoc), Name: st.Name, Alias: st.Alias})
+
+		case ast.ClassDeclarationStatement:
+			cls := Class{
+				Pos:        posOf(st.SourceLoc),
+				Name:       st.Name,
+				Implements: st.Implements,
+				Internal:   st.IsInternal,
+				Members:    make([]Member, 0),
+			}
+			for _, memberI := range st.Body {
+				switch member := memberI.(type) {
+				case ast.VariableDeclarationStatement:
+					for _, f := range fieldsOf(member) {
+						cls.Members = append(cls.Members, Member{Field: f})
+					}
+				case ast.FunctionDefinitionStatement:
+					m := methodOf(member.SourceLoc, member.Name, member.Parameters, member.ReturnType)
+					m.Static = member.IsStatic
+					m.Internal = member.IsInternal
+					cls.Members = append(cls.Members, Member{Method: m})
+				}
+			}
+			p.Classes = append(p.Classes, cls)
+
+		case ast.InterfaceDeclarationStatement:
+			ifs := Interface{Pos: posOf(st.SourceLoc), Name: st.Name, Methods: make([]Method, 0)}
+			for _, memberI := range st.Body {
+				switch member := memberI.(type) {
+				case ast.FunctionDefinitionStatement:
+					ifs.Methods = append(ifs.Methods, *methodOf(member.SourceLoc, member.Name, member.Parameters, member.ReturnType))
+				case ast.FunctionDeclarationStatement:
+					ifs.Methods = append(ifs.Methods, *methodOf(member.SourceLoc, member.Name, member.Parameters, member.ReturnType))
+				}
+			}
+			p.Interfaces = append(p.Interfaces, ifs)
+		}
+	}
+	return p
+}
+
+// fieldsOf returns the fields declared by st, one per variable of a multiple
+// declaration like say a: int, b: int.
+func fieldsOf(st ast.VariableDeclarationStatement) []*Field {
+	field := func(name string, tp ast.Type) *Field {
+		return &Field{
+			Pos:      posOf(st.SourceLoc),
+			Name:     name,
+			Type:     typeOf(tp),
+			Static:   st.IsStatic,
+			Atomic:   st.IsAtomic,
+			Internal: st.IsInternal,
+			Constant: st.Constant,
+		}
+	}
+
+	if len(st.Identifiers) == 0 {
+		return []*Field{field(st.Identifier, st.ExplicitType)}
+	}
+	fields := make([]*Field, len(st.Identifiers))
+	for i, name := range st.Identifiers {
+		var tp ast.Type
+		if i < len(st.ExplicitTypes) {
+			tp = st.ExplicitTypes[i]
+		}
+		fields[i] = field(name, tp)
+	}
+	return fields
+}
+
+func methodOf(loc ast.SourceLoc, name string, params []ast.Parameter, ret ast.Type) *Method {
+	m := &Method{Pos: posOf(loc), Name: name, Params: make([]Param, len(params)), Return: typeOf(ret)}
+	for i, param := range params {
+		m.Params[i] = Param{Name: param.Name, Type: typeOf(param.Type)}
+	}
+	return m
+}
+
+func typeOf(tp ast.Type) *Type {
+	switch tp := tp.(type) {
+	case *ast.SymbolType:
+		return &Type{Kind: SymbolKind, Atomic: tp.Atomic, Name: tp.Value}
+	case *ast.ListType:
+		return &Type{Kind: ListKind, Atomic: tp.Atomic, Length: tp.Length, Elem: typeOf(tp.Underlying)}
+	case *ast.TupleType:
+		t := &Type{Kind: TupleKind, Atomic: tp.Atomic, Types: make([]*Type, len(tp.Types))}
+		for i, elem := range tp.Types {
+			t.Types[i] = typeOf(elem)
+		}
+		return t
+	}
+	return nil
+}
+
+// AST returns the declarations of p as the parser would, with empty method
+// bodies, for the semantic pass and codegen to declare them.
+func (p *Package) AST() (ast.BlockStatement, error) {
+	tree := ast.BlockStatement{Body: make([]ast.Statement, 0)}
+	for _, imp := range p.Imports {
+		tree.Body = append(tree.Body, ast.ImportStatement{SourceLoc: imp.loc(), Name: imp.Name, Alias: imp.Alias})
+	}
+
+	for _, ifs := range p.Interfaces {
+		st := ast.InterfaceDeclarationStatement{SourceLoc: ifs.loc(), Name: ifs.Name, Body: make([]ast.Statement, 0)}
+		for i := range ifs.Methods {
+			m, err := ifs.Methods[i].ast()
+			if err != nil {
+				return ast.BlockStatement{}, fmt.Errorf("interface %s: %w", ifs.Name, err)
+			}
+			st.Body = append(st.Body, m)
+		}
+		tree.Body = append(tree.Body, st)
+	}
+
+	for _, cls := range p.Classes {
+		st := ast.ClassDeclarationStatement{
+			SourceLoc:  cls.loc(),
+			Name:       cls.Name,
+			Implements: cls.Implements,
+			IsInternal: cls.Internal,
+			Body:       make([]ast.Statement, 0),
+		}
+		for _, member := range cls.Members {
+			switch {
+			case member.Field != nil:
+				f := member.Field
+				tp, err := f.Type.ast()
+				if err != nil {
+					return ast.BlockStatement{}, fmt.Errorf("field %s.%s: %w", cls.Name, f.Name, err)
+				}
+				st.Body = append(st.Body, ast.VariableDeclarationStatement{
+					SourceLoc:    f.loc(),
+					Identifier:   f.Name,
+					ExplicitType: tp,
+					IsStatic:     f.Static,
+					IsAtomic:     f.Atomic,
+					IsInternal:   f.Internal,
+					Constant:     f.Constant,
+				})
+			case member.Method != nil:
+				m, err := member.Method.ast()
+				if err != nil {
+					return ast.BlockStatement{}, fmt.Errorf("class %s: %w", cls.Name, err)
+				}
+				st.Body = append(st.Body, m)
+			default:
+				return ast.BlockStatement{}, fmt.Errorf("class %s: member is neither a field nor a method", cls.Name)
+			}
+		}
+		tree.Body = append(tree.Body, st)
+	}
+	return tree, nil
+}
+
+func (m *Method) ast() (ast.FunctionDefinitionStatement, error) {
+	fn := ast.FunctionDefinitionStatement{
+		SourceLoc:  m.loc(),
+		Name:       m.Name,
+		Parameters: make([]ast.Parameter, len(m.Params)),
+		Body:       []ast.Statement{},
+		IsStatic:   m.Static,
+		IsInternal: m.Internal,
+	}
+	for i, param := range m.Params {
+		tp, err := param.Type.ast()
+		if err != nil {
+			return fn, fmt.Errorf("method %s: parameter %s: %w", m.Name, param.Name, err)
+		}
+		fn.Parameters[i] = ast.Parameter{Name: param.Name, Type: tp}
+	}
+	ret, err := m.Return.ast()
+	if err != nil {
+		return fn, fmt.Errorf("method %s: return: %w", m.Name, err)
+	}
+	fn.ReturnType = ret
+	return fn, nil
+}
+
+func (t *Type) ast() (ast.Type, error) {
+	if t == nil {
+		return nil, nil
+	}
+	switch t.Kind {
+	case SymbolKind:
+		return &ast.SymbolType{Atomic: t.Atomic, Value: t.Name}, nil
+	case ListKind:
+		elem, err := t.Elem.ast()
+		if err != nil {
+			return nil, err
+		}
+		return &ast.ListType{Atomic: t.Atomic, Length: t.Length, Underlying: elem}, nil
+	case TupleKind:
+		tuple := &ast.TupleType{Atomic: t.Atomic, Types: make([]ast.Type, len(t.Types))}
+		for i, elem := range t.Types {
+			tp, err := elem.ast()
+			if err != nil {
+				return nil, err
+			}
+			tuple.Types[i] = tp
+		}
+		return tuple, nil
+	}
+	return nil, fmt.Errorf("unknown type kind %q", t.Kind)
+}
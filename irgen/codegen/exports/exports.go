@@ -0,0 +1,218 @@
+// Package exports defines the .exports file the build writes next to the IR
+// of every package: the declarations its importers are built against, i.e.
+// its classes with their fields and method signatures, and its interfaces.
+// Packages that are not rebuilt contribute their declarations from it.
+//
+// The file is JSON with an explicit schema rather than an encoding of the
+// AST, so that it survives changes to the AST and can be inspected, see
+// irgen exports. Version is bumped on any change to the schema; a file of
+// another version is rejected and its package rebuilt.
+package exports
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/nagarajRPoojari/picasso/irgen/ast"
+)
+
+// Version is the version of the schema below.
+const Version = 1
+
+// ErrVersion is returned by Read for a file of another version.
+var ErrVersion = errors.New("unsupported .exports version")
+
+// Package is the content of a .exports file.
+type Package struct {
+	Version    int         `json:"version"`
+	Name       string      `json:"name"`
+	Imports    []Import    `json:"imports,omitempty"`
+	Classes    []Class     `json:"classes,omitempty"`
+	Interfaces []Interface `json:"interfaces,omitempty"`
+}
+
+// Pos locates a declaration in the source, for diagnostics pointing at it.
+type Pos struct {
+	File string `json:"file"`
+	Line int    `json:"line"`
+	Col  int    `json:"col"`
+}
+
+// Import is an import of the package; declarations refer to types through
+// the aliases.
+type Import struct {
+	Pos
+	Name  string `json:"name"`
+	Alias string `json:"alias"`
+}
+
+// Class is a class declaration. Members are kept in declaration order, which
+// lays out the class.
+type Class struct {
+	Pos
+	Name       string   `json:"name"`
+	Implements string   `json:"implements,omitempty"`
+	Internal   bool     `json:"internal,omitempty"`
+	Members    []Member `json:"members"`
+}
+
+// Member is either a field or a method.
+type Member struct {
+	Field  *Field  `json:"field,omitempty"`
+	Method *Method `json:"method,omitempty"`
+}
+
+// Field is a class field. Initial values are code, so they are not exported.
+type Field struct {
+	Pos
+	Name     string `json:"name"`
+	Type     *Type  `json:"type"`
+	Static   bool   `json:"static,omitempty"`
+	Atomic   bool   `json:"atomic,omitempty"`
+	Internal bool   `json:"internal,omitempty"`
+	Constant bool   `json:"constant,omitempty"`
+}
+
+// Method is the signature of a class or interface method.
+type Method struct {
+	Pos
+	Name     string  `json:"name"`
+	Params   []Param `json:"params"`
+	Return   *Type   `json:"return,omitempty"`
+	Static   bool    `json:"static,omitempty"`
+	Internal bool    `json:"internal,omitempty"`
+}
+
+// Param is a method parameter.
+type Param struct {
+	Name string `json:"name"`
+	Type *Type  `json:"type"`
+}
+
+// Interface is an interface declaration.
+type Interface struct {
+	Pos
+	Name    string   `json:"name"`
+	Methods []Method `json:"methods"`
+}
+
+// Kinds of Type.
+const (
+	SymbolKind = "symbol"
+	ListKind   = "list"
+	TupleKind  = "tuple"
+)
+
+// Type is a type as written in the source, e.g. int, ex.Error or []int.
+type Type struct {
+	Kind   string `json:"kind"`
+	Atomic bool   `json:"atomic,omitempty"`
+	// Name of a symbol type
+	Name string `json:"name,omitempty"`
+	// Length and Elem of a list type
+	Length int   `json:"length,omitempty"`
+	Elem   *Type `json:"elem,omitempty"`
+	// Types of a tuple type
+	Types []*Type `json:"types,omitempty"`
+}
+
+// Path returns the path of the .exports file of pkgName in the build
+// directory dir.
+func Path(dir, pkgName string) string {
+	return filepath.Join(dir, pkgName+".exports")
+}
+
+// Read reads the .exports file at path.
+func Read(path string) (*Package, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// the version alone first, the rest may not fit the schema
+	var header struct {
+		Version int `json:"version"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if header.Version != Version {
+		return nil, fmt.Errorf("%s: %w %d, want %d", path, ErrVersion, header.Version, Version)
+	}
+
+	p := &Package{}
+	if err := json.Unmarshal(data, p); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return p, nil
+}
+
+// Write writes p to the .exports file at path.
+func Write(path string, p *Package) error {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// Hash identifies the declarations of p. Positions are left out, so that
+// moving code around does not change it.
+func (p *Package) Hash() string {
+	q := *p
+	q.Imports = make([]Import, len(p.Imports))
+	for i, imp := range p.Imports {
+		imp.Pos = Pos{}
+		q.Imports[i] = imp
+	}
+	q.Classes = make([]Class, len(p.Classes))
+	for i, cls := range p.Classes {
+		cls.Pos = Pos{}
+		cls.Members = make([]Member, len(p.Classes[i].Members))
+		for j, m := range p.Classes[i].Members {
+			if m.Field != nil {
+				f := *m.Field
+				f.Pos = Pos{}
+				m.Field = &f
+			}
+			if m.Method != nil {
+				m.Method = withoutPos(m.Method)
+			}
+			cls.Members[j] = m
+		}
+		q.Classes[i] = cls
+	}
+	q.Interfaces = make([]Interface, len(p.Interfaces))
+	for i, ifs := range p.Interfaces {
+		ifs.Pos = Pos{}
+		ifs.Methods = make([]Method, len(p.Interfaces[i].Methods))
+		for j := range p.Interfaces[i].Methods {
+			ifs.Methods[j] = *withoutPos(&p.Interfaces[i].Methods[j])
+		}
+		q.Interfaces[i] = ifs
+	}
+
+	// encoding a value of the schema cannot fail
+	data, _ := json.Marshal(q)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func withoutPos(m *Method) *Method {
+	c := *m
+	c.Pos = Pos{}
+	return &c
+}
+
+func posOf(loc ast.SourceLoc) Pos {
+	return Pos{File: loc.FilePath, Line: loc.Line, Col: loc.Col}
+}
+
+func (p Pos) loc() ast.SourceLoc {
+	return ast.SourceLoc{FilePath: p.File, Line: p.Line, Col: p.Col}
+}
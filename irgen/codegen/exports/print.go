@@ -0,0 +1,164 @@
+package exports
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Fprint writes p to w in a form close to the source, e.g.
+//
+//	package a (exports v1)
+//
+//	using "builtin/syncio" as syncio;
+//
+//	class Counter {                     // a.pic:3:1
+//	    say internal n: int;            // a.pic:4:5
+//	    fn Counter();                   // a.pic:5:5
+//	    fn add(by: int): int;           // a.pic:6:5
+//	}
+func Fprint(w io.Writer, p *Package) error {
+	pw := &printer{}
+	pw.line(fmt.Sprintf("package %s (exports v%d)", p.Name, p.Version), Pos{})
+
+	if len(p.Imports) > 0 {
+		pw.line("", Pos{})
+		for _, imp := range p.Imports {
+			pw.line(fmt.Sprintf("using %q as %s;", strings.ReplaceAll(imp.Name, ".", "/"), imp.Alias), imp.Pos)
+		}
+	}
+
+	for _, ifs := range p.Interfaces {
+		pw.line("", Pos{})
+		pw.line(fmt.Sprintf("interface %s {", ifs.Name), ifs.Pos)
+		for i := range ifs.Methods {
+			pw.line("    "+ifs.Methods[i].String()+";", ifs.Methods[i].Pos)
+		}
+		pw.line("}", Pos{})
+	}
+
+	for _, cls := range p.Classes {
+		head := "class " + cls.Name
+		if cls.Internal {
+			head = "internal " + head
+		}
+		if cls.Implements != "" {
+			head += ": " + cls.Implements
+		}
+		pw.line("", Pos{})
+		pw.line(head+" {", cls.Pos)
+		for _, member := range cls.Members {
+			switch {
+			case member.Field != nil:
+				pw.line("    "+member.Field.String()+";", member.Field.Pos)
+			case member.Method != nil:
+				pw.line("    "+member.Method.String()+";", member.Method.Pos)
+			}
+		}
+		pw.line("}", Pos{})
+	}
+
+	return pw.flush(w)
+}
+
+// printer aligns the positions of the lines in a column.
+type printer struct {
+	lines []string
+	pos   []Pos
+}
+
+func (pw *printer) line(s string, pos Pos) {
+	pw.lines = append(pw.lines, s)
+	pw.pos = append(pw.pos, pos)
+}
+
+func (pw *printer) flush(w io.Writer) error {
+	width := 0
+	for i, s := range pw.lines {
+		if pw.pos[i].File != "" {
+			width = max(width, len(s))
+		}
+	}
+	for i, s := range pw.lines {
+		if pos := pw.pos[i]; pos.File != "" {
+			s = fmt.Sprintf("%-*s  // %s:%d:%d", width, s, pos.File, pos.Line, pos.Col)
+		}
+		if _, err := fmt.Fprintln(w, s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *Field) String() string {
+	var b strings.Builder
+	b.WriteString("say ")
+	for _, mod := range []struct {
+		on   bool
+		name string
+	}{{f.Static, "static"}, {f.Internal, "internal"}, {f.Constant, "const"}} {
+		if mod.on {
+			b.WriteString(mod.name + " ")
+		}
+	}
+	b.WriteString(f.Name)
+	if f.Type != nil {
+		b.WriteString(": ")
+		if f.Atomic && !f.Type.Atomic {
+			b.WriteString("atomic ")
+		}
+		b.WriteString(f.Type.String())
+	}
+	return b.String()
+}
+
+func (m *Method) String() string {
+	var b strings.Builder
+	if m.Static {
+		b.WriteString("static ")
+	}
+	if m.Internal {
+		b.WriteString("internal ")
+	}
+	b.WriteString("fn " + m.Name + "(")
+	for i, param := range m.Params {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(param.Name + ": " + param.Type.String())
+	}
+	b.WriteString(")")
+	if m.Return != nil {
+		b.WriteString(": " + m.Return.String())
+	}
+	return b.String()
+}
+
+func (t *Type) String() string {
+	if t == nil {
+		return "?"
+	}
+	s := ""
+	switch t.Kind {
+	case SymbolKind:
+		s = t.Name
+	case ListKind:
+		if t.Length > 0 {
+			s = fmt.Sprintf("[%d]%s", t.Length, t.Elem)
+		} else {
+			s = "[]" + t.Elem.String()
+		}
+	case TupleKind:
+		elems := make([]string, len(t.Types))
+		for i, elem := range t.Types {
+			elems[i] = elem.String()
+		}
+		s = "(" + strings.Join(elems, ", ") + ")"
+	default:
+		s = t.Kind + "?"
+	}
+	if t.Atomic {
+		return "atomic " + s
+	}
+	return s
+}
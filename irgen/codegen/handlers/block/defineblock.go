@@ -19,7 +19,9 @@ import (
 //  2. Dispatching: Iterates through the AST node slice, delegating specific
 //     codegen logic to specialized handlers (Statement, Expression, etc.)
 //     via the Mediator.
-//  3. Control Flow: Handles early termination for 'Break' and 'Return'
+//  3. Recovery: A statement that fails to compile is recorded in
+//     the collector of the build and skipped.
+//  4. Control Flow: Handles early termination for 'Break' and 'Return'
 //     statements to ensure subsequent unreachable AST nodes are not
 //     translated into the current basic block.
 func (t *BlockHandler) ProcessBlock(fn *ir.Func, bh *bc.BlockHolder, sts []ast.Statement) {
@@ -30,34 +32,50 @@ func (t *BlockHandler) ProcessBlock(fn *ir.Func, bh *bc.BlockHolder, sts []ast.S
 	sh := t.m.GetStatementHandler().(*statement.StatementHandler)
 
 	for _, stI := range sts {
-		switch st := stI.(type) {
-		case ast.VariableDeclarationStatement:
-			sh.DeclareVariable(bh, &st)
+		// A failing statement is reported and skipped so that errors in the
+		// rest of the block are reported in the same run.
+		loc := stI.GetSrc()
+		terminated := false
+		ok := t.st.Diagnostics.GuardAt(loc.FilePath, loc.Line, loc.Col, func() {
+			terminated = t.processStatement(fn, bh, sh, stI)
+		})
+		if ok && terminated {
+			return // Stop processing this block after a break or return
+		}
+	}
+}
 
-		case ast.ExpressionStatement:
-			t.processExpressionStatement(st, bh, sh)
+// processStatement dispatches a single statement and reports whether it
+// terminates the current block.
+func (t *BlockHandler) processStatement(fn *ir.Func, bh *bc.BlockHolder, sh *statement.StatementHandler, stI ast.Statement) bool {
+	switch st := stI.(type) {
+	case ast.VariableDeclarationStatement:
+		sh.DeclareVariable(bh, &st)
 
-		case ast.AtomicBlockStatement:
-			t.processAtomicBlock(fn, st, bh)
+	case ast.ExpressionStatement:
+		t.processExpressionStatement(st, bh, sh)
 
-		case ast.IfStatement:
-			t.processIfElseBlock(fn, bh, &st, nil)
+	case ast.AtomicBlockStatement:
+		t.processAtomicBlock(fn, st, bh)
 
-		case ast.ForeachStatement:
-			t.processForBlock(fn, bh, &st)
+	case ast.IfStatement:
+		t.processIfElseBlock(fn, bh, &st, nil)
 
-		case ast.WhileStatement:
-			t.processWhileBlock(fn, bh, &st)
+	case ast.ForeachStatement:
+		t.processForBlock(fn, bh, &st)
 
-		case ast.BreakStatement:
-			t.handleBreak(bh)
-			return // Stop processing this block after a break
+	case ast.WhileStatement:
+		t.processWhileBlock(fn, bh, &st)
 
-		case ast.ReturnStatement:
-			sh.Return(bh, &st, t.getRetType(fn))
-			return // Stop processing this block after a return
-		}
+	case ast.BreakStatement:
+		t.handleBreak(bh)
+		return true
+
+	case ast.ReturnStatement:
+		sh.Return(bh, &st, t.getRetType(fn))
+		return true
 	}
+	return false
 }
 
 // processExpressionStatement handles standalone expressions (e.g, func calls, variable assignments etc.)
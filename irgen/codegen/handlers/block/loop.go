@@ -6,6 +6,7 @@ import (
 	"github.com/llir/llvm/ir/enum"
 	"github.com/llir/llvm/ir/types"
 	"github.com/nagarajRPoojari/picasso/irgen/ast"
+	"github.com/nagarajRPoojari/picasso/irgen/codegen/analysis"
 	"github.com/nagarajRPoojari/picasso/irgen/codegen/handlers/expression"
 	"github.com/nagarajRPoojari/picasso/irgen/codegen/handlers/state"
 	tf "github.com/nagarajRPoojari/picasso/irgen/codegen/type"
@@ -23,6 +24,8 @@ import (
 //     registers it in a new lexical scope so it is accessible within the body.
 //   - Control Flow: Manages the 'Loopend' stack to support 'break' statements
 //     inside the loop body, ensuring they jump to the correct exit block.
+//   - Bounds Facts: Records whether the index provably stays within an array's
+//     length so element accesses in the body can skip their bounds checks.
 //   - Increment Logic: Automatically generates the i++ logic and branches
 //     back to the header to re-evaluate the loop invariant.
 func (t *BlockHandler) processForBlock(fn *ir.Func, bh *bc.BlockHolder, st *ast.ForeachStatement) {
@@ -64,7 +67,9 @@ func (t *BlockHandler) processForBlock(fn *ir.Func, bh *bc.BlockHolder, st *ast.
 	// loop blocks need to be appended to a temporary stack to remove 'break' statements
 	// with respect to last pushed loop block
 	t.st.Loopend = append(t.st.Loopend, state.LoopEntry{End: loopEnd})
+	t.st.LoopBounds = append(t.st.LoopBounds, analysis.ForeachBound(*st, t.st.IsArrayLib))
 	t.ProcessBlock(fn, loopBody, st.Body)
+	t.st.LoopBounds = t.st.LoopBounds[:len(t.st.LoopBounds)-1]
 	t.st.Loopend = t.st.Loopend[:len(t.st.Loopend)-1]
 
 	if loopBody.N.Term == nil {
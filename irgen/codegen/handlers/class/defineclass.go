@@ -11,6 +11,7 @@ import (
 	"github.com/nagarajRPoojari/picasso/irgen/codegen/handlers/identifier"
 	"github.com/nagarajRPoojari/picasso/irgen/codegen/handlers/state"
 	typedef "github.com/nagarajRPoojari/picasso/irgen/codegen/type"
+	errorsx "github.com/nagarajRPoojari/picasso/irgen/error"
 	"github.com/nagarajRPoojari/picasso/irgen/utils/logger"
 )
 
@@ -24,7 +25,11 @@ func (t *ClassHandler) DefineClassFuncs(cls ast.ClassDeclarationStatement) {
 	for _, stI := range cls.Body {
 		switch st := stI.(type) {
 		case ast.FunctionDefinitionStatement:
-			t.m.GetFuncHandler().(*funcs.FuncHandler).DefineFunc(fqClsName, &st, avoid)
+			// a method that fails to compile is skipped, its errors recorded
+			loc := st.GetSrc()
+			t.st.Diagnostics.GuardAt(loc.FilePath, loc.Line, loc.Col, func() {
+				t.m.GetFuncHandler().(*funcs.FuncHandler).DefineFunc(fqClsName, &st, avoid)
+			})
 			avoid[st.Name] = struct{}{}
 		}
 	}
@@ -120,13 +125,17 @@ func (t *ClassHandler) DefineClass(cls ast.ClassDeclarationStatement, sourcePkg
 						IsInternal:   st.IsInternal,
 						Constant:     st.Constant,
 					}
-					t.defineField(i, fqName, clsMeta, &fieldTypes, vars, singleFieldStmt)
-					i++
+					if t.guardMember(singleFieldStmt, func() {
+						t.defineField(i, fqName, clsMeta, &fieldTypes, vars, singleFieldStmt)
+					}) {
+						i++
+					}
 				}
 			} else {
 				// Single field declaration
-				t.defineField(i, fqName, clsMeta, &fieldTypes, vars, st)
-				i++
+				if t.guardMember(st, func() { t.defineField(i, fqName, clsMeta, &fieldTypes, vars, st) }) {
+					i++
+				}
 			}
 
 		case ast.FunctionDefinitionStatement:
@@ -150,11 +159,23 @@ func (t *ClassHandler) DefineClass(cls ast.ClassDeclarationStatement, sourcePkg
 	st.Fields = fieldTypes
 }
 
+// guardMember runs fn, which defines the class member st. A member that fails
+// to define is reported and skipped so that the rest of the class is still
+// laid out; it returns false in that case.
+func (t *ClassHandler) guardMember(st ast.Statement, fn func()) bool {
+	loc := st.GetSrc()
+	return t.st.Diagnostics.GuardAt(loc.FilePath, loc.Line, loc.Col, fn)
+}
+
 // defineField registers all needed info about class field in class metadata
 func (t *ClassHandler) defineField(i int, fqName string, clsMeta *typedef.MetaClass, fieldTypes *[]types.Type, vars map[string]struct{}, st ast.VariableDeclarationStatement) {
 	fqVarName := fmt.Sprintf("%s.%s", fqName, st.Identifier)
 	if _, ok := vars[fqVarName]; ok {
-		errorutils.Abort(errorutils.VariableRedeclaration, st.Identifier)
+		prev := clsMeta.VarAST[fqVarName].SourceLoc
+		errorutils.AbortWithNotes(
+			[]errorsx.Note{errorutils.NoteAt(prev, errorutils.PreviousDeclaration, st.Identifier)},
+			errorutils.VariableRedeclaration, st.Identifier,
+		)
 	}
 
 	clsMeta.FieldIndexMap[fqVarName] = i
@@ -16,6 +16,7 @@ import (
 	"github.com/nagarajRPoojari/picasso/irgen/codegen/handlers/state"
 	tf "github.com/nagarajRPoojari/picasso/irgen/codegen/type"
 	bc "github.com/nagarajRPoojari/picasso/irgen/codegen/type/block"
+	errorsx "github.com/nagarajRPoojari/picasso/irgen/error"
 )
 
 // ExpressionHandler encapsulates the state required to generate IR
@@ -26,16 +27,12 @@ type ExpressionHandler struct {
 	m  contract.Mediator
 }
 
-// NewExpressionHandler initializes the handler and populates the
-// operator lookup tables (arithmetic, logical, and comparison)
-// used during binary expression processing.
+// NewExpressionHandler initializes the handler.
 func NewExpressionHandler(st *state.State, m contract.Mediator) *ExpressionHandler {
-	ex := &ExpressionHandler{
+	return &ExpressionHandler{
 		st: st,
 		m:  m,
 	}
-	initOpLookUpTables(ex)
-	return ex
 }
 
 // ProcessExpression acts as the central dispatcher for the expression
@@ -55,12 +52,20 @@ func (t *ExpressionHandler) ProcessExpression(bh *bc.BlockHolder, expI ast.Expre
 		return tf.NewNullVar(types.NewPointer(types.NewStruct()))
 	}
 
+	// errors raised below without a location point at this expression
+	loc := expI.GetSrc()
+	defer errorsx.At(loc.FilePath, loc.Line, loc.Col)
+
 	switch ex := expI.(type) {
 
 	case ast.NullExpression:
 		return tf.NewNullVar(types.NewPointer(types.NewStruct()))
 
 	case ast.SymbolExpression:
+		// a variable shadows the type of the same name
+		if t.st.SymbolOf(ex) != nil {
+			return t.processSymbolExpression(ex)
+		}
 		if ret, ok := t.loopUpTypeTable(bh, ex.Value); ok {
 			return ret
 		}
@@ -80,7 +85,8 @@ func (t *ExpressionHandler) ProcessExpression(bh *bc.BlockHolder, expI ast.Expre
 		return t.ProcessNewExpression(bh, ex)
 
 	case ast.MemberExpression:
-		if m, ok := ex.Member.(ast.SymbolExpression); ok {
+		// alias.Name, unless alias is a variable
+		if m, ok := ex.Member.(ast.SymbolExpression); ok && t.st.SymbolOf(m) == nil {
 			if ret, ok := t.loopUpTypeTable(bh, t.st.ResolveAlias(fmt.Sprintf("%s.%s", m.Value, ex.Property))); ok {
 				return ret
 			}
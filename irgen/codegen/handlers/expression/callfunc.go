@@ -1,6 +1,7 @@
 package expression
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/llir/llvm/ir"
@@ -12,9 +13,12 @@ import (
 	errorutils "github.com/nagarajRPoojari/picasso/irgen/codegen/error"
 	"github.com/nagarajRPoojari/picasso/irgen/codegen/handlers/constants"
 	"github.com/nagarajRPoojari/picasso/irgen/codegen/handlers/utils"
+	"github.com/nagarajRPoojari/picasso/irgen/codegen/libs"
+	function "github.com/nagarajRPoojari/picasso/irgen/codegen/libs/func"
 	"github.com/nagarajRPoojari/picasso/irgen/codegen/libs/libutils"
 	tf "github.com/nagarajRPoojari/picasso/irgen/codegen/type"
 	bc "github.com/nagarajRPoojari/picasso/irgen/codegen/type/block"
+	errorsx "github.com/nagarajRPoojari/picasso/irgen/error"
 )
 
 // CallFunc orchestrates function and method invocation in the LLVM IR.
@@ -70,9 +74,9 @@ func (t *ExpressionHandler) callLibMethod(bh *bc.BlockHolder, ex ast.CallExpress
 		return nil, false
 	}
 
+	moduleName := t.st.Imports[x.Value].Name
 	var fn *ir.Func
 	{
-		moduleName := t.st.Imports[x.Value].Name
 		fnName := fmt.Sprintf("__public__%s_%s", moduleName, m.Property)
 
 		ffiModule, ok := t.st.FFIModules[moduleName]
@@ -86,10 +90,39 @@ func (t *ExpressionHandler) callLibMethod(bh *bc.BlockHolder, ex ast.CallExpress
 		res := t.ProcessExpression(bh, v)
 		args = append(args, res)
 	}
+	if fc, ok := libs.ModuleList[moduleName].(libs.FormatChecker); ok {
+		t.checkFormat(fc, moduleName, m.Property, ex, args)
+	}
 	ret := f(fn, t.st.TypeHandler, t.st.Module, bh, args)
 	return ret, true
 }
 
+// checkFormat checks the arguments of a call of fn, a function of the lib
+// module taking a format string, against its format if it is a string
+// literal. A mismatch is reported at the argument, with a note at the
+// format for the verb it is for.
+func (t *ExpressionHandler) checkFormat(fc libs.FormatChecker, module, fn string, ex ast.CallExpression, args []tf.Var) {
+	if len(ex.Arguments) == 0 {
+		return
+	}
+	format, ok := ex.Arguments[0].(ast.StringExpression)
+	if !ok {
+		return
+	}
+
+	var fe *function.FormatError
+	if !errors.As(fc.CheckFormat(fn, format.Value, args[1:]), &fe) {
+		return
+	}
+	var notes []errorsx.Note
+	if fe.Arg > 0 && fe.Verb != "" {
+		notes = append(notes, errorutils.NoteAt(format.GetSrc(), "%s is in this format", fe.Verb))
+	}
+	loc := ex.Arguments[fe.Arg].GetSrc()
+	defer errorsx.At(loc.FilePath, loc.Line, loc.Col)
+	errorutils.AbortWithNotes(notes, errorutils.FormatMismatch, module+"."+fn, fe.Msg)
+}
+
 func (t *ExpressionHandler) callFFIMethod(bh *bc.BlockHolder, ex ast.CallExpression) (tf.Var, bool) {
 	m, ok := ex.Method.(ast.MemberExpression)
 	if !ok {
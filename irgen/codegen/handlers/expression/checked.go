@@ -0,0 +1,81 @@
+package expression
+
+import (
+	"fmt"
+
+	"github.com/llir/llvm/ir"
+	"github.com/llir/llvm/ir/constant"
+	"github.com/llir/llvm/ir/enum"
+	"github.com/llir/llvm/ir/types"
+	"github.com/llir/llvm/ir/value"
+	"github.com/nagarajRPoojari/picasso/irgen/ast"
+	rterr "github.com/nagarajRPoojari/picasso/irgen/codegen/libs/private/runtime"
+	bc "github.com/nagarajRPoojari/picasso/irgen/codegen/type/block"
+)
+
+// overflow intrinsics used in checked arithmetic mode, all operating on i64.
+// Narrower operands are widened first, so only 64-bit overflow traps here; a
+// result stored back into a narrower integer is range checked by the cast.
+const (
+	INTRINSIC_SADD = "llvm.sadd.with.overflow.i64"
+	INTRINSIC_UADD = "llvm.uadd.with.overflow.i64"
+	INTRINSIC_SSUB = "llvm.ssub.with.overflow.i64"
+	INTRINSIC_USUB = "llvm.usub.with.overflow.i64"
+	INTRINSIC_SMUL = "llvm.smul.with.overflow.i64"
+	INTRINSIC_UMUL = "llvm.umul.with.overflow.i64"
+)
+
+// overflowIntrinsic returns the declaration of given *.with.overflow.i64
+// intrinsic, declaring it in the current module on first use.
+func (t *ExpressionHandler) overflowIntrinsic(name string) *ir.Func {
+	if f, ok := t.st.GlobalFuncList[name]; ok {
+		return f
+	}
+	ret := types.NewStruct(types.I64, types.I1)
+	f := t.st.Module.NewFunc(name, ret, ir.NewParam("", types.I64), ir.NewParam("", types.I64))
+	t.st.GlobalFuncList[name] = f
+	return f
+}
+
+// checkedIntOp emits l <op> r through the given overflow intrinsic and traps
+// with a runtime error pointing at loc if the result overflowed.
+func (t *ExpressionHandler) checkedIntOp(bh *bc.BlockHolder, intrinsic string, l, r value.Value, loc ast.SourceLoc) value.Value {
+	res := bh.N.NewCall(t.overflowIntrinsic(intrinsic), l, r)
+	v := bh.N.NewExtractValue(res, 0)
+	overflow := bh.N.NewExtractValue(res, 1)
+
+	trap(bh, overflow, fmt.Sprintf("integer overflow at %s", formatLoc(loc)))
+	return v
+}
+
+// checkDivisor traps on a zero divisor and, for signed operands, on the
+// INT64_MIN / -1 case which is not representable.
+func checkDivisor(bh *bc.BlockHolder, l, r value.Value, signed bool, loc ast.SourceLoc) {
+	checkZeroDivisor(bh, r, loc)
+	if !signed {
+		return
+	}
+	isMin := bh.N.NewICmp(enum.IPredEQ, l, constant.NewInt(types.I64, -1<<63))
+	isNegOne := bh.N.NewICmp(enum.IPredEQ, r, constant.NewInt(types.I64, -1))
+	trap(bh, bh.N.NewAnd(isMin, isNegOne), fmt.Sprintf("integer overflow at %s", formatLoc(loc)))
+}
+
+// checkZeroDivisor traps on a zero divisor r.
+func checkZeroDivisor(bh *bc.BlockHolder, r value.Value, loc ast.SourceLoc) {
+	zero := bh.N.NewICmp(enum.IPredEQ, r, constant.NewInt(types.I64, 0))
+	trap(bh, zero, fmt.Sprintf("division by zero at %s", formatLoc(loc)))
+}
+
+// trap raises a runtime error with msg when cond holds, continuing codegen in
+// the fall-through block otherwise.
+func trap(bh *bc.BlockHolder, cond value.Value, msg string) {
+	failBlk := bh.N.Parent.NewBlock("")
+	passBlk := bh.N.Parent.NewBlock("")
+	bh.N.NewCondBr(cond, failBlk, passBlk)
+	rterr.RaiseRTError(failBlk, msg+"\n")
+	bh.Update(bh.V, passBlk)
+}
+
+func formatLoc(loc ast.SourceLoc) string {
+	return fmt.Sprintf("%s:%d:%d", loc.FilePath, loc.Line, loc.Col)
+}
@@ -19,11 +19,39 @@ import (
 //     expression through the global ExpressionHandler.
 //   - Offset Calculation: Delegates the actual pointer arithmetic to the Array
 //     type's LoadByIndex method, which handles multi-dimensional stride calculations
+//   - Bounds Check Elimination: Skips the runtime check for a leading index
+//     proven in range, e.g. a foreach variable bounded by array.len(arr).
+//   - Slicing: A range as the last index (e.g., arr[lo..hi] or m[i, lo..hi])
+//     yields a view sharing the array's storage instead of an element.
 func (t *ExpressionHandler) ProcessIndexingExpression(bh *bc.BlockHolder, ex ast.ComputedExpression) tf.Var {
 	base := t.ProcessExpression(bh, ex.Member)
+	arr := base.(*tf.Array)
+
+	last := len(ex.Indices) - 1
+	if r, ok := ex.Indices[last].(ast.RangeExpression); ok {
+		if last > 0 {
+			arr = arr.LoadSubarrayByIndexProven(bh, t.processIndices(bh, ex.Indices[:last]), t.st.ProvenIndices(ex))
+		}
+		bounds := t.processIndices(bh, []ast.Expression{r.Lower, r.Upper})
+		return arr.Slice(bh, bounds[0], bounds[1])
+	}
+
+	indices := t.processIndices(bh, ex.Indices)
+	proven := t.st.ProvenIndices(ex)
+
+	if len(indices) < arr.Rank {
+		subarray := arr.LoadSubarrayByIndexProven(bh, indices, proven)
+		return subarray
+	} else {
+		v := arr.LoadByIndexProven(bh, indices, proven)
+		return t.st.TypeHandler.BuildVar(bh, tf.NewType(arr.ElementTypeString), v)
+	}
+}
 
-	indices := make([]value.Value, 0)
-	for _, i := range ex.Indices {
+// processIndices evaluates index expressions and coerces each to INT64.
+func (t *ExpressionHandler) processIndices(bh *bc.BlockHolder, exprs []ast.Expression) []value.Value {
+	indices := make([]value.Value, 0, len(exprs))
+	for _, i := range exprs {
 		v := t.ProcessExpression(bh, i)
 
 		// may be i can safely replace int64 with something lower dtype. need to check @todo
@@ -32,13 +60,5 @@ func (t *ExpressionHandler) ProcessIndexingExpression(bh *bc.BlockHolder, ex ast
 
 		indices = append(indices, c.Load(bh))
 	}
-	arr := base.(*tf.Array)
-
-	if len(indices) < arr.Rank {
-		subarray := arr.LoadSubarrayByIndex(bh, indices)
-		return subarray
-	} else {
-		v := arr.LoadByIndex(bh, indices)
-		return t.st.TypeHandler.BuildVar(bh, tf.NewType(arr.ElementTypeString), v)
-	}
+	return indices
 }
@@ -18,7 +18,9 @@ import (
 	"github.com/nagarajRPoojari/picasso/irgen/lexer"
 )
 
-type BinaryOperation func(th *tf.TypeHandler, bh *bc.BlockHolder, lex, rex ast.Expression) (tf.Var, error)
+// BinaryOperation is a method of ExpressionHandler, so that the lookup tables
+// are shared by the handlers of all modules.
+type BinaryOperation func(t *ExpressionHandler, th *tf.TypeHandler, bh *bc.BlockHolder, ex ast.BinaryExpression) (tf.Var, error)
 
 var arithmatic map[lexer.TokenKind]BinaryOperation
 var comparision map[lexer.TokenKind]BinaryOperation
@@ -98,8 +100,6 @@ func normalizeOperands(th *tf.TypeHandler, bh *bc.BlockHolder, lv, rv tf.Var) (v
 
 	k := commonKind(lk, rk)
 	if k == KindInvalid {
-		fmt.Printf("lk: %v\n", lk)
-		fmt.Printf("rk: %v\n", rk)
 		return nil, nil, KindInvalid,
 			fmt.Errorf("incompatible operands for operation")
 	}
@@ -168,27 +168,27 @@ func buildBooleanFromValue(bh *bc.BlockHolder, v value.Value) tf.Var {
 //     result, returning a wrapped tf.Var for subsequent use in the pipeline.
 func (t *ExpressionHandler) ProcessBinaryExpression(bh *bc.BlockHolder, ex ast.BinaryExpression) tf.Var {
 	if op, ok := arithmatic[ex.Operator.Kind]; ok {
-		res, err := op(t.st.TypeHandler, bh, ex.Left, ex.Right)
+		res, err := op(t, t.st.TypeHandler, bh, ex)
 		if err != nil {
 			errorutils.Abort(errorutils.BinaryOperationError, err.Error())
 		}
 		return res
 
 	} else if op, ok := comparision[ex.Operator.Kind]; ok {
-		res, err := op(t.st.TypeHandler, bh, ex.Left, ex.Right)
+		res, err := op(t, t.st.TypeHandler, bh, ex)
 		if err != nil {
 			errorutils.Abort(errorutils.BinaryOperationError, err.Error())
 		}
 		return res
 
 	} else if op, ok := logical[ex.Operator.Kind]; ok {
-		res, err := op(t.st.TypeHandler, bh, ex.Left, ex.Right)
+		res, err := op(t, t.st.TypeHandler, bh, ex)
 		if err != nil {
 			errorutils.Abort(errorutils.BinaryOperationError, err.Error())
 		}
 		return res
 	} else if op, ok := bitwise[ex.Operator.Kind]; ok {
-		res, err := op(t.st.TypeHandler, bh, ex.Left, ex.Right)
+		res, err := op(t, t.st.TypeHandler, bh, ex)
 		if err != nil {
 			errorutils.Abort(errorutils.BinaryOperationError, err.Error())
 		}
@@ -199,42 +199,42 @@ func (t *ExpressionHandler) ProcessBinaryExpression(bh *bc.BlockHolder, ex ast.B
 	return nil
 }
 
-// initOpLookUpTables inits lookup table mapping operand token with its
-// corresponding operation
-func initOpLookUpTables(ex *ExpressionHandler) {
+// init fills the lookup tables mapping operand token with its corresponding
+// operation
+func init() {
 	arithmatic = make(map[lexer.TokenKind]BinaryOperation)
 	comparision = make(map[lexer.TokenKind]BinaryOperation)
 	logical = make(map[lexer.TokenKind]BinaryOperation)
 	bitwise = make(map[lexer.TokenKind]BinaryOperation)
 
-	arithmatic[lexer.PLUS] = ex.add
-	arithmatic[lexer.DASH] = ex.sub
-	arithmatic[lexer.STAR] = ex.mul
-	arithmatic[lexer.SLASH] = ex.div
-	arithmatic[lexer.PERCENT] = ex.mod
+	arithmatic[lexer.PLUS] = (*ExpressionHandler).add
+	arithmatic[lexer.DASH] = (*ExpressionHandler).sub
+	arithmatic[lexer.STAR] = (*ExpressionHandler).mul
+	arithmatic[lexer.SLASH] = (*ExpressionHandler).div
+	arithmatic[lexer.PERCENT] = (*ExpressionHandler).mod
 
-	comparision[lexer.LESS] = ex.lt
-	comparision[lexer.LESS_EQUALS] = ex.lte
-	comparision[lexer.GREATER] = ex.gt
-	comparision[lexer.GREATER_EQUALS] = ex.gte
-	comparision[lexer.EQUALS] = ex.eq
-	comparision[lexer.NOT_EQUALS] = ex.ne
+	comparision[lexer.LESS] = (*ExpressionHandler).lt
+	comparision[lexer.LESS_EQUALS] = (*ExpressionHandler).lte
+	comparision[lexer.GREATER] = (*ExpressionHandler).gt
+	comparision[lexer.GREATER_EQUALS] = (*ExpressionHandler).gte
+	comparision[lexer.EQUALS] = (*ExpressionHandler).eq
+	comparision[lexer.NOT_EQUALS] = (*ExpressionHandler).ne
 
-	logical[lexer.AND] = ex.logicalAnd
-	logical[lexer.OR] = ex.logicalOr
-	logical[lexer.QUESTION] = ex.logicalInstanceOf
+	logical[lexer.AND] = (*ExpressionHandler).logicalAnd
+	logical[lexer.OR] = (*ExpressionHandler).logicalOr
+	logical[lexer.QUESTION] = (*ExpressionHandler).logicalInstanceOf
 
-	bitwise[lexer.BITWISE_AND] = ex.bitwiseAND
-	bitwise[lexer.BITWISE_OR] = ex.bitwiseOR
-	bitwise[lexer.BITWISE_XOR] = ex.bitwiseXOR
-	bitwise[lexer.BITWIZE_LEFTSHIFT] = ex.bitwiseLeftShift
-	bitwise[lexer.BITWIZE_RIGHTSHIFT] = ex.bitwiseRightShift
+	bitwise[lexer.BITWISE_AND] = (*ExpressionHandler).bitwiseAND
+	bitwise[lexer.BITWISE_OR] = (*ExpressionHandler).bitwiseOR
+	bitwise[lexer.BITWISE_XOR] = (*ExpressionHandler).bitwiseXOR
+	bitwise[lexer.BITWIZE_LEFTSHIFT] = (*ExpressionHandler).bitwiseLeftShift
+	bitwise[lexer.BITWIZE_RIGHTSHIFT] = (*ExpressionHandler).bitwiseRightShift
 }
 
-func (t *ExpressionHandler) add(th *tf.TypeHandler, bh *bc.BlockHolder, lex, rex ast.Expression) (tf.Var, error) {
-	lv := t.ProcessExpression(bh, lex)
+func (t *ExpressionHandler) add(th *tf.TypeHandler, bh *bc.BlockHolder, ex ast.BinaryExpression) (tf.Var, error) {
+	lv := t.ProcessExpression(bh, ex.Left)
 
-	rv := t.ProcessExpression(bh, rex)
+	rv := t.ProcessExpression(bh, ex.Right)
 
 	if lv == nil || rv == nil {
 		errorutils.Abort(errorutils.InvalidBinaryExpressionOperand)
@@ -253,10 +253,16 @@ func (t *ExpressionHandler) add(th *tf.TypeHandler, bh *bc.BlockHolder, lex, rex
 	case KindSignedInt:
 		lf := th.ImplicitIntCast(bh, l, types.I64)
 		rf := th.ImplicitIntCast(bh, r, types.I64)
+		if t.st.CheckedArith {
+			return buildSignedInt64FromValue(bh, t.checkedIntOp(bh, INTRINSIC_SADD, lf, rf, ex.GetSrc())), nil
+		}
 		return buildSignedInt64FromValue(bh, bh.N.NewAdd(lf, rf)), nil
 	case KindUnsignedInt:
 		lf := th.ImplicitUnsignedIntCast(bh, l, types.I64)
 		rf := th.ImplicitUnsignedIntCast(bh, r, types.I64)
+		if t.st.CheckedArith {
+			return buildUnsignedInt64FromValue(bh, t.checkedIntOp(bh, INTRINSIC_UADD, lf, rf, ex.GetSrc())), nil
+		}
 		return buildUnsignedInt64FromValue(bh, bh.N.NewAdd(lf, rf)), nil
 
 	case KindPointer:
@@ -266,10 +272,10 @@ func (t *ExpressionHandler) add(th *tf.TypeHandler, bh *bc.BlockHolder, lex, rex
 	return nil, fmt.Errorf("unsupported add operands")
 }
 
-func (t *ExpressionHandler) sub(th *tf.TypeHandler, bh *bc.BlockHolder, lex, rex ast.Expression) (tf.Var, error) {
-	lv := t.ProcessExpression(bh, lex)
+func (t *ExpressionHandler) sub(th *tf.TypeHandler, bh *bc.BlockHolder, ex ast.BinaryExpression) (tf.Var, error) {
+	lv := t.ProcessExpression(bh, ex.Left)
 
-	rv := t.ProcessExpression(bh, rex)
+	rv := t.ProcessExpression(bh, ex.Right)
 
 	if lv == nil || rv == nil {
 		errorutils.Abort(errorutils.InvalidBinaryExpressionOperand)
@@ -288,10 +294,16 @@ func (t *ExpressionHandler) sub(th *tf.TypeHandler, bh *bc.BlockHolder, lex, rex
 	case KindSignedInt:
 		lf := th.ImplicitIntCast(bh, l, types.I64)
 		rf := th.ImplicitIntCast(bh, r, types.I64)
+		if t.st.CheckedArith {
+			return buildSignedInt64FromValue(bh, t.checkedIntOp(bh, INTRINSIC_SSUB, lf, rf, ex.GetSrc())), nil
+		}
 		return buildSignedInt64FromValue(bh, bh.N.NewSub(lf, rf)), nil
 	case KindUnsignedInt:
 		lf := th.ImplicitUnsignedIntCast(bh, l, types.I64)
 		rf := th.ImplicitUnsignedIntCast(bh, r, types.I64)
+		if t.st.CheckedArith {
+			return buildUnsignedInt64FromValue(bh, t.checkedIntOp(bh, INTRINSIC_USUB, lf, rf, ex.GetSrc())), nil
+		}
 		return buildUnsignedInt64FromValue(bh, bh.N.NewSub(lf, rf)), nil
 
 	case KindPointer:
@@ -301,10 +313,10 @@ func (t *ExpressionHandler) sub(th *tf.TypeHandler, bh *bc.BlockHolder, lex, rex
 	return nil, fmt.Errorf("unsupported sub operands")
 }
 
-func (t *ExpressionHandler) mul(th *tf.TypeHandler, bh *bc.BlockHolder, lex, rex ast.Expression) (tf.Var, error) {
-	lv := t.ProcessExpression(bh, lex)
+func (t *ExpressionHandler) mul(th *tf.TypeHandler, bh *bc.BlockHolder, ex ast.BinaryExpression) (tf.Var, error) {
+	lv := t.ProcessExpression(bh, ex.Left)
 
-	rv := t.ProcessExpression(bh, rex)
+	rv := t.ProcessExpression(bh, ex.Right)
 
 	if lv == nil || rv == nil {
 		errorutils.Abort(errorutils.InvalidBinaryExpressionOperand)
@@ -322,10 +334,16 @@ func (t *ExpressionHandler) mul(th *tf.TypeHandler, bh *bc.BlockHolder, lex, rex
 	case KindSignedInt:
 		lf := th.ImplicitIntCast(bh, l, types.I64)
 		rf := th.ImplicitIntCast(bh, r, types.I64)
+		if t.st.CheckedArith {
+			return buildSignedInt64FromValue(bh, t.checkedIntOp(bh, INTRINSIC_SMUL, lf, rf, ex.GetSrc())), nil
+		}
 		return buildSignedInt64FromValue(bh, bh.N.NewMul(lf, rf)), nil
 	case KindUnsignedInt:
 		lf := th.ImplicitUnsignedIntCast(bh, l, types.I64)
 		rf := th.ImplicitUnsignedIntCast(bh, r, types.I64)
+		if t.st.CheckedArith {
+			return buildUnsignedInt64FromValue(bh, t.checkedIntOp(bh, INTRINSIC_UMUL, lf, rf, ex.GetSrc())), nil
+		}
 		return buildUnsignedInt64FromValue(bh, bh.N.NewMul(lf, rf)), nil
 
 	case KindPointer:
@@ -335,10 +353,10 @@ func (t *ExpressionHandler) mul(th *tf.TypeHandler, bh *bc.BlockHolder, lex, rex
 	return nil, fmt.Errorf("unsupported mul operands")
 }
 
-func (t *ExpressionHandler) div(th *tf.TypeHandler, bh *bc.BlockHolder, lex, rex ast.Expression) (tf.Var, error) {
-	lv := t.ProcessExpression(bh, lex)
+func (t *ExpressionHandler) div(th *tf.TypeHandler, bh *bc.BlockHolder, ex ast.BinaryExpression) (tf.Var, error) {
+	lv := t.ProcessExpression(bh, ex.Left)
 
-	rv := t.ProcessExpression(bh, rex)
+	rv := t.ProcessExpression(bh, ex.Right)
 
 	if lv == nil || rv == nil {
 		errorutils.Abort(errorutils.InvalidBinaryExpressionOperand)
@@ -357,10 +375,16 @@ func (t *ExpressionHandler) div(th *tf.TypeHandler, bh *bc.BlockHolder, lex, rex
 	case KindSignedInt:
 		lf := th.ImplicitIntCast(bh, l, types.I64)
 		rf := th.ImplicitIntCast(bh, r, types.I64)
+		if t.st.CheckedArith {
+			checkDivisor(bh, lf, rf, true, ex.GetSrc())
+		}
 		return buildSignedInt64FromValue(bh, bh.N.NewSDiv(lf, rf)), nil
 	case KindUnsignedInt:
 		lf := th.ImplicitUnsignedIntCast(bh, l, types.I64)
 		rf := th.ImplicitUnsignedIntCast(bh, r, types.I64)
+		if t.st.CheckedArith {
+			checkDivisor(bh, lf, rf, false, ex.GetSrc())
+		}
 		return buildUnsignedInt64FromValue(bh, bh.N.NewUDiv(lf, rf)), nil
 
 	case KindPointer:
@@ -370,10 +394,10 @@ func (t *ExpressionHandler) div(th *tf.TypeHandler, bh *bc.BlockHolder, lex, rex
 	return nil, fmt.Errorf("unsupported mul operands")
 }
 
-func (t *ExpressionHandler) mod(th *tf.TypeHandler, bh *bc.BlockHolder, lex, rex ast.Expression) (tf.Var, error) {
-	lv := t.ProcessExpression(bh, lex)
+func (t *ExpressionHandler) mod(th *tf.TypeHandler, bh *bc.BlockHolder, ex ast.BinaryExpression) (tf.Var, error) {
+	lv := t.ProcessExpression(bh, ex.Left)
 
-	rv := t.ProcessExpression(bh, rex)
+	rv := t.ProcessExpression(bh, ex.Right)
 
 	if lv == nil || rv == nil {
 		errorutils.Abort(errorutils.InvalidBinaryExpressionOperand)
@@ -385,6 +409,15 @@ func (t *ExpressionHandler) mod(th *tf.TypeHandler, bh *bc.BlockHolder, lex, rex
 
 	switch k {
 	case KindFloat, KindSignedInt, KindUnsignedInt:
+		if t.st.CheckedArith && k != KindFloat {
+			// integers are divided as floats, so only a zero divisor traps;
+			// INT64_MIN % -1 is 0
+			ri := th.ImplicitIntCast(bh, r, types.I64)
+			if k == KindUnsignedInt {
+				ri = th.ImplicitUnsignedIntCast(bh, r, types.I64)
+			}
+			checkZeroDivisor(bh, ri, ex.GetSrc())
+		}
 		lf := th.ImplicitFloatCast(bh, l, types.Double)
 		rf := th.ImplicitFloatCast(bh, r, types.Double)
 
@@ -397,10 +430,10 @@ func (t *ExpressionHandler) mod(th *tf.TypeHandler, bh *bc.BlockHolder, lex, rex
 	return nil, fmt.Errorf("unsupported mod operands")
 }
 
-func (t *ExpressionHandler) eq(th *tf.TypeHandler, bh *bc.BlockHolder, lex, rex ast.Expression) (tf.Var, error) {
-	lv := t.ProcessExpression(bh, lex)
+func (t *ExpressionHandler) eq(th *tf.TypeHandler, bh *bc.BlockHolder, ex ast.BinaryExpression) (tf.Var, error) {
+	lv := t.ProcessExpression(bh, ex.Left)
 
-	rv := t.ProcessExpression(bh, rex)
+	rv := t.ProcessExpression(bh, ex.Right)
 
 	if lv == nil || rv == nil {
 		errorutils.Abort(errorutils.InvalidBinaryExpressionOperand)
@@ -420,10 +453,10 @@ func (t *ExpressionHandler) eq(th *tf.TypeHandler, bh *bc.BlockHolder, lex, rex
 	return nil, fmt.Errorf("unsupported eq")
 }
 
-func (t *ExpressionHandler) ne(th *tf.TypeHandler, bh *bc.BlockHolder, lex, rex ast.Expression) (tf.Var, error) {
-	lv := t.ProcessExpression(bh, lex)
+func (t *ExpressionHandler) ne(th *tf.TypeHandler, bh *bc.BlockHolder, ex ast.BinaryExpression) (tf.Var, error) {
+	lv := t.ProcessExpression(bh, ex.Left)
 
-	rv := t.ProcessExpression(bh, rex)
+	rv := t.ProcessExpression(bh, ex.Right)
 
 	if lv == nil || rv == nil {
 		errorutils.Abort(errorutils.InvalidBinaryExpressionOperand)
@@ -443,10 +476,10 @@ func (t *ExpressionHandler) ne(th *tf.TypeHandler, bh *bc.BlockHolder, lex, rex
 	return nil, fmt.Errorf("unsupported ne")
 }
 
-func (t *ExpressionHandler) lt(th *tf.TypeHandler, bh *bc.BlockHolder, lex, rex ast.Expression) (tf.Var, error) {
-	lv := t.ProcessExpression(bh, lex)
+func (t *ExpressionHandler) lt(th *tf.TypeHandler, bh *bc.BlockHolder, ex ast.BinaryExpression) (tf.Var, error) {
+	lv := t.ProcessExpression(bh, ex.Left)
 
-	rv := t.ProcessExpression(bh, rex)
+	rv := t.ProcessExpression(bh, ex.Right)
 
 	if lv == nil || rv == nil {
 		errorutils.Abort(errorutils.InvalidBinaryExpressionOperand)
@@ -469,10 +502,10 @@ func (t *ExpressionHandler) lt(th *tf.TypeHandler, bh *bc.BlockHolder, lex, rex
 	return nil, fmt.Errorf("unsupported lt")
 }
 
-func (t *ExpressionHandler) lte(th *tf.TypeHandler, bh *bc.BlockHolder, lex, rex ast.Expression) (tf.Var, error) {
-	lv := t.ProcessExpression(bh, lex)
+func (t *ExpressionHandler) lte(th *tf.TypeHandler, bh *bc.BlockHolder, ex ast.BinaryExpression) (tf.Var, error) {
+	lv := t.ProcessExpression(bh, ex.Left)
 
-	rv := t.ProcessExpression(bh, rex)
+	rv := t.ProcessExpression(bh, ex.Right)
 
 	if lv == nil || rv == nil {
 		errorutils.Abort(errorutils.InvalidBinaryExpressionOperand)
@@ -493,10 +526,10 @@ func (t *ExpressionHandler) lte(th *tf.TypeHandler, bh *bc.BlockHolder, lex, rex
 	return nil, fmt.Errorf("unsupported lte")
 }
 
-func (t *ExpressionHandler) gt(th *tf.TypeHandler, bh *bc.BlockHolder, lex, rex ast.Expression) (tf.Var, error) {
-	lv := t.ProcessExpression(bh, lex)
+func (t *ExpressionHandler) gt(th *tf.TypeHandler, bh *bc.BlockHolder, ex ast.BinaryExpression) (tf.Var, error) {
+	lv := t.ProcessExpression(bh, ex.Left)
 
-	rv := t.ProcessExpression(bh, rex)
+	rv := t.ProcessExpression(bh, ex.Right)
 
 	if lv == nil || rv == nil {
 		errorutils.Abort(errorutils.InvalidBinaryExpressionOperand)
@@ -517,10 +550,10 @@ func (t *ExpressionHandler) gt(th *tf.TypeHandler, bh *bc.BlockHolder, lex, rex
 	return nil, fmt.Errorf("unsupported gt")
 }
 
-func (t *ExpressionHandler) gte(th *tf.TypeHandler, bh *bc.BlockHolder, lex, rex ast.Expression) (tf.Var, error) {
-	lv := t.ProcessExpression(bh, lex)
+func (t *ExpressionHandler) gte(th *tf.TypeHandler, bh *bc.BlockHolder, ex ast.BinaryExpression) (tf.Var, error) {
+	lv := t.ProcessExpression(bh, ex.Left)
 
-	rv := t.ProcessExpression(bh, rex)
+	rv := t.ProcessExpression(bh, ex.Right)
 
 	if lv == nil || rv == nil {
 		errorutils.Abort(errorutils.InvalidBinaryExpressionOperand)
@@ -572,14 +605,14 @@ func toBool(_ *tf.TypeHandler, bh *bc.BlockHolder, v tf.Var) (value.Value, error
 	}
 }
 
-func (t *ExpressionHandler) logicalAnd(th *tf.TypeHandler, bh *bc.BlockHolder, lex, rex ast.Expression) (tf.Var, error) {
+func (t *ExpressionHandler) logicalAnd(th *tf.TypeHandler, bh *bc.BlockHolder, ex ast.BinaryExpression) (tf.Var, error) {
 	// Short-circuit evaluation: if left is false, result is false without evaluating right
 
 	// Get the parent function to create new blocks
 	fn := bh.N.Parent
 
 	// Evaluate left operand
-	lv := t.ProcessExpression(bh, lex)
+	lv := t.ProcessExpression(bh, ex.Left)
 	if lv == nil {
 		errorutils.Abort(errorutils.InvalidBinaryExpressionOperand)
 	}
@@ -595,7 +628,7 @@ func (t *ExpressionHandler) logicalAnd(th *tf.TypeHandler, bh *bc.BlockHolder, l
 	leftBlock := bh.N
 
 	bh.N.NewCondBr(lb, rhsBlock.N, endBlock.N)
-	rv := t.ProcessExpression(rhsBlock, rex)
+	rv := t.ProcessExpression(rhsBlock, ex.Right)
 	if rv == nil {
 		errorutils.Abort(errorutils.InvalidBinaryExpressionOperand)
 	}
@@ -616,10 +649,10 @@ func (t *ExpressionHandler) logicalAnd(th *tf.TypeHandler, bh *bc.BlockHolder, l
 	return buildBooleanFromValue(bh, phi), nil
 }
 
-func (t *ExpressionHandler) logicalOr(th *tf.TypeHandler, bh *bc.BlockHolder, lex, rex ast.Expression) (tf.Var, error) {
+func (t *ExpressionHandler) logicalOr(th *tf.TypeHandler, bh *bc.BlockHolder, ex ast.BinaryExpression) (tf.Var, error) {
 	fn := bh.N.Parent
 
-	lv := t.ProcessExpression(bh, lex)
+	lv := t.ProcessExpression(bh, ex.Left)
 	if lv == nil {
 		errorutils.Abort(errorutils.InvalidBinaryExpressionOperand)
 	}
@@ -636,7 +669,7 @@ func (t *ExpressionHandler) logicalOr(th *tf.TypeHandler, bh *bc.BlockHolder, le
 
 	bh.N.NewCondBr(lb, endBlock.N, rhsBlock.N)
 
-	rv := t.ProcessExpression(rhsBlock, rex)
+	rv := t.ProcessExpression(rhsBlock, ex.Right)
 	if rv == nil {
 		errorutils.Abort(errorutils.InvalidBinaryExpressionOperand)
 	}
@@ -659,10 +692,10 @@ func (t *ExpressionHandler) logicalOr(th *tf.TypeHandler, bh *bc.BlockHolder, le
 	return buildBooleanFromValue(bh, phi), nil
 }
 
-func (t *ExpressionHandler) logicalInstanceOf(th *tf.TypeHandler, bh *bc.BlockHolder, lex, rex ast.Expression) (tf.Var, error) {
-	lv := t.ProcessExpression(bh, lex)
+func (t *ExpressionHandler) logicalInstanceOf(th *tf.TypeHandler, bh *bc.BlockHolder, ex ast.BinaryExpression) (tf.Var, error) {
+	lv := t.ProcessExpression(bh, ex.Left)
 
-	rv := t.ProcessExpression(bh, rex)
+	rv := t.ProcessExpression(bh, ex.Right)
 
 	if lv == nil || rv == nil {
 		errorutils.Abort(errorutils.InvalidBinaryExpressionOperand)
@@ -753,10 +786,10 @@ func not(th *tf.TypeHandler, bh *bc.BlockHolder, v tf.Var) (tf.Var, error) {
 	return buildBooleanFromValue(bh, bh.N.NewXor(vb, one)), nil
 }
 
-func (t *ExpressionHandler) bitwiseOR(th *tf.TypeHandler, bh *bc.BlockHolder, lex, rex ast.Expression) (tf.Var, error) {
-	lv := t.ProcessExpression(bh, lex)
+func (t *ExpressionHandler) bitwiseOR(th *tf.TypeHandler, bh *bc.BlockHolder, ex ast.BinaryExpression) (tf.Var, error) {
+	lv := t.ProcessExpression(bh, ex.Left)
 
-	rv := t.ProcessExpression(bh, rex)
+	rv := t.ProcessExpression(bh, ex.Right)
 
 	if lv == nil || rv == nil {
 		errorutils.Abort(errorutils.InvalidBinaryExpressionOperand)
@@ -785,10 +818,10 @@ func (t *ExpressionHandler) bitwiseOR(th *tf.TypeHandler, bh *bc.BlockHolder, le
 	return nil, fmt.Errorf("unsupported bitwise or operands")
 }
 
-func (t *ExpressionHandler) bitwiseXOR(th *tf.TypeHandler, bh *bc.BlockHolder, lex, rex ast.Expression) (tf.Var, error) {
-	lv := t.ProcessExpression(bh, lex)
+func (t *ExpressionHandler) bitwiseXOR(th *tf.TypeHandler, bh *bc.BlockHolder, ex ast.BinaryExpression) (tf.Var, error) {
+	lv := t.ProcessExpression(bh, ex.Left)
 
-	rv := t.ProcessExpression(bh, rex)
+	rv := t.ProcessExpression(bh, ex.Right)
 
 	if lv == nil || rv == nil {
 		errorutils.Abort(errorutils.InvalidBinaryExpressionOperand)
@@ -818,10 +851,10 @@ func (t *ExpressionHandler) bitwiseXOR(th *tf.TypeHandler, bh *bc.BlockHolder, l
 	return nil, fmt.Errorf("unsupported bitwise xor operands")
 }
 
-func (t *ExpressionHandler) bitwiseAND(th *tf.TypeHandler, bh *bc.BlockHolder, lex, rex ast.Expression) (tf.Var, error) {
-	lv := t.ProcessExpression(bh, lex)
+func (t *ExpressionHandler) bitwiseAND(th *tf.TypeHandler, bh *bc.BlockHolder, ex ast.BinaryExpression) (tf.Var, error) {
+	lv := t.ProcessExpression(bh, ex.Left)
 
-	rv := t.ProcessExpression(bh, rex)
+	rv := t.ProcessExpression(bh, ex.Right)
 
 	if lv == nil || rv == nil {
 		errorutils.Abort(errorutils.InvalidBinaryExpressionOperand)
@@ -851,9 +884,9 @@ func (t *ExpressionHandler) bitwiseAND(th *tf.TypeHandler, bh *bc.BlockHolder, l
 	return nil, fmt.Errorf("unsupported bitwise and operands")
 }
 
-func (t *ExpressionHandler) bitwiseLeftShift(th *tf.TypeHandler, bh *bc.BlockHolder, lex, rex ast.Expression) (tf.Var, error) {
-	lv := t.ProcessExpression(bh, lex)
-	rv := t.ProcessExpression(bh, rex)
+func (t *ExpressionHandler) bitwiseLeftShift(th *tf.TypeHandler, bh *bc.BlockHolder, ex ast.BinaryExpression) (tf.Var, error) {
+	lv := t.ProcessExpression(bh, ex.Left)
+	rv := t.ProcessExpression(bh, ex.Right)
 	if lv == nil || rv == nil {
 		errorutils.Abort(errorutils.InvalidBinaryExpressionOperand)
 	}
@@ -878,9 +911,9 @@ func (t *ExpressionHandler) bitwiseLeftShift(th *tf.TypeHandler, bh *bc.BlockHol
 	return nil, fmt.Errorf("unsupported bitwise left shift operands")
 }
 
-func (t *ExpressionHandler) bitwiseRightShift(th *tf.TypeHandler, bh *bc.BlockHolder, lex, rex ast.Expression) (tf.Var, error) {
-	lv := t.ProcessExpression(bh, lex)
-	rv := t.ProcessExpression(bh, rex)
+func (t *ExpressionHandler) bitwiseRightShift(th *tf.TypeHandler, bh *bc.BlockHolder, ex ast.BinaryExpression) (tf.Var, error) {
+	lv := t.ProcessExpression(bh, ex.Left)
+	rv := t.ProcessExpression(bh, ex.Right)
 	if lv == nil || rv == nil {
 		errorutils.Abort(errorutils.InvalidBinaryExpressionOperand)
 	}
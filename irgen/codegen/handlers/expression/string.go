@@ -38,7 +38,7 @@ func (t *ExpressionHandler) ProcessStringLiteral(bh *bc.BlockHolder, ex ast.Stri
 		constant.NewInt(types.I32, 0),
 		constant.NewInt(types.I32, 0),
 	)
-	allocFn := c.Instance.Funcs[c.FUNC_STRING_ALLOC]
+	allocFn := c.For(bh.N).Funcs[c.FUNC_STRING_ALLOC]
 	s := bh.N.NewCall(allocFn, gep, constant.NewInt(types.I64, int64(len(formatStr))))
 	return tf.NewString(bh, s)
 }
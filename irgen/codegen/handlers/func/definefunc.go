@@ -9,6 +9,7 @@ import (
 	"github.com/llir/llvm/ir/types"
 	"github.com/llir/llvm/ir/value"
 	"github.com/nagarajRPoojari/picasso/irgen/ast"
+	"github.com/nagarajRPoojari/picasso/irgen/codegen/analysis"
 	"github.com/nagarajRPoojari/picasso/irgen/codegen/c"
 	errorutils "github.com/nagarajRPoojari/picasso/irgen/codegen/error"
 	"github.com/nagarajRPoojari/picasso/irgen/codegen/handlers/block"
@@ -41,7 +42,8 @@ func (t *FuncHandler) DefineConstructor(fqClsName string, instance *tf.Class, bh
 	structType := classMeta.StructType()
 	meta := t.st.Classes[fqClsName]
 
-	for name, index := range meta.FieldIndexMap {
+	for _, name := range meta.Fields() {
+		index := meta.FieldIndexMap[name]
 		// if field is not found in meta.VarAST indicating func type, update instance
 		// to point to the function. future function calls on that instance will directly
 		// refer to this pointed function.
@@ -116,6 +118,9 @@ func (t *FuncHandler) DefineFunc(fqClsName string, fn *ast.FunctionDefinitionSta
 	t.st.Vars.AddFunc()
 	defer t.st.Vars.RemoveFunc()
 
+	// array lengths known up front, used to drop redundant bounds checks
+	t.st.FixedLens = analysis.FixedLengths(fn.Body, fn.Parameters, t.st.IsArrayLib)
+
 	fqFuncName := fmt.Sprintf("%s.%s", fqClsName, fn.Name)
 	var f *ir.Func
 	f = t.st.Classes[fqClsName].Methods[fqFuncName]
@@ -169,6 +174,12 @@ func (t *FuncHandler) DefineFunc(fqClsName string, fn *ast.FunctionDefinitionSta
 
 	if fn.ReturnType == nil {
 		bh.N.NewRet(nil)
+		return
+	}
+	// every path returns a value, checked by sema; the end is still
+	// reachable in the IR, e.g. after an if whose branches both return
+	if bh.N.Term == nil {
+		bh.N.NewUnreachable()
 	}
 }
 
@@ -196,6 +207,9 @@ func (t *FuncHandler) DefineMainFunc(fn *ast.FunctionDefinitionStatement, avoid
 	t.st.Vars.AddFunc()
 	defer t.st.Vars.RemoveFunc()
 
+	// array lengths known up front, used to drop redundant bounds checks
+	t.st.FixedLens = analysis.FixedLengths(fn.Body, fn.Parameters, t.st.IsArrayLib)
+
 	var f *ir.Func = t.st.MainFunc
 	bh := bc.NewBlockHolder(bc.VarBlock{Block: f.NewBlock(constants.ENTRY)}, f.NewBlock(""))
 	// t.Init(bh)
@@ -213,7 +227,6 @@ func (t *FuncHandler) DefineMainFunc(fn *ast.FunctionDefinitionStatement, avoid
 			pt := fn.Parameters[i].Type
 			paramType := tf.NewType(t.st.ResolveAlias(pt.Get()), t.st.ResolveAlias(pt.GetUnderlyingType()))
 			t.st.Vars.AddNewVar(p.LocalName, t.st.TypeHandler.BuildVar(bh, paramType, p))
-			fmt.Printf("p.LocalName: %v\n", p.LocalName)
 		}
 	}
 
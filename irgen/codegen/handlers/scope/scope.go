@@ -1,6 +1,7 @@
 package scope
 
 import (
+	"github.com/nagarajRPoojari/picasso/irgen/ast"
 	errorutils "github.com/nagarajRPoojari/picasso/irgen/codegen/error"
 	tf "github.com/nagarajRPoojari/picasso/irgen/codegen/type"
 	bc "github.com/nagarajRPoojari/picasso/irgen/codegen/type/block"
@@ -10,6 +11,10 @@ import (
 type VarTree struct {
 	tree    []map[string]*tf.Var // Stack of local scopes
 	globals map[string]*tf.Var   // Top-level global symbols
+
+	// locs mirrors tree with the declaration site of each variable,
+	// used to point at the original when a name is redeclared.
+	locs []map[string]ast.SourceLoc
 }
 
 // NewVarTree initializes an empty variable tree.
@@ -17,12 +22,14 @@ func NewVarTree() *VarTree {
 	return &VarTree{
 		tree:    make([]map[string]*tf.Var, 0),
 		globals: make(map[string]*tf.Var),
+		locs:    make([]map[string]ast.SourceLoc, 0),
 	}
 }
 
 // AddBlock pushes a new lexical block (e.g., if-statement, loop) onto the stack.
 func (t *VarTree) AddBlock() {
 	t.tree = append(t.tree, make(map[string]*tf.Var))
+	t.locs = append(t.locs, make(map[string]ast.SourceLoc))
 }
 
 // AddFunc pushes a function boundary. It uses a nil marker or double block
@@ -30,6 +37,7 @@ func (t *VarTree) AddBlock() {
 func (t *VarTree) AddFunc() {
 	// Add a marker for function boundary
 	t.tree = append(t.tree, nil)
+	t.locs = append(t.locs, nil)
 	t.AddBlock()
 }
 
@@ -37,6 +45,7 @@ func (t *VarTree) AddFunc() {
 func (t *VarTree) RemoveBlock() {
 	if len(t.tree) > 0 {
 		t.tree = t.tree[:len(t.tree)-1]
+		t.locs = t.locs[:len(t.locs)-1]
 	}
 }
 
@@ -55,6 +64,22 @@ func (t *VarTree) AddNewVar(name string, v tf.Var) {
 	}
 }
 
+// AddNewVarAt is AddNewVar for a variable declared at loc.
+func (t *VarTree) AddNewVarAt(name string, v tf.Var, loc ast.SourceLoc) {
+	t.AddNewVar(name, v)
+	t.locs[len(t.locs)-1][name] = loc
+}
+
+// DeclaredAt returns where a variable of the current scope was declared, if
+// it was added with AddNewVarAt.
+func (t *VarTree) DeclaredAt(name string) (ast.SourceLoc, bool) {
+	if len(t.locs) == 0 || t.locs[len(t.locs)-1] == nil {
+		return ast.SourceLoc{}, false
+	}
+	loc, ok := t.locs[len(t.locs)-1][name]
+	return loc, ok
+}
+
 // RegisterTypeHolders adds a symbol to the global scope.
 func (t *VarTree) RegisterTypeHolders(block *bc.BlockHolder, name string, s tf.Var) {
 	t.globals[name] = &s
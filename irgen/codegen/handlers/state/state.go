@@ -1,6 +1,7 @@
 package state
 
 import (
+	"fmt"
 	"strings"
 	"sync/atomic"
 
@@ -8,13 +9,17 @@ import (
 
 	"github.com/llir/llvm/ir"
 	"github.com/nagarajRPoojari/picasso/irgen/ast"
+	"github.com/nagarajRPoojari/picasso/irgen/codegen/analysis"
 	"github.com/nagarajRPoojari/picasso/irgen/codegen/c"
+	"github.com/nagarajRPoojari/picasso/irgen/codegen/handlers/constants"
 	"github.com/nagarajRPoojari/picasso/irgen/codegen/handlers/identifier"
 	"github.com/nagarajRPoojari/picasso/irgen/codegen/handlers/scope"
 	function "github.com/nagarajRPoojari/picasso/irgen/codegen/libs/func"
+	"github.com/nagarajRPoojari/picasso/irgen/sema"
 
 	tf "github.com/nagarajRPoojari/picasso/irgen/codegen/type"
 	bc "github.com/nagarajRPoojari/picasso/irgen/codegen/type/block"
+	errorsx "github.com/nagarajRPoojari/picasso/irgen/error"
 )
 
 type PackageEntry struct {
@@ -107,6 +112,25 @@ type State struct {
 
 	// atomic block count
 	AC *atomic.Int32 // kill me for this overkill
+
+	// CheckedArith makes integer add/sub/mul/div trap on overflow and
+	// division by zero instead of silently wrapping.
+	CheckedArith bool
+
+	// LoopBounds holds facts about enclosing foreach index variables,
+	// innermost last. Used to skip provably redundant bounds checks.
+	LoopBounds []analysis.LoopBound
+
+	// FixedLens maps array variables of the current function to their
+	// statically known minimum first dimension.
+	FixedLens map[string]int64
+
+	// Diagnostics receives the errors of the build, see errorsx.Collector.
+	Diagnostics *errorsx.Collector
+
+	// Info is the typed program produced by the semantic pass, shared by
+	// all packages of a build. Nil if the pass did not run.
+	Info *sema.Info
 }
 
 type FFIDeclarations struct {
@@ -134,10 +158,59 @@ func NewCompileState(outputDir string, pkgName string, module *ir.Module) *State
 		IdentifierBuilder: identifier.NewIdentifierBuilder(pkgName),
 		AliasMap:          make(map[string]string),
 		LibMethods:        make(map[string]function.Func),
-		CI:                c.Instance,
+		CI:                c.Of(module),
 		Imports:           make(map[string]PackageEntry),
 		AC:                &atomic.Int32{},
+		FixedLens:         make(map[string]int64),
+	}
+}
+
+// IsArrayLib reports whether alias refers to the builtin array module
+// imported in current package.
+func (t *State) IsArrayLib(alias string) bool {
+	imp, ok := t.Imports[alias]
+	if !ok || imp.Name != analysis.ARRAY_MODULE {
+		return false
+	}
+	_, ok = t.LibMethods[fmt.Sprintf("%s.%s.%s", constants.BUILTIN, imp.Name, analysis.ARRAY_LEN)]
+	return ok
+}
+
+// SymbolOf returns the symbol the semantic pass resolved e to, nil if e is
+// not a variable, parameter or member, or if the pass did not run.
+func (t *State) SymbolOf(e ast.Expression) *sema.Symbol {
+	if t.Info == nil {
+		return nil
+	}
+	return t.Info.SymbolOf(e)
+}
+
+// CastTarget returns the type a value assigned to e is implicitly cast to:
+// the type the semantic pass found for e, fallback if it found none or did
+// not run. Arrays are not cast, fallback is returned for them too.
+func (t *State) CastTarget(e ast.Expression, fallback string) string {
+	if t.Info == nil {
+		return fallback
+	}
+	typ := t.Info.TypeOf(e)
+	if typ == nil || typ.Get() == constants.ARRAY {
+		return fallback
+	}
+	// fully qualified already, e.g. start.Point
+	return typ.Get()
+}
+
+// ProvenIndices returns how many leading indices of ex are statically known
+// to be within range, so their runtime bounds checks can be skipped.
+func (t *State) ProvenIndices(ex ast.ComputedExpression) int {
+	sym, ok := ex.Member.(ast.SymbolExpression)
+	if !ok || len(ex.Indices) == 0 {
+		return 0
+	}
+	if analysis.InBounds(t.LoopBounds, t.FixedLens, sym.Value, ex.Indices[0]) {
+		return 1
 	}
+	return 0
 }
 
 func (t *State) ResolveAlias(aliasField string) string {
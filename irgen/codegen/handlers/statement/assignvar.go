@@ -108,7 +108,7 @@ func (t *StatementHandler) assignToTarget(bh *bc.BlockHolder, expHandler *expres
 		}
 
 		if v.NativeTypeString() != constants.ARRAY {
-			typeName := v.NativeTypeString()
+			typeName := t.st.CastTarget(m, v.NativeTypeString())
 			casted := t.st.TypeHandler.ImplicitTypeCast(bh, typeName, rhs.Load(bh))
 			castedVar := t.st.TypeHandler.BuildVar(bh, tf.NewType(typeName), casted)
 			v.Update(bh, castedVar.Load(bh))
@@ -133,7 +133,7 @@ func (t *StatementHandler) assignToTarget(bh *bc.BlockHolder, expHandler *expres
 		index := classMeta.FieldIndexMap[fqName]
 		fieldType := structType.Fields[index]
 
-		typeName := t.st.ResolveAlias(classMeta.VarAST[fqName].ExplicitType.Get())
+		typeName := t.st.CastTarget(m, t.st.ResolveAlias(classMeta.VarAST[fqName].ExplicitType.Get()))
 
 		if resolveRootMember(m) != constants.THIS {
 			if _, ok := classMeta.InternalFields[fqName]; ok {
@@ -148,6 +148,9 @@ func (t *StatementHandler) assignToTarget(bh *bc.BlockHolder, expHandler *expres
 		cls.UpdateField(bh, t.st.TypeHandler, index, rhs.Load(bh), fieldType)
 
 	case ast.ComputedExpression:
+		if _, ok := m.Indices[len(m.Indices)-1].(ast.RangeExpression); ok {
+			errorutils.Abort(errorutils.SliceAssignmentError)
+		}
 		base := expHandler.ProcessExpression(bh, m.Member)
 		indices := make([]value.Value, 0)
 		for _, idx := range m.Indices {
@@ -158,18 +161,19 @@ func (t *StatementHandler) assignToTarget(bh *bc.BlockHolder, expHandler *expres
 		}
 
 		arr := base.(*tf.Array)
+		proven := t.st.ProvenIndices(m)
 
 		if len(indices) < arr.Rank {
 			rhsArray, ok := rhs.(*tf.Array)
 			if !ok {
 				errorutils.Abort(errorutils.InternalError, errorutils.InternalMemberExprError, "partial array indexing requires array value on RHS")
 			}
-			arr.StoreSubarrayByIndex(bh, indices, rhsArray)
+			arr.StoreSubarrayByIndexProven(bh, indices, rhsArray, proven)
 		} else {
-			needed := arr.ElementTypeString
+			needed := t.st.CastTarget(m, arr.ElementTypeString)
 			casted := t.st.TypeHandler.ImplicitTypeCast(bh, needed, rhs.Load(bh))
 			c := t.st.TypeHandler.BuildVar(bh, tf.NewType(needed), casted)
-			arr.StoreByIndex(bh, indices, c.Load(bh))
+			arr.StoreByIndexProven(bh, indices, c.Load(bh), proven)
 		}
 	}
 }
@@ -7,6 +7,7 @@ import (
 	"github.com/nagarajRPoojari/picasso/irgen/codegen/handlers/expression"
 	tf "github.com/nagarajRPoojari/picasso/irgen/codegen/type"
 	bc "github.com/nagarajRPoojari/picasso/irgen/codegen/type/block"
+	errorsx "github.com/nagarajRPoojari/picasso/irgen/error"
 )
 
 // DeclareVariable handles variable declarations, optionally initializing
@@ -35,9 +36,16 @@ func (t *StatementHandler) DeclareVariable(bh *bc.BlockHolder, st *ast.VariableD
 
 	// Check for redeclarations
 	for _, id := range identifiers {
-		if t.st.Vars.Exists(id) {
-			errorutils.Abort(errorutils.VariableRedeclaration, id)
+		if !t.st.Vars.Exists(id) {
+			continue
 		}
+		if prev, ok := t.st.Vars.DeclaredAt(id); ok {
+			errorutils.AbortWithNotes(
+				[]errorsx.Note{errorutils.NoteAt(prev, errorutils.PreviousDeclaration, id)},
+				errorutils.VariableRedeclaration, id,
+			)
+		}
+		errorutils.Abort(errorutils.VariableRedeclaration, id)
 	}
 
 	// if rhs is provided then must provide for all lhs types: sucessfull tuple unpacking
@@ -55,7 +63,7 @@ func (t *StatementHandler) DeclareVariable(bh *bc.BlockHolder, st *ast.VariableD
 	}
 
 	for i, id := range identifiers {
-		t.st.Vars.AddNewVar(id, declaredVars[i])
+		t.st.Vars.AddNewVarAt(id, declaredVars[i], st.SourceLoc)
 	}
 }
 
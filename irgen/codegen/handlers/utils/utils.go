@@ -255,6 +255,6 @@ func AddYield(ac *atomic.Int32, bh *bc.BlockHolder) {
 	if ac.Load() > 0 {
 		return
 	}
-	yieldFunc := c.Instance.Funcs[c.FUNC_SELF_YIELD]
+	yieldFunc := c.For(bh.N).Funcs[c.FUNC_SELF_YIELD]
 	bh.N.NewCall(yieldFunc)
 }
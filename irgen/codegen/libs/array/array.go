@@ -25,6 +25,7 @@ func (t *ArrayHandler) ListAllFuncs() map[string]function.Func {
 	funcs["len"] = t.len
 	funcs["shape"] = t.shape
 	funcs["append"] = t.append
+	funcs["copy"] = t.copy
 	return funcs
 }
 
@@ -41,7 +42,7 @@ func (t *ArrayHandler) create(_ *ir.Func, th *tf.TypeHandler, module *ir.Module,
 
 func (t *ArrayHandler) append(_ *ir.Func, th *tf.TypeHandler, module *ir.Module, bh *bc.BlockHolder, args []typedef.Var) typedef.Var {
 	arr := args[0].(*tf.Array)
-	extendFn := c.Instance.Funcs[c.FUNC_EXTEND_ARRAY]
+	extendFn := c.For(bh.N).Funcs[c.FUNC_EXTEND_ARRAY]
 	// Get the new length before extending
 	lastIdx := arr.Len(bh).Load(bh)
 
@@ -68,3 +69,8 @@ func (t *ArrayHandler) shape(_ *ir.Func, th *tf.TypeHandler, module *ir.Module,
 	shape := arr.LoadShapeArray(bh)
 	return shape
 }
+
+func (t *ArrayHandler) copy(_ *ir.Func, th *tf.TypeHandler, module *ir.Module, bh *bc.BlockHolder, args []typedef.Var) typedef.Var {
+	arr := args[0].(*tf.Array)
+	return arr.Copy(bh)
+}
@@ -0,0 +1,217 @@
+// Package assert implements builtin/assert, the checks of irgen test. Each
+// check calls a runtime function which, when the check fails, prints what
+// was expected and the stack trace and ends the process with status 1.
+package assert
+
+import (
+	"fmt"
+
+	"github.com/llir/llvm/ir"
+	"github.com/llir/llvm/ir/types"
+	"github.com/llir/llvm/ir/value"
+	"github.com/nagarajRPoojari/picasso/irgen/codegen/c"
+	errorutils "github.com/nagarajRPoojari/picasso/irgen/codegen/error"
+	function "github.com/nagarajRPoojari/picasso/irgen/codegen/libs/func"
+	tf "github.com/nagarajRPoojari/picasso/irgen/codegen/type"
+	bc "github.com/nagarajRPoojari/picasso/irgen/codegen/type/block"
+	"github.com/nagarajRPoojari/picasso/irgen/codegen/type/primitives/boolean"
+	"github.com/nagarajRPoojari/picasso/irgen/codegen/type/primitives/floats"
+	"github.com/nagarajRPoojari/picasso/irgen/codegen/type/primitives/ints"
+)
+
+// Runtime functions of the checks, see runtime/headers/asserts.h.
+const (
+	FUNC_EQUAL_INT    = "__public__assert_equal_int"
+	FUNC_EQUAL_UINT   = "__public__assert_equal_uint"
+	FUNC_EQUAL_FLOAT  = "__public__assert_equal_float"
+	FUNC_EQUAL_BOOL   = "__public__assert_equal_bool"
+	FUNC_EQUAL_STRING = "__public__assert_equal_string"
+	FUNC_EQUAL_PTR    = "__public__assert_equal_ptr"
+	FUNC_NOT_NULL     = "__public__assert_not_null"
+	FUNC_FAILS        = "__public__assert_fails"
+)
+
+type AssertHandler struct {
+}
+
+func NewAssertHandler() *AssertHandler {
+	return &AssertHandler{}
+}
+
+func (t *AssertHandler) ListAllFuncs() map[string]function.Func {
+	funcs := make(map[string]function.Func)
+	funcs["equal"] = t.equal
+	funcs["notNull"] = t.notNull
+	funcs["fails"] = t.fails
+	return funcs
+}
+
+// kind is how two values are compared by equal.
+type kind int
+
+const (
+	kindInvalid kind = iota
+	kindSigned
+	kindUnsigned
+	kindFloat
+	kindBool
+	kindString
+	kindPointer
+	kindNull
+)
+
+func classify(v tf.Var) kind {
+	switch v.(type) {
+	case *ints.Int8, *ints.Int16, *ints.Int32, *ints.Int64:
+		return kindSigned
+	case *ints.UInt8, *ints.UInt16, *ints.UInt32, *ints.UInt64:
+		return kindUnsigned
+	case *floats.Float16, *floats.Float32, *floats.Float64:
+		return kindFloat
+	case *boolean.Boolean:
+		return kindBool
+	case *tf.String:
+		return kindString
+	case *tf.Array, *tf.Class, *tf.InterfaceH:
+		return kindPointer
+	case *tf.NullVar:
+		return kindNull
+	}
+	return kindInvalid
+}
+
+// common returns the kind got and want are compared as. Integer literals
+// are signed, so a signed and an unsigned integer compare as unsigned, and
+// integers compare with floats as floats.
+func common(got, want kind) kind {
+	switch {
+	case got == kindInvalid || want == kindInvalid:
+		return kindInvalid
+	case got == want:
+		return got
+	case got == kindNull:
+		return common(want, got)
+	case want == kindNull:
+		if got == kindString || got == kindPointer {
+			return got
+		}
+	case got == kindFloat || want == kindFloat:
+		if isInt(got) || isInt(want) {
+			return kindFloat
+		}
+	case isInt(got) && isInt(want):
+		return kindUnsigned
+	}
+	return kindInvalid
+}
+
+func isInt(k kind) bool {
+	return k == kindSigned || k == kindUnsigned
+}
+
+// equal checks that got equals want: integers and floats by value, strings
+// by their bytes, objects and arrays by identity.
+func (t *AssertHandler) equal(_ *ir.Func, th *tf.TypeHandler, module *ir.Module, bh *bc.BlockHolder, args []tf.Var) tf.Var {
+	if len(args) != 2 {
+		errorutils.Abort(errorutils.ParamsError, "assert.equal", "2 arguments")
+	}
+	got, want := args[0], args[1]
+	k := common(classify(got), classify(want))
+
+	var name string
+	var typ types.Type
+	switch k {
+	case kindSigned:
+		name, typ = FUNC_EQUAL_INT, types.I64
+	case kindUnsigned:
+		name, typ = FUNC_EQUAL_UINT, types.I64
+	case kindFloat:
+		name, typ = FUNC_EQUAL_FLOAT, types.Double
+	case kindBool:
+		name, typ = FUNC_EQUAL_BOOL, types.I32
+	case kindString:
+		name, typ = FUNC_EQUAL_STRING, types.NewPointer(c.For(bh.N).Types[c.TYPE_STRING])
+	case kindPointer, kindNull:
+		name, typ = FUNC_EQUAL_PTR, types.I8Ptr
+	default:
+		errorutils.Abort(errorutils.TypeError, "assert.equal",
+			fmt.Sprintf("cannot compare %s with %s", got.NativeTypeString(), want.NativeTypeString()))
+	}
+
+	fn := declare(module, name, typ, typ)
+	bh.N.NewCall(fn, convert(th, bh, k, got, typ), convert(th, bh, k, want, typ))
+	return nil
+}
+
+// convert loads v as a typ argument of the check of kind k.
+func convert(th *tf.TypeHandler, bh *bc.BlockHolder, k kind, v tf.Var, typ types.Type) value.Value {
+	val := v.Load(bh)
+	switch k {
+	case kindSigned:
+		return th.ImplicitIntCast(bh, val, types.I64)
+	case kindUnsigned:
+		if classify(v) == kindSigned {
+			return th.ImplicitIntCast(bh, val, types.I64)
+		}
+		return th.ImplicitUnsignedIntCast(bh, val, types.I64)
+	case kindFloat:
+		switch classify(v) {
+		case kindSigned:
+			return bh.N.NewSIToFP(th.ImplicitIntCast(bh, val, types.I64), types.Double)
+		case kindUnsigned:
+			return bh.N.NewUIToFP(th.ImplicitUnsignedIntCast(bh, val, types.I64), types.Double)
+		}
+		return th.ImplicitFloatCast(bh, val, types.Double)
+	case kindBool:
+		return bh.N.NewZExt(val, types.I32)
+	}
+	if val.Type().Equal(typ) {
+		return val
+	}
+	return bh.N.NewBitCast(val, typ)
+}
+
+// notNull checks that an object, array or string is not null.
+func (t *AssertHandler) notNull(_ *ir.Func, th *tf.TypeHandler, module *ir.Module, bh *bc.BlockHolder, args []tf.Var) tf.Var {
+	if len(args) != 1 {
+		errorutils.Abort(errorutils.ParamsError, "assert.notNull", "1 argument")
+	}
+	switch classify(args[0]) {
+	case kindString, kindPointer, kindNull:
+	default:
+		errorutils.Abort(errorutils.TypeError, "assert.notNull",
+			fmt.Sprintf("%s cannot be null", args[0].NativeTypeString()))
+	}
+
+	fn := declare(module, FUNC_NOT_NULL, types.I8Ptr)
+	bh.N.NewCall(fn, convert(th, bh, kindPointer, args[0], types.I8Ptr))
+	return nil
+}
+
+// fails fails the test with the given message, e.g. in a branch that should
+// not be reached.
+func (t *AssertHandler) fails(_ *ir.Func, th *tf.TypeHandler, module *ir.Module, bh *bc.BlockHolder, args []tf.Var) tf.Var {
+	if len(args) != 1 || classify(args[0]) != kindString {
+		errorutils.Abort(errorutils.ParamsError, "assert.fails", "1 string argument")
+	}
+
+	str := types.NewPointer(c.For(bh.N).Types[c.TYPE_STRING])
+	fn := declare(module, FUNC_FAILS, str)
+	bh.N.NewCall(fn, convert(th, bh, kindString, args[0], str))
+	return nil
+}
+
+// declare returns the declaration of the runtime function name taking
+// params, declaring it in module on first use.
+func declare(module *ir.Module, name string, params ...types.Type) *ir.Func {
+	for _, f := range module.Funcs {
+		if f.Name() == name {
+			return f
+		}
+	}
+	irParams := make([]*ir.Param, len(params))
+	for i, p := range params {
+		irParams[i] = ir.NewParam("", p)
+	}
+	return module.NewFunc(name, types.Void, irParams...)
+}
@@ -7,3 +7,18 @@ import (
 )
 
 type Func func(f *ir.Func, typeHandler *typedef.TypeHandler, module *ir.Module, block *bc.BlockHolder, args []typedef.Var) typedef.Var
+
+// FormatError is an argument of a call of a lib function that does not match
+// the format string the call passes it, e.g. a string for %d.
+type FormatError struct {
+	// Arg is the index of the argument in the call, 0 for the format string
+	// itself, e.g. for a verb with no argument.
+	Arg int
+	// Verb is the verb the argument is for, e.g. %d, if any.
+	Verb string
+	Msg  string
+}
+
+func (e *FormatError) Error() string {
+	return e.Msg
+}
@@ -0,0 +1,339 @@
+package io
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/llir/llvm/ir/types"
+	function "github.com/nagarajRPoojari/picasso/irgen/codegen/libs/func"
+	typedef "github.com/nagarajRPoojari/picasso/irgen/codegen/type"
+	"github.com/nagarajRPoojari/picasso/irgen/codegen/type/primitives/boolean"
+	"github.com/nagarajRPoojari/picasso/irgen/codegen/type/primitives/floats"
+	"github.com/nagarajRPoojari/picasso/irgen/codegen/type/primitives/ints"
+)
+
+// verb is a conversion of a format string, e.g. %-8.2lf.
+type verb struct {
+	spec   string // as written
+	length string // length modifier, e.g. l
+	conv   byte
+	// stars is the number of * widths and precisions of printf, each
+	// taking an int argument before the value
+	stars int
+	// suppress is set for the assignment suppressing %*d of scanf, which
+	// takes no argument
+	suppress bool
+}
+
+// lengths are the length modifiers of C, longest first.
+var lengths = []string{"hh", "ll", "h", "l", "j", "z", "t", "L"}
+
+const (
+	intVerbs   = "diuoxX"
+	floatVerbs = "fFeEgGaA"
+)
+
+// parseFormat returns the verbs of format, as printf reads it or as scanf
+// does if scan is set.
+func parseFormat(format string, scan bool) ([]verb, error) {
+	var verbs []verb
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		start := i
+		i++
+		if i < len(format) && format[i] == '%' {
+			continue
+		}
+
+		var v verb
+		digits := func() {
+			for i < len(format) && format[i] >= '0' && format[i] <= '9' {
+				i++
+			}
+		}
+		if scan {
+			if i < len(format) && format[i] == '*' {
+				v.suppress = true
+				i++
+			}
+			digits()
+		} else {
+			for i < len(format) && strings.IndexByte("-+ #0'", format[i]) >= 0 {
+				i++
+			}
+			if i < len(format) && format[i] == '*' {
+				v.stars++
+				i++
+			} else {
+				digits()
+			}
+			if i < len(format) && format[i] == '.' {
+				i++
+				if i < len(format) && format[i] == '*' {
+					v.stars++
+					i++
+				} else {
+					digits()
+				}
+			}
+		}
+		for _, l := range lengths {
+			if strings.HasPrefix(format[i:], l) {
+				v.length = l
+				i += len(l)
+				break
+			}
+		}
+		if i >= len(format) {
+			return nil, &function.FormatError{Msg: fmt.Sprintf("%s at the end of the format has no verb", format[start:])}
+		}
+
+		v.conv = format[i]
+		if scan && v.conv == '[' {
+			// a ] right after [ or [^ is one of the set
+			end := i + 1
+			if end < len(format) && format[end] == '^' {
+				end++
+			}
+			if end < len(format) && format[end] == ']' {
+				end++
+			}
+			closing := strings.IndexByte(format[end:], ']')
+			if closing == -1 {
+				return nil, &function.FormatError{Msg: fmt.Sprintf("%s has no closing ]", format[start:])}
+			}
+			i = end + closing
+		}
+		v.spec = format[start : i+1]
+		if err := v.valid(scan); err != nil {
+			return nil, err
+		}
+		verbs = append(verbs, v)
+	}
+	return verbs, nil
+}
+
+// valid reports a verb that is not one of printf, or scanf if scan is set,
+// or one with a length modifier that does not apply to it.
+func (v verb) valid(scan bool) error {
+	errorf := func(format string, args ...any) error {
+		return &function.FormatError{Verb: v.spec, Msg: fmt.Sprintf(format, args...)}
+	}
+	switch {
+	case v.conv == 'n':
+		return errorf("%s is not supported", v.spec)
+	case strings.IndexByte(intVerbs, v.conv) >= 0:
+		if v.length == "L" {
+			return errorf("%s: length L does not apply to integers", v.spec)
+		}
+	case strings.IndexByte(floatVerbs, v.conv) >= 0:
+		switch v.length {
+		case "", "l":
+		case "L":
+			return errorf("%s: long double is not supported", v.spec)
+		default:
+			return errorf("%s: length %s does not apply to floats", v.spec, v.length)
+		}
+	case v.conv == 'c' || v.conv == 's' || v.conv == 'p' || scan && v.conv == '[':
+		if v.length != "" {
+			return errorf("%s: length %s does not apply to %%%c", v.spec, v.length, v.conv)
+		}
+	default:
+		return errorf("unknown verb %s", v.spec)
+	}
+	return nil
+}
+
+// checkPrintf checks the arguments of a call of printf, after its format,
+// against the verbs of format. Any integer or bool goes with an integer
+// verb, of any length, as sprintf promotes them, and any float with a float
+// verb.
+func checkPrintf(format string, args []typedef.Var) error {
+	verbs, err := parseFormat(format, false)
+	if err != nil {
+		return err
+	}
+	n := 0
+	for _, v := range verbs {
+		for range v.stars {
+			if n == len(args) {
+				return missing(v, verbs)
+			}
+			if _, ok := intBits(args[n]); !ok {
+				return mismatch(v, n, args[n], "an integer width or precision", "")
+			}
+			n++
+		}
+		if n == len(args) {
+			return missing(v, verbs)
+		}
+
+		arg := args[n]
+		var want, hint string
+		switch {
+		case v.conv == 'c' || strings.IndexByte(intVerbs, v.conv) >= 0:
+			if _, ok := intBits(arg); !ok {
+				if _, ok := arg.(*boolean.Boolean); !ok || v.conv == 'c' {
+					want = "an integer"
+				}
+			}
+		case strings.IndexByte(floatVerbs, v.conv) >= 0:
+			if _, ok := floatBits(arg); !ok {
+				want = "a float"
+			}
+		case v.conv == 's':
+			if !isString(arg) {
+				want = "a string"
+			}
+		case v.conv == 'p':
+			switch arg.(type) {
+			case *typedef.String, *typedef.Array, *typedef.Class, *typedef.InterfaceH, *typedef.NullVar:
+			default:
+				want = "an object, array or string"
+			}
+		}
+		if want != "" {
+			return mismatch(v, n, arg, want, hint)
+		}
+		n++
+	}
+	return extra(n, args, verbs)
+}
+
+// checkScanf checks the arguments of a call of scanf, after its format,
+// against the verbs of format. scanf stores through the arguments, so an
+// integer or float must be as wide as its verb says.
+func checkScanf(format string, args []typedef.Var) error {
+	verbs, err := parseFormat(format, true)
+	if err != nil {
+		return err
+	}
+	n := 0
+	for _, v := range verbs {
+		if v.suppress {
+			continue
+		}
+		if n == len(args) {
+			return missing(v, verbs)
+		}
+
+		arg := args[n]
+		var want, hint string
+		switch {
+		case strings.IndexByte(intVerbs, v.conv) >= 0:
+			bits := map[string]int{"hh": 8, "h": 16, "": 32}[v.length]
+			if bits == 0 {
+				bits = 64
+			}
+			if got, ok := intBits(arg); !ok || got != bits {
+				want = fmt.Sprintf("an int%d or uint%d", bits, bits)
+				if ok {
+					hint = fmt.Sprintf("%%%s%c", map[int]string{8: "hh", 16: "h", 32: "", 64: "l"}[got], v.conv)
+				}
+			}
+		case v.conv == 'c':
+			if got, ok := intBits(arg); !ok || got != 8 {
+				want = "an int8 or uint8"
+			}
+		case strings.IndexByte(floatVerbs, v.conv) >= 0:
+			bits := 32
+			if v.length == "l" {
+				bits = 64
+			}
+			if got, ok := floatBits(arg); !ok || got != bits {
+				want = fmt.Sprintf("a float%d", bits)
+				if got == 32 || got == 64 {
+					hint = fmt.Sprintf("%%%s%c", map[int]string{32: "", 64: "l"}[got], v.conv)
+				}
+			}
+		case v.conv == 's' || v.conv == '[':
+			if !isString(arg) {
+				want = "a string"
+			}
+		}
+		if want != "" {
+			return mismatch(v, n, arg, want, hint)
+		}
+		n++
+	}
+	return extra(n, args, verbs)
+}
+
+// isString reports whether v is a string, which a string field of a class
+// is loaded as an object of the string struct.
+func isString(v typedef.Var) bool {
+	switch v := v.(type) {
+	case *typedef.String:
+		return true
+	case *typedef.Class:
+		st, ok := v.UDT.ElemType.(*types.StructType)
+		return ok && st.Name() == typedef.STRINGSTRUCT.Name()
+	}
+	return false
+}
+
+func intBits(v typedef.Var) (int, bool) {
+	switch v.(type) {
+	case *ints.Int8, *ints.UInt8:
+		return 8, true
+	case *ints.Int16, *ints.UInt16:
+		return 16, true
+	case *ints.Int32, *ints.UInt32:
+		return 32, true
+	case *ints.Int64, *ints.UInt64:
+		return 64, true
+	}
+	return 0, false
+}
+
+func floatBits(v typedef.Var) (int, bool) {
+	switch v.(type) {
+	case *floats.Float16:
+		return 16, true
+	case *floats.Float32:
+		return 32, true
+	case *floats.Float64:
+		return 64, true
+	}
+	return 0, false
+}
+
+// mismatch reports the argument at n, after the format, which v does not
+// take, and the verb that would, if hint is set.
+func mismatch(v verb, n int, arg typedef.Var, want, hint string) error {
+	msg := fmt.Sprintf("%s wants %s, got %s", v.spec, want, arg.NativeTypeString())
+	if hint != "" {
+		msg += fmt.Sprintf(", use %s for it", hint)
+	}
+	return &function.FormatError{Arg: n + 1, Verb: v.spec, Msg: msg}
+}
+
+func missing(v verb, verbs []verb) error {
+	return &function.FormatError{
+		Verb: v.spec,
+		Msg:  fmt.Sprintf("%s has no argument, the format takes %d", v.spec, countArgs(verbs)),
+	}
+}
+
+func extra(n int, args []typedef.Var, verbs []verb) error {
+	if n == len(args) {
+		return nil
+	}
+	return &function.FormatError{
+		Arg: n + 1,
+		Msg: fmt.Sprintf("extra argument, the format takes %d but got %d", countArgs(verbs), len(args)),
+	}
+}
+
+// countArgs returns the number of arguments verbs take.
+func countArgs(verbs []verb) int {
+	n := 0
+	for _, v := range verbs {
+		if !v.suppress {
+			n += 1 + v.stars
+		}
+	}
+	return n
+}
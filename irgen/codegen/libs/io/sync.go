@@ -9,6 +9,7 @@ import (
 	function "github.com/nagarajRPoojari/picasso/irgen/codegen/libs/func"
 	typedef "github.com/nagarajRPoojari/picasso/irgen/codegen/type"
 	bc "github.com/nagarajRPoojari/picasso/irgen/codegen/type/block"
+	"github.com/nagarajRPoojari/picasso/irgen/codegen/type/primitives/boolean"
 	"github.com/nagarajRPoojari/picasso/irgen/codegen/type/primitives/floats"
 	"github.com/nagarajRPoojari/picasso/irgen/codegen/type/primitives/ints"
 )
@@ -32,6 +33,20 @@ func (t *SyncIO) ListAllFuncs() map[string]function.Func {
 	return funcs
 }
 
+// CheckFormat checks the arguments of a call of fn after its format, a
+// string literal, against the verbs of format.
+func (t *SyncIO) CheckFormat(fn, format string, args []typedef.Var) error {
+	switch fn {
+	case ALIAS_SPRINTF:
+		return checkPrintf(format, args)
+	case ALIAS_SSCANF:
+		return checkScanf(format, args)
+	}
+	return nil
+}
+
+// sprintf passes its arguments as C passes variadic ones: integers
+// narrower than int and bools as int, floats as double.
 func (t *SyncIO) sprintf(f *ir.Func, typeHandler *typedef.TypeHandler, module *ir.Module, bh *bc.BlockHolder, args []typedef.Var) typedef.Var {
 	castedArgs := []value.Value{args[0].Load(bh)} // The Format String
 
@@ -42,6 +57,9 @@ func (t *SyncIO) sprintf(f *ir.Func, typeHandler *typedef.TypeHandler, module *i
 			continue
 		}
 		switch arg.(type) {
+		case *boolean.Boolean:
+			res := typeHandler.ImplicitUnsignedIntCast(bh, val, types.I32)
+			castedArgs = append(castedArgs, res)
 		case *ints.Int8, *ints.Int16, *ints.Int32:
 			res := typeHandler.ImplicitIntCast(bh, val, types.I32)
 			castedArgs = append(castedArgs, res)
@@ -2,16 +2,27 @@ package libs
 
 import (
 	"github.com/nagarajRPoojari/picasso/irgen/codegen/libs/array"
+	"github.com/nagarajRPoojari/picasso/irgen/codegen/libs/assert"
 	function "github.com/nagarajRPoojari/picasso/irgen/codegen/libs/func"
 	"github.com/nagarajRPoojari/picasso/irgen/codegen/libs/io"
 	"github.com/nagarajRPoojari/picasso/irgen/codegen/libs/strings"
 	types "github.com/nagarajRPoojari/picasso/irgen/codegen/libs/type"
+	typedef "github.com/nagarajRPoojari/picasso/irgen/codegen/type"
 )
 
 type Module interface {
 	ListAllFuncs() map[string]function.Func
 }
 
+// FormatChecker is implemented by modules with functions taking a format
+// string, e.g. syncio.printf, to check at compile time the arguments of the
+// calls passing a string literal as format. CheckFormat returns a
+// *function.FormatError for the first argument, after the format, that
+// does not match it.
+type FormatChecker interface {
+	CheckFormat(fn, format string, args []typedef.Var) error
+}
+
 var ModuleList = make(map[string]Module)
 
 func init() {
@@ -19,4 +30,5 @@ func init() {
 	ModuleList["array"] = array.NewArrayHandler()
 	ModuleList["syncio"] = io.NewSyncIO()
 	ModuleList["strings"] = strings.NewStringsHandler()
+	ModuleList["assert"] = assert.NewAssertHandler()
 }
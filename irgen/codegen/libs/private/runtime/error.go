@@ -2,6 +2,7 @@ package rterr
 
 import (
 	"fmt"
+	"sync"
 
 	"github.com/llir/llvm/ir"
 	"github.com/llir/llvm/ir/constant"
@@ -17,17 +18,29 @@ type ErrorHandler struct {
 	err *ir.Func
 }
 
-var Instance *ErrorHandler
+// handlers holds the ErrorHandler of every module being generated, keyed by
+// *ir.Module.
+var handlers sync.Map
 
 func InitErrorHandler(mod *ir.Module) *ErrorHandler {
-	Instance = &ErrorHandler{
+	t := &ErrorHandler{
 		err: mod.NewFunc(RUNTIME_ERR, types.Void, ir.NewParam("msg", types.I8Ptr)),
 	}
-	return Instance
+	handlers.Store(mod, t)
+	return t
 }
 
-func (t *ErrorHandler) RaiseRTError(block *ir.Block, msg string) {
+// Release forgets the handler of mod once it has been generated.
+func Release(mod *ir.Module) {
+	handlers.Delete(mod)
+}
+
+// RaiseRTError ends block with a call reporting msg at runtime, using the
+// handler of the module containing block.
+func RaiseRTError(block *ir.Block, msg string) {
 	m := block.Parent.Parent
+	h, _ := handlers.Load(m)
+	t := h.(*ErrorHandler)
 	strConst := constant.NewCharArrayFromString(fmt.Sprintf("===== %s", msg) + "\x00")
 	msgGlobal := m.NewGlobalDef("", strConst)
 	msgGlobal.Immutable = true
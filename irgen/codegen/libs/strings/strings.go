@@ -25,13 +25,13 @@ func (t *StringsHandler) ListAllFuncs() map[string]function.Func {
 }
 
 func (t *StringsHandler) substring(_ *ir.Func, th *tf.TypeHandler, module *ir.Module, bh *bc.BlockHolder, args []typedef.Var) typedef.Var {
-	fn := c.Instance.Funcs[c.FUNC_STRING_SUBSTRING]
+	fn := c.For(bh.N).Funcs[c.FUNC_STRING_SUBSTRING]
 	s := bh.N.NewCall(fn, args[0].Load(bh), args[1].Load(bh), args[2].Load(bh))
 	return tf.NewString(bh, s)
 }
 
 func (t *StringsHandler) format(_ *ir.Func, th *tf.TypeHandler, module *ir.Module, bh *bc.BlockHolder, args []typedef.Var) typedef.Var {
-	fn := c.Instance.Funcs[c.FUNC_STRING_FORMAT]
+	fn := c.For(bh.N).Funcs[c.FUNC_STRING_FORMAT]
 	params := []value.Value{}
 	for _, a := range args {
 		params = append(params, a.Load(bh))
@@ -58,7 +58,7 @@ func (t *TypeHandler) TypeOf(_ *ir.Func, typeHandler *typedef.TypeHandler, modul
 		constant.NewInt(types.I32, 0),
 	)
 
-	allocFn := c.Instance.Funcs[c.FUNC_STRING_ALLOC]
+	allocFn := c.For(bh.N).Funcs[c.FUNC_STRING_ALLOC]
 	s := bh.N.NewCall(allocFn, gep, constant.NewInt(types.I64, int64(len(typ))))
 	return typedef.NewString(bh, s)
 }
@@ -54,15 +54,18 @@ func (t *LLVM) AddImportEntry(entry state.PackageEntry) {
 	t.st.Imports[entry.Alias] = entry
 }
 
-func (t *LLVM) Dump(outputDir string, file string) {
+func (t *LLVM) Dump(outputDir string, file string) error {
 	f, err := os.Create(fmt.Sprintf("%s/%s.ll", outputDir, file))
 	if err != nil {
-		panic(err)
+		return err
 	}
 	defer f.Close()
-	f.WriteString(t.st.Module.String())
+	_, err = f.WriteString(t.st.Module.String())
+	return err
 }
 
-func (t *LLVM) ParseAST(tree ast.BlockStatement) {
-	pipeline.NewPipeline(t.st, t.m, tree).Run(state.PackageEntry{Name: t.ModuleName, Alias: t.ModuleAliasName})
+// ParseAST builds the IR of tree, stopping after given pipeline step, or
+// going through all of them if it is "".
+func (t *LLVM) ParseAST(tree ast.BlockStatement, last pipeline.Step) {
+	pipeline.NewPipeline(t.st, t.m, tree).RunUntil(state.PackageEntry{Name: t.ModuleName, Alias: t.ModuleAliasName}, last)
 }
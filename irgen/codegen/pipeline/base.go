@@ -1,6 +1,9 @@
 package pipeline
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/nagarajRPoojari/picasso/irgen/ast"
 	"github.com/nagarajRPoojari/picasso/irgen/codegen/contract"
 	"github.com/nagarajRPoojari/picasso/irgen/codegen/handlers/state"
@@ -51,12 +54,57 @@ func (t *Pipeline) Optimize() {
 	// t.insertYields()
 }
 
+// Step names a step of Run, after which it can be stopped to look at
+// the IR built so far.
+type Step string
+
+const (
+	StepRegister Step = "register"
+	StepDeclare  Step = "declare"
+	StepDefine   Step = "define"
+	StepOptimize Step = "optimize"
+)
+
+// Steps lists the steps of Run in order.
+var Steps = []Step{StepRegister, StepDeclare, StepDefine, StepOptimize}
+
+// ParseStep returns the step named s, "" for all steps.
+func ParseStep(s string) (Step, error) {
+	for _, step := range Steps {
+		if string(step) == s {
+			return step, nil
+		}
+	}
+	if s == "" {
+		return "", nil
+	}
+	names := make([]string, len(Steps))
+	for i, step := range Steps {
+		names[i] = string(step)
+	}
+	return "", fmt.Errorf("unknown pipeline step %q, want one of %s", s, strings.Join(names, ", "))
+}
+
 // Run will be called only for own module which does both
 // declaration & definition. It is expected that all imported
 // types/funcs are already declared.
 func (t *Pipeline) Run(sourcePkg state.PackageEntry) {
-	t.Register()
-	t.Declare(sourcePkg)
-	t.Define()
-	t.Optimize()
+	t.RunUntil(sourcePkg, "")
+}
+
+// RunUntil is Run, stopping after given step; all steps are run if
+// it is "".
+func (t *Pipeline) RunUntil(sourcePkg state.PackageEntry, last Step) {
+	steps := []func(){
+		t.Register,
+		func() { t.Declare(sourcePkg) },
+		t.Define,
+		t.Optimize,
+	}
+	for i, step := range steps {
+		step()
+		if Steps[i] == last {
+			return
+		}
+	}
 }
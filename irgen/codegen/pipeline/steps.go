@@ -1,6 +1,9 @@
 package pipeline
 
 import (
+	"maps"
+	"slices"
+
 	"github.com/llir/llvm/ir"
 	"github.com/llir/llvm/ir/types"
 	"github.com/nagarajRPoojari/picasso/irgen/ast"
@@ -11,26 +14,29 @@ import (
 	interfaceh "github.com/nagarajRPoojari/picasso/irgen/codegen/handlers/interface"
 	"github.com/nagarajRPoojari/picasso/irgen/codegen/handlers/state"
 	typedef "github.com/nagarajRPoojari/picasso/irgen/codegen/type"
+	errorsx "github.com/nagarajRPoojari/picasso/irgen/error"
 	"github.com/nagarajRPoojari/picasso/irgen/utils/logger"
 )
 
 func (t *Pipeline) predeclareClasses(sourcePkg state.PackageEntry) {
 	logger.Debug(t.st.ModuleName, "predeclaring classes of module:%s", sourcePkg.Alias)
-	Loop(t.tree, func(st ast.ClassDeclarationStatement) {
+	Loop(t.st.Diagnostics, t.tree, func(st ast.ClassDeclarationStatement) {
 		t.m.GetClassHandler().(*class.ClassHandler).DeclareOpaqueClass(st, sourcePkg)
 	})
 }
 
 func (t *Pipeline) predeclareInterfraces(sourcePkg state.PackageEntry) {
 	logger.Debug(t.st.ModuleName, "predeclaring interfaces of module:%s", sourcePkg.Alias)
-	Loop(t.tree, func(st ast.InterfaceDeclarationStatement) {
+	Loop(t.st.Diagnostics, t.tree, func(st ast.InterfaceDeclarationStatement) {
 		t.m.GetInterfaceHandler().(*interfaceh.InterfaceHandler).DeclareInterface(st, sourcePkg)
 	})
 }
 
 func (t *Pipeline) registerTypes() {
 	logger.Debug(t.st.ModuleName, "registering predefined types")
-	for tpc, udt := range t.st.CI.Types {
+	// sorted, so that the type definitions come out in the same order
+	for _, tpc := range slices.Sorted(maps.Keys(t.st.CI.Types)) {
+		udt := t.st.CI.Types[tpc]
 		t.st.Module.NewTypeDef(tpc, udt)
 		mc := &typedef.MetaClass{
 			FieldIndexMap:     make(map[string]int),
@@ -58,7 +64,7 @@ func (t *Pipeline) declareClassFields(sourcePkg state.PackageEntry) {
 
 	t.st.TypeHeirarchy.ClassRoots = roots
 	for _, i := range roots {
-		t.m.GetClassHandler().(*class.ClassHandler).DefineClass(i, sourcePkg)
+		t.guard(i, func() { t.m.GetClassHandler().(*class.ClassHandler).DefineClass(i, sourcePkg) })
 	}
 }
 
@@ -74,21 +80,21 @@ func (t *Pipeline) declareInterfaceFields(sourcePkg state.PackageEntry) {
 
 	t.st.TypeHeirarchy.InterfaceRoots = roots
 	for _, i := range roots {
-		t.m.GetInterfaceHandler().(*interfaceh.InterfaceHandler).DefineInterfaceUDT(i, sourcePkg)
+		t.guard(i, func() { t.m.GetInterfaceHandler().(*interfaceh.InterfaceHandler).DefineInterfaceUDT(i, sourcePkg) })
 	}
 }
 
 func (t *Pipeline) declareClassFuncs(sourcePkg state.PackageEntry) {
 	logger.Debug(t.st.ModuleName, "declaring class funcs of module:%s", sourcePkg.Alias)
 	for _, i := range t.st.TypeHeirarchy.ClassRoots {
-		t.m.GetClassHandler().(*class.ClassHandler).DeclareClassFuncs(i, sourcePkg)
+		t.guard(i, func() { t.m.GetClassHandler().(*class.ClassHandler).DeclareClassFuncs(i, sourcePkg) })
 	}
 }
 
 func (t *Pipeline) declareInterfaceFuncs(sourcePkg state.PackageEntry) {
 	logger.Debug(t.st.ModuleName, "declaring interface funcs of module:%s", sourcePkg.Alias)
 	for _, i := range t.st.TypeHeirarchy.InterfaceRoots {
-		t.m.GetInterfaceHandler().(*interfaceh.InterfaceHandler).DeclareClassFuncs(i, sourcePkg)
+		t.guard(i, func() { t.m.GetInterfaceHandler().(*interfaceh.InterfaceHandler).DeclareClassFuncs(i, sourcePkg) })
 	}
 }
 
@@ -101,20 +107,32 @@ func (t *Pipeline) defineClasses() {
 
 func (t *Pipeline) defineMain() {
 	logger.Debug(t.st.ModuleName, "defining main func")
-	Loop(t.tree, func(st ast.FunctionDefinitionStatement) {
+	Loop(t.st.Diagnostics, t.tree, func(st ast.FunctionDefinitionStatement) {
 		if st.Name == constants.MAIN {
-			f := t.st.Module.NewFunc(constants.MAIN, types.NewPointer(types.I8), ir.NewParam("args", types.NewPointer(c.Instance.Types[c.TYPE_ARRAY])))
+			f := t.st.Module.NewFunc(constants.MAIN, types.NewPointer(types.I8), ir.NewParam("args", types.NewPointer(t.st.CI.Types[c.TYPE_ARRAY])))
 			t.st.MainFunc = f
 			t.m.GetFuncHandler().(*funcs.FuncHandler).DefineMainFunc(&st, make(map[string]struct{}))
 		}
 	})
 }
 
-func Loop[T ast.Statement](tree ast.BlockStatement, fn func(T)) {
+// Loop calls fn for every top-level statement of type T. A statement that
+// fails to compile is skipped, its errors recorded in diags.
+func Loop[T ast.Statement](diags *errorsx.Collector, tree ast.BlockStatement, fn func(T)) {
 	for _, stI := range tree.Body {
 		switch st := stI.(type) {
 		case T:
-			fn(st)
+			guard(diags, st, func() { fn(st) })
 		}
 	}
 }
+
+// guard runs fn, recovering from errors reported while compiling st.
+func guard(diags *errorsx.Collector, st ast.Statement, fn func()) {
+	loc := st.GetSrc()
+	diags.GuardAt(loc.FilePath, loc.Line, loc.Col, fn)
+}
+
+func (t *Pipeline) guard(st ast.Statement, fn func()) {
+	guard(t.st.Diagnostics, st, fn)
+}
@@ -0,0 +1,217 @@
+package generator
+
+import (
+	"fmt"
+	"runtime/debug"
+	"slices"
+
+	"github.com/nagarajRPoojari/picasso/irgen/ast"
+	errorutils "github.com/nagarajRPoojari/picasso/irgen/codegen/error"
+	"github.com/nagarajRPoojari/picasso/irgen/codegen/tools"
+	errorsx "github.com/nagarajRPoojari/picasso/irgen/error"
+	"github.com/nagarajRPoojari/picasso/irgen/utils/logger"
+)
+
+// buildPlan lists the packages to build, each after the packages it imports.
+type buildPlan struct {
+	order []string
+	// deps holds the user imports of each package that are built as well
+	deps map[string][]string
+}
+
+// plan walks the user imports reachable from root and loads the C modules
+// they import, so that packages can then be built in any order respecting
+// their imports. Packages that are not modified are not rebuilt; their
+// declarations come from .exports.
+func (t *generator) plan(root string) buildPlan {
+	p := buildPlan{deps: make(map[string][]string)}
+
+	// rebuilt tells for each package seen whether it is built
+	rebuilt := make(map[string]bool)
+	visiting := make(map[string]struct{})
+	// path holds the imports from root to the package visited
+	var path []tools.Edge
+
+	var visit func(pkgName string) bool
+	visit = func(pkgName string) bool {
+		if built, ok := rebuilt[pkgName]; ok {
+			return built
+		}
+		if _, ok := visiting[pkgName]; ok {
+			t.abortCycle(path, pkgName)
+		}
+		if _, ok := t.allPkgs[pkgName]; !ok {
+			errorutils.Abort(errorutils.UnknownModule, pkgName)
+		}
+
+		tree, ok := t.packages[pkgName]
+		if !ok {
+			// package exists but not modified, so no rebuild needed. The
+			// packages it imports may still be.
+			logger.Warn(pkgName, "[skip] %s", pkgName)
+			if t.cache != nil {
+				visiting[pkgName] = struct{}{}
+				for _, imp := range userImportStmts(t.cache.Imports(pkgName)) {
+					path = append(path, tools.Edge{From: pkgName, To: imp.Name, Loc: imp.SourceLoc})
+					visit(imp.Name)
+					path = path[:len(path)-1]
+				}
+				delete(visiting, pkgName)
+			}
+			rebuilt[pkgName] = false
+			return false
+		}
+
+		visiting[pkgName] = struct{}{}
+		deps := make([]string, 0)
+		for _, imp := range userImportStmts(tree) {
+			path = append(path, tools.Edge{From: pkgName, To: imp.Name, Loc: imp.SourceLoc})
+			if visit(imp.Name) && !slices.Contains(deps, imp.Name) {
+				deps = append(deps, imp.Name)
+			}
+			path = path[:len(path)-1]
+		}
+		delete(visiting, pkgName)
+
+		// C modules are loaded up front, as packages built at once share them
+		for _, imp := range t.extractFFIimports(tree) {
+			t.buildFFIPackage(imp)
+		}
+		for _, imp := range t.extractStdLibImports(tree) {
+			t.buildStdLib(imp)
+		}
+
+		rebuilt[pkgName] = true
+		p.deps[pkgName] = deps
+		p.order = append(p.order, pkgName)
+		return true
+	}
+
+	visit(root)
+	return p
+}
+
+// abortCycle reports the import cycle closed by importing pkgName, the
+// last import of path, with a note at every import of the cycle.
+func (t *generator) abortCycle(path []tools.Edge, pkgName string) {
+	start := len(path) - 1
+	for start > 0 && path[start-1].To != pkgName {
+		start--
+	}
+	cycle := path[start:]
+
+	notes := make([]errorsx.Note, 0, len(cycle))
+	for _, e := range cycle {
+		notes = append(notes, errorutils.NoteAt(e.Loc, "%s imports %s here", e.From, e.To))
+	}
+	errorutils.AbortWithNotes(notes, errorutils.CyclicImport, tools.CyclePath(cycle))
+}
+
+// userImportStmts returns the imports of non-builtin packages of tree.
+func userImportStmts(tree ast.BlockStatement) []ast.ImportStatement {
+	var imports []ast.ImportStatement
+	for _, st := range tree.Body {
+		if stc, ok := st.(ast.ImportStatement); ok && !stc.IsBuiltIn() && !stc.IsFFI() {
+			imports = append(imports, stc)
+		}
+	}
+	return imports
+}
+
+// buildAllPackages builds the packages of p on t.opts.Jobs workers, each as
+// soon as the packages it imports are built. Every package has its own
+// module and state, so only the generator itself is shared.
+//
+// A package bailing out with a compile error skips the packages importing
+// it while the others are still built, so the errors reported do not depend
+// on the number of workers. Any other failure stops the build.
+func (t *generator) buildAllPackages(p buildPlan) {
+	jobs := max(t.opts.Jobs, 1)
+
+	pending := make(map[string]int, len(p.order))
+	importers := make(map[string][]string)
+	ready := make([]string, 0)
+	for _, pkgName := range p.order {
+		pending[pkgName] = len(p.deps[pkgName])
+		for _, dep := range p.deps[pkgName] {
+			importers[dep] = append(importers[dep], pkgName)
+		}
+		if pending[pkgName] == 0 {
+			ready = append(ready, pkgName)
+		}
+	}
+
+	type result struct {
+		pkgName string
+		ok      bool
+		fatal   any
+	}
+	results := make(chan result)
+	running := 0
+	var fatal any
+
+	for running > 0 || len(ready) > 0 && fatal == nil {
+		for len(ready) > 0 && running < jobs && fatal == nil {
+			pkgName := ready[0]
+			ready = ready[1:]
+			running++
+			go func() {
+				ok, r := t.tryBuildPackage(pkgName)
+				results <- result{pkgName: pkgName, ok: ok, fatal: r}
+			}()
+		}
+
+		r := <-results
+		running--
+		switch {
+		case r.fatal != nil:
+			if fatal == nil {
+				fatal = r.fatal
+			}
+		case r.ok:
+			for _, importer := range importers[r.pkgName] {
+				pending[importer]--
+				if pending[importer] == 0 {
+					ready = append(ready, importer)
+				}
+			}
+		}
+	}
+
+	// raised again on the calling goroutine, see BuildAllContext
+	if fatal != nil {
+		panic(fatal)
+	}
+}
+
+// tryBuildPackage builds pkgName and reports whether it did not bail out
+// with a compile error. Any other panic is returned as fatal.
+func (t *generator) tryBuildPackage(pkgName string) (ok bool, fatal any) {
+	defer func() {
+		if r := recover(); r != nil {
+			if t.diags.Caught(r) {
+				return
+			}
+			switch r.(type) {
+			case buildError:
+				fatal = r
+			default:
+				fatal = packagePanic{pkgName: pkgName, value: r, stack: debug.Stack()}
+			}
+		}
+	}()
+	t.buildPackage(pkgName)
+	return true, nil
+}
+
+// packagePanic is a compiler bug hit while building a package. It keeps the
+// stack of the worker, as it is raised again on the calling goroutine.
+type packagePanic struct {
+	pkgName string
+	value   any
+	stack   []byte
+}
+
+func (p packagePanic) Error() string {
+	return fmt.Sprintf("%v (building %s)\n\n%s", p.value, p.pkgName, p.stack)
+}
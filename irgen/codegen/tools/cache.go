@@ -1,226 +1,171 @@
 package tools
 
 import (
-	"fmt"
-	"io/fs"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"maps"
 	"os"
-	"path/filepath"
-	"strings"
-	"time"
 
 	"github.com/nagarajRPoojari/picasso/irgen/ast"
-	"github.com/nagarajRPoojari/picasso/irgen/codegen/utils"
 )
 
-type LastBuildTime struct {
-	Time time.Time
+// ManifestVersion is bumped whenever the manifest or the way hashes are
+// computed changes. A manifest of another version is ignored, rebuilding everything.
+const ManifestVersion = 4
+
+// Manifest records, for every package of the last successful build, the
+// hashes it was built from.
+type Manifest struct {
+	Version int
+	// Options is the hash of the build options changing the IR generated,
+	// e.g. checked arithmetic. Builds with other options rebuild every
+	// package.
+	Options  string
+	Packages map[string]Entry
 }
 
-type ImportTree struct {
-	graph map[string][]string
+// Entry holds the hashes of a package.
+type Entry struct {
+	// Source is the hash of the package's .pic file.
+	Source string
+	// Interface is the hash of its .exports, see exports.Package.Hash.
+	Interface string
+	// Deps holds the interface hashes of the user imports it was built
+	// against, direct and transitive, as their declarations end up in its IR.
+	Deps map[string]string
 }
 
+// BuildCache tells which packages need rebuilding: those whose source
+// changed, and those importing a package whose interface changed. A change
+// confined to function bodies only rebuilds the package itself.
 type BuildCache struct {
-	pkgs           map[string]ast.BlockStatement
-	projectRootDir string
-}
+	// pkgs holds the imports of every package
+	pkgs     map[string]ast.BlockStatement
+	manifest Manifest
 
-func NewBuildCache(pkgs map[string]ast.BlockStatement, projectRootDir string) *BuildCache {
-	buildCache := &BuildCache{
-		pkgs:           pkgs,
-		projectRootDir: projectRootDir,
-	}
-	return buildCache
+	sources    map[string]string
+	interfaces map[string]string
+	dirty      map[string]struct{}
 }
 
-func (t *BuildCache) buildTree(it *ImportTree, visitStack map[string]struct{}, pkgName string) error {
-	if _, ok := visitStack[pkgName]; ok {
-		return fmt.Errorf("cyclic dependency found involving %s", pkgName)
+// NewBuildCache returns the cache of the packages in pkgs, which only need
+// their imports parsed, as of the manifest at manifestPath, for a build with
+// the options hashed to options. A missing or outdated manifest, or one of
+// a build with other options, makes every package dirty.
+func NewBuildCache(pkgs map[string]ast.BlockStatement, manifestPath, options string) *BuildCache {
+	manifest := Manifest{}
+	data, err := os.ReadFile(manifestPath)
+	if err == nil {
+		err = json.Unmarshal(data, &manifest)
 	}
-	pkg := t.pkgs[pkgName]
-	it.graph[pkgName] = make([]string, 0)
-	visitStack[pkgName] = struct{}{}
-	for _, stmt := range pkg.Body {
-		if imp, ok := stmt.(ast.ImportStatement); ok {
-			// avoid base package
-			if imp.IsBuiltIn() || imp.IsFFI() {
-				continue
-			}
-			// use fully qualified name .Name instead of .Alias
-			it.graph[pkgName] = append(it.graph[pkgName], imp.Name)
-			t.buildTree(it, visitStack, imp.Name)
-		}
+	if err != nil || manifest.Version != ManifestVersion || manifest.Options != options {
+		manifest = Manifest{Version: ManifestVersion, Options: options}
 	}
-	return nil
-}
-
-func (t *BuildCache) pkgNameFromPath(root, path string) string {
-	rel, err := relpath(root, path)
-	if err != nil {
-		return ""
+	if manifest.Packages == nil {
+		manifest.Packages = make(map[string]Entry)
 	}
 
-	rel = strings.TrimSuffix(rel, filepath.Ext(rel))
-	parts := strings.Split(rel, string(os.PathSeparator))
-	return strings.Join(parts, ".")
+	return &BuildCache{
+		pkgs:       pkgs,
+		manifest:   manifest,
+		sources:    make(map[string]string),
+		interfaces: make(map[string]string),
+	}
 }
 
-func (t *BuildCache) findModifiedPkgs(lastBuild time.Time) (map[string]struct{}, error) {
-	modified := make(map[string]struct{})
-
-	err := walk(t.projectRootDir, func(path string, d fs.DirEntry, err error) error {
+// Changed hashes the source of every package, given as package name to file
+// path, and returns the packages whose source differs from the last build.
+func (t *BuildCache) Changed(paths map[string]string) (map[string]struct{}, error) {
+	changed := make(map[string]struct{})
+	for pkgName, path := range paths {
+		src, err := os.ReadFile(path)
 		if err != nil {
-			return err
+			return nil, err
 		}
+		sum := sha256.Sum256(src)
+		t.sources[pkgName] = hex.EncodeToString(sum[:])
 
-		// skip build directory
-		if d.IsDir() && d.Name() == "build" {
-			return filepath.SkipDir
-		}
-
-		if d.IsDir() {
-			return nil
-		}
-
-		if filepath.Ext(d.Name()) != ".pic" {
-			return nil
-		}
-
-		info, err := d.Info()
-		if err != nil {
-			return err
-		}
-
-		if info.ModTime().After(lastBuild) {
-			pkg := t.pkgNameFromPath(t.projectRootDir, path)
-			modified[pkg] = struct{}{}
-		}
-
-		return nil
-	})
-
-	return modified, err
-}
-
-func (t *BuildCache) reverseImportTree(it *ImportTree) map[string][]string {
-	rev := make(map[string][]string)
-
-	for pkg, imports := range it.graph {
-		for _, imp := range imports {
-			rev[imp] = append(rev[imp], pkg)
+		entry, ok := t.manifest.Packages[pkgName]
+		if !ok || entry.Source != t.sources[pkgName] {
+			changed[pkgName] = struct{}{}
+			continue
 		}
+		t.interfaces[pkgName] = entry.Interface
 	}
-
-	return rev
+	return changed, nil
 }
 
-func (t *BuildCache) propagateModified(initial map[string]struct{}, reverse map[string][]string) map[string]struct{} {
-
-	dirty := make(map[string]struct{})
-	queue := make([]string, 0)
-
-	for pkg := range initial {
-		dirty[pkg] = struct{}{}
-		queue = append(queue, pkg)
-	}
-
-	for len(queue) > 0 {
-		p := queue[0]
-		queue = queue[1:]
-
-		for _, parent := range reverse[p] {
-			if _, seen := dirty[parent]; seen {
-				continue
-			}
-			dirty[parent] = struct{}{}
-			queue = append(queue, parent)
+// Dirty returns the packages to rebuild, given the interface hashes of the
+// changed packages: those plus every package that was built against another
+// interface of one of its imports.
+func (t *BuildCache) Dirty(interfaces map[string]string) map[string]struct{} {
+	maps.Copy(t.interfaces, interfaces)
+
+	t.dirty = make(map[string]struct{})
+	for pkgName := range t.pkgs {
+		if _, ok := interfaces[pkgName]; ok {
+			t.dirty[pkgName] = struct{}{}
+			continue
+		}
+		entry := t.manifest.Packages[pkgName]
+		if !maps.Equal(entry.Deps, t.deps(pkgName)) {
+			t.dirty[pkgName] = struct{}{}
 		}
 	}
-
-	return dirty
+	return t.dirty
 }
 
-func (t *BuildCache) CheckBuildCache() (map[string]struct{}, error) {
-	lastBuild := LastBuildTime{}
-	err := utils.LoadFromFile(filepath.Join(t.projectRootDir, "build", "build.meta"), &lastBuild)
-	if err != nil {
+// Save writes the manifest to path once a build succeeded. built lists the
+// dirty packages that were built; the others stay dirty.
+func (t *BuildCache) Save(path string, built map[string]struct{}) error {
+	manifest := Manifest{Version: ManifestVersion, Options: t.manifest.Options, Packages: make(map[string]Entry)}
+	for pkgName := range t.pkgs {
+		_, dirty := t.dirty[pkgName]
+		_, ok := built[pkgName]
+		if dirty && !ok {
+			continue
+		}
+		manifest.Packages[pkgName] = Entry{
+			Source:    t.sources[pkgName],
+			Interface: t.interfaces[pkgName],
+			Deps:      t.deps(pkgName),
+		}
 	}
-
-	modified, err := t.findModifiedPkgs(lastBuild.Time)
-
+	// JSON, as it encodes maps in key order
+	data, err := json.MarshalIndent(manifest, "", "  ")
 	if err != nil {
-		return nil, err
-	}
-
-	it := &ImportTree{
-		graph: make(map[string][]string),
-	}
-
-	visit := make(map[string]struct{})
-	if err := t.buildTree(it, visit, "start"); err != nil {
-		return nil, err
+		return err
 	}
-
-	reverse := t.reverseImportTree(it)
-	dirty := t.propagateModified(modified, reverse)
-
-	return dirty, nil
+	return os.WriteFile(path, append(data, '\n'), 0o644)
 }
 
-func walk(root string, fn fs.WalkDirFunc) error {
-	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			return err
-		}
-
-		// If it's a symlink
-		if d.Type()&os.ModeSymlink != 0 {
-			target, err := os.Readlink(path)
-			if err != nil {
-				return nil
-			}
+// Imports returns the import statements of pkgName.
+func (t *BuildCache) Imports(pkgName string) ast.BlockStatement {
+	return t.pkgs[pkgName]
+}
 
-			// Make target absolute if needed
-			if !filepath.IsAbs(target) {
-				target = filepath.Join(filepath.Dir(path), target)
+// deps returns the current interface hashes of the user imports of pkgName,
+// direct and transitive.
+func (t *BuildCache) deps(pkgName string) map[string]string {
+	deps := make(map[string]string)
+	var visit func(pkgName string)
+	visit = func(pkgName string) {
+		for _, stmt := range t.pkgs[pkgName].Body {
+			imp, ok := stmt.(ast.ImportStatement)
+			// avoid base package
+			if !ok || imp.IsBuiltIn() || imp.IsFFI() {
+				continue
 			}
-
-			info, err := os.Stat(target)
-			if err == nil && info.IsDir() {
-				// Walk the symlink target
-				return walk(target, fn)
+			// use fully qualified name .Name instead of .Alias; an import
+			// cycle is reported by the build, it only needs to end here
+			if _, seen := deps[imp.Name]; seen {
+				continue
 			}
+			deps[imp.Name] = t.interfaces[imp.Name]
+			visit(imp.Name)
 		}
-
-		return fn(path, d, err)
-	})
-}
-
-func isRootOf(a, b string) (bool, error) {
-	aAbs, err := filepath.Abs(a)
-	if err != nil {
-		return false, err
-	}
-	bAbs, err := filepath.Abs(b)
-	if err != nil {
-		return false, err
-	}
-
-	aClean := filepath.Clean(aAbs)
-	bClean := filepath.Clean(bAbs)
-
-	rel, err := filepath.Rel(aClean, bClean)
-	if err != nil {
-		return false, err
-	}
-
-	// If rel starts with "..", b is outside a
-	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)), nil
-}
-
-func relpath(root string, path string) (string, error) {
-	if ok, _ := isRootOf(root, path); ok {
-		return filepath.Rel(root, path)
 	}
-	return filepath.Rel(os.Getenv("PICASSO_INCLUDE"), path)
+	visit(pkgName)
+	return deps
 }
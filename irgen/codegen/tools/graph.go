@@ -0,0 +1,323 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/nagarajRPoojari/picasso/irgen/ast"
+)
+
+// NodeKind tells what an import graph node is built from.
+type NodeKind string
+
+const (
+	// KindPackage is a package of the project.
+	KindPackage NodeKind = "package"
+	// KindLib is a package of the picasso libs, e.g. picasso.os.
+	KindLib NodeKind = "lib"
+	// KindBuiltin is a module of the compiler, e.g. builtin.syncio.
+	KindBuiltin NodeKind = "builtin"
+	// KindFFI is a C module, e.g. c.ffi.coo.
+	KindFFI NodeKind = "ffi"
+)
+
+// libPrefix starts the names of the lib packages, linked into the project
+// as its picasso directory.
+const libPrefix = "picasso."
+
+// Node is a package of an ImportGraph.
+type Node struct {
+	Name string   `json:"name"`
+	Kind NodeKind `json:"kind"`
+}
+
+// Edge is an import of an ImportGraph, From importing To.
+type Edge struct {
+	From  string        `json:"from"`
+	To    string        `json:"to"`
+	Alias string        `json:"alias,omitempty"`
+	Loc   ast.SourceLoc `json:"-"`
+}
+
+// FanIn counts the packages importing a package, directly or not.
+type FanIn struct {
+	Name      string `json:"name"`
+	Importers int    `json:"importers"`
+}
+
+// ImportGraph is the graph of the imports between packages, with the
+// builtin and C modules they import.
+type ImportGraph struct {
+	nodes map[string]NodeKind
+	// imports and importers hold the edges by importing and imported
+	// package, in source order.
+	imports   map[string][]Edge
+	importers map[string][]Edge
+}
+
+// NewImportGraph returns the import graph of pkgs, keyed by package name,
+// which only need their imports parsed. Lib packages are only part of the
+// graph if imported.
+func NewImportGraph(pkgs map[string]ast.BlockStatement) *ImportGraph {
+	g := &ImportGraph{
+		nodes:     make(map[string]NodeKind),
+		imports:   make(map[string][]Edge),
+		importers: make(map[string][]Edge),
+	}
+
+	var add func(name string)
+	add = func(name string) {
+		if _, ok := g.nodes[name]; ok {
+			return
+		}
+		g.nodes[name] = kindOf(name)
+		for _, stI := range pkgs[name].Body {
+			imp, ok := stI.(ast.ImportStatement)
+			if !ok {
+				continue
+			}
+			e := Edge{From: name, To: imp.Name, Alias: imp.Alias, Loc: imp.SourceLoc}
+			g.imports[name] = append(g.imports[name], e)
+			g.importers[imp.Name] = append(g.importers[imp.Name], e)
+			add(imp.Name)
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(pkgs)) {
+		if kindOf(name) == KindPackage {
+			add(name)
+		}
+	}
+	return g
+}
+
+func kindOf(name string) NodeKind {
+	switch imp := (ast.ImportStatement{Name: name}); {
+	case imp.IsBuiltIn():
+		return KindBuiltin
+	case imp.IsFFI():
+		return KindFFI
+	case strings.HasPrefix(name, libPrefix):
+		return KindLib
+	}
+	return KindPackage
+}
+
+// Nodes returns the packages of the graph by name.
+func (g *ImportGraph) Nodes() []Node {
+	nodes := make([]Node, 0, len(g.nodes))
+	for _, name := range slices.Sorted(maps.Keys(g.nodes)) {
+		nodes = append(nodes, Node{Name: name, Kind: g.nodes[name]})
+	}
+	return nodes
+}
+
+// Edges returns the imports of the graph by importing package, in source
+// order.
+func (g *ImportGraph) Edges() []Edge {
+	edges := []Edge{}
+	for _, name := range slices.Sorted(maps.Keys(g.imports)) {
+		edges = append(edges, g.imports[name]...)
+	}
+	return edges
+}
+
+// Unused returns the packages of the project that root does not import,
+// directly or not. Test packages, named *_test, are run on their own and
+// never unused.
+func (g *ImportGraph) Unused(root string) []string {
+	used := g.reachable(root, g.imports, func(e Edge) string { return e.To })
+	unused := []string{}
+	for _, n := range g.Nodes() {
+		if _, ok := used[n.Name]; !ok && n.Kind == KindPackage && !strings.HasSuffix(n.Name, "_test") {
+			unused = append(unused, n.Name)
+		}
+	}
+	return unused
+}
+
+// TopFanIn returns the n packages most imported, directly or not, most
+// imported first. Packages nothing imports are left out.
+func (g *ImportGraph) TopFanIn(n int) []FanIn {
+	fanIn := []FanIn{}
+	for _, node := range g.Nodes() {
+		importers := g.reachable(node.Name, g.importers, func(e Edge) string { return e.From })
+		// a package in a cycle imports itself
+		delete(importers, node.Name)
+		if len(importers) > 0 {
+			fanIn = append(fanIn, FanIn{Name: node.Name, Importers: len(importers)})
+		}
+	}
+	slices.SortStableFunc(fanIn, func(a, b FanIn) int { return b.Importers - a.Importers })
+	return fanIn[:min(n, len(fanIn))]
+}
+
+// reachable returns the packages reachable from name by following edges,
+// name included.
+func (g *ImportGraph) reachable(name string, edges map[string][]Edge, next func(Edge) string) map[string]struct{} {
+	seen := make(map[string]struct{})
+	var visit func(name string)
+	visit = func(name string) {
+		if _, ok := seen[name]; ok {
+			return
+		}
+		seen[name] = struct{}{}
+		for _, e := range edges[name] {
+			visit(next(e))
+		}
+	}
+	visit(name)
+	return seen
+}
+
+// Cycle returns the imports of an import cycle, the first found visiting
+// packages by name, e.g. a -> b, b -> a; nil if there is none.
+func (g *ImportGraph) Cycle() []Edge {
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := make(map[string]int)
+	var stack []Edge
+
+	var visit func(name string) []Edge
+	visit = func(name string) []Edge {
+		state[name] = visiting
+		for _, e := range g.imports[name] {
+			switch state[e.To] {
+			case visiting:
+				// the cycle starts where e.To was imported
+				start := len(stack)
+				for start > 0 && stack[start-1].To != e.To {
+					start--
+				}
+				return append(slices.Clone(stack[start:]), e)
+			case done:
+				continue
+			}
+			stack = append(stack, e)
+			if cycle := visit(e.To); cycle != nil {
+				return cycle
+			}
+			stack = stack[:len(stack)-1]
+		}
+		state[name] = done
+		return nil
+	}
+
+	for _, n := range g.Nodes() {
+		if state[n.Name] == 0 {
+			if cycle := visit(n.Name); cycle != nil {
+				return cycle
+			}
+		}
+	}
+	return nil
+}
+
+// CyclePackages returns the packages of cycle, as returned by Cycle, the
+// first one last again, e.g. a, b, a.
+func CyclePackages(cycle []Edge) []string {
+	if len(cycle) == 0 {
+		return nil
+	}
+	names := []string{cycle[0].From}
+	for _, e := range cycle {
+		names = append(names, e.To)
+	}
+	return names
+}
+
+// CyclePath formats the packages of cycle, e.g. a -> b -> a.
+func CyclePath(cycle []Edge) string {
+	return strings.Join(CyclePackages(cycle), " -> ")
+}
+
+// Report is an ImportGraph with what it tells about the project, as
+// written by WriteJSON and WriteDOT.
+type Report struct {
+	Nodes  []Node   `json:"nodes"`
+	Edges  []Edge   `json:"edges"`
+	Unused []string `json:"unused"`
+	FanIn  []FanIn  `json:"fan_in"`
+	// Cycle lists the packages of an import cycle, the first one last
+	// again, if any.
+	Cycle []string `json:"cycle,omitempty"`
+}
+
+// Report returns the graph with its packages unused by root and the top
+// packages by fan-in.
+func (g *ImportGraph) Report(root string, top int) Report {
+	return Report{
+		Nodes:  g.Nodes(),
+		Edges:  g.Edges(),
+		Unused: g.Unused(root),
+		FanIn:  g.TopFanIn(top),
+		Cycle:  CyclePackages(g.Cycle()),
+	}
+}
+
+// WriteJSON writes r to w as indented JSON.
+func (r Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// dotAttrs are the Graphviz attributes of the nodes of each kind.
+var dotAttrs = map[NodeKind]string{
+	KindPackage: "shape=box",
+	KindLib:     "shape=box, style=rounded",
+	KindBuiltin: "shape=ellipse, style=dashed",
+	KindFFI:     "shape=hexagon",
+}
+
+// WriteDOT writes r to w as a Graphviz digraph. Unused packages are grayed
+// out and the imports of the cycle drawn in red; the report is also written
+// as comments.
+func (r Report) WriteDOT(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("digraph imports {\n")
+	for _, name := range r.Unused {
+		fmt.Fprintf(&b, "  // unused: %s\n", name)
+	}
+	for _, f := range r.FanIn {
+		fmt.Fprintf(&b, "  // fan-in: %s (%d)\n", f.Name, f.Importers)
+	}
+	if r.Cycle != nil {
+		fmt.Fprintf(&b, "  // cycle: %s\n", strings.Join(r.Cycle, " -> "))
+	}
+
+	b.WriteString("  node [fontname=monospace];\n")
+	for _, n := range r.Nodes {
+		attrs := dotAttrs[n.Kind]
+		if slices.Contains(r.Unused, n.Name) {
+			attrs += ", color=gray, fontcolor=gray"
+		}
+		fmt.Fprintf(&b, "  %q [%s];\n", n.Name, attrs)
+	}
+	inCycle := make(map[[2]string]bool)
+	for i := 1; i < len(r.Cycle); i++ {
+		inCycle[[2]string{r.Cycle[i-1], r.Cycle[i]}] = true
+	}
+	for _, e := range r.Edges {
+		var attrs []string
+		if e.Alias != "" && e.Alias != e.To[strings.LastIndex(e.To, ".")+1:] {
+			attrs = append(attrs, fmt.Sprintf("label=%q", e.Alias))
+		}
+		if inCycle[[2]string{e.From, e.To}] {
+			attrs = append(attrs, "color=red")
+		}
+		if len(attrs) > 0 {
+			fmt.Fprintf(&b, "  %q -> %q [%s];\n", e.From, e.To, strings.Join(attrs, ", "))
+		} else {
+			fmt.Fprintf(&b, "  %q -> %q;\n", e.From, e.To)
+		}
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -39,7 +39,7 @@ func init() {
 
 // Assuming bh, c, value, types, and constant are correctly defined/imported.
 func NewArray(bh *bc.BlockHolder, elemType types.Type, eleSize value.Value, dims []value.Value, ElementTypeString string) *Array {
-	allocFn := c.Instance.Funcs[c.FUNC_ARRAY_ALLOC]
+	allocFn := c.For(bh.N).Funcs[c.FUNC_ARRAY_ALLOC]
 	rankVal := constant.NewInt(types.I32, int64(len(dims)))
 
 	args := []value.Value{eleSize, rankVal}
@@ -135,7 +135,7 @@ func (a *Array) LoadShapeArray(block *bc.BlockHolder) *Array {
 
 	rankVal := constant.NewInt(types.I32, 1)
 
-	structAlloc := b.NewCall(c.Instance.Funcs[c.FUNC_ARRAY_ALLOC], elemSize, rankVal, rank)
+	structAlloc := b.NewCall(c.For(block.N).Funcs[c.FUNC_ARRAY_ALLOC], elemSize, rankVal, rank)
 	arrayPtr := b.NewBitCast(structAlloc, types.NewPointer(ARRAYSTRUCT))
 
 	origShapePtr := a.LoadShapePtr(block)
@@ -177,163 +177,147 @@ func (a *Array) LoadShapeArray(block *bc.BlockHolder) *Array {
 
 // StoreByIndex updates element value at given index in a jagged array
 func (a *Array) StoreByIndex(block *bc.BlockHolder, indices []value.Value, val value.Value) {
-	b := block.N
-	currentArray := a.Ptr
-
-	for i := 0; i < len(indices)-1; i++ {
-		idx := indices[i]
-
-		checkIntCond(block, idx, constant.NewInt(types.I64, 0), enum.IPredSGE, "array index < 0")
-		b = block.N
-
-		lengthPtr := b.NewGetElementPtr(a.ArrayType, currentArray,
-			constant.NewInt(types.I32, 0),
-			constant.NewInt(types.I32, 2),
-		)
-		length := b.NewLoad(types.I64, lengthPtr)
-		checkIntCond(block, idx, length, enum.IPredSLT, "array index out of bounds\n")
-		b = block.N
-
-		getSubarrayFn := c.Instance.Funcs[c.FUNC_GET_SUBARRAY]
-		currentArray = b.NewCall(getSubarrayFn, currentArray, idx)
-	}
-
-	lastIdx := indices[len(indices)-1]
-
-	checkIntCond(block, lastIdx, constant.NewInt(types.I64, 0), enum.IPredSGE, "array index < 0")
-	b = block.N
-	lengthPtr := b.NewGetElementPtr(a.ArrayType, currentArray,
-		constant.NewInt(types.I32, 0),
-		constant.NewInt(types.I32, 2),
-	)
-	length := b.NewLoad(types.I64, lengthPtr)
-	checkIntCond(block, lastIdx, length, enum.IPredSLT, "array index out of bounds\n")
-	b = block.N
+	a.StoreByIndexProven(block, indices, val, 0)
+}
 
-	dataPtrField := b.NewGetElementPtr(a.ArrayType, currentArray,
-		constant.NewInt(types.I32, 0),
-		constant.NewInt(types.I32, 0),
-	)
-	raw := b.NewLoad(types.NewPointer(types.I8), dataPtrField)
-	elemsPtr := b.NewBitCast(raw, types.NewPointer(a.ElemType))
-	elemPtr := b.NewGetElementPtr(a.ElemType, elemsPtr, lastIdx)
-	b.NewStore(val, elemPtr)
+// StoreByIndexProven is StoreByIndex skipping bounds checks for the first
+// proven indices, which are statically known to be in range.
+func (a *Array) StoreByIndexProven(block *bc.BlockHolder, indices []value.Value, val value.Value, proven int) {
+	elemPtr := a.elementPtr(block, indices, proven)
+	block.N.NewStore(val, elemPtr)
 }
 
 // StoreSubarrayByIndex stores a subarray at given index (partial indexing)
 func (a *Array) StoreSubarrayByIndex(block *bc.BlockHolder, indices []value.Value, subarray *Array) {
-	b := block.N
-	currentArray := a.Ptr
-
-	for i := 0; i < len(indices)-1; i++ {
-		idx := indices[i]
-
-		checkIntCond(block, idx, constant.NewInt(types.I64, 0), enum.IPredSGE, "array index < 0")
-		b = block.N
+	a.StoreSubarrayByIndexProven(block, indices, subarray, 0)
+}
 
-		lengthPtr := b.NewGetElementPtr(a.ArrayType, currentArray,
-			constant.NewInt(types.I32, 0),
-			constant.NewInt(types.I32, 2),
-		)
-		length := b.NewLoad(types.I64, lengthPtr)
-		checkIntCond(block, idx, length, enum.IPredSLT, "array index out of bounds\n")
-		b = block.N
+// StoreSubarrayByIndexProven is StoreSubarrayByIndex skipping bounds checks
+// for the first proven indices.
+func (a *Array) StoreSubarrayByIndexProven(block *bc.BlockHolder, indices []value.Value, subarray *Array, proven int) {
+	last := len(indices) - 1
+	currentArray := a.walkSubarrays(block, indices[:last], proven)
 
-		getSubarrayFn := c.Instance.Funcs[c.FUNC_GET_SUBARRAY]
-		currentArray = b.NewCall(getSubarrayFn, currentArray, idx)
+	lastIdx := indices[last]
+	if last >= proven {
+		a.checkIndex(block, currentArray, lastIdx)
 	}
 
-	lastIdx := indices[len(indices)-1]
-
-	checkIntCond(block, lastIdx, constant.NewInt(types.I64, 0), enum.IPredSGE, "array index < 0")
-	b = block.N
-	lengthPtr := b.NewGetElementPtr(a.ArrayType, currentArray,
-		constant.NewInt(types.I32, 0),
-		constant.NewInt(types.I32, 2),
-	)
-	length := b.NewLoad(types.I64, lengthPtr)
-	checkIntCond(block, lastIdx, length, enum.IPredSLT, "array index out of bounds\n")
-	b = block.N
-
 	// Store the subarray using set_subarray
-	setSubarrayFn := c.Instance.Funcs[c.FUNC_SET_SUBARRAY]
-	b.NewCall(setSubarrayFn, currentArray, lastIdx, subarray.Ptr)
+	setSubarrayFn := c.For(block.N).Funcs[c.FUNC_SET_SUBARRAY]
+	block.N.NewCall(setSubarrayFn, currentArray, lastIdx, subarray.Ptr)
 }
 
 // LoadByIndex retrieves element value at given index in a jagged array
 func (a *Array) LoadByIndex(block *bc.BlockHolder, indices []value.Value) value.Value {
-	b := block.N
-	currentArray := a.Ptr
+	return a.LoadByIndexProven(block, indices, 0)
+}
 
-	for i := 0; i < len(indices)-1; i++ {
-		idx := indices[i]
+// LoadByIndexProven is LoadByIndex skipping bounds checks for the first
+// proven indices, which are statically known to be in range.
+func (a *Array) LoadByIndexProven(block *bc.BlockHolder, indices []value.Value, proven int) value.Value {
+	elemPtr := a.elementPtr(block, indices, proven)
+	return block.N.NewLoad(a.ElemType, elemPtr)
+}
 
-		checkIntCond(block, idx, constant.NewInt(types.I64, 0), enum.IPredSGE, "array index < 0")
-		b = block.N
+// LoadSubarrayByIndex retrieves a subarray at given index (partial indexing)
+func (a *Array) LoadSubarrayByIndex(block *bc.BlockHolder, indices []value.Value) *Array {
+	return a.LoadSubarrayByIndexProven(block, indices, 0)
+}
 
-		lengthPtr := b.NewGetElementPtr(a.ArrayType, currentArray,
-			constant.NewInt(types.I32, 0),
-			constant.NewInt(types.I32, 2),
-		)
-		length := b.NewLoad(types.I64, lengthPtr)
-		checkIntCond(block, idx, length, enum.IPredSLT, "array index out of bounds\n")
-		b = block.N
+// LoadSubarrayByIndexProven is LoadSubarrayByIndex skipping bounds checks for
+// the first proven indices.
+func (a *Array) LoadSubarrayByIndexProven(block *bc.BlockHolder, indices []value.Value, proven int) *Array {
+	currentArray := a.walkSubarrays(block, indices, proven)
 
-		getSubarrayFn := c.Instance.Funcs[c.FUNC_GET_SUBARRAY]
-		currentArray = b.NewCall(getSubarrayFn, currentArray, idx)
+	return &Array{
+		Ptr:               currentArray,
+		ElemType:          a.ElemType,
+		ArrayType:         a.ArrayType,
+		ElementTypeString: a.ElementTypeString,
+		Rank:              a.Rank - len(indices),
 	}
+}
 
-	lastIdx := indices[len(indices)-1]
+// elementPtr resolves all but the last index through nested subarrays and
+// returns a pointer to the addressed element.
+func (a *Array) elementPtr(block *bc.BlockHolder, indices []value.Value, proven int) value.Value {
+	last := len(indices) - 1
+	currentArray := a.walkSubarrays(block, indices[:last], proven)
 
-	checkIntCond(block, lastIdx, constant.NewInt(types.I64, 0), enum.IPredSGE, "array index < 0")
-	b = block.N
-	lengthPtr := b.NewGetElementPtr(a.ArrayType, currentArray,
-		constant.NewInt(types.I32, 0),
-		constant.NewInt(types.I32, 2),
-	)
-	length := b.NewLoad(types.I64, lengthPtr)
-	checkIntCond(block, lastIdx, length, enum.IPredSLT, "array index out of bounds\n")
-	b = block.N
+	lastIdx := indices[last]
+	if last >= proven {
+		a.checkIndex(block, currentArray, lastIdx)
+	}
 
+	b := block.N
 	dataPtrField := b.NewGetElementPtr(a.ArrayType, currentArray,
 		constant.NewInt(types.I32, 0),
 		constant.NewInt(types.I32, 0),
 	)
 	raw := b.NewLoad(types.NewPointer(types.I8), dataPtrField)
 	elemsPtr := b.NewBitCast(raw, types.NewPointer(a.ElemType))
-	elemPtr := b.NewGetElementPtr(a.ElemType, elemsPtr, lastIdx)
-	return b.NewLoad(a.ElemType, elemPtr)
+	return b.NewGetElementPtr(a.ElemType, elemsPtr, lastIdx)
 }
 
-// LoadSubarrayByIndex retrieves a subarray at given index (partial indexing)
-func (a *Array) LoadSubarrayByIndex(block *bc.BlockHolder, indices []value.Value) *Array {
-	b := block.N
+// walkSubarrays descends one dimension per index, returning the innermost array.
+func (a *Array) walkSubarrays(block *bc.BlockHolder, indices []value.Value, proven int) value.Value {
 	currentArray := a.Ptr
+	for i, idx := range indices {
+		if i >= proven {
+			a.checkIndex(block, currentArray, idx)
+		}
+		getSubarrayFn := c.For(block.N).Funcs[c.FUNC_GET_SUBARRAY]
+		currentArray = block.N.NewCall(getSubarrayFn, currentArray, idx)
+	}
+	return currentArray
+}
 
-	for i := 0; i < len(indices); i++ {
-		idx := indices[i]
+// checkIndex emits 0 <= idx < length(arr), raising a runtime error otherwise.
+func (a *Array) checkIndex(block *bc.BlockHolder, arr value.Value, idx value.Value) {
+	checkIntCond(block, idx, constant.NewInt(types.I64, 0), enum.IPredSGE, "array index < 0")
 
-		checkIntCond(block, idx, constant.NewInt(types.I64, 0), enum.IPredSGE, "array index < 0")
-		b = block.N
+	lengthPtr := block.N.NewGetElementPtr(a.ArrayType, arr,
+		constant.NewInt(types.I32, 0),
+		constant.NewInt(types.I32, 2),
+	)
+	length := block.N.NewLoad(types.I64, lengthPtr)
+	checkIntCond(block, idx, length, enum.IPredSLT, "array index out of bounds\n")
+}
 
-		lengthPtr := b.NewGetElementPtr(a.ArrayType, currentArray,
-			constant.NewInt(types.I32, 0),
-			constant.NewInt(types.I32, 2),
-		)
-		length := b.NewLoad(types.I64, lengthPtr)
-		checkIntCond(block, idx, length, enum.IPredSLT, "array index out of bounds\n")
-		b = block.N
+// Slice returns a view over a[lo..hi) along the first dimension. The view shares
+// backing storage with a, so writes through either are visible in both.
+func (a *Array) Slice(block *bc.BlockHolder, lo, hi value.Value) *Array {
+	checkIntCond(block, lo, constant.NewInt(types.I64, 0), enum.IPredSGE, "slice lower bound < 0")
+	checkIntCond(block, lo, hi, enum.IPredSLE, "slice lower bound > upper bound")
+
+	b := block.N
+	lengthPtr := b.NewGetElementPtr(a.ArrayType, a.Ptr,
+		constant.NewInt(types.I32, 0),
+		constant.NewInt(types.I32, 2),
+	)
+	length := b.NewLoad(types.I64, lengthPtr)
+	checkIntCond(block, hi, length, enum.IPredSLE, "slice upper bound out of range")
 
-		getSubarrayFn := c.Instance.Funcs[c.FUNC_GET_SUBARRAY]
-		currentArray = b.NewCall(getSubarrayFn, currentArray, idx)
+	view := block.N.NewCall(c.For(block.N).Funcs[c.FUNC_SLICE_ARRAY], a.Ptr, lo, hi)
+	return &Array{
+		Ptr:               view,
+		ElemType:          a.ElemType,
+		ArrayType:         a.ArrayType,
+		ElementTypeString: a.ElementTypeString,
+		Rank:              a.Rank,
 	}
+}
 
+// Copy returns a deep copy of a with its own backing storage.
+func (a *Array) Copy(block *bc.BlockHolder) *Array {
+	dup := block.N.NewCall(c.For(block.N).Funcs[c.FUNC_COPY_ARRAY], a.Ptr)
 	return &Array{
-		Ptr:               currentArray,
+		Ptr:               dup,
 		ElemType:          a.ElemType,
 		ArrayType:         a.ArrayType,
 		ElementTypeString: a.ElementTypeString,
-		Rank:              a.Rank - len(indices),
+		Rank:              a.Rank,
 	}
 }
 
@@ -343,6 +327,6 @@ func checkIntCond(block *bc.BlockHolder, v1, v2 value.Value, pred enum.IPred, er
 	failBlk := b.Parent.NewBlock("")
 	cond := b.NewICmp(pred, v1, v2)
 	b.NewCondBr(cond, passBlk, failBlk)
-	rterr.Instance.RaiseRTError(failBlk, errMsg)
+	rterr.RaiseRTError(failBlk, errMsg)
 	block.Update(block.V, passBlk)
 }
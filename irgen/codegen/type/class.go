@@ -44,7 +44,7 @@ func NewClass(block *bc.BlockHolder, name string, rawType types.Type) *Class {
 	gep := constant.NewGetElementPtr(ptrType.ElemType, zero, one)
 	size := constant.NewPtrToInt(gep, types.I64)
 
-	mallocCall := block.N.NewCall(c.Instance.Funcs[c.FUNC_ALLOC], size)
+	mallocCall := block.N.NewCall(c.For(block.N).Funcs[c.FUNC_ALLOC], size)
 	heapPtr := block.N.NewBitCast(mallocCall, ptrType)
 
 	// Create a slot on the stack that holds a %MyStruct*
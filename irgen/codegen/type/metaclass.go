@@ -1,6 +1,10 @@
 package typedef
 
 import (
+	"cmp"
+	"maps"
+	"slices"
+
 	"github.com/llir/llvm/ir"
 	"github.com/llir/llvm/ir/types"
 	"github.com/nagarajRPoojari/picasso/irgen/ast"
@@ -51,6 +55,15 @@ func NewMetaClass(udt *types.PointerType, implements string) *MetaClass {
 		Implements:        implements,
 	}
 }
+
+// Fields returns the fully qualified names in FieldIndexMap, methods
+// included, in struct order.
+func (m *MetaClass) Fields() []string {
+	return slices.SortedFunc(maps.Keys(m.FieldIndexMap), func(a, b string) int {
+		return cmp.Compare(m.FieldIndexMap[a], m.FieldIndexMap[b])
+	})
+}
+
 func (m *MetaClass) FieldType(idx int) types.Type {
 	return m.StructType().Fields[idx]
 }
@@ -2,6 +2,8 @@ package typedef
 
 import (
 	"fmt"
+	"maps"
+	"slices"
 	"strings"
 
 	"github.com/llir/llvm/ir/constant"
@@ -445,6 +447,7 @@ func (t *TypeHandler) GetLLVMType(_type string) types.Type {
 			types.I64,                   // rank
 			types.I64,                   // capacity
 			types.I32,                   // elesize
+			types.NewPointer(types.I8),  // base (owning array for slice views)
 		)
 
 		s.SetName("array")
@@ -471,14 +474,14 @@ func (t *TypeHandler) GetLLVMType(_type string) types.Type {
 	if resolvedType == _type {
 		// Resolution didn't change the type, try fuzzy match
 		suffix := "." + _type
-		for fqName, meta := range t.ClassUDTS {
+		for _, fqName := range slices.Sorted(maps.Keys(t.ClassUDTS)) {
 			if strings.HasSuffix(fqName, suffix) {
-				return meta.UDT
+				return t.ClassUDTS[fqName].UDT
 			}
 		}
-		for fqName, meta := range t.InterfaceUDTS {
+		for _, fqName := range slices.Sorted(maps.Keys(t.InterfaceUDTS)) {
 			if strings.HasSuffix(fqName, suffix) {
-				return meta.UDT
+				return t.InterfaceUDTS[fqName].UDT
 			}
 		}
 	}
@@ -590,9 +593,9 @@ func (t *TypeHandler) ImplicitTypeCast(bh *bc.BlockHolder, target string, v valu
 	// Try fuzzy matching if resolution didn't work
 	if resolvedTarget == target {
 		suffix := "." + target
-		for fqName, meta := range t.ClassUDTS {
+		for _, fqName := range slices.Sorted(maps.Keys(t.ClassUDTS)) {
 			if strings.HasSuffix(fqName, suffix) {
-				ret, err := ensureClassType(bh, t, v, meta.UDT)
+				ret, err := ensureClassType(bh, t, v, t.ClassUDTS[fqName].UDT)
 				if err != nil {
 					panic(err)
 				}
@@ -648,7 +651,7 @@ func (t *TypeHandler) catchLossLessIntToIntDownCast(block *bc.BlockHolder, v val
 	overflow := b.NewOr(overflowMax, overflowMin)
 
 	b.NewCondBr(overflow, abort, safe)
-	rterr.Instance.RaiseRTError(abort, "runtime overflow in int downcast\n")
+	rterr.RaiseRTError(abort, "runtime overflow in int downcast\n")
 
 	vTrunc := safe.NewTrunc(v, dst)
 	block.Update(block.V, safe)
@@ -696,7 +699,7 @@ func (t *TypeHandler) catchLossLessIntToUnsignedDownCast(block *bc.BlockHolder,
 	overflow := b.NewOr(overflowMax, overflowMin)
 
 	b.NewCondBr(overflow, abort, safe)
-	rterr.Instance.RaiseRTError(abort, "runtime overflow in unsigned int downcast\n")
+	rterr.RaiseRTError(abort, "runtime overflow in unsigned int downcast\n")
 
 	vTrunc := safe.NewTrunc(v, dst)
 	block.Update(block.V, safe)
@@ -749,7 +752,7 @@ func (t *TypeHandler) catchLossLessFloatToIntDownCast(block *bc.BlockHolder, v v
 	overflow := b.NewOr(b.NewOr(overflowMax, overflowMin), isNaN)
 
 	b.NewCondBr(overflow, abort, safe)
-	rterr.Instance.RaiseRTError(abort, "runtime overflow in float → int downcast\n")
+	rterr.RaiseRTError(abort, "runtime overflow in float → int downcast\n")
 
 	res := safe.NewFPToSI(vAsDouble, dst)
 	block.Update(block.V, safe)
@@ -793,7 +796,7 @@ func (t *TypeHandler) catchLossLessFloatToUnsignedIntDownCast(block *bc.BlockHol
 	overflow := b.NewOr(b.NewOr(overflowMax, overflowMin), isNaN)
 
 	b.NewCondBr(overflow, abort, safe)
-	rterr.Instance.RaiseRTError(abort, "runtime overflow in float → unsigned int downcast\n")
+	rterr.RaiseRTError(abort, "runtime overflow in float → unsigned int downcast\n")
 
 	// IMPORTANT: FPToUI (unsigned!)
 	res := safe.NewFPToUI(vAsDouble, dst)
@@ -962,7 +965,7 @@ func (t *TypeHandler) catchLossLessFloatToFloatDowncast(block *bc.BlockHolder, v
 
 	b.NewCondBr(overflow, abort, safe)
 
-	rterr.Instance.RaiseRTError(abort, "runtime overflow in float demotion")
+	rterr.RaiseRTError(abort, "runtime overflow in float demotion")
 
 	// Safe block: actually truncate original value to dst type
 	vTrunc := safe.NewFPTrunc(v, dst)
@@ -1009,7 +1012,7 @@ func (t *TypeHandler) catchLossLessIntToFloatDowncast(block *bc.BlockHolder, v v
 
 	b.NewCondBr(overflow, abort, safe)
 
-	rterr.Instance.RaiseRTError(abort, "runtime overflow converting int → float")
+	rterr.RaiseRTError(abort, "runtime overflow converting int → float")
 
 	// Safe block: return converted float in requested dst width
 	res := safe.NewSIToFP(v, dst)
@@ -0,0 +1,178 @@
+// Package compiler compiles Picasso projects from Go, without touching the
+// process environment: sources and libs are read from fs.FS values, nothing
+// is written outside OutDir and failures are returned rather than exiting.
+// It is meant for test harnesses and tooling running many compilations in
+// one process, possibly at once.
+package compiler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/llir/llvm/ir"
+	"github.com/nagarajRPoojari/picasso/irgen/ast"
+	generator "github.com/nagarajRPoojari/picasso/irgen/codegen"
+	"github.com/nagarajRPoojari/picasso/irgen/codegen/pipeline"
+	errorsx "github.com/nagarajRPoojari/picasso/irgen/error"
+	"github.com/nagarajRPoojari/picasso/irgen/parser"
+)
+
+// libDir is the directory the libs are imported from, e.g. picasso/os/io.
+const libDir = "picasso"
+
+// Options describes a compilation.
+type Options struct {
+	// Sources holds the project, with start.pic at its root.
+	Sources fs.FS
+	// IncludeFS holds the libs like $PICASSO_INCLUDE does, i.e. with the
+	// picasso directory at its root. It may be nil if no lib is imported.
+	IncludeFS fs.FS
+	// OutDir receives a .ll and a .exports file per package. C modules
+	// imported with FFI are read from its tmp directory. Nothing is written
+	// if empty.
+	OutDir string
+
+	// CheckedArith emits overflow and division-by-zero traps for integer
+	// arithmetic.
+	CheckedArith bool
+	// Warnings are -W flags without the -W, e.g. all or error=shadow.
+	Warnings []string
+	// Jobs is the number of packages built at once; 0 builds one at a time.
+	Jobs int
+	// StopAfter ends the pipeline of every package after given step, e.g.
+	// to look at its declarations; "" runs all steps.
+	StopAfter pipeline.Step
+}
+
+// Result is the output of a successful compilation.
+type Result struct {
+	// Modules holds the IR of every package built, keyed by package name,
+	// e.g. start or picasso.os.io.
+	Modules map[string]*ir.Module
+}
+
+// Diagnostic is an error or warning found in the sources.
+type Diagnostic = errorsx.Diagnostic
+
+// ErrCompile is returned when the sources have errors, which are listed in
+// the diagnostics.
+var ErrCompile = generator.ErrCompile
+
+// Compile parses, checks and generates IR for the project in opts.Sources.
+// Diagnostics, warnings included, are returned whether or not compilation
+// succeeds. The error is ErrCompile if the sources have errors, and
+// describes the failure otherwise, e.g. an unreadable file or ctx being
+// done. Compile may be called from several goroutines: each compilation
+// records its diagnostics in a collector of its own.
+func Compile(ctx context.Context, opts Options) (res *Result, diags []Diagnostic, err error) {
+	if opts.Sources == nil {
+		return nil, nil, errors.New("compiler: no sources")
+	}
+
+	collector := errorsx.NewCollector()
+	src := sources{project: opts.Sources, include: opts.IncludeFS}
+	collector.SetSources(src)
+	for _, flag := range opts.Warnings {
+		if err := collector.SetWarningFlag(flag); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	defer func() {
+		// a compiler bug must not take the caller down
+		if r := recover(); r != nil {
+			res, err = nil, fmt.Errorf("internal compiler error: %v", r)
+		}
+		diags = collector.Resolved()
+	}()
+
+	pkgs, err := parse(ctx, collector, src)
+	if err != nil {
+		return nil, nil, err
+	}
+	if collector.Errors() > 0 {
+		return nil, nil, ErrCompile
+	}
+
+	if opts.OutDir != "" {
+		if err := os.MkdirAll(opts.OutDir, 0o755); err != nil {
+			return nil, nil, err
+		}
+	}
+	g := generator.NewGeneratorFor(pkgs, opts.OutDir, generator.Options{Diagnostics: collector, CheckedArith: opts.CheckedArith, Jobs: opts.Jobs, StopAfter: opts.StopAfter})
+	if err := g.BuildAllContext(ctx); err != nil {
+		return nil, nil, err
+	}
+	return &Result{Modules: g.Modules()}, nil, nil
+}
+
+// parse parses every .pic file of the project and the libs, keyed by
+// package name like generator.LoadPackages does.
+func parse(ctx context.Context, diags *errorsx.Collector, src sources) (map[string]ast.BlockStatement, error) {
+	pkgs := make(map[string]ast.BlockStatement)
+
+	visit := func(fsys fs.FS, root string, project bool) error {
+		return fs.WalkDir(fsys, root, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if d.IsDir() {
+				// build output is not source, and libs come from IncludeFS
+				if project && (p == generator.BUILD || p == libDir && src.include != nil) {
+					return fs.SkipDir
+				}
+				return nil
+			}
+			if path.Ext(p) != ".pic" {
+				return nil
+			}
+
+			f, err := fsys.Open(p)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			pkgs[packageName(p)] = parser.ParseAllFrom(diags, p, f)
+			return nil
+		})
+	}
+
+	if err := visit(src.project, ".", true); err != nil {
+		return nil, err
+	}
+	if src.include != nil {
+		if _, err := fs.Stat(src.include, libDir); err == nil {
+			if err := visit(src.include, libDir, false); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return pkgs, nil
+}
+
+// packageName names the package of the file at p, e.g. os.io for os/io.pic.
+func packageName(p string) string {
+	return strings.ReplaceAll(strings.TrimSuffix(p, ".pic"), "/", ".")
+}
+
+// sources serves the project and the libs as a single tree, as the picasso
+// symlink in the project does for the command line.
+type sources struct {
+	project fs.FS
+	include fs.FS
+}
+
+func (s sources) Open(name string) (fs.File, error) {
+	if s.include != nil && (name == libDir || strings.HasPrefix(name, libDir+"/")) {
+		return s.include.Open(name)
+	}
+	return s.project.Open(name)
+}
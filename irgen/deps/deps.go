@@ -0,0 +1,229 @@
+// Package deps vendors the dependencies of a project, declared in its
+// manifest, into its vendor directory, and records what it vendored in its
+// lockfile, picasso.lock:
+//
+//	# generated by irgen deps, do not edit
+//	geo	1.2.0	https://example.com/geo-1.2.0.tar.gz	sha256:3f2a...
+//	shared	-	../shared	sha256:9c41...
+//
+// A dependency is the packages of a directory, or of the source roots of
+// its own manifest if it has one, and is vendored in vendor/NAME, so that
+// its packages are named vendor.NAME.<path> and can't collide with those of
+// the project or of another dependency. Builds check the vendor directory
+// against the lockfile with Verify.
+package deps
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/nagarajRPoojari/picasso/irgen/manifest"
+)
+
+// LockPath returns the path of the lockfile of the project m describes.
+func LockPath(m *manifest.Manifest) string {
+	return filepath.Join(m.Dir, LockFile)
+}
+
+// Vendor copies the packages of every dependency of m into its vendor
+// directory, removes those of dependencies it no longer has and writes its
+// lockfile. A tarball vendored again at the version and URL of the lockfile
+// must have the same checksum; a path has the one it has now. It returns
+// the new entries of the lockfile, in manifest order.
+func Vendor(m *manifest.Manifest) ([]Locked, error) {
+	lock, err := ReadLock(LockPath(m))
+	if err != nil {
+		return nil, err
+	}
+	vendor := m.Vendor()
+	if err := os.MkdirAll(vendor, 0o755); err != nil {
+		return nil, err
+	}
+
+	next := make(Lock, len(m.Deps))
+	var locked []Locked
+	for _, d := range m.Deps {
+		l, err := vendorDep(m, d, lock)
+		if err != nil {
+			return nil, fmt.Errorf("dependency %s: %w", d.Name, err)
+		}
+		next[d.Name] = l
+		locked = append(locked, l)
+	}
+
+	entries, err := os.ReadDir(vendor)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		if _, ok := next[e.Name()]; !ok && e.IsDir() {
+			if err := os.RemoveAll(filepath.Join(vendor, e.Name())); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return locked, next.Write(LockPath(m))
+}
+
+// vendorDep copies the packages of d into vendor/NAME, replacing what was
+// there unless their checksum does not match the one lock has for them.
+func vendorDep(m *manifest.Manifest, d manifest.Dependency, lock Lock) (Locked, error) {
+	l := Locked{Name: d.Name, Version: version(d), Source: source(m, d)}
+
+	src := d.Path
+	if d.URL != "" {
+		archive, err := fetch(m, d)
+		if err != nil {
+			return l, err
+		}
+		if src, err = os.MkdirTemp("", "picasso-"+d.Name+"-"); err != nil {
+			return l, err
+		}
+		defer os.RemoveAll(src)
+		if err := extract(archive, src); err != nil {
+			return l, err
+		}
+	}
+
+	// copied aside in the vendor directory first, so a failure leaves the
+	// vendored packages as they were
+	tmp, err := os.MkdirTemp(m.Vendor(), "."+d.Name+"-")
+	if err != nil {
+		return l, err
+	}
+	defer os.RemoveAll(tmp)
+	if err := copyPackages(src, tmp); err != nil {
+		return l, err
+	}
+	if l.Sum, err = Sum(tmp); err != nil {
+		return l, err
+	}
+	if old, ok := lock[d.Name]; ok && d.URL != "" && old.Source == l.Source && old.Version == l.Version && old.Sum != l.Sum {
+		return l, fmt.Errorf("checksum mismatch for %s at %s, %s has %s but it is now %s",
+			l.Source, l.Version, LockFile, old.Sum, l.Sum)
+	}
+
+	dst := filepath.Join(m.Vendor(), d.Name)
+	if err := os.RemoveAll(dst); err != nil {
+		return l, err
+	}
+	return l, os.Rename(tmp, dst)
+}
+
+// copyPackages copies the .pic files of the project in src to dst, those
+// of its source roots if it has a manifest.
+func copyPackages(src, dst string) error {
+	roots := []string{src}
+	dep, err := manifest.Load(src)
+	switch {
+	case err == nil && len(dep.Deps) > 0:
+		return fmt.Errorf("%s has dependencies of its own, which are not supported yet", dep.Path)
+	case err == nil:
+		roots = dep.Sources
+	case !errors.Is(err, manifest.ErrNotFound):
+		return err
+	}
+
+	copied := make(map[string]string)
+	for _, root := range roots {
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				// its build output, vendor directory and the like
+				if path != root && (strings.HasPrefix(d.Name(), ".") || d.Name() == manifest.VendorDir || slices.Contains(roots, path)) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !d.Type().IsRegular() || filepath.Ext(path) != ".pic" {
+				return nil
+			}
+
+			rel, err := filepath.Rel(root, path)
+			if err != nil {
+				return err
+			}
+			if prev, ok := copied[rel]; ok {
+				return fmt.Errorf("package %s is defined twice, in %s and %s", rel, prev, path)
+			}
+			copied[rel] = path
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			if err := os.MkdirAll(filepath.Join(dst, filepath.Dir(rel)), 0o755); err != nil {
+				return err
+			}
+			return os.WriteFile(filepath.Join(dst, rel), data, 0o644)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if len(copied) == 0 {
+		return fmt.Errorf("no .pic files in %s", src)
+	}
+	return nil
+}
+
+// Verify checks that every dependency of m is vendored as its lockfile
+// records it.
+func Verify(m *manifest.Manifest) error {
+	if len(m.Deps) == 0 {
+		return nil
+	}
+	lock, err := ReadLock(LockPath(m))
+	if err != nil {
+		return err
+	}
+	for _, d := range m.Deps {
+		l, ok := lock[d.Name]
+		switch {
+		case !ok:
+			return fmt.Errorf("dependency %s is not in %s, run irgen deps", d.Name, LockFile)
+		case l.Source != source(m, d) || l.Version != version(d):
+			return fmt.Errorf("dependency %s changed since %s was written, run irgen deps", d.Name, LockFile)
+		}
+
+		dir := filepath.Join(m.Vendor(), d.Name)
+		if _, err := os.Stat(dir); err != nil {
+			return fmt.Errorf("dependency %s is not vendored in %s, run irgen deps", d.Name, dir)
+		}
+		sum, err := Sum(dir)
+		if err != nil {
+			return err
+		}
+		if sum != l.Sum {
+			return fmt.Errorf("dependency %s: %s does not match its checksum in %s, run irgen deps", d.Name, dir, LockFile)
+		}
+	}
+	return nil
+}
+
+// version returns the version of d as the lockfile records it.
+func version(d manifest.Dependency) string {
+	if d.Version == "" {
+		return "-"
+	}
+	return d.Version
+}
+
+// source returns what d is recorded as vendored from: its URL, or its path
+// relative to the manifest so the lockfile does not depend on where the
+// project is.
+func source(m *manifest.Manifest, d manifest.Dependency) string {
+	if d.URL != "" {
+		return d.URL
+	}
+	if rel, err := filepath.Rel(m.Dir, d.Path); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return d.Path
+}
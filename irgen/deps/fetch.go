@@ -0,0 +1,146 @@
+package deps
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/nagarajRPoojari/picasso/irgen/manifest"
+)
+
+// CacheEnv is the variable overriding the directory downloaded tarballs are
+// kept in, by default picasso in the user cache directory.
+const CacheEnv = "PICASSO_CACHE"
+
+// CacheDir returns the directory downloaded tarballs are kept in.
+func CacheDir() (string, error) {
+	if dir := os.Getenv(CacheEnv); dir != "" {
+		return dir, nil
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "picasso"), nil
+}
+
+// fetch returns the path of the tarball of d, downloading it into the cache
+// unless it is there already. A URL without http(s) scheme is a local file,
+// relative to the manifest of m.
+func fetch(m *manifest.Manifest, d manifest.Dependency) (string, error) {
+	if local, ok := strings.CutPrefix(d.URL, "file://"); ok {
+		return local, nil
+	}
+	if !strings.HasPrefix(d.URL, "http://") && !strings.HasPrefix(d.URL, "https://") {
+		if filepath.IsAbs(d.URL) {
+			return d.URL, nil
+		}
+		return filepath.Join(m.Dir, d.URL), nil
+	}
+
+	cache, err := CacheDir()
+	if err != nil {
+		return "", err
+	}
+	// keyed by URL too, as two of them may have the same name and version
+	key := sha256.Sum256([]byte(d.URL))
+	dst := filepath.Join(cache, fmt.Sprintf("%s-%s-%x.tar.gz", d.Name, d.Version, key[:6]))
+	if _, err := os.Stat(dst); err == nil {
+		return dst, nil
+	}
+	if err := os.MkdirAll(cache, 0o755); err != nil {
+		return "", err
+	}
+
+	resp, err := http.Get(d.URL)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("downloading %s: %s", d.URL, resp.Status)
+	}
+
+	// written aside and renamed, so an interrupted download is not cached
+	f, err := os.CreateTemp(cache, ".download-")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(f.Name())
+	_, err = io.Copy(f, resp.Body)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return "", fmt.Errorf("downloading %s: %w", d.URL, err)
+	}
+	return dst, os.Rename(f.Name(), dst)
+}
+
+// extract extracts the regular files of the .tar.gz at archive into dir. A
+// directory all the files are in, as in the tarballs of a release, is left
+// out.
+func extract(archive, dir string) error {
+	f, err := os.Open(archive)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return fmt.Errorf("%s: %w", archive, err)
+	}
+
+	files := make(map[string][]byte)
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", archive, err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		name := path.Clean(hdr.Name)
+		if path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+			return fmt.Errorf("%s: %s is outside of the archive", archive, hdr.Name)
+		}
+		if files[name], err = io.ReadAll(tr); err != nil {
+			return fmt.Errorf("%s: %w", archive, err)
+		}
+	}
+
+	top := ""
+	for name := range files {
+		first, _, ok := strings.Cut(name, "/")
+		if !ok || top != "" && first != top {
+			top = ""
+			break
+		}
+		top = first
+	}
+	for name, data := range files {
+		if top != "" {
+			name = strings.TrimPrefix(name, top+"/")
+		}
+		dst := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(dst, data, 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
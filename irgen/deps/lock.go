@@ -0,0 +1,76 @@
+package deps
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+// LockFile is the lockfile irgen deps writes next to the manifest.
+const LockFile = "picasso.lock"
+
+const lockHeader = "# generated by irgen deps, do not edit\n"
+
+// Locked is the entry of a dependency in the lockfile: what it was
+// vendored from and the checksum of its vendored packages, see Sum.
+type Locked struct {
+	Name string
+	// Version is "-" for a path without version.
+	Version string
+	// Source is its URL, or its path relative to the manifest.
+	Source string
+	Sum    string
+}
+
+// Lock is a lockfile, its dependencies by name.
+type Lock map[string]Locked
+
+// ReadLock reads the lockfile at path. A missing lockfile is empty.
+func ReadLock(path string) (Lock, error) {
+	lock := make(Lock)
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return lock, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	for n := 1; sc.Scan(); n++ {
+		line := sc.Text()
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Split(line, "\t")
+		if len(fields) != 4 {
+			return nil, fmt.Errorf("%s:%d: want name, version, source and checksum separated by tabs", path, n)
+		}
+		l := Locked{Name: fields[0], Version: fields[1], Source: fields[2], Sum: fields[3]}
+		if _, ok := lock[l.Name]; ok {
+			return nil, fmt.Errorf("%s:%d: duplicate dependency %s", path, n, l.Name)
+		}
+		lock[l.Name] = l
+	}
+	return lock, sc.Err()
+}
+
+// Write writes l to path, a line per dependency sorted by name.
+func (l Lock) Write(path string) error {
+	var b strings.Builder
+	b.WriteString(lockHeader)
+	names := make([]string, 0, len(l))
+	for name := range l {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		e := l[name]
+		fmt.Fprintf(&b, "%s\t%s\t%s\t%s\n", e.Name, e.Version, e.Source, e.Sum)
+	}
+	return os.WriteFile(path, []byte(b.String()), 0o644)
+}
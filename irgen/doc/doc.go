@@ -0,0 +1,299 @@
+// Package doc extracts the API documentation of Picasso packages, see
+// irgen doc, and renders it as Markdown and static HTML.
+//
+// The doc comment of a declaration is the block of // comments on the lines
+// right above it, up to a blank line. The doc comment of a package is the
+// first block of comments of its file, unless it documents the first
+// declaration. Internal classes and members are left out.
+package doc
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/nagarajRPoojari/picasso/irgen/ast"
+	errorsx "github.com/nagarajRPoojari/picasso/irgen/error"
+	"github.com/nagarajRPoojari/picasso/irgen/format"
+	"github.com/nagarajRPoojari/picasso/irgen/lexer"
+	"github.com/nagarajRPoojari/picasso/irgen/parser"
+)
+
+// ErrSyntax is returned by Parse for source that does not parse; the
+// syntax errors are recorded in the collector given.
+var ErrSyntax = errors.New("syntax errors")
+
+// Package is the documentation of a package.
+type Package struct {
+	// Name is the package name, e.g. picasso.net.
+	Name string
+	// Path is the source file.
+	Path string
+	Doc  string
+
+	Classes    []*Class
+	Interfaces []*Interface
+
+	// imports maps import aliases to package names.
+	imports map[string]string
+}
+
+// Class is the documentation of a class.
+type Class struct {
+	Name string
+	Doc  string
+	Line int
+	// Implements is the fully qualified interface the class implements,
+	// as written if it is not documented, "" if none.
+	Implements string
+	// Constructors are the methods named like the class.
+	Constructors []*Member
+	Fields       []*Member
+	Methods      []*Member
+}
+
+// Interface is the documentation of an interface.
+type Interface struct {
+	Name    string
+	Doc     string
+	Line    int
+	Methods []*Member
+	// Implementers are the fully qualified classes implementing the
+	// interface, set by Link.
+	Implementers []string
+}
+
+// Member is the documentation of a field or method.
+type Member struct {
+	Name string
+	// Decl is the declaration without body or value, e.g.
+	// fn write(buf: []uint8): int.
+	Decl string
+	Doc  string
+	Line int
+}
+
+// Parse extracts the documentation of src, the source of package name in
+// the file at path. Syntax errors are recorded in diags.
+func Parse(diags *errorsx.Collector, name, path string, src []byte) (*Package, error) {
+	errs := diags.Errors()
+	tokens := lexer.TokenizeReader(diags, path, bytes.NewReader(src))
+	tree := parser.ParseTokens(diags, path, tokens)
+	if diags.Errors() > errs {
+		return nil, fmt.Errorf("%s: %w", path, ErrSyntax)
+	}
+
+	e := &extractor{tokens: tokens, at: make(map[lexer.SourceLoc]int, len(tokens))}
+	for i, tok := range tokens {
+		e.at[tok.Src] = i
+	}
+
+	p := &Package{Name: name, Path: path, imports: map[string]string{name: name}}
+	for _, stI := range tree.Body {
+		switch st := stI.(type) {
+		case ast.ImportStatement:
+			p.imports[st.Alias] = st.Name
+		case ast.ClassDeclarationStatement:
+			if !st.IsInternal {
+				p.Classes = append(p.Classes, e.class(st))
+			}
+		case ast.InterfaceDeclarationStatement:
+			p.Interfaces = append(p.Interfaces, e.iface(st))
+		}
+	}
+	p.Doc = e.packageDoc()
+	return p, nil
+}
+
+// Link resolves the interface each class of pkgs implements and records
+// the implementers of each interface. Names resolve like in the compiler:
+// fully qualified, through an import alias or in the same package.
+func Link(pkgs []*Package) {
+	ifaces := make(map[string]*Interface)
+	var names []string
+	for _, p := range pkgs {
+		for _, ifs := range p.Interfaces {
+			ifaces[p.Name+"."+ifs.Name] = ifs
+			names = append(names, p.Name+"."+ifs.Name)
+		}
+	}
+	sort.Strings(names)
+
+	resolve := func(p *Package, name string) string {
+		if _, ok := ifaces[name]; ok {
+			return name
+		}
+		if dot := strings.Index(name, "."); dot != -1 {
+			if pkg, ok := p.imports[name[:dot]]; ok {
+				if _, ok := ifaces[pkg+name[dot:]]; ok {
+					return pkg + name[dot:]
+				}
+			}
+		} else if _, ok := ifaces[p.Name+"."+name]; ok {
+			return p.Name + "." + name
+		}
+		for _, fq := range names {
+			if strings.HasSuffix(fq, "."+name) {
+				return fq
+			}
+		}
+		return name
+	}
+
+	for _, p := range pkgs {
+		for _, cls := range p.Classes {
+			if cls.Implements == "" {
+				continue
+			}
+			cls.Implements = resolve(p, cls.Implements)
+			if ifs, ok := ifaces[cls.Implements]; ok {
+				ifs.Implementers = append(ifs.Implementers, p.Name+"."+cls.Name)
+			}
+		}
+	}
+	for _, ifs := range ifaces {
+		sort.Strings(ifs.Implementers)
+	}
+}
+
+// extractor finds the doc comments of declarations in the comments the
+// lexer keeps with the tokens.
+type extractor struct {
+	tokens []lexer.Token
+	// at maps token locations to indices in tokens.
+	at map[lexer.SourceLoc]int
+}
+
+func (e *extractor) class(st ast.ClassDeclarationStatement) *Class {
+	cls := &Class{Name: st.Name, Doc: e.doc(st.SourceLoc), Line: st.Line, Implements: st.Implements}
+	for _, memberI := range st.Body {
+		switch member := memberI.(type) {
+		case ast.VariableDeclarationStatement:
+			if !member.IsInternal {
+				cls.Fields = append(cls.Fields, e.fields(member)...)
+			}
+		case ast.FunctionDefinitionStatement:
+			if member.IsInternal {
+				continue
+			}
+			m := e.method(member.SourceLoc, member.Name, member.Parameters, member.ReturnType, member.IsStatic)
+			if member.Name == st.Name {
+				cls.Constructors = append(cls.Constructors, m)
+			} else {
+				cls.Methods = append(cls.Methods, m)
+			}
+		}
+	}
+	return cls
+}
+
+func (e *extractor) iface(st ast.InterfaceDeclarationStatement) *Interface {
+	ifs := &Interface{Name: st.Name, Doc: e.doc(st.SourceLoc), Line: st.Line}
+	for _, memberI := range st.Body {
+		switch member := memberI.(type) {
+		case ast.FunctionDefinitionStatement:
+			ifs.Methods = append(ifs.Methods, e.method(member.SourceLoc, member.Name, member.Parameters, member.ReturnType, member.IsStatic))
+		case ast.FunctionDeclarationStatement:
+			ifs.Methods = append(ifs.Methods, e.method(member.SourceLoc, member.Name, member.Parameters, member.ReturnType, member.IsStatic))
+		}
+	}
+	return ifs
+}
+
+// fields documents the fields declared by st, one per variable of a
+// multiple declaration like say a: int, b: int.
+func (e *extractor) fields(st ast.VariableDeclarationStatement) []*Member {
+	names, types := st.Identifiers, st.ExplicitTypes
+	if len(names) == 0 {
+		names, types = []string{st.Identifier}, []ast.Type{st.ExplicitType}
+	}
+
+	doc := e.doc(st.SourceLoc)
+	fields := make([]*Member, len(names))
+	for i, name := range names {
+		decl := "say "
+		if st.IsStatic {
+			decl += "static "
+		}
+		decl += name
+		if i < len(types) && types[i] != nil {
+			decl += ": " + format.TypeString(types[i])
+		}
+		fields[i] = &Member{Name: name, Decl: decl, Doc: doc, Line: st.Line}
+	}
+	return fields
+}
+
+func (e *extractor) method(loc ast.SourceLoc, name string, params []ast.Parameter, ret ast.Type, static bool) *Member {
+	var b strings.Builder
+	b.WriteString("fn ")
+	if static {
+		b.WriteString("static ")
+	}
+	b.WriteString(name + "(")
+	for i, param := range params {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(param.Name + ": " + format.TypeString(param.Type))
+	}
+	b.WriteByte(')')
+	if ret != nil {
+		b.WriteString(": " + format.TypeString(ret))
+	}
+	return &Member{Name: name, Decl: b.String(), Doc: e.doc(loc), Line: loc.Line}
+}
+
+// doc returns the doc comment of the declaration starting at loc.
+func (e *extractor) doc(loc ast.SourceLoc) string {
+	i, ok := e.at[lexer.SourceLoc(loc)]
+	if !ok {
+		return ""
+	}
+	// a comment on the line of the previous token belongs to it
+	prev := 0
+	if i > 0 {
+		prev = e.tokens[i-1].Src.Line
+	}
+
+	comments := e.tokens[i].Comments
+	start, next := len(comments), e.tokens[i].Src.Line
+	for start > 0 && comments[start-1].Src.Line == next-1 && comments[start-1].Src.Line != prev {
+		start--
+		next = comments[start].Src.Line
+	}
+	return text(comments[start:])
+}
+
+// packageDoc returns the first block of comments of the file, unless it is
+// the doc comment of the first declaration.
+func (e *extractor) packageDoc() string {
+	first := e.tokens[0]
+	comments := first.Comments
+	if len(comments) == 0 {
+		return ""
+	}
+	end := 1
+	for end < len(comments) && comments[end].Src.Line == comments[end-1].Src.Line+1 {
+		end++
+	}
+	if end == len(comments) && comments[end-1].Src.Line == first.Src.Line-1 {
+		switch first.Kind {
+		case lexer.CLASS, lexer.INTERFACE:
+			return ""
+		}
+	}
+	return text(comments[:end])
+}
+
+// text returns the text of comments without the slashes.
+func text(comments []lexer.Comment) string {
+	lines := make([]string, len(comments))
+	for i, c := range comments {
+		line := strings.TrimPrefix(c.Text, "//")
+		lines[i] = strings.TrimRight(strings.TrimPrefix(line, " "), " \t\r")
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,127 @@
+package doc
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+)
+
+const htmlHead = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>%s</title>
+<style>
+body { font-family: sans-serif; max-width: 60em; margin: 2em auto; padding: 0 1em; line-height: 1.5; }
+pre { background: #f4f4f4; padding: 0.5em 1em; overflow-x: auto; }
+code { font-family: monospace; }
+a.source { font-size: 0.85em; }
+</style>
+</head>
+<body>
+`
+
+const htmlTail = "</body>\n</html>\n"
+
+func writeHTMLIndex(w *bytes.Buffer, pkgs []*Package) {
+	fmt.Fprintf(w, htmlHead, "Packages")
+	w.WriteString("<h1>Packages</h1>\n<ul>\n")
+	for _, p := range pkgs {
+		fmt.Fprintf(w, "<li><a href=\"%s.html\">%s</a>", html.EscapeString(p.Name), html.EscapeString(p.Name))
+		if s := synopsis(p.Doc); s != "" {
+			fmt.Fprintf(w, ": %s", html.EscapeString(s))
+		}
+		w.WriteString("</li>\n")
+	}
+	w.WriteString("</ul>\n" + htmlTail)
+}
+
+func writeHTML(w *bytes.Buffer, pg *page) {
+	fmt.Fprintf(w, htmlHead, "Package "+html.EscapeString(pg.Name))
+	fmt.Fprintf(w, "<h1>Package %s</h1>\n", html.EscapeString(pg.Name))
+	htmlDoc(w, pg.Doc)
+	fmt.Fprintf(w, "<p>Source: <a href=\"%s\">%s</a></p>\n", html.EscapeString(pg.source), html.EscapeString(pg.source))
+
+	if len(pg.Interfaces)+len(pg.Classes) > 0 {
+		w.WriteString("<h2>Index</h2>\n<ul>\n")
+		for _, ifs := range pg.Interfaces {
+			fmt.Fprintf(w, "<li><a href=\"#%s\">interface %s</a></li>\n", ifs.Name, ifs.Name)
+		}
+		for _, cls := range pg.Classes {
+			fmt.Fprintf(w, "<li><a href=\"#%s\">class %s</a></li>\n", cls.Name, cls.Name)
+		}
+		w.WriteString("</ul>\n")
+	}
+
+	if len(pg.Interfaces) > 0 {
+		w.WriteString("<h2>Interfaces</h2>\n")
+	}
+	for _, ifs := range pg.Interfaces {
+		fmt.Fprintf(w, "<h3 id=\"%s\">interface %s</h3>\n", ifs.Name, ifs.Name)
+		htmlDoc(w, ifs.Doc)
+		htmlSource(w, pg, ifs.Line)
+		if len(ifs.Implementers) > 0 {
+			w.WriteString("<p>Implemented by:</p>\n<ul>\n")
+			for _, fq := range ifs.Implementers {
+				fmt.Fprintf(w, "<li><a href=\"%s\">%s</a></li>\n", html.EscapeString(pg.link(fq)), html.EscapeString(fq))
+			}
+			w.WriteString("</ul>\n")
+		}
+		htmlMembers(w, pg, ifs.Name, "Methods", ifs.Methods)
+	}
+
+	if len(pg.Classes) > 0 {
+		w.WriteString("<h2>Classes</h2>\n")
+	}
+	for _, cls := range pg.Classes {
+		fmt.Fprintf(w, "<h3 id=\"%s\">class %s</h3>\n", cls.Name, cls.Name)
+		htmlDoc(w, cls.Doc)
+		htmlSource(w, pg, cls.Line)
+		if cls.Implements != "" {
+			if link := pg.link(cls.Implements); link != "" {
+				fmt.Fprintf(w, "<p>Implements <a href=\"%s\">%s</a>.</p>\n", html.EscapeString(link), html.EscapeString(cls.Implements))
+			} else {
+				fmt.Fprintf(w, "<p>Implements %s.</p>\n", html.EscapeString(cls.Implements))
+			}
+		}
+		if len(cls.Fields) > 0 {
+			w.WriteString("<h4>Fields</h4>\n<ul>\n")
+			for _, f := range cls.Fields {
+				fmt.Fprintf(w, "<li><code>%s</code>", html.EscapeString(f.Decl))
+				if f.Doc != "" {
+					fmt.Fprintf(w, ": %s", html.EscapeString(f.Doc))
+				}
+				fmt.Fprintf(w, " <a class=\"source\" href=\"%s\">source</a></li>\n", html.EscapeString(pg.sourceLink(f.Line)))
+			}
+			w.WriteString("</ul>\n")
+		}
+		htmlMembers(w, pg, cls.Name, "Constructors", cls.Constructors)
+		htmlMembers(w, pg, cls.Name, "Methods", cls.Methods)
+	}
+	w.WriteString(htmlTail)
+}
+
+// htmlMembers writes the methods of class or interface owner under
+// heading.
+func htmlMembers(w *bytes.Buffer, pg *page, owner, heading string, members []*Member) {
+	if len(members) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "<h4>%s</h4>\n", heading)
+	for _, m := range members {
+		fmt.Fprintf(w, "<h5 id=\"%s.%s\">%s</h5>\n", owner, m.Name, m.Name)
+		fmt.Fprintf(w, "<pre><code>%s</code></pre>\n", html.EscapeString(m.Decl))
+		htmlDoc(w, m.Doc)
+		htmlSource(w, pg, m.Line)
+	}
+}
+
+func htmlSource(w *bytes.Buffer, pg *page, line int) {
+	fmt.Fprintf(w, "<p><a class=\"source\" href=\"%s\">source</a></p>\n", html.EscapeString(pg.sourceLink(line)))
+}
+
+func htmlDoc(w *bytes.Buffer, doc string) {
+	for _, para := range paragraphs(doc) {
+		fmt.Fprintf(w, "<p>%s</p>\n", html.EscapeString(para))
+	}
+}
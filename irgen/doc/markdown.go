@@ -0,0 +1,99 @@
+package doc
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+func writeMarkdownIndex(w *bytes.Buffer, pkgs []*Package) {
+	w.WriteString("# Packages\n\n")
+	for _, p := range pkgs {
+		fmt.Fprintf(w, "- [%s](%s.md)", p.Name, p.Name)
+		if s := synopsis(p.Doc); s != "" {
+			fmt.Fprintf(w, ": %s", s)
+		}
+		w.WriteByte('\n')
+	}
+}
+
+func writeMarkdown(w *bytes.Buffer, pg *page) {
+	fmt.Fprintf(w, "# Package %s\n\n", pg.Name)
+	markdownDoc(w, pg.Doc)
+	fmt.Fprintf(w, "Source: [%s](%s)\n", pg.source, pg.source)
+
+	if len(pg.Interfaces)+len(pg.Classes) > 0 {
+		w.WriteString("\n## Index\n\n")
+		for _, ifs := range pg.Interfaces {
+			fmt.Fprintf(w, "- [interface %s](#%s)\n", ifs.Name, ifs.Name)
+		}
+		for _, cls := range pg.Classes {
+			fmt.Fprintf(w, "- [class %s](#%s)\n", cls.Name, cls.Name)
+		}
+	}
+
+	if len(pg.Interfaces) > 0 {
+		w.WriteString("\n## Interfaces\n")
+	}
+	for _, ifs := range pg.Interfaces {
+		fmt.Fprintf(w, "\n<a id=\"%s\"></a>\n\n### interface %s\n\n", ifs.Name, ifs.Name)
+		markdownDoc(w, ifs.Doc)
+		fmt.Fprintf(w, "[source](%s)\n", pg.sourceLink(ifs.Line))
+		if len(ifs.Implementers) > 0 {
+			w.WriteString("\nImplemented by:\n\n")
+			for _, fq := range ifs.Implementers {
+				fmt.Fprintf(w, "- [%s](%s)\n", fq, pg.link(fq))
+			}
+		}
+		markdownMembers(w, pg, ifs.Name, "Methods", ifs.Methods)
+	}
+
+	if len(pg.Classes) > 0 {
+		w.WriteString("\n## Classes\n")
+	}
+	for _, cls := range pg.Classes {
+		fmt.Fprintf(w, "\n<a id=\"%s\"></a>\n\n### class %s\n\n", cls.Name, cls.Name)
+		markdownDoc(w, cls.Doc)
+		fmt.Fprintf(w, "[source](%s)\n", pg.sourceLink(cls.Line))
+		if cls.Implements != "" {
+			if link := pg.link(cls.Implements); link != "" {
+				fmt.Fprintf(w, "\nImplements [%s](%s).\n", cls.Implements, link)
+			} else {
+				fmt.Fprintf(w, "\nImplements %s.\n", cls.Implements)
+			}
+		}
+		if len(cls.Fields) > 0 {
+			w.WriteString("\n#### Fields\n\n")
+			for _, f := range cls.Fields {
+				fmt.Fprintf(w, "- `%s`", f.Decl)
+				if f.Doc != "" {
+					fmt.Fprintf(w, ": %s", strings.ReplaceAll(f.Doc, "\n", "\n  "))
+				}
+				fmt.Fprintf(w, " ([source](%s))\n", pg.sourceLink(f.Line))
+			}
+		}
+		markdownMembers(w, pg, cls.Name, "Constructors", cls.Constructors)
+		markdownMembers(w, pg, cls.Name, "Methods", cls.Methods)
+	}
+}
+
+// markdownMembers writes the methods of class or interface owner under
+// heading.
+func markdownMembers(w *bytes.Buffer, pg *page, owner, heading string, members []*Member) {
+	if len(members) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\n#### %s\n", heading)
+	for _, m := range members {
+		fmt.Fprintf(w, "\n<a id=\"%s.%s\"></a>\n\n##### %s\n\n", owner, m.Name, m.Name)
+		fmt.Fprintf(w, "```picasso\n%s\n```\n\n", m.Decl)
+		markdownDoc(w, m.Doc)
+		fmt.Fprintf(w, "[source](%s)\n", pg.sourceLink(m.Line))
+	}
+}
+
+func markdownDoc(w *bytes.Buffer, doc string) {
+	for _, para := range paragraphs(doc) {
+		w.WriteString(para + "\n\n")
+	}
+}
@@ -0,0 +1,111 @@
+package doc
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Write renders the documentation of pkgs into dir: a Markdown and an HTML
+// page per package, named after it, e.g. picasso.net.md, and an index of
+// each. Source links are relative to dir.
+func Write(dir string, pkgs []*Package) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+
+	sorted := append([]*Package(nil), pkgs...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+	types := make(map[string]string)
+	for _, p := range sorted {
+		for _, cls := range p.Classes {
+			types[p.Name+"."+cls.Name] = p.Name
+		}
+		for _, ifs := range p.Interfaces {
+			types[p.Name+"."+ifs.Name] = p.Name
+		}
+	}
+
+	for _, p := range sorted {
+		src, err := filepath.Abs(p.Path)
+		if err != nil {
+			return err
+		}
+		if src, err = filepath.Rel(abs, src); err != nil {
+			return err
+		}
+		for _, ext := range []string{".md", ".html"} {
+			pg := &page{Package: p, source: filepath.ToSlash(src), ext: ext, types: types}
+			var buf bytes.Buffer
+			if ext == ".md" {
+				writeMarkdown(&buf, pg)
+			} else {
+				writeHTML(&buf, pg)
+			}
+			if err := os.WriteFile(filepath.Join(dir, p.Name+ext), buf.Bytes(), 0o644); err != nil {
+				return err
+			}
+		}
+	}
+
+	var md, html bytes.Buffer
+	writeMarkdownIndex(&md, sorted)
+	writeHTMLIndex(&html, sorted)
+	if err := os.WriteFile(filepath.Join(dir, "index.md"), md.Bytes(), 0o644); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, "index.html"), html.Bytes(), 0o644)
+}
+
+// page is a package page being rendered.
+type page struct {
+	*Package
+	// source links the source file from the page.
+	source string
+	// ext is the extension of the pages, .md or .html.
+	ext string
+	// types maps documented classes and interfaces to their package.
+	types map[string]string
+}
+
+// sourceLink links line of the source file.
+func (pg *page) sourceLink(line int) string {
+	return fmt.Sprintf("%s#L%d", pg.source, line)
+}
+
+// link links the fully qualified class or interface fq, "" if it is not
+// documented.
+func (pg *page) link(fq string) string {
+	pkg, ok := pg.types[fq]
+	if !ok {
+		return ""
+	}
+	return pkg + pg.ext + "#" + strings.TrimPrefix(fq, pkg+".")
+}
+
+// paragraphs splits a doc comment at blank lines.
+func paragraphs(doc string) []string {
+	var paras []string
+	for _, para := range strings.Split(doc, "\n\n") {
+		if para = strings.Trim(para, "\n"); para != "" {
+			paras = append(paras, para)
+		}
+	}
+	return paras
+}
+
+// synopsis returns the first sentence of a doc comment, on one line.
+func synopsis(doc string) string {
+	doc = strings.Join(strings.Fields(doc), " ")
+	if i := strings.Index(doc, ". "); i != -1 {
+		return doc[:i+1]
+	}
+	return doc
+}
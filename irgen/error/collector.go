@@ -0,0 +1,331 @@
+package errorsx
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"sort"
+	"sync"
+)
+
+// Severity tells how serious a Diagnostic is.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Stable codes of lexer and parser errors. Codegen and semantic errors take
+// theirs from the error templates, see errorutils.Code.
+const (
+	CodeLexer  = "E1000"
+	CodeSyntax = "E1001"
+)
+
+// Diagnostic is a single error recorded during compilation.
+type Diagnostic struct {
+	Phase Phase
+	// Severity defaults to SeverityError when empty.
+	Severity Severity
+	// Code identifies the kind of error across releases, e.g. E0012.
+	Code    string
+	Message string
+	Path    string
+	Line    int
+	Col     int
+	// EndLine and EndCol locate the end of the offending token, exclusive.
+	// When unset they are computed from the source when printing.
+	EndLine int
+	EndCol  int
+
+	// Notes point at related locations, e.g. a previous declaration.
+	Notes []Note
+}
+
+// Note is a source location related to a Diagnostic.
+type Note struct {
+	Message string
+	Path    string
+	Line    int
+	Col     int
+}
+
+// key identifies a diagnostic for deduplication, e.g. errors from a file
+// parsed twice.
+type key struct {
+	phase     Phase
+	msg, path string
+	line, col int
+}
+
+func (d Diagnostic) key() key {
+	return key{d.Phase, d.Message, d.Path, d.Line, d.Col}
+}
+
+// Collector accumulates diagnostics from the lexer, parser and codegen so
+// that a single run can report every error it finds.
+type Collector struct {
+	mu       sync.Mutex
+	diags    []Diagnostic
+	format   Format
+	warnings *warningConfig
+	// sources, if set, holds the files diagnostics point into
+	sources fs.FS
+	// directives holds the picasso:ignore comments of each file by line
+	directives map[string]map[int]Comment
+}
+
+// Bailout is the panic value used to unwind out of a failing statement,
+// function or file. It carries the error until a recovery point, see
+// Collector.Recover and Collector.Guard, records it in its collector.
+type Bailout struct {
+	// d is nil if the error has been recorded already.
+	d *Diagnostic
+}
+
+// Diagnostics is the collector of the command line tools, which compile one
+// project per process. Code compiling from Go takes its own collector.
+var Diagnostics = NewCollector()
+
+func NewCollector() *Collector {
+	return &Collector{format: FormatText, warnings: newWarningConfig()}
+}
+
+// Add records d and returns its id.
+func (c *Collector) Add(d Diagnostic) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.diags = append(c.diags, d)
+	return len(c.diags) - 1
+}
+
+// Len returns the number of recorded diagnostics, warnings included.
+func (c *Collector) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.diags)
+}
+
+// Errors returns the number of recorded errors.
+func (c *Collector) Errors() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n := 0
+	for _, d := range c.diags {
+		if d.severity() == SeverityError {
+			n++
+		}
+	}
+	return n
+}
+
+// Sorted returns recorded diagnostics without duplicates, ordered by file,
+// line and column. Diagnostics without a location keep their relative order
+// at the end.
+func (c *Collector) Sorted() []Diagnostic {
+	c.mu.Lock()
+	out := make([]Diagnostic, 0, len(c.diags))
+	seen := make(map[key]struct{})
+	for _, d := range c.diags {
+		if _, ok := seen[d.key()]; ok {
+			continue
+		}
+		seen[d.key()] = struct{}{}
+		out = append(out, d)
+	}
+	c.mu.Unlock()
+
+	sort.SliceStable(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if (a.Path == "") != (b.Path == "") {
+			return b.Path == ""
+		}
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Col < b.Col
+	})
+	return out
+}
+
+// SetFormat selects how Print writes diagnostics.
+func (c *Collector) SetFormat(f Format) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.format = f
+}
+
+// SetSources makes the collector read the source it quotes from fsys
+// rather than the file system, for code compiled from memory.
+func (c *Collector) SetSources(fsys fs.FS) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sources = fsys
+}
+
+func (c *Collector) open(path string) (io.ReadCloser, error) {
+	c.mu.Lock()
+	fsys := c.sources
+	c.mu.Unlock()
+	if fsys != nil {
+		return fsys.Open(path)
+	}
+	return os.Open(path)
+}
+
+// Resolved returns the diagnostics of Sorted with the end of each location
+// filled in, for callers that cannot read the source themselves.
+func (c *Collector) Resolved() []Diagnostic {
+	diags := c.Sorted()
+	for i, d := range diags {
+		if d.Path != "" {
+			diags[i].EndLine, diags[i].EndCol = c.end(d)
+		}
+	}
+	return diags
+}
+
+// Format returns the format used by Print.
+func (c *Collector) Format() Format {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.format
+}
+
+// Print writes every recorded diagnostic in the selected format. Text output
+// ends with a summary line and is empty without diagnostics; JSON and SARIF
+// output is always a complete document.
+func (c *Collector) Print() {
+	diags := c.Sorted()
+	switch c.Format() {
+	case FormatJSON:
+		c.writeJSON(os.Stdout, diags)
+		return
+	case FormatSARIF:
+		c.writeSARIF(os.Stdout, diags)
+		return
+	}
+
+	errors, warnings := 0, 0
+	for _, d := range diags {
+		c.printSourceContext(d)
+		for _, n := range d.Notes {
+			c.printNote(n)
+		}
+		if d.severity() == SeverityError {
+			errors++
+		} else {
+			warnings++
+		}
+	}
+	switch {
+	case errors > 0 && warnings > 0:
+		fmt.Printf("%d error(s), %d warning(s)\n", errors, warnings)
+	case errors > 0:
+		fmt.Printf("%d error(s)\n", errors)
+	case warnings > 0:
+		fmt.Printf("%d warning(s)\n", warnings)
+	}
+}
+
+// Reset discards all recorded diagnostics.
+func (c *Collector) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.diags = nil
+}
+
+// ExitOnErrors prints all diagnostics and exits with status 1 if any errors
+// were recorded. Warnings alone are left to be printed later.
+func (c *Collector) ExitOnErrors() {
+	if c.Errors() == 0 {
+		return
+	}
+	c.Print()
+	os.Exit(1)
+}
+
+// Report raises an error, see Raise.
+func Report(phase Phase, msg string, path string, line int, col int, notes ...Note) {
+	Raise(Diagnostic{Phase: phase, Message: msg, Path: path, Line: line, Col: col, Notes: notes})
+}
+
+// Raise unwinds to the nearest recovery point, which records d in its
+// collector.
+func Raise(d Diagnostic) {
+	panic(Bailout{d: &d})
+}
+
+// Bail unwinds to the nearest recovery point after an error has been
+// recorded in a collector.
+func Bail() {
+	panic(Bailout{})
+}
+
+// locate attaches a location to the error carried by b if it was raised
+// without one. Handlers that cannot see the current node raise without a
+// location and the enclosing node fills it in while unwinding.
+func (b Bailout) locate(path string, line, col int) {
+	if b.d != nil && b.d.Path == "" {
+		b.d.Path, b.d.Line, b.d.Col = path, line, col
+	}
+}
+
+// At, when deferred by code compiling the node at path:line:col, attributes
+// an error raised without a location to that node and keeps unwinding.
+func At(path string, line int, col int) {
+	if r := recover(); r != nil {
+		if b, ok := r.(Bailout); ok {
+			b.locate(path, line, col)
+		}
+		panic(r)
+	}
+}
+
+// Caught records the error carried by r, a value recovered from a panic, and
+// reports whether r is a Bailout. Recovery points that do more than Recover
+// call it from their own deferred function.
+func (c *Collector) Caught(r any) bool {
+	b, ok := r.(Bailout)
+	if ok && b.d != nil {
+		c.Add(*b.d)
+	}
+	return ok
+}
+
+// Recover stops a Bailout from unwinding further, recording its error in c.
+// It must be deferred directly; any other panic is propagated.
+func (c *Collector) Recover() {
+	if r := recover(); r != nil && !c.Caught(r) {
+		panic(r)
+	}
+}
+
+// Guard runs fn and reports whether it completed without bailing out.
+func (c *Collector) Guard(fn func()) bool {
+	return c.GuardAt("", 0, 0, fn)
+}
+
+// GuardAt is Guard for code compiling the node at path:line:col. An error
+// raised without a location while running fn is attributed to it.
+func (c *Collector) GuardAt(path string, line int, col int, fn func()) (ok bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			if b, isBailout := r.(Bailout); isBailout && path != "" {
+				b.locate(path, line, col)
+			}
+			if !c.Caught(r) {
+				panic(r)
+			}
+			ok = false
+		}
+	}()
+	fn()
+	return true
+}
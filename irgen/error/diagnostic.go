@@ -3,90 +3,105 @@ package errorsx
 import (
 	"bufio"
 	"fmt"
-	"os"
 	"strings"
 
 	"github.com/fatih/color"
 )
 
-func printSourceContext(
-	path string,
-	line int,
-	col int,
-	msg string,
-	phase Phase,
-) {
-	redBold := color.New(color.FgRed, color.Bold).SprintFunc()
-	red := color.New(color.FgRed).SprintFunc()
+func (c *Collector) printSourceContext(d Diagnostic) {
+	attr, label := color.FgRed, "Error"
+	if d.severity() == SeverityWarning {
+		attr, label = color.FgYellow, "Warning"
+	}
+	bold := color.New(attr, color.Bold).SprintFunc()
+	plain := color.New(attr).SprintFunc()
 	gray := color.New(color.FgHiBlack).SprintFunc()
 
-	// If no path provided, fall back to header-only error
-	if path == "" {
-		fmt.Printf(
-			"%s %s\n",
-			redBold(fmt.Sprintf("[%s Error]:", phase)),
-			gray(msg),
-		)
-		return
-	}
+	c.printSnippet(d.Path, d.Line, d.Col, plain)
 
-	f, err := os.Open(path)
-	if err != nil {
-		// Graceful fallback instead of exit
-		fmt.Printf(
-			"%s:%d:%d\n%s %s\n",
-			path,
-			line,
-			col,
-			redBold(fmt.Sprintf("[%s Error]:", phase)),
-			gray(msg),
-		)
-		return
+	// Error message
+	header := fmt.Sprintf("[%s %s]:", d.Phase, label)
+	if d.Code != "" {
+		header = fmt.Sprintf("[%s %s %s]:", d.Phase, label, d.Code)
 	}
-	defer f.Close()
+	fmt.Printf("%s %s\n", bold(header), gray(d.Message))
+}
 
-	scanner := bufio.NewScanner(f)
-	current := 1
-	var srcLine string
+// printNote prints a location related to the preceding error, e.g. where a
+// conflicting symbol was first declared.
+func (c *Collector) printNote(n Note) {
+	cyanBold := color.New(color.FgCyan, color.Bold).SprintFunc()
+	cyan := color.New(color.FgCyan).SprintFunc()
+	gray := color.New(color.FgHiBlack).SprintFunc()
 
-	for scanner.Scan() {
-		if current == line {
-			srcLine = scanner.Text()
-			break
-		}
-		current++
-	}
+	c.printSnippet(n.Path, n.Line, n.Col, cyan)
+	fmt.Printf("%s %s\n", cyanBold("note:"), gray(n.Message))
+}
 
-	// If line not found, fall back gracefully
-	if srcLine == "" {
-		fmt.Printf(
-			"%s:%d:%d\n%s %s\n",
-			path,
-			line,
-			col,
-			redBold(fmt.Sprintf("[%s Error]:", phase)),
-			gray(msg),
-		)
+// printSnippet prints path:line:col followed by the source line and an
+// underline below the token at col. Nothing but the header is printed if
+// the line cannot be read, and nothing at all without a path.
+func (c *Collector) printSnippet(path string, line int, col int, mark func(a ...interface{}) string) {
+	if path == "" {
 		return
 	}
-
-	// Header
 	fmt.Printf("%s:%d:%d\n", path, line, col)
 
+	srcLine, ok := c.readLine(path, line)
+	if !ok {
+		return
+	}
+
 	// Source line
 	fmt.Printf("  %s\n", srcLine)
 
-	// Caret underline
+	// Underline, keeping tabs so that it lines up with the source line
 	if col < 1 {
 		col = 1
 	}
-	prefix := strings.Repeat(" ", col-1)
-	fmt.Printf("  %s%s\n", prefix, red("^"))
+	var prefix strings.Builder
+	for i := 0; i < col-1 && i < len(srcLine); i++ {
+		if srcLine[i] == '\t' {
+			prefix.WriteByte('\t')
+		} else {
+			prefix.WriteByte(' ')
+		}
+	}
+	underline := "^" + strings.Repeat("~", tokenWidth(srcLine, col-1)-1)
+	fmt.Printf("  %s%s\n", prefix.String(), mark(underline))
+}
 
-	// Error message
-	fmt.Printf(
-		"%s %s\n",
-		redBold(fmt.Sprintf("[%s Error]:", phase)),
-		gray(msg),
-	)
+// readLine returns line of the file at path, read from the sources of c.
+func (c *Collector) readLine(path string, line int) (string, bool) {
+	f, err := c.open(path)
+	if err != nil {
+		return "", false
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for current := 1; scanner.Scan(); current++ {
+		if current == line {
+			return scanner.Text(), scanner.Text() != ""
+		}
+	}
+	return "", false
+}
+
+// tokenWidth returns the length of the identifier or number starting at
+// offset i of s, or 1 for any other token.
+func tokenWidth(s string, i int) int {
+	n := 0
+	for j := i; j < len(s); j++ {
+		c := s[j]
+		if c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' {
+			n++
+			continue
+		}
+		break
+	}
+	if n == 0 {
+		return 1
+	}
+	return n
 }
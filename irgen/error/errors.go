@@ -4,7 +4,6 @@ package errorsx
 
 import (
 	"fmt"
-	"os"
 )
 
 // Phase represents the stage of compilation where an error occurred.
@@ -14,6 +13,7 @@ const (
 	PhaseCompilation Phase = "Compilation"
 	PhaseLexer       Phase = "Lexer"
 	PhaseParser      Phase = "Parser"
+	PhaseSemantic    Phase = "Semantic"
 )
 
 // Error represents a structured generator error.
@@ -69,38 +69,18 @@ func NewParserError(msg string, l ...int) *Error {
 	return &Error{Phase: PhaseParser, Message: msg, Line: line, Column: col}
 }
 
-// PanicLexerError panics with a lexer error.
+// PanicCompilationError records a compilation error and unwinds to the
+// nearest recovery point.
 func PanicCompilationError(msg string, path string, line int, col int) {
-	printSourceContext(
-		path,
-		line,
-		col,
-		msg,
-		PhaseCompilation,
-	)
-	os.Exit(1)
+	Report(PhaseCompilation, msg, path, line, col)
 }
 
-// PanicLexerError panics with a lexer error.
+// PanicLexerError records a lexer error and unwinds to the nearest recovery point.
 func PanicLexerError(msg string, path string, line int, col int) {
-	printSourceContext(
-		path,
-		line,
-		col,
-		msg,
-		PhaseLexer,
-	)
-	os.Exit(1)
+	Raise(Diagnostic{Phase: PhaseLexer, Code: CodeLexer, Message: msg, Path: path, Line: line, Col: col})
 }
 
-// PanicParserError panics with a parser error.
+// PanicParserError records a parser error and unwinds to the nearest recovery point.
 func PanicParserError(msg string, path string, line int, col int) {
-	printSourceContext(
-		path,
-		line,
-		col,
-		msg,
-		PhaseParser,
-	)
-	os.Exit(1)
+	Raise(Diagnostic{Phase: PhaseParser, Code: CodeSyntax, Message: msg, Path: path, Line: line, Col: col})
 }
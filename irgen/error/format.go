@@ -0,0 +1,241 @@
+package errorsx
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+)
+
+// Format is an output format for diagnostics.
+type Format string
+
+const (
+	// FormatText is the human readable output with source snippets.
+	FormatText Format = "text"
+	// FormatJSON writes a single JSON document listing all diagnostics.
+	FormatJSON Format = "json"
+	// FormatSARIF writes a SARIF 2.1.0 log, understood by code review
+	// tools that annotate pull requests.
+	FormatSARIF Format = "sarif"
+)
+
+// ParseFormat validates a --diagnostics-format value.
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(s); f {
+	case FormatText, FormatJSON, FormatSARIF:
+		return f, nil
+	}
+	return "", fmt.Errorf("unknown diagnostics format %q, want text, json or sarif", s)
+}
+
+// severity returns the severity of d, SeverityError if unset.
+func (d Diagnostic) severity() Severity {
+	if d.Severity == "" {
+		return SeverityError
+	}
+	return d.Severity
+}
+
+// span returns the end of the token at line:col in path, exclusive. Without
+// readable source the token is taken to be a single character.
+func (c *Collector) span(path string, line int, col int) (int, int) {
+	if src, ok := c.readLine(path, line); ok && col >= 1 {
+		return line, col + tokenWidth(src, col-1)
+	}
+	return line, col + 1
+}
+
+// end returns the end of d, read from the sources of c if not set.
+func (c *Collector) end(d Diagnostic) (int, int) {
+	if d.EndLine > 0 {
+		return d.EndLine, d.EndCol
+	}
+	return c.span(d.Path, d.Line, d.Col)
+}
+
+type jsonLocation struct {
+	Message string `json:"message,omitempty"`
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Col     int    `json:"col"`
+	EndLine int    `json:"endLine"`
+	EndCol  int    `json:"endCol"`
+}
+
+type jsonDiagnostic struct {
+	Severity Severity       `json:"severity"`
+	Code     string         `json:"code,omitempty"`
+	Phase    Phase          `json:"phase"`
+	Message  string         `json:"message"`
+	File     string         `json:"file,omitempty"`
+	Line     int            `json:"line,omitempty"`
+	Col      int            `json:"col,omitempty"`
+	EndLine  int            `json:"endLine,omitempty"`
+	EndCol   int            `json:"endCol,omitempty"`
+	Related  []jsonLocation `json:"related,omitempty"`
+}
+
+func (c *Collector) writeJSON(w io.Writer, diags []Diagnostic) {
+	out := struct {
+		Diagnostics []jsonDiagnostic `json:"diagnostics"`
+	}{Diagnostics: make([]jsonDiagnostic, 0, len(diags))}
+
+	for _, d := range diags {
+		jd := jsonDiagnostic{
+			Severity: d.severity(),
+			Code:     d.Code,
+			Phase:    d.Phase,
+			Message:  d.Message,
+			File:     d.Path,
+			Line:     d.Line,
+			Col:      d.Col,
+		}
+		if d.Path != "" {
+			jd.EndLine, jd.EndCol = c.end(d)
+		}
+		for _, n := range d.Notes {
+			endLine, endCol := c.span(n.Path, n.Line, n.Col)
+			jd.Related = append(jd.Related, jsonLocation{
+				Message: n.Message,
+				File:    n.Path,
+				Line:    n.Line,
+				Col:     n.Col,
+				EndLine: endLine,
+				EndCol:  endCol,
+			})
+		}
+		out.Diagnostics = append(out.Diagnostics, jd)
+	}
+	encode(w, out)
+}
+
+// The subset of SARIF 2.1.0 needed to report results with locations.
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name  string      `json:"name"`
+	Rules []sarifRule `json:"rules,omitempty"`
+}
+
+type sarifRule struct {
+	ID string `json:"id"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID           string          `json:"ruleId,omitempty"`
+	Level            string          `json:"level"`
+	Message          sarifMessage    `json:"message"`
+	Locations        []sarifLocation `json:"locations,omitempty"`
+	RelatedLocations []sarifLocation `json:"relatedLocations,omitempty"`
+}
+
+type sarifLocation struct {
+	ID               *int                  `json:"id,omitempty"`
+	Message          *sarifMessage         `json:"message,omitempty"`
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifact `json:"artifactLocation"`
+	Region           sarifRegion   `json:"region"`
+}
+
+type sarifArtifact struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn"`
+	EndLine     int `json:"endLine"`
+	EndColumn   int `json:"endColumn"`
+}
+
+func (c *Collector) writeSARIF(w io.Writer, diags []Diagnostic) {
+	run := sarifRun{
+		Tool:    sarifTool{Driver: sarifDriver{Name: "irgen"}},
+		Results: make([]sarifResult, 0, len(diags)),
+	}
+
+	seen := make(map[string]bool)
+	for _, d := range diags {
+		if d.Code != "" && !seen[d.Code] {
+			seen[d.Code] = true
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{ID: d.Code})
+		}
+
+		r := sarifResult{
+			RuleID:  d.Code,
+			Level:   string(d.severity()),
+			Message: sarifMessage{Text: d.Message},
+		}
+		if d.Path != "" {
+			endLine, endCol := c.end(d)
+			r.Locations = []sarifLocation{sarifLocationAt(d.Path, d.Line, d.Col, endLine, endCol)}
+		}
+		for i, n := range d.Notes {
+			endLine, endCol := c.span(n.Path, n.Line, n.Col)
+			loc := sarifLocationAt(n.Path, n.Line, n.Col, endLine, endCol)
+			id := i
+			loc.ID = &id
+			loc.Message = &sarifMessage{Text: n.Message}
+			r.RelatedLocations = append(r.RelatedLocations, loc)
+		}
+		run.Results = append(run.Results, r)
+	}
+
+	encode(w, sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{run},
+	})
+}
+
+func sarifLocationAt(path string, line, col, endLine, endCol int) sarifLocation {
+	return sarifLocation{
+		PhysicalLocation: sarifPhysicalLocation{
+			ArtifactLocation: sarifArtifact{URI: fileURI(path)},
+			Region: sarifRegion{
+				StartLine:   line,
+				StartColumn: col,
+				EndLine:     endLine,
+				EndColumn:   endCol,
+			},
+		},
+	}
+}
+
+// fileURI turns path into the URI form SARIF expects. Relative paths stay
+// relative so that tools resolve them against the repository root.
+func fileURI(path string) string {
+	if filepath.IsAbs(path) {
+		return "file://" + filepath.ToSlash(path)
+	}
+	return filepath.ToSlash(path)
+}
+
+func encode(w io.Writer, v any) {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	// messages quote source, e.g. '<', which must stay readable
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(v)
+}
@@ -0,0 +1,216 @@
+package errorsx
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Warning is a kind of warning. Each can be enabled, disabled or promoted
+// to an error with -W flags.
+type Warning string
+
+const (
+	UnusedVariable  Warning = "unused-variable"
+	UnusedParameter Warning = "unused-parameter"
+	UnusedImport    Warning = "unused-import"
+	Shadow          Warning = "shadow"
+	Unreachable     Warning = "unreachable"
+	UnusedResult    Warning = "unused-result"
+	InfiniteLoop    Warning = "infinite-loop"
+)
+
+type warningInfo struct {
+	code string
+	// on by default, without -W flags
+	enabled bool
+}
+
+var warnings = map[Warning]warningInfo{
+	UnusedVariable:  {code: "W0001", enabled: true},
+	UnusedParameter: {code: "W0002"},
+	UnusedImport:    {code: "W0003", enabled: true},
+	Shadow:          {code: "W0004"},
+	Unreachable:     {code: "W0005", enabled: true},
+	UnusedResult:    {code: "W0006"},
+	InfiniteLoop:    {code: "W0007", enabled: true},
+}
+
+// warningConfig is the outcome of the -W flags.
+type warningConfig struct {
+	enabled map[Warning]bool
+	// asError holds the warnings given to error= or no-error=, which take
+	// precedence over allError.
+	asError  map[Warning]bool
+	allError bool
+}
+
+func newWarningConfig() *warningConfig {
+	cfg := &warningConfig{
+		enabled: make(map[Warning]bool),
+		asError: make(map[Warning]bool),
+	}
+	for w, info := range warnings {
+		cfg.enabled[w] = info.enabled
+	}
+	return cfg
+}
+
+// SetWarningFlag applies a single -W flag, given without the -W:
+//
+//	all            enable every warning
+//	none           disable every warning
+//	NAME           enable warning NAME, e.g. unused-import
+//	no-NAME        disable warning NAME
+//	error          report every enabled warning as an error
+//	error=NAME     enable warning NAME and report it as an error
+//	no-error=NAME  report warning NAME as a warning again
+//
+// Flags apply in order, so later flags override earlier ones, except that
+// error=NAME and no-error=NAME hold whatever the position of error.
+func (c *Collector) SetWarningFlag(flag string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cfg := c.warnings
+
+	name := func(s string) (Warning, error) {
+		w := Warning(s)
+		if _, ok := warnings[w]; !ok {
+			return "", fmt.Errorf("unknown warning %q, want one of %s", s, strings.Join(WarningNames(), ", "))
+		}
+		return w, nil
+	}
+
+	switch {
+	case flag == "all" || flag == "none":
+		for w := range warnings {
+			cfg.enabled[w] = flag == "all"
+		}
+	case flag == "error":
+		cfg.allError = true
+	case strings.HasPrefix(flag, "error="):
+		w, err := name(strings.TrimPrefix(flag, "error="))
+		if err != nil {
+			return err
+		}
+		cfg.enabled[w] = true
+		cfg.asError[w] = true
+	case strings.HasPrefix(flag, "no-error="):
+		w, err := name(strings.TrimPrefix(flag, "no-error="))
+		if err != nil {
+			return err
+		}
+		cfg.asError[w] = false
+	case strings.HasPrefix(flag, "no-"):
+		w, err := name(strings.TrimPrefix(flag, "no-"))
+		if err != nil {
+			return err
+		}
+		cfg.enabled[w] = false
+	default:
+		w, err := name(flag)
+		if err != nil {
+			return err
+		}
+		cfg.enabled[w] = true
+	}
+	return nil
+}
+
+// WarningNames lists the known warnings in a stable order.
+func WarningNames() []string {
+	names := make([]string, 0, len(warnings))
+	for w := range warnings {
+		names = append(names, string(w))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Warn records d as warning w, unless w is disabled or suppressed by a
+// comment at d's location. d's severity and code are filled in from w, so
+// a promoted warning stops the build like any other error.
+func (c *Collector) Warn(w Warning, d Diagnostic) {
+	c.mu.Lock()
+	cfg := c.warnings
+	enabled := cfg.enabled[w]
+	asError, set := cfg.asError[w]
+	if !set {
+		asError = cfg.allError
+	}
+	c.mu.Unlock()
+
+	if !enabled || c.suppressed(d.Path, d.Line, w) {
+		return
+	}
+
+	d.Code = warnings[w].code
+	d.Message = fmt.Sprintf("%s [-W%s]", d.Message, w)
+	d.Severity = SeverityWarning
+	if asError {
+		d.Severity = SeverityError
+	}
+	c.Add(d)
+}
+
+// ignoreDirective starts a comment suppressing warnings, e.g.
+//
+//	say tmp: int = 0; // picasso:ignore unused-variable
+//
+// A directive applies to its own line and, on a line of its own, to the
+// line below. Without names it suppresses every warning.
+const ignoreDirective = "picasso:ignore"
+
+// Comment is a // comment as read by the lexer.
+type Comment struct {
+	Line int
+	// Text excludes the slashes.
+	Text string
+	// Alone is set when no token precedes the comment on its line.
+	Alone bool
+}
+
+// SetComments records the comments of the file at path, replacing those of
+// an earlier read, so that the directives among them suppress warnings.
+func (c *Collector) SetComments(path string, comments []Comment) {
+	directives := make(map[int]Comment)
+	for _, cm := range comments {
+		if strings.HasPrefix(strings.TrimSpace(cm.Text), ignoreDirective) {
+			directives[cm.Line] = cm
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.directives == nil {
+		c.directives = make(map[string]map[int]Comment)
+	}
+	c.directives[path] = directives
+}
+
+func (c *Collector) suppressed(path string, line int, w Warning) bool {
+	c.mu.Lock()
+	directives := c.directives[path]
+	c.mu.Unlock()
+
+	for _, l := range []int{line, line - 1} {
+		cm, ok := directives[l]
+		// a directive above only covers the next line if it stands alone
+		if !ok || (l != line && !cm.Alone) {
+			continue
+		}
+		comment := strings.TrimPrefix(strings.TrimSpace(cm.Text), ignoreDirective)
+		names := strings.FieldsFunc(comment, func(r rune) bool {
+			return r == ',' || r == ' ' || r == '\t'
+		})
+		if len(names) == 0 {
+			return true
+		}
+		for _, n := range names {
+			if Warning(n) == w {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,155 @@
+package format
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// context is the number of unchanged lines around changes in a diff.
+const context = 3
+
+// maxDiffCells bounds the table of the diff; files differing in more lines
+// are diffed as a single change.
+const maxDiffCells = 1 << 24
+
+type edit struct {
+	op   byte // ' ', '-' or '+'
+	line string
+}
+
+// Diff returns a unified diff from old to new, the content of the file at
+// path before and after formatting. It is empty if they are equal.
+func Diff(path string, old, new []byte) []byte {
+	if bytes.Equal(old, new) {
+		return nil
+	}
+	edits := diffLines(splitLines(old), splitLines(new))
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "--- %s.orig\n+++ %s\n", path, path)
+	for i := 0; i < len(edits); {
+		if edits[i].op == ' ' {
+			i++
+			continue
+		}
+		// a hunk runs until more than twice the context is unchanged
+		start := max(0, i-context)
+		end := i
+		for unchanged := 0; end < len(edits) && unchanged <= 2*context; end++ {
+			if edits[end].op == ' ' {
+				unchanged++
+			} else {
+				unchanged = 0
+			}
+		}
+		for end > i && edits[end-1].op == ' ' {
+			end--
+		}
+		end = min(len(edits), end+context)
+
+		oldLine, newLine := 1, 1
+		for _, e := range edits[:start] {
+			if e.op != '+' {
+				oldLine++
+			}
+			if e.op != '-' {
+				newLine++
+			}
+		}
+		oldCount, newCount := 0, 0
+		for _, e := range edits[start:end] {
+			if e.op != '+' {
+				oldCount++
+			}
+			if e.op != '-' {
+				newCount++
+			}
+		}
+		fmt.Fprintf(&out, "@@ -%s +%s @@\n", hunkRange(oldLine, oldCount), hunkRange(newLine, newCount))
+		for _, e := range edits[start:end] {
+			out.WriteByte(e.op)
+			out.WriteString(e.line)
+			out.WriteByte('\n')
+		}
+		i = end
+	}
+	return out.Bytes()
+}
+
+func hunkRange(line, count int) string {
+	if count == 0 {
+		// an empty range names the line before it
+		return fmt.Sprintf("%d,0", line-1)
+	}
+	if count == 1 {
+		return fmt.Sprint(line)
+	}
+	return fmt.Sprintf("%d,%d", line, count)
+}
+
+func splitLines(b []byte) []string {
+	lines := strings.Split(string(b), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffLines returns the edits turning a into b, from a longest common
+// subsequence of their lines.
+func diffLines(a, b []string) []edit {
+	var head, tail []edit
+	for len(a) > 0 && len(b) > 0 && a[0] == b[0] {
+		head = append(head, edit{' ', a[0]})
+		a, b = a[1:], b[1:]
+	}
+	for len(a) > 0 && len(b) > 0 && a[len(a)-1] == b[len(b)-1] {
+		tail = append(tail, edit{' ', a[len(a)-1]})
+		a, b = a[:len(a)-1], b[:len(b)-1]
+	}
+
+	edits := head
+	if (len(a)+1)*(len(b)+1) > maxDiffCells {
+		for _, line := range a {
+			edits = append(edits, edit{'-', line})
+		}
+		for _, line := range b {
+			edits = append(edits, edit{'+', line})
+		}
+	} else {
+		// lcs[i][j] is the length of one of a[i:] and b[j:]
+		lcs := make([][]int32, len(a)+1)
+		for i := range lcs {
+			lcs[i] = make([]int32, len(b)+1)
+		}
+		for i := len(a) - 1; i >= 0; i-- {
+			for j := len(b) - 1; j >= 0; j-- {
+				if a[i] == b[j] {
+					lcs[i][j] = lcs[i+1][j+1] + 1
+				} else {
+					lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+				}
+			}
+		}
+		i, j := 0, 0
+		for i < len(a) || j < len(b) {
+			switch {
+			case i < len(a) && j < len(b) && a[i] == b[j]:
+				edits = append(edits, edit{' ', a[i]})
+				i, j = i+1, j+1
+			case j == len(b) || i < len(a) && lcs[i+1][j] >= lcs[i][j+1]:
+				edits = append(edits, edit{'-', a[i]})
+				i++
+			default:
+				edits = append(edits, edit{'+', b[j]})
+				j++
+			}
+		}
+	}
+
+	for k := len(tail) - 1; k >= 0; k-- {
+		edits = append(edits, tail[k])
+	}
+	return edits
+}
@@ -0,0 +1,194 @@
+package format
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/nagarajRPoojari/picasso/irgen/ast"
+	"github.com/nagarajRPoojari/picasso/irgen/lexer"
+	"github.com/nagarajRPoojari/picasso/irgen/parser"
+)
+
+const (
+	assignmentPower = parser.AssignmentPower
+	listPower       = parser.ListPower
+	unaryPower      = parser.UnaryPower
+
+	// open is the spine of an expression that extends to whatever follows
+	open = parser.BindingPower(-1)
+	// closed is the spine of an expression that nothing following extends
+	closed = parser.BindingPower(math.MaxInt)
+)
+
+// expr prints e as an operand the parser parses with binding power bp,
+// in parentheses if it would stop short of an operator of e.
+func (p *printer) expr(e ast.Expression, bp parser.BindingPower) {
+	if prec, ok := precedence(e); ok && prec <= bp {
+		p.paren(e)
+		return
+	}
+	p.expr0(e)
+}
+
+// left prints e as the left operand of the operator kind, in parentheses
+// if the operator would be parsed into e.
+func (p *printer) left(e ast.Expression, kind lexer.TokenKind) {
+	if parser.Precedence(kind) > spine(e) {
+		p.paren(e)
+		return
+	}
+	p.expr0(e)
+}
+
+func (p *printer) paren(e ast.Expression) {
+	p.buf.WriteByte('(')
+	p.expr0(e)
+	p.buf.WriteByte(')')
+}
+
+func (p *printer) list(exprs []ast.Expression, bp parser.BindingPower) {
+	for i, e := range exprs {
+		if i > 0 {
+			p.buf.WriteString(", ")
+		}
+		p.expr(e, bp)
+	}
+}
+
+func (p *printer) expr0(e ast.Expression) {
+	switch e := e.(type) {
+	case ast.NumberExpression:
+		// as written, 1.0 and 1 are the same number
+		p.literal(e.SourceLoc, lexer.NUMBER, strconv.FormatFloat(e.Value, 'f', -1, 64))
+
+	case ast.StringExpression:
+		p.literal(e.SourceLoc, lexer.STRING, strconv.Quote(e.Value))
+
+	case ast.SymbolExpression:
+		p.buf.WriteString(e.Value)
+
+	case ast.NullExpression:
+		p.buf.WriteString("null")
+
+	case ast.BinaryExpression:
+		p.left(e.Left, e.Operator.Kind)
+		p.buf.WriteString(" " + e.Operator.Value + " ")
+		p.expr(e.Right, parser.Precedence(e.Operator.Kind)-1)
+
+	case ast.RangeExpression:
+		p.left(e.Lower, lexer.DOT_DOT)
+		p.buf.WriteString("..")
+		// parsed with the binding power of the range, which is lower
+		p.expr(e.Upper, parser.Precedence(lexer.DOT_DOT)-1)
+
+	case ast.AssignmentExpression:
+		op := e.Operator.Value
+		if op == "" {
+			op = "="
+		}
+		if len(e.AssignedValues) > 0 {
+			p.list(e.Assignees, assignmentPower)
+			p.buf.WriteString(" " + op + " ")
+			p.list(e.AssignedValues, assignmentPower)
+			return
+		}
+		p.left(e.Assignee, e.Operator.Kind)
+		p.buf.WriteString(" " + op + " ")
+		p.expr(e.AssignedValue, 0)
+
+	case ast.PrefixExpression:
+		p.buf.WriteString(e.Operator.Value)
+		if operand, ok := e.Operand.(ast.PrefixExpression); e.Operator.Kind == lexer.TYPEOF ||
+			ok && operand.Operator.Value == e.Operator.Value {
+			// - -x, not --x
+			p.buf.WriteByte(' ')
+		}
+		p.expr(e.Operand, unaryPower)
+
+	case ast.MemberExpression:
+		p.left(e.Member, lexer.DOT)
+		p.buf.WriteString("." + e.Property)
+
+	case ast.ComputedExpression:
+		p.left(e.Member, lexer.OPEN_BRACKET)
+		p.buf.WriteByte('[')
+		p.list(e.Indices, assignmentPower)
+		p.buf.WriteByte(']')
+
+	case ast.CallExpression:
+		p.left(e.Method, lexer.OPEN_PAREN)
+		p.buf.WriteByte('(')
+		p.list(e.Arguments, assignmentPower)
+		p.buf.WriteByte(')')
+
+	case ast.NewExpression:
+		p.buf.WriteString("new ")
+		p.expr0(e.Instantiation)
+
+	case ast.ListExpression:
+		p.buf.WriteByte('[')
+		p.list(e.Constants, listPower)
+		p.buf.WriteByte(']')
+
+	case ast.FunctionExpression:
+		p.buf.WriteString("fn")
+		p.signature(e.Parameters, e.ReturnType)
+		p.buf.WriteByte(' ')
+		open := p.bodyOpen(p.tokenAt(e.SourceLoc))
+		p.block(open, p.closing(open), e.Body)
+	}
+}
+
+// literal prints the token of kind at loc as written, or s if there is none.
+func (p *printer) literal(loc ast.SourceLoc, kind lexer.TokenKind, s string) {
+	if i, ok := p.at[lexer.SourceLoc(loc)]; ok && p.tokens[i].Kind == kind {
+		s = p.tokens[i].Value
+	}
+	p.buf.WriteString(s)
+}
+
+// precedence returns the binding power of the operator of e for the
+// expressions the parser builds from an operand followed by an operator.
+func precedence(e ast.Expression) (parser.BindingPower, bool) {
+	switch e := e.(type) {
+	case ast.BinaryExpression:
+		return parser.Precedence(e.Operator.Kind), true
+	case ast.RangeExpression:
+		return parser.Precedence(lexer.DOT_DOT), true
+	case ast.AssignmentExpression:
+		if e.Operator.Kind == lexer.EOF {
+			return parser.Precedence(lexer.ASSIGNMENT), true
+		}
+		return parser.Precedence(e.Operator.Kind), true
+	case ast.MemberExpression:
+		return parser.Precedence(lexer.DOT), true
+	case ast.ComputedExpression:
+		return parser.Precedence(lexer.OPEN_BRACKET), true
+	case ast.CallExpression:
+		return parser.Precedence(lexer.OPEN_PAREN), true
+	}
+	return 0, false
+}
+
+// spine returns the lowest binding power an operand at the end of e is
+// parsed with, as printed: an operator following e with a higher one would
+// be parsed into e.
+func spine(e ast.Expression) parser.BindingPower {
+	switch e := e.(type) {
+	case ast.BinaryExpression:
+		bp := parser.Precedence(e.Operator.Kind) - 1
+		if prec, ok := precedence(e.Right); ok && prec <= bp {
+			return bp
+		}
+		return min(bp, spine(e.Right))
+	case ast.PrefixExpression:
+		if prec, ok := precedence(e.Operand); ok && prec <= unaryPower {
+			return unaryPower
+		}
+		return min(unaryPower, spine(e.Operand))
+	case ast.RangeExpression, ast.AssignmentExpression, ast.NewExpression:
+		// their last operand is parsed with the binding power they are
+		return open
+	}
+	return closed
+}
@@ -0,0 +1,47 @@
+// Package format prints Picasso source in its canonical style, see irgen fmt:
+// four space indentation, one statement per line, a space around binary
+// operators, opening braces on the line of their statement, no parentheses
+// the parser does not need, imports sorted and at most one blank line
+// between statements.
+//
+// Source is printed from its AST, comments from the trivia the lexer keeps
+// with the tokens. A comment stays before the statement it was above, or at
+// the end of the line it ended.
+package format
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	errorsx "github.com/nagarajRPoojari/picasso/irgen/error"
+	"github.com/nagarajRPoojari/picasso/irgen/lexer"
+	"github.com/nagarajRPoojari/picasso/irgen/parser"
+)
+
+// ErrSyntax is returned by Source for source that does not parse; the
+// syntax errors are recorded in the collector given.
+var ErrSyntax = errors.New("syntax errors")
+
+// Source formats src, the content of the file at path. path only names the
+// file in errors, which are recorded in diags.
+func Source(diags *errorsx.Collector, path string, src []byte) ([]byte, error) {
+	errs := diags.Errors()
+	tokens := lexer.TokenizeReader(diags, path, bytes.NewReader(src))
+	tree := parser.ParseTokens(diags, path, tokens)
+	if diags.Errors() > errs {
+		return nil, fmt.Errorf("%s: %w", path, ErrSyntax)
+	}
+
+	p := newPrinter(tokens)
+	p.stmts(tree.Body, -1, len(tokens)-1)
+	if p.buf.Len() > 0 {
+		p.buf.WriteByte('\n')
+	}
+
+	// every comment is printed once; losing one is a bug, not a style
+	if p.printed != p.comments {
+		return nil, fmt.Errorf("%s: printed %d of %d comments", path, p.printed, p.comments)
+	}
+	return p.align(), nil
+}
@@ -0,0 +1,567 @@
+package format
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/nagarajRPoojari/picasso/irgen/ast"
+	"github.com/nagarajRPoojari/picasso/irgen/lexer"
+)
+
+const indentation = "    "
+
+// printer prints statements and expressions into buf. The AST does not say
+// where statements end or what they contained besides the nodes, so the
+// printer looks at the tokens they were parsed from, found by position.
+type printer struct {
+	buf    bytes.Buffer
+	indent int
+
+	tokens []lexer.Token
+	at     map[lexer.SourceLoc]int
+
+	// comments in tokens and comments printed so far
+	comments, printed int
+	// offsets in buf of the comments ending lines with code
+	trailers []int
+}
+
+func newPrinter(tokens []lexer.Token) *printer {
+	p := &printer{tokens: tokens, at: make(map[lexer.SourceLoc]int, len(tokens))}
+	for i, tk := range tokens {
+		p.at[tk.Src] = i
+		p.comments += len(tk.Comments)
+	}
+	return p
+}
+
+// item is a statement of a list with the comments printed before it.
+type item struct {
+	stmt ast.Statement
+	// indices of its first and last token
+	start, end int
+
+	// comments on the lines above it; blank if a blank line precedes them,
+	// or it
+	leading []lexer.Comment
+	blank   bool
+	// comments separated from it by a blank line, which do not move with it
+	// when imports are sorted
+	detached []lexer.Comment
+	// comments between its tokens, see inner
+	inner []lexer.Comment
+}
+
+// stmts prints list, the statements between the tokens at open and close.
+// At the top level open is -1 and close is EOF.
+func (p *printer) stmts(list []ast.Statement, open, close int) {
+	items := make([]item, len(list))
+	for i, s := range list {
+		it := item{stmt: s, start: p.tokenAt(s.GetSrc())}
+		it.end = p.end(s, it.start)
+		it.leading = p.before(it.start)
+		it.inner = p.inner(s, it.start, it.end)
+
+		first := p.tokens[it.start].Src.Line
+		if len(it.leading) > 0 {
+			first = it.leading[0].Src.Line
+		}
+		it.blank = i > 0 && first > p.tokens[items[i-1].end].Src.Line+1
+		items[i] = it
+	}
+	if open < 0 {
+		sortImports(items)
+	}
+
+	for _, it := range items {
+		blank := it.blank
+		if len(it.detached) > 0 {
+			p.leading(it.detached, blank, 0)
+			blank = true
+		}
+		blank = p.leading(it.leading, blank, p.tokens[it.start].Src.Line)
+		for _, c := range it.inner {
+			p.line(blank)
+			p.comment(c)
+			blank = false
+		}
+		p.line(blank)
+		p.stmt(it.stmt, it.start)
+		p.trailing(it.end)
+	}
+
+	// comments below the last statement
+	if rest := p.before(close); len(rest) > 0 {
+		blank := false
+		if len(items) > 0 && close > 0 {
+			blank = rest[0].Src.Line > p.tokens[close-1].Src.Line+1
+		}
+		p.leading(rest, blank, rest[len(rest)-1].Src.Line)
+	}
+}
+
+// sortImports sorts runs of imports not separated by a blank line by name.
+// Comments move with their import.
+func sortImports(items []item) {
+	for i := 0; i < len(items); {
+		j := i
+		for j < len(items) && isImport(items[j].stmt) && (j == i || !items[j].blank) {
+			j++
+		}
+		if j-i > 1 {
+			blank := items[i].blank
+			// e.g. a comment on the file, above the imports
+			var detached []lexer.Comment
+			leading := items[i].leading
+			for k := len(leading) - 1; k >= 0; k-- {
+				next := items[i].stmt.GetSrc().Line
+				if k+1 < len(leading) {
+					next = leading[k+1].Src.Line
+				}
+				if next > leading[k].Src.Line+1 {
+					detached, items[i].leading = leading[:k+1], leading[k+1:]
+					break
+				}
+			}
+			slices.SortStableFunc(items[i:j], func(a, b item) int {
+				return strings.Compare(a.stmt.(ast.ImportStatement).Name, b.stmt.(ast.ImportStatement).Name)
+			})
+			for k := i; k < j; k++ {
+				items[k].blank = false
+			}
+			items[i].blank = blank
+			items[i].detached = detached
+		}
+		i = max(j, i+1)
+	}
+}
+
+func isImport(s ast.Statement) bool {
+	_, ok := s.(ast.ImportStatement)
+	return ok
+}
+
+// leading prints comments on lines of their own, keeping blank lines
+// between them. It returns whether a blank line separates the last one from
+// what is printed on line next.
+func (p *printer) leading(comments []lexer.Comment, blank bool, next int) bool {
+	for _, c := range comments {
+		p.line(blank)
+		p.comment(c)
+		blank = next > c.Src.Line+1
+	}
+	return blank
+}
+
+// trailing prints the comment ending the line of the token at i, if any.
+func (p *printer) trailing(i int) {
+	if c, ok := p.after(i); ok {
+		p.buf.WriteByte(' ')
+		p.trailers = append(p.trailers, p.buf.Len())
+		p.comment(c)
+	}
+}
+
+// align lines up the comments ending consecutive lines of the same
+// indentation, e.g.
+//
+//	t.first();  // one
+//	t.second(); // two
+func (p *printer) align() []byte {
+	src := p.buf.Bytes()
+	code := func(at int) []byte {
+		start := bytes.LastIndexByte(src[:at], '\n') + 1
+		return bytes.TrimRight(src[start:at], " ")
+	}
+	indent := func(line []byte) int {
+		return len(line) - len(bytes.TrimLeft(line, " "))
+	}
+
+	var out bytes.Buffer
+	last := 0
+	for i := 0; i < len(p.trailers); {
+		j, width := i, 0
+		for ; j < len(p.trailers); j++ {
+			if j > i && (bytes.Count(src[p.trailers[j-1]:p.trailers[j]], []byte{'\n'}) != 1 ||
+				indent(code(p.trailers[j])) != indent(code(p.trailers[i]))) {
+				break
+			}
+			width = max(width, utf8.RuneCount(code(p.trailers[j])))
+		}
+		for k := i; k < j; k++ {
+			line := code(p.trailers[k])
+			start := bytes.LastIndexByte(src[:p.trailers[k]], '\n') + 1
+			out.Write(src[last:start])
+			out.Write(line)
+			out.WriteString(strings.Repeat(" ", width-utf8.RuneCount(line)+1))
+			last = p.trailers[k]
+		}
+		i = j
+	}
+	out.Write(src[last:])
+	return out.Bytes()
+}
+
+func (p *printer) comment(c lexer.Comment) {
+	p.buf.WriteString(strings.TrimRight(c.Text, " \t\r"))
+	p.printed++
+}
+
+// line starts a new line, after an empty one if blank.
+func (p *printer) line(blank bool) {
+	if p.buf.Len() > 0 {
+		if blank {
+			p.buf.WriteByte('\n')
+		}
+		p.buf.WriteByte('\n')
+	}
+	p.buf.WriteString(strings.Repeat(indentation, p.indent))
+}
+
+// after returns the comment on the line of the token at i following it. The
+// lexer attaches it to the next token.
+func (p *printer) after(i int) (lexer.Comment, bool) {
+	if i < 0 || i+1 >= len(p.tokens) {
+		return lexer.Comment{}, false
+	}
+	for _, c := range p.tokens[i+1].Comments {
+		if c.Src.Line == p.tokens[i].Src.Line {
+			return c, true
+		}
+	}
+	return lexer.Comment{}, false
+}
+
+// before returns the comments on the lines above the token at i, i.e. its
+// comments less the one after.
+func (p *printer) before(i int) []lexer.Comment {
+	var comments []lexer.Comment
+	for _, c := range p.tokens[i].Comments {
+		if i > 0 && c.Src.Line == p.tokens[i-1].Src.Line {
+			continue
+		}
+		comments = append(comments, c)
+	}
+	return comments
+}
+
+// inner returns the comments between the first and the last token of s
+// that are not printed in one of its bodies. They are printed above it.
+func (p *printer) inner(s ast.Statement, start, end int) []lexer.Comment {
+	var comments []lexer.Comment
+	between := func(from, to int) {
+		for i := from; i <= to; i++ {
+			comments = append(comments, p.tokens[i].Comments...)
+		}
+	}
+
+	switch s := s.(type) {
+	case ast.BlockStatement, ast.AtomicBlockStatement:
+		return nil
+
+	case ast.IfStatement:
+		// the conditions and elses of the chain
+		for {
+			open := p.tokenAt(s.Consequent.GetSrc())
+			between(start+1, open)
+			if s.Alternate == nil {
+				return comments
+			}
+			start = p.closing(open)
+			next, ok := s.Alternate.(ast.IfStatement)
+			if !ok {
+				between(start+1, p.tokenAt(s.Alternate.GetSrc()))
+				return comments
+			}
+			between(start+1, p.tokenAt(next.GetSrc()))
+			s, start = next, p.tokenAt(next.GetSrc())
+		}
+
+	case ast.FunctionDefinitionStatement, ast.ClassDeclarationStatement,
+		ast.InterfaceDeclarationStatement, ast.WhileStatement, ast.ForeachStatement:
+		between(start+1, p.bodyOpen(start))
+		return comments
+	}
+
+	// function literals print their bodies
+	depth := 0
+	for i := start + 1; i <= end; i++ {
+		if depth == 0 {
+			comments = append(comments, p.tokens[i].Comments...)
+		}
+		switch p.tokens[i].Kind {
+		case lexer.OPEN_CURLY:
+			depth++
+		case lexer.CLOSE_CURLY:
+			depth--
+		}
+	}
+	return comments
+}
+
+// end returns the index of the last token of s, which starts at start.
+func (p *printer) end(s ast.Statement, start int) int {
+	switch s := s.(type) {
+	case ast.IfStatement:
+		if s.Alternate != nil {
+			return p.end(s.Alternate, p.tokenAt(s.Alternate.GetSrc()))
+		}
+		return p.closing(p.tokenAt(s.Consequent.GetSrc()))
+
+	case ast.BlockStatement, ast.AtomicBlockStatement:
+		return p.closing(start)
+
+	case ast.FunctionDefinitionStatement, ast.ClassDeclarationStatement,
+		ast.InterfaceDeclarationStatement, ast.WhileStatement, ast.ForeachStatement:
+		return p.closing(p.bodyOpen(start))
+	}
+
+	// the ';' ending it, function literals may contain others
+	depth := 0
+	for i := start; i < len(p.tokens); i++ {
+		switch p.tokens[i].Kind {
+		case lexer.OPEN_CURLY, lexer.OPEN_PAREN, lexer.OPEN_BRACKET, lexer.OPEN_ATOMIC:
+			depth++
+		case lexer.CLOSE_CURLY, lexer.CLOSE_PAREN, lexer.CLOSE_BRACKET, lexer.CLOSE_ATOMIC:
+			depth--
+		case lexer.SEMI_COLON:
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(p.tokens) - 1
+}
+
+// bodyOpen returns the index of the '{' opening the body of the statement
+// or function literal starting at start.
+func (p *printer) bodyOpen(start int) int {
+	for i := start; i < len(p.tokens); i++ {
+		if p.tokens[i].Kind == lexer.OPEN_CURLY {
+			return i
+		}
+	}
+	return len(p.tokens) - 1
+}
+
+// closing returns the index of the token closing the one at open.
+func (p *printer) closing(open int) int {
+	depth := 0
+	for i := open; i < len(p.tokens); i++ {
+		switch p.tokens[i].Kind {
+		case lexer.OPEN_CURLY, lexer.OPEN_PAREN, lexer.OPEN_BRACKET, lexer.OPEN_ATOMIC:
+			depth++
+		case lexer.CLOSE_CURLY, lexer.CLOSE_PAREN, lexer.CLOSE_BRACKET, lexer.CLOSE_ATOMIC:
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(p.tokens) - 1
+}
+
+// tokenAt returns the index of the token at loc. Nodes are located at a
+// token of theirs, mostly the first.
+func (p *printer) tokenAt(loc ast.SourceLoc) int {
+	i, ok := p.at[lexer.SourceLoc(loc)]
+	if !ok {
+		panic(fmt.Sprintf("format: no token at %s:%d:%d", loc.FilePath, loc.Line, loc.Col))
+	}
+	return i
+}
+
+// block prints body between the tokens at open and close, i.e. { } or (* *).
+func (p *printer) block(open, close int, body []ast.Statement) {
+	p.buf.WriteString(p.tokens[open].Value)
+	_, trailing := p.after(open)
+	if len(body) == 0 && !trailing && len(p.before(close)) == 0 {
+		p.buf.WriteString(p.tokens[close].Value)
+		return
+	}
+
+	p.trailing(open)
+	p.indent++
+	p.stmts(body, open, close)
+	p.indent--
+	p.line(false)
+	p.buf.WriteString(p.tokens[close].Value)
+}
+
+// stmt prints s, which starts at the token at start.
+func (p *printer) stmt(s ast.Statement, start int) {
+	switch s := s.(type) {
+	case ast.ImportStatement:
+		p.buf.WriteString(`using "` + strings.ReplaceAll(s.Name, ".", "/") + `"`)
+		if s.Alias != s.EndName() {
+			p.buf.WriteString(" as " + s.Alias)
+		}
+		p.buf.WriteByte(';')
+
+	case ast.VariableDeclarationStatement:
+		p.buf.WriteString("say ")
+		if s.IsInternal {
+			p.buf.WriteString("internal ")
+		}
+		if s.IsStatic {
+			p.buf.WriteString("static ")
+		}
+		names, types, values := s.Identifiers, s.ExplicitTypes, s.AssignedValues
+		if len(names) == 0 {
+			names, types = []string{s.Identifier}, []ast.Type{s.ExplicitType}
+			values = nil
+			if s.AssignedValue != nil {
+				values = []ast.Expression{s.AssignedValue}
+			}
+		}
+		for i, name := range names {
+			if i > 0 {
+				p.buf.WriteString(", ")
+			}
+			p.buf.WriteString(name)
+			if i < len(types) && types[i] != nil {
+				p.buf.WriteString(": " + TypeString(types[i]))
+			}
+		}
+		if len(values) > 0 {
+			p.buf.WriteString(" = ")
+			p.list(values, assignmentPower)
+		}
+		p.buf.WriteByte(';')
+
+	case ast.ExpressionStatement:
+		p.expr(s.Expression, 0)
+		p.buf.WriteByte(';')
+
+	case ast.FunctionDefinitionStatement:
+		if s.IsTest {
+			p.buf.WriteString("test ")
+		}
+		p.buf.WriteString("fn ")
+		if s.IsInternal {
+			p.buf.WriteString("internal ")
+		}
+		if s.IsStatic {
+			p.buf.WriteString("static ")
+		}
+		p.buf.WriteString(s.Name)
+		p.signature(s.Parameters, s.ReturnType)
+		p.buf.WriteByte(' ')
+		open := p.bodyOpen(start)
+		p.block(open, p.closing(open), s.Body)
+
+	case ast.ClassDeclarationStatement:
+		p.buf.WriteString("class ")
+		if s.IsInternal {
+			p.buf.WriteString("internal ")
+		}
+		p.buf.WriteString(s.Name)
+		if s.Implements != "" {
+			p.buf.WriteString(": " + s.Implements)
+		}
+		p.buf.WriteByte(' ')
+		open := p.bodyOpen(start)
+		p.block(open, p.closing(open), s.Body)
+
+	case ast.InterfaceDeclarationStatement:
+		p.buf.WriteString("interface " + s.Name + " ")
+		open := p.bodyOpen(start)
+		p.block(open, p.closing(open), s.Body)
+
+	case ast.IfStatement:
+		p.buf.WriteString("if ")
+		p.expr(s.Condition, assignmentPower)
+		p.buf.WriteByte(' ')
+		p.stmt(s.Consequent, p.tokenAt(s.Consequent.GetSrc()))
+		if s.Alternate != nil {
+			p.buf.WriteString(" else ")
+			p.stmt(s.Alternate, p.tokenAt(s.Alternate.GetSrc()))
+		}
+
+	case ast.WhileStatement:
+		p.buf.WriteString("while ")
+		p.expr(s.Condition, assignmentPower)
+		p.buf.WriteByte(' ')
+		open := p.bodyOpen(start)
+		p.block(open, p.closing(open), s.Body)
+
+	case ast.ForeachStatement:
+		p.buf.WriteString("foreach " + s.Value)
+		if s.Index {
+			p.buf.WriteString(", " + s.IndexName)
+		}
+		p.buf.WriteString(" in ")
+		p.expr(s.Iterable, 0)
+		p.buf.WriteByte(' ')
+		open := p.bodyOpen(start)
+		p.block(open, p.closing(open), s.Body)
+
+	case ast.ReturnStatement:
+		switch {
+		case s.IsVoid:
+			p.buf.WriteString("return;")
+		case len(s.Values) > 0:
+			p.buf.WriteString("return ")
+			p.list(s.Values, assignmentPower)
+			p.buf.WriteByte(';')
+		case s.Value.Expression != nil:
+			p.buf.WriteString("return ")
+			p.expr(s.Value.Expression, assignmentPower)
+			p.buf.WriteByte(';')
+		default:
+			p.buf.WriteString("return null;")
+		}
+
+	case ast.BreakStatement:
+		p.buf.WriteString("break;")
+
+	case ast.BlockStatement:
+		p.block(start, p.closing(start), s.Body)
+
+	case ast.AtomicBlockStatement:
+		p.block(start, p.closing(start), s.Body)
+
+	default:
+		// the parser does not produce others
+		panic(fmt.Sprintf("format: unexpected %T", s))
+	}
+}
+
+func (p *printer) signature(params []ast.Parameter, ret ast.Type) {
+	p.buf.WriteByte('(')
+	for i, param := range params {
+		if i > 0 {
+			p.buf.WriteString(", ")
+		}
+		p.buf.WriteString(param.Name + ": " + TypeString(param.Type))
+	}
+	p.buf.WriteByte(')')
+	if ret != nil {
+		p.buf.WriteString(": " + TypeString(ret))
+	}
+}
+
+// TypeString formats type t as it is written in canonical source, e.g.
+// []start.Point.
+func TypeString(t ast.Type) string {
+	var s string
+	switch t := t.(type) {
+	case *ast.SymbolType:
+		s = t.Value
+	case *ast.ListType:
+		s = "[]" + TypeString(t.Underlying)
+	case *ast.TupleType:
+		elems := make([]string, len(t.Types))
+		for i, elem := range t.Types {
+			elems[i] = TypeString(elem)
+		}
+		s = "(" + strings.Join(elems, ", ") + ")"
+	}
+	if t.IsAtomic() {
+		return "atomic " + s
+	}
+	return s
+}
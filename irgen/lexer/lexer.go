@@ -2,6 +2,7 @@ package lexer
 
 import (
 	"bufio"
+	"io"
 	"os"
 	"regexp"
 
@@ -19,6 +20,11 @@ type lexer struct {
 	patterns []RegexPattern
 	Tokens   []Token
 
+	// comments read since the last token, attached to the next one
+	comments []Comment
+	// every comment of the file, handed to the collector at the end
+	lineComments []errorsx.Comment
+
 	reader   *bufio.Reader
 	buffer   []byte
 	eof      bool
@@ -30,15 +36,24 @@ type lexer struct {
 
 type regexHandler func(lex *lexer, regex *regexp.Regexp)
 
-func Tokenize(path string) []Token {
+// Tokenize tokenizes the file at path, recording errors in diags.
+func Tokenize(diags *errorsx.Collector, path string) []Token {
 	f, err := os.Open(path)
 	if err != nil {
 		panic(err)
 	}
 	defer f.Close()
 
-	lex := newLexer(path, bufio.NewReader(f))
+	return TokenizeReader(diags, path, f)
+}
+
+// TokenizeReader tokenizes the source read from r. path only names the file
+// in token locations and diagnostics.
+func TokenizeReader(diags *errorsx.Collector, path string, r io.Reader) []Token {
+	lex := newLexer(path, bufio.NewReader(r))
 
+	// a run of unrecognized bytes is reported once, at its first byte
+	skipping := false
 	for {
 		if lex.eof && len(lex.buffer) == 0 {
 			break
@@ -50,22 +65,38 @@ func Tokenize(path string) []Token {
 		for _, pattern := range lex.patterns {
 			loc := pattern.regex.FindIndex(lex.buffer)
 			if loc != nil && loc[0] == 0 {
+				n := len(lex.Tokens)
 				pattern.handler(lex, pattern.regex)
+				if len(lex.Tokens) > n {
+					lex.attachComments(n)
+				}
 				matched = true
 				break
 			}
 		}
 
 		if !matched {
-			errorsx.PanicLexerError(
-				"lexer error: unrecognized token",
-				lex.filePath,
-				lex.line,
-				lex.col,
-			)
+			if !skipping {
+				diags.Add(errorsx.Diagnostic{
+					Phase:   errorsx.PhaseLexer,
+					Code:    errorsx.CodeLexer,
+					Message: "lexer error: unrecognized token",
+					Path:    lex.filePath,
+					Line:    lex.line,
+					Col:     lex.col,
+				})
+			}
+			skipping = true
+			lex.advance(1)
+			continue
 		}
+		skipping = false
 	}
 
+	// the parser stops at EOF instead of running off the end of the tokens
+	lex.Tokens = append(lex.Tokens, newUniqueToken(EOF, "", lex.srcLoc()))
+	lex.attachComments(len(lex.Tokens) - 1)
+	diags.SetComments(path, lex.lineComments)
 	return lex.Tokens
 }
 
@@ -225,11 +256,32 @@ func skipHandler(lex *lexer, regex *regexp.Regexp) {
 	lex.advance(loc[1])
 }
 
+// commentHandler keeps comments as trivia of the token following them, see
+// Token.Comments. The parser skips them; tools printing source use them.
 func commentHandler(lex *lexer, regex *regexp.Regexp) {
 	loc := regex.FindIndex(lex.buffer)
+	text := string(lex.buffer[:loc[1]])
+	lex.comments = append(lex.comments, Comment{
+		Text: text,
+		Src:  lex.srcLoc(),
+	})
+	n := len(lex.Tokens)
+	lex.lineComments = append(lex.lineComments, errorsx.Comment{
+		Line:  lex.line,
+		Text:  text[len("//"):],
+		Alone: n == 0 || lex.Tokens[n-1].Src.Line != lex.line,
+	})
 	lex.advance(loc[1])
 }
 
+// attachComments attaches the pending comments to the token at i.
+func (l *lexer) attachComments(i int) {
+	if len(l.comments) > 0 {
+		l.Tokens[i].Comments = l.comments
+		l.comments = nil
+	}
+}
+
 func preview(buf []byte) string {
 	if len(buf) > 20 {
 		return string(buf[:20]) + "..."
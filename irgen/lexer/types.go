@@ -135,6 +135,16 @@ type Token struct {
 	Kind  TokenKind
 	Value string
 	Src   SourceLoc
+
+	// Comments are the comments between the previous token and this one,
+	// in source order. Comments at the end of a file belong to EOF.
+	Comments []Comment
+}
+
+// Comment is a // comment, Text including the slashes.
+type Comment struct {
+	Text string
+	Src  SourceLoc
 }
 
 func (t Token) IsOneOfMany(expectedTokens ...TokenKind) bool {
@@ -146,12 +156,18 @@ func (t Token) IsOneOfMany(expectedTokens ...TokenKind) bool {
 	return false
 }
 
-func (token Token) Debug() {
+// String returns the kind of the token and, for identifiers and literals,
+// its value, e.g. identifier(x) or plus().
+func (token Token) String() string {
 	if token.Kind == IDENTIFIER || token.Kind == NUMBER || token.Kind == STRING {
-		fmt.Printf("%s(%s)\n", TokenKindString(token.Kind), token.Value)
-	} else {
-		fmt.Printf("%s()\n", TokenKindString(token.Kind))
+		return fmt.Sprintf("%s(%s)", TokenKindString(token.Kind), token.Value)
 	}
+	return fmt.Sprintf("%s()", TokenKindString(token.Kind))
+}
+
+// Debug prints the token, with its position, to stdout.
+func (token Token) Debug() {
+	fmt.Printf("%s:%d:%d\t%s\n", token.Src.FilePath, token.Src.Line, token.Src.Col, token)
 }
 
 func TokenKindString(kind TokenKind) string {
@@ -297,6 +313,37 @@ func TokenKindString(kind TokenKind) string {
 	}
 }
 
+// symbols spells the punctuation kinds as written in source.
+var symbols = map[TokenKind]string{
+	OPEN_BRACKET: "[", CLOSE_BRACKET: "]",
+	OPEN_CURLY: "{", CLOSE_CURLY: "}",
+	OPEN_PAREN: "(", CLOSE_PAREN: ")",
+	OPEN_ATOMIC: "(*", CLOSE_ATOMIC: "*)",
+	ASSIGNMENT: "=", EQUALS: "==", NOT_EQUALS: "!=", NOT: "!",
+	LESS: "<", LESS_EQUALS: "<=", GREATER: ">", GREATER_EQUALS: ">=",
+	OR: "||", AND: "&&",
+	BITWIZE_LEFTSHIFT: "<<", BITWIZE_RIGHTSHIFT: ">>",
+	BITWISE_OR: "|", BITWISE_XOR: "^", BITWISE_NOT: "~", BITWISE_AND: "&",
+	DOT: ".", DOT_DOT: "..", SEMI_COLON: ";", COLON: ":", QUESTION: "?", COMMA: ",",
+	PLUS_PLUS: "++", MINUS_MINUS: "--", PLUS_EQUALS: "+=", MINUS_EQUALS: "-=",
+	PLUS: "+", DASH: "-", SLASH: "/", STAR: "*", PERCENT: "%",
+}
+
+// TokenKindSymbol returns how kind is written in source, e.g. ";" for
+// SEMI_COLON. Kinds without a fixed spelling, like identifiers, are named
+// by TokenKindString.
+func TokenKindSymbol(kind TokenKind) string {
+	if s, ok := symbols[kind]; ok {
+		return s
+	}
+	for word, k := range reserved_keywords {
+		if k == kind {
+			return word
+		}
+	}
+	return TokenKindString(kind)
+}
+
 func newUniqueToken(kind TokenKind, value string, src SourceLoc) Token {
 	return Token{
 		Kind:  kind,
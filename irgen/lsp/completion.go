@@ -0,0 +1,141 @@
+package lsp
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/nagarajRPoojari/picasso/irgen/ast"
+	"github.com/nagarajRPoojari/picasso/irgen/codegen/handlers/constants"
+	errorsx "github.com/nagarajRPoojari/picasso/irgen/error"
+	"github.com/nagarajRPoojari/picasso/irgen/lexer"
+)
+
+// placeholder completes the member being typed so that the document
+// parses; its receiver is then typed like any other expression.
+const placeholder = "__complete__"
+
+// complete returns the completions of the member being typed at pos, after
+// a dot: the fields and methods of a class or interface, the types of an
+// imported package or the functions of a builtin module.
+func (s *Server) complete(uri string, pos Position) []CompletionItem {
+	name, ok := s.nameOf(uri)
+	if !ok {
+		return nil
+	}
+	text, err := s.w.read(name)
+	if err != nil {
+		return nil
+	}
+	offset := textOffset(text, pos)
+	start, end := offset, offset
+	for start > 0 && isWordByte(text[start-1]) {
+		start--
+	}
+	for end < len(text) && isWordByte(text[end]) {
+		end++
+	}
+	if start == 0 || text[start-1] != '.' {
+		return nil
+	}
+
+	// the statement may be unfinished too
+	for _, suffix := range []string{"", ";"} {
+		w := *s.w
+		w.docs = make(map[string]string, len(s.w.docs))
+		for k, v := range s.w.docs {
+			w.docs[k] = v
+		}
+		w.docs[name] = text[:start] + placeholder + suffix + text[end:]
+
+		snap := w.check()
+		if snap.info == nil {
+			return nil
+		}
+		q := &query{w: &w, snap: snap, info: snap.info, name: name, pkg: w.packageName(name), text: w.docs[name]}
+		q.tree = snap.info.Packages[q.pkg]
+		if recv := q.receiver(); recv != nil {
+			return sorted(q.completions(recv))
+		}
+
+		// not an expression, e.g. the type of a declaration, but possibly
+		// a type of an imported package
+		alias := start - 1
+		for alias > 0 && isWordByte(text[alias-1]) {
+			alias--
+		}
+		if st, ok := q.imports()[text[alias:start-1]]; ok && (alias == 0 || text[alias-1] != '.') {
+			if t := q.importTarget(st); t.lib {
+				return sorted(moduleFuncs(t.pkg))
+			}
+			return sorted(q.packageTypes(st.Name))
+		}
+	}
+	return nil
+}
+
+func sorted(items []CompletionItem) []CompletionItem {
+	sort.Slice(items, func(i, j int) bool { return items[i].Label < items[j].Label })
+	return items
+}
+
+// receiver returns the expression the placeholder is a member of.
+func (q *query) receiver() ast.Expression {
+	var recv ast.Expression
+	ast.Inspect(q.tree, func(n ast.Node) bool {
+		if m, ok := n.(ast.MemberExpression); ok && m.Property == placeholder {
+			recv = m.Member
+		}
+		return recv == nil
+	})
+	return recv
+}
+
+func (q *query) completions(recv ast.Expression) []CompletionItem {
+	if sym, ok := recv.(ast.SymbolExpression); ok && q.info.SymbolOf(recv) == nil {
+		if st, ok := q.imports()[sym.Value]; ok {
+			if t := q.importTarget(st); t.lib {
+				return moduleFuncs(t.pkg)
+			}
+			return q.packageTypes(st.Name)
+		}
+		// static members, e.g. Math.max
+		if fq := q.typeName(sym.Value); fq != "" {
+			return q.members(fq, false)
+		}
+	}
+
+	tp := q.info.TypeOf(recv)
+	if tp == nil {
+		return nil
+	}
+	if _, ok := tp.(*ast.SymbolType); !ok {
+		return nil
+	}
+	return q.members(tp.Get(), isThis(recv))
+}
+
+// isThis reports whether e is this, through which internal members are
+// accessible.
+func isThis(e ast.Expression) bool {
+	sym, ok := e.(ast.SymbolExpression)
+	return ok && sym.Value == constants.THIS
+}
+
+// textOffset returns the byte offset of pos in text, clamped to its line.
+func textOffset(text string, pos Position) int {
+	offset := 0
+	for i := 0; i < pos.Line; i++ {
+		nl := strings.IndexByte(text[offset:], '\n')
+		if nl == -1 {
+			return len(text)
+		}
+		offset += nl + 1
+	}
+	return offset + byteOffset(lineOf(text[offset:], 1), pos.Character)
+}
+
+// tokenize tokenizes text, discarding lexer errors, which checking the
+// workspace reports.
+func tokenize(name, text string) []lexer.Token {
+	return lexer.TokenizeReader(errorsx.NewCollector(), name, strings.NewReader(text))
+}
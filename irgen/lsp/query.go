@@ -0,0 +1,423 @@
+package lsp
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/nagarajRPoojari/picasso/irgen/ast"
+	"github.com/nagarajRPoojari/picasso/irgen/codegen/libs"
+	"github.com/nagarajRPoojari/picasso/irgen/lexer"
+	"github.com/nagarajRPoojari/picasso/irgen/sema"
+)
+
+// query answers requests about a position in an open document, from the
+// snapshot of the last check.
+type query struct {
+	w    *workspace
+	snap *snapshot
+	info *sema.Info
+
+	name   string // of the file
+	pkg    string
+	text   string
+	tree   ast.BlockStatement
+	tokens []lexer.Token
+	// at is the index in tokens of the token at the position, -1 if none.
+	at int
+}
+
+func (s *Server) query(uri string, pos Position) (*query, bool) {
+	name, ok := s.nameOf(uri)
+	if !ok || s.snap == nil || s.snap.info == nil {
+		return nil, false
+	}
+	text, err := s.w.read(name)
+	if err != nil {
+		return nil, false
+	}
+	q := &query{
+		w:      s.w,
+		snap:   s.snap,
+		info:   s.snap.info,
+		name:   name,
+		pkg:    s.w.packageName(name),
+		text:   text,
+		tree:   s.snap.info.Packages[s.w.packageName(name)],
+		tokens: tokenize(name, text),
+	}
+	q.at = q.tokenAt(pos)
+	return q, true
+}
+
+// tokenAt returns the index of the token at pos, or of the identifier it
+// ends, -1 if there is none.
+func (q *query) tokenAt(pos Position) int {
+	line := pos.Line + 1
+	col := byteOffset(lineOf(q.text, line), pos.Character) + 1
+	found := -1
+	for i, tok := range q.tokens {
+		if tok.Src.Line != line || tok.Kind == lexer.EOF {
+			continue
+		}
+		end := tok.Src.Col + len(tok.Value)
+		switch {
+		case tok.Src.Col <= col && col < end:
+			return i
+		case col == end && tok.Kind == lexer.IDENTIFIER:
+			found = i
+		}
+	}
+	return found
+}
+
+// token returns the token at the position, false if there is none.
+func (q *query) token() (lexer.Token, bool) {
+	if q.at == -1 {
+		return lexer.Token{}, false
+	}
+	return q.tokens[q.at], true
+}
+
+// exprAt returns the symbol or member expression located at loc, the
+// location of its name.
+func (q *query) exprAt(loc lexer.SourceLoc) ast.Expression {
+	var found ast.Expression
+	ast.Inspect(q.tree, func(n ast.Node) bool {
+		if found != nil {
+			return false
+		}
+		switch n := n.(type) {
+		case ast.SymbolExpression, ast.MemberExpression:
+			if src := n.GetSrc(); src.Line == loc.Line && src.Col == loc.Col {
+				found = n.(ast.Expression)
+				return false
+			}
+		}
+		return true
+	})
+	return found
+}
+
+// imports returns the import statements of the document by alias.
+func (q *query) imports() map[string]ast.ImportStatement {
+	imps := make(map[string]ast.ImportStatement)
+	for _, stI := range q.tree.Body {
+		if st, ok := stI.(ast.ImportStatement); ok {
+			imps[st.Alias] = st
+		}
+	}
+	return imps
+}
+
+// dotted returns the names of the dotted name ending at token i, e.g. a, b
+// and C for the C of a.b.C.
+func (q *query) dotted(i int) []string {
+	names := []string{q.tokens[i].Value}
+	for i >= 2 && q.tokens[i-1].Kind == lexer.DOT && q.tokens[i-2].Kind == lexer.IDENTIFIER {
+		i -= 2
+		names = append([]string{q.tokens[i].Value}, names...)
+	}
+	return names
+}
+
+// target is what a name refers to: a symbol, a class or interface, or a
+// package.
+type target struct {
+	sym   *sema.Symbol
+	tp    ast.Type // of an expression without symbol
+	class string   // fully qualified
+	iface string
+	pkg   string
+	lib   bool // pkg is a builtin module
+}
+
+// resolve finds what the identifier at the position refers to.
+func (q *query) resolve() (target, bool) {
+	tok, ok := q.token()
+	if !ok {
+		return target{}, false
+	}
+
+	if tok.Kind == lexer.STRING {
+		for _, st := range q.imports() {
+			if st.SourceLoc.Line == tok.Src.Line {
+				return q.importTarget(st), true
+			}
+		}
+		return target{}, false
+	}
+	if tok.Kind != lexer.IDENTIFIER {
+		return target{}, false
+	}
+
+	e := q.exprAt(tok.Src)
+	if e != nil {
+		if sym := q.info.SymbolOf(e); sym != nil {
+			return target{sym: sym}, true
+		}
+	}
+
+	names := q.dotted(q.at)
+	if st, ok := q.imports()[names[0]]; ok && !q.isVar(names[0]) {
+		if len(names) == 1 {
+			return q.importTarget(st), true
+		}
+		if st.IsBuiltIn() || st.IsFFI() {
+			return target{}, false
+		}
+		names = append([]string{st.Name}, names[1:]...)
+	}
+	if fq := q.typeName(strings.Join(names, ".")); fq != "" {
+		if _, ok := q.info.Classes[fq]; ok {
+			return target{class: fq}, true
+		}
+		return target{iface: fq}, true
+	}
+
+	if e != nil {
+		if tp := q.info.TypeOf(e); tp != nil {
+			return target{tp: tp}, true
+		}
+	}
+	return target{}, false
+}
+
+// isVar reports whether name is used as a variable in the document, which
+// hides an import of the same name.
+func (q *query) isVar(name string) bool {
+	found := false
+	ast.Inspect(q.tree, func(n ast.Node) bool {
+		if ex, ok := n.(ast.SymbolExpression); ok && ex.Value == name && q.info.SymbolOf(ex) != nil {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+func (q *query) importTarget(st ast.ImportStatement) target {
+	if st.IsBuiltIn() || st.IsFFI() {
+		return target{pkg: st.EndName(), lib: true}
+	}
+	return target{pkg: st.Name}
+}
+
+// typeName resolves the name of a class or interface as the checker does:
+// fully qualified, or by its suffix. It returns "" if there is none.
+func (q *query) typeName(name string) string {
+	if q.isType(name) {
+		return name
+	}
+	if q.isType(q.pkg + "." + name) {
+		return q.pkg + "." + name
+	}
+	var names []string
+	for fq := range q.info.Classes {
+		names = append(names, fq)
+	}
+	for fq := range q.info.Interfaces {
+		names = append(names, fq)
+	}
+	sort.Strings(names)
+	for _, fq := range names {
+		if strings.HasSuffix(fq, "."+name) {
+			return fq
+		}
+	}
+	return ""
+}
+
+func (q *query) isType(fq string) bool {
+	_, class := q.info.Classes[fq]
+	_, iface := q.info.Interfaces[fq]
+	return class || iface
+}
+
+// definition returns where the name at the position is declared.
+func (q *query) definition() (*Location, bool) {
+	t, ok := q.resolve()
+	if !ok {
+		return nil, false
+	}
+	switch {
+	case t.sym != nil && t.sym.Decl.FilePath != "":
+		return q.location(t.sym.Decl), true
+	case t.class != "":
+		return q.location(q.info.Classes[t.class].Decl), true
+	case t.iface != "":
+		return q.location(q.info.Interfaces[t.iface].Decl), true
+	case t.pkg != "" && !t.lib:
+		if name, ok := q.snap.files[t.pkg]; ok {
+			return &Location{URI: pathURI(name)}, true
+		}
+	}
+	return nil, false
+}
+
+// location returns the location of the name declared at loc.
+func (q *query) location(loc ast.SourceLoc) *Location {
+	text, _ := q.w.read(loc.FilePath)
+	return &Location{URI: pathURI(loc.FilePath), Range: wordRange(text, loc.Line, loc.Col)}
+}
+
+// hover describes the name at the position.
+func (q *query) hover() (*Hover, bool) {
+	t, ok := q.resolve()
+	if !ok {
+		return nil, false
+	}
+
+	var desc string
+	switch {
+	case t.sym != nil:
+		desc = q.describe(t.sym)
+	case t.tp != nil:
+		desc = sema.TypeString(t.tp)
+	case t.class != "":
+		desc = "class " + t.class
+		if impl := q.info.Classes[t.class].Implements; impl != "" {
+			desc += ": " + impl
+		}
+	case t.iface != "":
+		desc = "interface " + t.iface
+	case t.lib:
+		desc = "builtin module " + t.pkg
+	default:
+		desc = "package " + t.pkg
+	}
+
+	tok := q.tokens[q.at]
+	r := wordRange(q.text, tok.Src.Line, tok.Src.Col)
+	return &Hover{Contents: MarkupContent{Kind: "markdown", Value: "```picasso\n" + desc + "\n```"}, Range: &r}, true
+}
+
+// describe formats sym like its declaration, with its inferred type.
+func (q *query) describe(sym *sema.Symbol) string {
+	internal := ""
+	if sym.Internal {
+		internal = "internal "
+	}
+	switch sym.Kind {
+	case sema.ParamSymbol:
+		return fmt.Sprintf("%s: %s", sym.Name, sema.TypeString(sym.Type))
+	case sema.FieldSymbol:
+		return fmt.Sprintf("%ssay %s.%s: %s", internal, sym.Owner, sym.Name, sema.TypeString(sym.Type))
+	case sema.MethodSymbol:
+		m := q.method(sym)
+		if m == nil {
+			return fmt.Sprintf("%sfn %s.%s", internal, sym.Owner, sym.Name)
+		}
+		return internal + signature(m)
+	}
+	return fmt.Sprintf("say %s: %s", sym.Name, sema.TypeString(sym.Type))
+}
+
+// method returns the method sym is the symbol of.
+func (q *query) method(sym *sema.Symbol) *sema.Method {
+	if cls, ok := q.info.Classes[sym.Owner]; ok {
+		return cls.Methods[sym.Name]
+	}
+	if ifs, ok := q.info.Interfaces[sym.Owner]; ok {
+		return ifs.Methods[sym.Name]
+	}
+	return nil
+}
+
+// signature formats m like its declaration, e.g. fn start.A.sum(x: int): int.
+func signature(m *sema.Method) string {
+	var b strings.Builder
+	if m.Static {
+		b.WriteString("static ")
+	}
+	fmt.Fprintf(&b, "fn %s.%s(", m.Owner, m.Name)
+	for i, p := range m.Params {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		fmt.Fprintf(&b, "%s: %s", p.Name, sema.TypeString(p.Type))
+	}
+	b.WriteString(")")
+	if m.Return != nil {
+		b.WriteString(": " + sema.TypeString(m.Return))
+	}
+	return b.String()
+}
+
+// members returns the completions of the members of class or interface fq.
+// Internal members are offered inside the class only.
+func (q *query) members(fq string, inside bool) []CompletionItem {
+	var items []CompletionItem
+	if cls, ok := q.info.Classes[fq]; ok {
+		for _, f := range cls.Fields {
+			if !f.Internal || inside {
+				items = append(items, CompletionItem{Label: f.Name, Kind: completionField, Detail: sema.TypeString(f.Type)})
+			}
+		}
+		for _, m := range cls.Methods {
+			if (!m.Internal || inside) && m.Name != lastName(fq) {
+				items = append(items, CompletionItem{Label: m.Name, Kind: completionMethod, Detail: signature(m)})
+			}
+		}
+	}
+	if ifs, ok := q.info.Interfaces[fq]; ok {
+		for _, m := range ifs.Methods {
+			items = append(items, CompletionItem{Label: m.Name, Kind: completionMethod, Detail: signature(m)})
+		}
+	}
+	return items
+}
+
+// packageTypes returns the completions of the classes and interfaces of
+// package pkg.
+func (q *query) packageTypes(pkg string) []CompletionItem {
+	var items []CompletionItem
+	for fq, cls := range q.info.Classes {
+		if cls.Package == pkg && (!cls.Internal || pkg == q.pkg) {
+			items = append(items, CompletionItem{Label: lastName(fq), Kind: completionClass, Detail: "class " + fq})
+		}
+	}
+	for fq, ifs := range q.info.Interfaces {
+		if ifs.Package == pkg {
+			items = append(items, CompletionItem{Label: lastName(fq), Kind: completionIface, Detail: "interface " + fq})
+		}
+	}
+	return items
+}
+
+// moduleFuncs returns the completions of the functions of builtin module
+// name.
+func moduleFuncs(name string) []CompletionItem {
+	mod, ok := libs.ModuleList[name]
+	if !ok {
+		return nil
+	}
+	var items []CompletionItem
+	for fn := range mod.ListAllFuncs() {
+		items = append(items, CompletionItem{Label: fn, Kind: completionFunction, Detail: name + "." + fn})
+	}
+	return items
+}
+
+func lastName(fq string) string {
+	return fq[strings.LastIndex(fq, ".")+1:]
+}
+
+// wordRange returns the range of the word at line:col of text, 1-based,
+// col in bytes. A position off any word gives an empty range.
+func wordRange(text string, line, col int) Range {
+	src := lineOf(text, line)
+	start := min(max(col-1, 0), len(src))
+	end := start
+	for end < len(src) && isWordByte(src[end]) {
+		end++
+	}
+	return Range{
+		Start: Position{Line: line - 1, Character: utf16Len(src[:start])},
+		End:   Position{Line: line - 1, Character: utf16Len(src[:end])},
+	}
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
@@ -0,0 +1,339 @@
+// Package lsp implements a language server for Picasso, see irgen lsp. It
+// speaks the Language Server Protocol over a pair of streams and supports
+// diagnostics, go to definition, hover and completion after a dot.
+//
+// Every change checks the whole project again, with the open documents in
+// place of their files, like irgen check would; requests are answered from
+// the result of the last check.
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+
+	errorsx "github.com/nagarajRPoojari/picasso/irgen/error"
+	"github.com/nagarajRPoojari/picasso/irgen/manifest"
+)
+
+// ErrNoShutdown is returned by Serve when the client exits without asking
+// the server to shut down first.
+var ErrNoShutdown = errors.New("lsp: exit without shutdown")
+
+// Server is a language server for the project at the root the client
+// names on initialization.
+type Server struct {
+	include string
+	out     io.Writer
+
+	// w is nil until the client initializes the server.
+	w    *workspace
+	snap *snapshot
+	// published holds the files diagnostics were last published for.
+	published map[string]bool
+	shutdown  bool
+}
+
+// NewServer returns a server looking packages up in the roots of the
+// manifest of the project, then in include, like $PICASSO_INCLUDE, if not
+// empty.
+func NewServer(include string) *Server {
+	return &Server{include: include, published: make(map[string]bool)}
+}
+
+// Serve reads requests from r and writes responses and notifications to w
+// until the client sends exit or r ends.
+func (s *Server) Serve(r io.Reader, w io.Writer) error {
+	s.out = w
+	in := bufio.NewReader(r)
+	for {
+		body, err := readMessage(in)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+
+		var msg message
+		if err := json.Unmarshal(body, &msg); err != nil {
+			if err := s.reply(nil, nil, &responseError{Code: codeParseError, Message: err.Error()}); err != nil {
+				return err
+			}
+			continue
+		}
+		if msg.Method == "exit" {
+			if !s.shutdown {
+				return ErrNoShutdown
+			}
+			return nil
+		}
+
+		result, rerr := s.handle(msg)
+		if msg.ID == nil {
+			// notifications have no response
+			continue
+		}
+		if err := s.reply(msg.ID, result, rerr); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Server) reply(id *json.RawMessage, result any, rerr *responseError) error {
+	if id == nil {
+		null := json.RawMessage("null")
+		id = &null
+	}
+	msg := &message{ID: id, Error: rerr}
+	if rerr == nil {
+		msg.Result = result
+		if result == nil {
+			msg.Result = json.RawMessage("null")
+		}
+	}
+	return writeMessage(s.out, msg)
+}
+
+func (s *Server) notify(method string, params any) {
+	body, err := json.Marshal(params)
+	if err != nil {
+		return
+	}
+	writeMessage(s.out, &message{Method: method, Params: body})
+}
+
+// handle handles a request or notification, returning the result of a
+// request.
+func (s *Server) handle(msg message) (any, *responseError) {
+	if s.w == nil && msg.Method != "initialize" {
+		if msg.ID == nil {
+			return nil, nil
+		}
+		return nil, &responseError{Code: codeServerNotInitialized, Message: "server not initialized"}
+	}
+
+	decode := func(v any) *responseError {
+		if err := json.Unmarshal(msg.Params, v); err != nil {
+			return &responseError{Code: codeInvalidParams, Message: err.Error()}
+		}
+		return nil
+	}
+
+	switch msg.Method {
+	case "initialize":
+		var params InitializeParams
+		if err := decode(&params); err != nil {
+			return nil, err
+		}
+		return s.initialize(params), nil
+
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil
+
+	case "textDocument/didOpen":
+		var params DidOpenTextDocumentParams
+		if err := decode(&params); err != nil {
+			return nil, err
+		}
+		if name, ok := s.nameOf(params.TextDocument.URI); ok {
+			s.w.docs[name] = params.TextDocument.Text
+			s.check()
+		}
+
+	case "textDocument/didChange":
+		var params DidChangeTextDocumentParams
+		if err := decode(&params); err != nil {
+			return nil, err
+		}
+		name, ok := s.nameOf(params.TextDocument.URI)
+		if ok && len(params.ContentChanges) > 0 {
+			s.w.docs[name] = params.ContentChanges[len(params.ContentChanges)-1].Text
+			s.check()
+		}
+
+	case "textDocument/didClose":
+		var params DidCloseTextDocumentParams
+		if err := decode(&params); err != nil {
+			return nil, err
+		}
+		if name, ok := s.nameOf(params.TextDocument.URI); ok {
+			delete(s.w.docs, name)
+			s.check()
+		}
+
+	case "textDocument/definition":
+		var params TextDocumentPositionParams
+		if err := decode(&params); err != nil {
+			return nil, err
+		}
+		if q, ok := s.query(params.TextDocument.URI, params.Position); ok {
+			if loc, ok := q.definition(); ok {
+				return loc, nil
+			}
+		}
+		return nil, nil
+
+	case "textDocument/hover":
+		var params TextDocumentPositionParams
+		if err := decode(&params); err != nil {
+			return nil, err
+		}
+		if q, ok := s.query(params.TextDocument.URI, params.Position); ok {
+			if h, ok := q.hover(); ok {
+				return h, nil
+			}
+		}
+		return nil, nil
+
+	case "textDocument/completion":
+		var params TextDocumentPositionParams
+		if err := decode(&params); err != nil {
+			return nil, err
+		}
+		items := s.complete(params.TextDocument.URI, params.Position)
+		if items == nil {
+			items = []CompletionItem{}
+		}
+		return CompletionList{Items: items}, nil
+
+	default:
+		if msg.ID != nil {
+			return nil, &responseError{Code: codeMethodNotFound, Message: "method not supported: " + msg.Method}
+		}
+	}
+	return nil, nil
+}
+
+func (s *Server) initialize(params InitializeParams) InitializeResult {
+	root := params.RootPath
+	if p, ok := uriPath(params.RootURI); ok {
+		root = p
+	}
+	if root == "" {
+		root, _ = os.Getwd()
+	}
+
+	m, err := manifest.Load(root)
+	if err != nil {
+		if !errors.Is(err, manifest.ErrNotFound) {
+			s.notify("window/showMessage", map[string]any{"type": severityError, "message": err.Error()})
+		}
+		// checked as a project without manifest
+		if m, err = manifest.Legacy(root); err != nil {
+			m = &manifest.Manifest{Dir: root, Sources: []string{root}, Output: filepath.Join(root, manifest.DefaultOutput)}
+		}
+	}
+	if s.include != "" && !slices.Contains(m.Include, s.include) {
+		m.Include = append(m.Include, s.include)
+	}
+	s.w = &workspace{m: m, docs: make(map[string]string)}
+
+	return InitializeResult{
+		Capabilities: ServerCapabilities{
+			TextDocumentSync:   1,
+			DefinitionProvider: true,
+			HoverProvider:      true,
+			CompletionProvider: &CompletionOptions{TriggerCharacters: []string{"."}},
+		},
+		ServerInfo: ServerInfo{Name: "picasso"},
+	}
+}
+
+// check checks the workspace and publishes the diagnostics of the open
+// files and of every file that has or had some.
+func (s *Server) check() {
+	s.snap = s.w.check()
+	if s.snap.err != nil {
+		s.notify("window/showMessage", map[string]any{"type": severityError, "message": s.snap.err.Error()})
+	}
+
+	var names []string
+	for name := range s.snap.diags {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	for name := range s.published {
+		if _, ok := s.snap.diags[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	for name := range s.w.docs {
+		if _, ok := s.snap.diags[name]; !ok && !s.published[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	published := make(map[string]bool)
+	for _, name := range names {
+		diags := s.diagnostics(name, s.snap.diags[name])
+		if len(diags) > 0 {
+			published[name] = true
+		}
+		s.notify("textDocument/publishDiagnostics", PublishDiagnosticsParams{URI: pathURI(name), Diagnostics: diags})
+	}
+	s.published = published
+}
+
+// diagnostics converts the diagnostics of the file named name.
+func (s *Server) diagnostics(name string, diags []errorsx.Diagnostic) []Diagnostic {
+	text, _ := s.w.read(name)
+	res := make([]Diagnostic, 0, len(diags))
+	for _, d := range diags {
+		severity := severityError
+		if d.Severity == errorsx.SeverityWarning {
+			severity = severityWarning
+		}
+		endLine, endCol := d.EndLine, d.EndCol
+		if endLine == 0 {
+			endLine, endCol = d.Line, d.Col+1
+		}
+		res = append(res, Diagnostic{
+			Range: Range{
+				Start: position(text, d.Line, d.Col),
+				End:   position(text, endLine, endCol),
+			},
+			Severity: severity,
+			Code:     d.Code,
+			Source:   "picasso",
+			Message:  d.Message,
+		})
+	}
+	return res
+}
+
+// position converts line:col of text, 1-based, col in bytes.
+func position(text string, line, col int) Position {
+	src := lineOf(text, line)
+	return Position{Line: max(line-1, 0), Character: utf16Len(src[:min(max(col-1, 0), len(src))])}
+}
+
+// nameOf returns the name of the file at uri in the workspace.
+func (s *Server) nameOf(uri string) (string, bool) {
+	p, ok := uriPath(uri)
+	if !ok {
+		return "", false
+	}
+	return s.w.name(p)
+}
+
+func uriPath(uri string) (string, bool) {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return "", false
+	}
+	return filepath.FromSlash(u.Path), true
+}
+
+func pathURI(p string) string {
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(p)}).String()
+}
@@ -0,0 +1,272 @@
+package lsp
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/nagarajRPoojari/picasso/irgen/ast"
+	"github.com/nagarajRPoojari/picasso/irgen/codegen/exports"
+	errorsx "github.com/nagarajRPoojari/picasso/irgen/error"
+	"github.com/nagarajRPoojari/picasso/irgen/manifest"
+	"github.com/nagarajRPoojari/picasso/irgen/parser"
+	"github.com/nagarajRPoojari/picasso/irgen/sema"
+)
+
+// workspace is the project being edited: its files on disk with the open
+// documents laid over them. Files are named by their path, as the compiler
+// names them, and belong to the package their path in the root of the
+// manifest holding them names.
+type workspace struct {
+	m *manifest.Manifest
+	// docs are the open documents by name.
+	docs map[string]string
+}
+
+// name returns the name of the file at the absolute path p, false if it
+// is in no root of the manifest.
+func (w *workspace) name(p string) (string, bool) {
+	p = filepath.Clean(p)
+	if _, ok := w.root(p); !ok || within(w.m.Output, p) {
+		return "", false
+	}
+	return p, true
+}
+
+// root returns the root of the manifest holding the file named name, the
+// innermost one if roots are nested in one another.
+func (w *workspace) root(name string) (string, bool) {
+	found := ""
+	for _, root := range w.m.Roots() {
+		if within(root, name) && len(root) > len(found) {
+			found = root
+		}
+	}
+	return found, found != ""
+}
+
+// isSource reports whether the file named name is in a source root, rather
+// than in an include path.
+func (w *workspace) isSource(name string) bool {
+	root, _ := w.root(name)
+	return slices.Contains(w.m.Sources, root)
+}
+
+// packageName names the package of the file named name, e.g. os.io for
+// os/io.pic in its root.
+func (w *workspace) packageName(name string) string {
+	root, _ := w.root(name)
+	rel, err := filepath.Rel(root, name)
+	if err != nil {
+		rel = name
+	}
+	return strings.ReplaceAll(strings.TrimSuffix(filepath.ToSlash(rel), ".pic"), "/", ".")
+}
+
+// read returns the content of the file named name, the open document if
+// there is one.
+func (w *workspace) read(name string) (string, error) {
+	if text, ok := w.docs[name]; ok {
+		return text, nil
+	}
+	b, err := os.ReadFile(name)
+	return string(b), err
+}
+
+// files returns the names of the .pic files of the roots of the manifest,
+// source roots first, open documents not yet saved included.
+func (w *workspace) files() []string {
+	seen := make(map[string]bool)
+	var names []string
+	add := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+
+	roots := w.m.Roots()
+	for _, root := range roots {
+		filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+			switch {
+			case err != nil:
+				return nil
+			case d.IsDir():
+				// build output is not source, and roots nested in one
+				// another are walked once
+				if p == w.m.Output || p != root && slices.Contains(roots, p) {
+					return filepath.SkipDir
+				}
+				return nil
+			case filepath.Ext(p) == ".pic":
+				add(p)
+			}
+			return nil
+		})
+	}
+	for name := range w.docs {
+		add(name)
+	}
+	return names
+}
+
+// within reports whether path is in dir.
+func within(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// snapshot is the result of checking the workspace once.
+type snapshot struct {
+	// info is nil if checking failed, see err.
+	info *sema.Info
+	err  error
+	// files maps package names to the names of their files.
+	files map[string]string
+	// diags holds the diagnostics of each file, by name.
+	diags map[string][]errorsx.Diagnostic
+}
+
+// check parses and checks every file of the workspace. A file with syntax
+// errors that is not open is replaced by the declarations of its last
+// build, if any, so that the packages importing it still resolve.
+func (w *workspace) check() (snap *snapshot) {
+	snap = &snapshot{files: make(map[string]string), diags: make(map[string][]errorsx.Diagnostic)}
+	collector := errorsx.NewCollector()
+	collector.SetSources(sourceFS{w})
+	defer func() {
+		// a compiler bug must not take the server down
+		if r := recover(); r != nil {
+			snap.info, snap.err = nil, fmt.Errorf("internal compiler error: %v", r)
+		}
+		for _, d := range collector.Resolved() {
+			snap.diags[d.Path] = append(snap.diags[d.Path], d)
+		}
+	}()
+
+	pkgs := make(map[string]ast.BlockStatement)
+	var targets []string
+	for _, name := range w.files() {
+		text, err := w.read(name)
+		if err != nil {
+			continue
+		}
+		pkg := w.packageName(name)
+		if _, ok := snap.files[pkg]; ok {
+			// defined in a source root and an include path
+			continue
+		}
+		snap.files[pkg] = name
+
+		errs := collector.Errors()
+		pkgs[pkg] = parser.ParseAllFrom(collector, name, strings.NewReader(text))
+		if _, open := w.docs[name]; !open && collector.Errors() > errs {
+			if tree, ok := w.built(pkg, name); ok {
+				pkgs[pkg] = tree
+			}
+		}
+		if w.isSource(name) {
+			targets = append(targets, pkg)
+		}
+	}
+	if len(targets) > 0 {
+		snap.info = sema.Check(collector, pkgs, targets...)
+	}
+	return snap
+}
+
+// built returns the declarations of package pkg recorded in its .exports
+// file, located in the file named name. It fails if the file is missing or
+// older than the source.
+func (w *workspace) built(pkg, name string) (ast.BlockStatement, bool) {
+	exportsPath := exports.Path(w.m.Output, pkg)
+	built, err := os.Stat(exportsPath)
+	if err != nil {
+		return ast.BlockStatement{}, false
+	}
+	if src, err := os.Stat(name); err != nil || src.ModTime().After(built.ModTime()) {
+		return ast.BlockStatement{}, false
+	}
+	p, err := exports.Read(exportsPath)
+	if err != nil {
+		return ast.BlockStatement{}, false
+	}
+	tree, err := p.AST()
+	if err != nil {
+		return ast.BlockStatement{}, false
+	}
+	return relocate(tree, name), true
+}
+
+// relocate points the declarations of tree into the file named name; the
+// build may have named it relative to another directory.
+func relocate(tree ast.BlockStatement, name string) ast.BlockStatement {
+	at := func(loc ast.SourceLoc) ast.SourceLoc {
+		loc.FilePath = name
+		return loc
+	}
+	for i, stI := range tree.Body {
+		switch st := stI.(type) {
+		case ast.ImportStatement:
+			st.SourceLoc = at(st.SourceLoc)
+			tree.Body[i] = st
+		case ast.InterfaceDeclarationStatement:
+			st.SourceLoc = at(st.SourceLoc)
+			for j, memberI := range st.Body {
+				if fn, ok := memberI.(ast.FunctionDefinitionStatement); ok {
+					fn.SourceLoc = at(fn.SourceLoc)
+					st.Body[j] = fn
+				}
+			}
+			tree.Body[i] = st
+		case ast.ClassDeclarationStatement:
+			st.SourceLoc = at(st.SourceLoc)
+			for j, memberI := range st.Body {
+				switch member := memberI.(type) {
+				case ast.VariableDeclarationStatement:
+					member.SourceLoc = at(member.SourceLoc)
+					st.Body[j] = member
+				case ast.FunctionDefinitionStatement:
+					member.SourceLoc = at(member.SourceLoc)
+					st.Body[j] = member
+				}
+			}
+			tree.Body[i] = st
+		}
+	}
+	return tree
+}
+
+// sourceFS serves the files of a workspace to the diagnostics collector,
+// which reads them to locate the end of diagnostics.
+type sourceFS struct {
+	w *workspace
+}
+
+func (s sourceFS) Open(name string) (fs.File, error) {
+	text, err := s.w.read(name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return &memFile{Reader: strings.NewReader(text), name: name}, nil
+}
+
+// memFile is a file read from memory.
+type memFile struct {
+	*strings.Reader
+	name string
+}
+
+func (f *memFile) Stat() (fs.FileInfo, error) { return f, nil }
+func (f *memFile) Close() error               { return nil }
+
+func (f *memFile) Name() string       { return path.Base(f.name) }
+func (f *memFile) Mode() fs.FileMode  { return 0o444 }
+func (f *memFile) ModTime() time.Time { return time.Time{} }
+func (f *memFile) IsDir() bool        { return false }
+func (f *memFile) Sys() any           { return nil }
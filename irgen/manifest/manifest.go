@@ -0,0 +1,378 @@
+// Package manifest reads the project manifest, picasso.toml or picasso.ini
+// at the root of a project, which declares how it is laid out and built:
+//
+//	[module]
+//	name = "shapes"
+//	entry = "start"          # package of fn start, the default
+//
+//	[build]
+//	sources = ["src"]        # source roots, the project root by default
+//	include = ["../libs"]    # roots of other packages, e.g. the picasso libs
+//	ffi = ["c/ffi"]          # roots of the C modules imported with c/ffi
+//	output = "build"         # IR, .exports and objects, the default
+//
+//	[dependencies.shared]    # vendored by irgen deps into vendor/shared
+//	path = "../shared"
+//
+//	[dependencies.geo]
+//	url = "https://example.com/geo-1.2.0.tar.gz"
+//	version = "1.2.0"
+//
+// Paths are relative to the manifest. Packages are named by their path in
+// their root, e.g. geo.shape for src/geo/shape.pic, and those of a
+// dependency by their path in the vendor directory, e.g. vendor.geo.shape
+// for vendor/geo/shape.pic.
+package manifest
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Manifest file names, in the order they are looked up.
+const (
+	TOML = "picasso.toml"
+	INI  = "picasso.ini"
+)
+
+// IncludeEnv is the variable the picasso CLI sets to its libs, which are
+// searched after the include paths of the manifest.
+const IncludeEnv = "PICASSO_INCLUDE"
+
+// VendorDir is the directory of the project the dependencies are vendored
+// in, and the first part of the names of their packages.
+const VendorDir = "vendor"
+
+// Defaults of the optional keys.
+const (
+	DefaultEntry  = "start"
+	DefaultOutput = "build"
+)
+
+// ErrNotFound is returned by Find and Load for a directory without a
+// manifest.
+var ErrNotFound = errors.New("no " + TOML + " or " + INI)
+
+// Error is an invalid manifest. Line is the line of the manifest at fault,
+// 0 if the problem is not at a line, e.g. a missing key.
+type Error struct {
+	Path string
+	Line int
+	Msg  string
+}
+
+func (e *Error) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("%s:%d: %s", e.Path, e.Line, e.Msg)
+	}
+	return fmt.Sprintf("%s: %s", e.Path, e.Msg)
+}
+
+// Manifest is a project manifest. Its paths are relative to the working
+// directory if the project directory it was loaded from is.
+type Manifest struct {
+	// Path is the manifest read, "" for a project without one, see Legacy.
+	Path string
+	// Dir is the root of the project, where the manifest is.
+	Dir string
+
+	// Module names the project.
+	Module string
+	// Entry is the package defining fn start.
+	Entry string
+
+	// Sources are the roots of the packages of the project.
+	Sources []string
+	// Include are the roots of the other packages it imports.
+	Include []string
+	// FFI are the roots of its C modules, a directory per module.
+	FFI []string
+	// Output is the directory the build writes to.
+	Output string
+
+	// Deps are the dependencies of the project, in manifest order.
+	Deps []Dependency
+}
+
+// Dependency is a [dependencies.NAME] section: a directory of packages,
+// Path, or a .tar.gz of one, URL, at Version.
+type Dependency struct {
+	Name    string
+	Path    string
+	URL     string
+	Version string
+	// Line is the line of its section in the manifest.
+	Line int
+}
+
+var (
+	moduleName  = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+	packageName = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*(\.[a-zA-Z_][a-zA-Z0-9_]*)*$`)
+)
+
+// Find returns the path of the manifest of the project in dir.
+func Find(dir string) (string, error) {
+	var found []string
+	for _, name := range []string{TOML, INI} {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err == nil {
+			found = append(found, path)
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return "", err
+		}
+	}
+	switch len(found) {
+	case 0:
+		return "", fmt.Errorf("%w in %s", ErrNotFound, dir)
+	case 1:
+		return found[0], nil
+	}
+	return "", fmt.Errorf("%s: both %s and %s, keep one", dir, TOML, INI)
+}
+
+// Load reads the manifest of the project in dir. A project without one is
+// laid out as Legacy describes if $PICASSO_INCLUDE is set, which used to
+// be required; otherwise ErrNotFound is returned.
+func Load(dir string) (*Manifest, error) {
+	path, err := Find(dir)
+	if errors.Is(err, ErrNotFound) && os.Getenv(IncludeEnv) != "" {
+		return Legacy(dir)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	m, err := Parse(path, f)
+	if err != nil {
+		return nil, err
+	}
+	m.addIncludeEnv()
+	return m, nil
+}
+
+// Legacy returns the layout of a project without manifest: its packages
+// are in dir, the libs in $PICASSO_INCLUDE, its C modules in c/ffi and the
+// build output in build.
+func Legacy(dir string) (*Manifest, error) {
+	dir = filepath.Clean(dir)
+	if !isDir(dir) {
+		return nil, fmt.Errorf("project directory %s does not exist", dir)
+	}
+	m := &Manifest{
+		Dir:     dir,
+		Entry:   DefaultEntry,
+		Sources: []string{dir},
+		Output:  filepath.Join(dir, DefaultOutput),
+	}
+	if ffi := filepath.Join(dir, "c", "ffi"); isDir(ffi) {
+		m.FFI = []string{ffi}
+	}
+	m.addIncludeEnv()
+	return m, nil
+}
+
+func (m *Manifest) addIncludeEnv() {
+	if env := filepath.Clean(os.Getenv(IncludeEnv)); env != "." && !slices.Contains(m.Include, env) {
+		m.Include = append(m.Include, env)
+	}
+}
+
+// Parse reads the manifest at path from r, in the format its name tells.
+// Its directories must exist, but the output.
+func Parse(path string, r io.Reader) (*Manifest, error) {
+	sections, err := parse(path, r, filepath.Ext(path) == ".ini")
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Manifest{Path: path, Dir: filepath.Dir(path), Entry: DefaultEntry, Output: DefaultOutput}
+	errorf := func(line int, format string, args ...any) error {
+		return &Error{Path: path, Line: line, Msg: fmt.Sprintf(format, args...)}
+	}
+
+	hasModule := false
+	for _, s := range sections {
+		switch s.name {
+		case "":
+			if len(s.entries) > 0 {
+				return nil, errorf(s.entries[0].line, "key %s outside of a section", s.entries[0].key)
+			}
+		case "module":
+			hasModule = true
+			for _, e := range s.entries {
+				switch e.key {
+				case "name":
+					m.Module, err = e.string()
+				case "entry":
+					m.Entry, err = e.string()
+					m.Entry = strings.ReplaceAll(m.Entry, "/", ".")
+				default:
+					err = fmt.Errorf("unknown key %s in [module], want name or entry", e.key)
+				}
+				if err != nil {
+					return nil, errorf(e.line, "%v", err)
+				}
+			}
+		case "build":
+			for _, e := range s.entries {
+				switch e.key {
+				case "sources":
+					m.Sources = e.strings()
+				case "include":
+					m.Include = e.strings()
+				case "ffi":
+					m.FFI = e.strings()
+				case "output":
+					m.Output, err = e.string()
+				default:
+					err = fmt.Errorf("unknown key %s in [build], want sources, include, ffi or output", e.key)
+				}
+				if err != nil {
+					return nil, errorf(e.line, "%v", err)
+				}
+			}
+		default:
+			name, ok := strings.CutPrefix(s.name, "dependencies.")
+			if !ok {
+				return nil, errorf(s.line, "unknown section [%s], want [module], [build] or [dependencies.NAME]", s.name)
+			}
+			d, err := parseDependency(name, s)
+			if err != nil {
+				return nil, errorf(s.line, "%v", err)
+			}
+			m.Deps = append(m.Deps, d)
+		}
+	}
+
+	switch {
+	case !hasModule || m.Module == "":
+		return nil, errorf(0, "missing module name, declare it with name = \"...\" in [module]")
+	case !moduleName.MatchString(m.Module):
+		return nil, errorf(line(sections, "module", "name"), "invalid module name %q, want letters, digits and _", m.Module)
+	case !packageName.MatchString(m.Entry):
+		return nil, errorf(line(sections, "module", "entry"), "invalid entry package %q", m.Entry)
+	}
+
+	if len(m.Sources) == 0 {
+		m.Sources = []string{"."}
+	}
+	for _, dirs := range []struct {
+		key   string
+		paths []string
+	}{{"sources", m.Sources}, {"include", m.Include}, {"ffi", m.FFI}} {
+		for i, p := range dirs.paths {
+			dirs.paths[i] = m.resolve(p)
+			if !isDir(dirs.paths[i]) {
+				return nil, errorf(line(sections, "build", dirs.key), "%s directory %s does not exist", dirs.key, p)
+			}
+		}
+	}
+	m.Output = m.resolve(m.Output)
+	for i, d := range m.Deps {
+		if d.Path == "" {
+			continue
+		}
+		m.Deps[i].Path = m.resolve(d.Path)
+		if !isDir(m.Deps[i].Path) {
+			return nil, errorf(d.Line, "dependency %s: directory %s does not exist", d.Name, d.Path)
+		}
+	}
+	return m, nil
+}
+
+func parseDependency(name string, s section) (Dependency, error) {
+	d := Dependency{Name: name, Line: s.line}
+	if !moduleName.MatchString(name) {
+		return d, fmt.Errorf("invalid dependency name %q, want letters, digits and _", name)
+	}
+	for _, e := range s.entries {
+		var err error
+		switch e.key {
+		case "path":
+			d.Path, err = e.string()
+		case "url":
+			d.URL, err = e.string()
+		case "version":
+			d.Version, err = e.string()
+		default:
+			err = fmt.Errorf("unknown key %s, want path, url or version", e.key)
+		}
+		if err != nil {
+			return d, fmt.Errorf("dependency %s: %v", name, err)
+		}
+	}
+	switch {
+	case d.Path == "" && d.URL == "":
+		return d, fmt.Errorf("dependency %s needs a path or a url", name)
+	case d.Path != "" && d.URL != "":
+		return d, fmt.Errorf("dependency %s has both a path and a url, keep one", name)
+	case d.URL != "" && d.Version == "":
+		return d, fmt.Errorf("dependency %s needs the version of its url", name)
+	}
+	return d, nil
+}
+
+// Roots returns the directories packages are looked up in, the source roots
+// first. The vendor directory is not one of them, see Vendor.
+func (m *Manifest) Roots() []string {
+	return append(slices.Clone(m.Sources), m.Include...)
+}
+
+// Vendor returns the directory the dependencies are vendored in. Its
+// packages are named by their path in the project directory.
+func (m *Manifest) Vendor() string {
+	return filepath.Join(m.Dir, VendorDir)
+}
+
+// resolve returns path, relative to the manifest, relative to the working
+// directory.
+func (m *Manifest) resolve(path string) string {
+	if filepath.IsAbs(path) {
+		return filepath.Clean(path)
+	}
+	return filepath.Join(m.Dir, path)
+}
+
+func (e entry) string() (string, error) {
+	if e.list {
+		return "", fmt.Errorf("%s must be a string, not an array", e.key)
+	}
+	return e.values[0], nil
+}
+
+// strings returns the values of e; a single string is a list of one.
+func (e entry) strings() []string {
+	return e.values
+}
+
+// line returns the line of key in section name, 0 if it is not there.
+func line(sections []section, name, key string) int {
+	for _, s := range sections {
+		if s.name != name {
+			continue
+		}
+		for _, e := range s.entries {
+			if e.key == key {
+				return e.line
+			}
+		}
+		return s.line
+	}
+	return 0
+}
+
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
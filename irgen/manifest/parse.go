@@ -0,0 +1,177 @@
+package manifest
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// section is a [name] of the manifest with its keys, in file order.
+type section struct {
+	name    string
+	line    int
+	entries []entry
+}
+
+// entry is a key = value line. A list is an array, e.g. ["a", "b"], or a
+// comma-separated list in picasso.ini.
+type entry struct {
+	key    string
+	line   int
+	values []string
+	list   bool
+}
+
+// parse reads the sections of the manifest at path from r. Both formats
+// share the subset of TOML the manifest needs: [section] headers, key =
+// value lines, comments starting with # or, in picasso.ini, ; and values
+// that are strings or arrays of strings. picasso.ini also takes strings
+// without quotes and lists without brackets. Keys before any header are in
+// a section named "".
+func parse(path string, r io.Reader, ini bool) ([]section, error) {
+	sections := []section{{}}
+	seen := map[string]bool{"": true}
+
+	sc := bufio.NewScanner(r)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(stripComment(sc.Text(), ini))
+		if line == "" {
+			continue
+		}
+		errorf := func(format string, args ...any) error {
+			return &Error{Path: path, Line: n, Msg: fmt.Sprintf(format, args...)}
+		}
+
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") {
+				return nil, errorf("unterminated section header %s", line)
+			}
+			name := strings.TrimSpace(line[1 : len(line)-1])
+			if name == "" {
+				return nil, errorf("empty section name")
+			}
+			if seen[name] {
+				return nil, errorf("duplicate section [%s]", name)
+			}
+			seen[name] = true
+			sections = append(sections, section{name: name, line: n})
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if !ok || key == "" {
+			return nil, errorf("expected key = value, found %s", line)
+		}
+		cur := &sections[len(sections)-1]
+		for _, e := range cur.entries {
+			if e.key == key {
+				return nil, errorf("duplicate key %s", key)
+			}
+		}
+		e := entry{key: key, line: n}
+		var err error
+		if strings.HasPrefix(value, "[") {
+			e.list = true
+			e.values, err = parseArray(value, ini)
+		} else if ini && strings.Contains(value, ",") && !strings.HasPrefix(value, `"`) {
+			e.list = true
+			e.values, err = parseItems(value, ini)
+		} else {
+			var s string
+			s, err = parseString(value, ini)
+			e.values = []string{s}
+		}
+		if err != nil {
+			return nil, errorf("value of %s: %v", key, err)
+		}
+		cur.entries = append(cur.entries, e)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return sections, nil
+}
+
+// stripComment removes a comment ending line, but in a quoted string.
+func stripComment(line string, ini bool) string {
+	quoted := false
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case c == '\\' && quoted:
+			i++
+		case c == '"':
+			quoted = !quoted
+		case !quoted && (c == '#' || ini && c == ';'):
+			return line[:i]
+		}
+	}
+	return line
+}
+
+func parseArray(value string, ini bool) ([]string, error) {
+	if !strings.HasSuffix(value, "]") {
+		return nil, fmt.Errorf("unterminated array %s", value)
+	}
+	return parseItems(value[1:len(value)-1], ini)
+}
+
+// parseItems parses the comma-separated strings of an array; a trailing
+// comma is allowed.
+func parseItems(s string, ini bool) ([]string, error) {
+	items := []string{}
+	for len(strings.TrimSpace(s)) > 0 {
+		s = strings.TrimSpace(s)
+		var item string
+		if strings.HasPrefix(s, `"`) {
+			// the closing quote, skipping escaped ones
+			end := 1
+			for end < len(s) && s[end] != '"' {
+				if s[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(s) {
+				return nil, fmt.Errorf("unterminated string %s", s)
+			}
+			item, s = s[:end+1], s[end+1:]
+		} else {
+			item, s, _ = strings.Cut(s, ",")
+			s = "," + s
+		}
+
+		v, err := parseString(strings.TrimSpace(item), ini)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, v)
+
+		s = strings.TrimSpace(s)
+		if s != "" && !strings.HasPrefix(s, ",") {
+			return nil, fmt.Errorf("expected , between items, found %s", s)
+		}
+		s = strings.TrimPrefix(s, ",")
+	}
+	return items, nil
+}
+
+// parseString parses a quoted string, or in picasso.ini a bare one.
+func parseString(value string, ini bool) (string, error) {
+	if strings.HasPrefix(value, `"`) {
+		s, err := strconv.Unquote(value)
+		if err != nil {
+			return "", fmt.Errorf("invalid string %s", value)
+		}
+		return s, nil
+	}
+	if !ini {
+		return "", fmt.Errorf("expected a quoted string, found %s", value)
+	}
+	if value == "" {
+		return "", fmt.Errorf("empty value")
+	}
+	return value, nil
+}
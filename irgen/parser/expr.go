@@ -1,11 +1,9 @@
 package parser
 
 import (
-	"fmt"
 	"strconv"
 
 	"github.com/nagarajRPoojari/picasso/irgen/ast"
-	errorsx "github.com/nagarajRPoojari/picasso/irgen/error"
 	"github.com/nagarajRPoojari/picasso/irgen/lexer"
 )
 
@@ -14,12 +12,7 @@ func parseExpr(p *Parser, bp BindingPower) ast.Expression {
 	nudFn, exists := nud_table[tokenKind]
 
 	if !exists {
-		errorsx.PanicParserError(
-			fmt.Sprintf("Unrecognized %s\n", lexer.TokenKindString(tokenKind)),
-			p.currentToken().Src.FilePath,
-			p.currentToken().Src.Line,
-			p.currentToken().Src.Col,
-		)
+		p.errorf(p.currentToken(), "expected expression, found %s", describe(p.currentToken()))
 	}
 
 	left := nudFn(p)
@@ -27,11 +20,9 @@ func parseExpr(p *Parser, bp BindingPower) ast.Expression {
 		tokenKind = p.currentTokenKind()
 		ledFn, exists := led_table[tokenKind]
 
+		// the caller reports what it expected after the expression
 		if !exists {
-			errorsx.PanicParserError(fmt.Sprintf("Unrecognized %s\n", lexer.TokenKindString(tokenKind)),
-				p.currentToken().Src.FilePath,
-				p.currentToken().Src.Line,
-				p.currentToken().Src.Col)
+			break
 		}
 
 		left = ledFn(p, left, bp)
@@ -41,18 +32,19 @@ func parseExpr(p *Parser, bp BindingPower) ast.Expression {
 }
 
 func parsePrefixExpr(p *Parser) ast.Expression {
+	start := ast.SourceLoc(p.currentToken().Src)
 	operatorToken := p.move()
 	expr := parseExpr(p, unary)
 
 	return ast.PrefixExpression{
-		SourceLoc: ast.SourceLoc(p.currentToken().Src),
+		SourceLoc: start,
 		Operator:  operatorToken,
 		Operand:   expr,
 	}
 }
 
 func parseAssignmentExpr(p *Parser, left ast.Expression, bp BindingPower) ast.Expression {
-	p.move() // consume '='
+	operator := p.move() // consume '='
 
 	// Check if left side has comma (multiple assignees)
 	// This needs to be handled at statement level, but we'll support it here
@@ -71,7 +63,8 @@ func parseAssignmentExpr(p *Parser, left ast.Expression, bp BindingPower) ast.Ex
 	// Single assignment (backward compatibility)
 	if len(assignedValues) == 1 {
 		return ast.AssignmentExpression{
-			SourceLoc:     ast.SourceLoc(p.currentToken().Src),
+			SourceLoc:     left.GetSrc(),
+			Operator:      operator,
 			Assignee:      left,
 			AssignedValue: assignedValues[0],
 		}
@@ -79,7 +72,8 @@ func parseAssignmentExpr(p *Parser, left ast.Expression, bp BindingPower) ast.Ex
 
 	// Multiple assignments
 	return ast.AssignmentExpression{
-		SourceLoc:      ast.SourceLoc(p.currentToken().Src),
+		SourceLoc:      left.GetSrc(),
+		Operator:       operator,
 		Assignees:      assignees,
 		AssignedValues: assignedValues,
 	}
@@ -88,7 +82,7 @@ func parseAssignmentExpr(p *Parser, left ast.Expression, bp BindingPower) ast.Ex
 func parseRangeExpr(p *Parser, left ast.Expression, bp BindingPower) ast.Expression {
 	p.move()
 	return ast.RangeExpression{
-		SourceLoc: ast.SourceLoc(p.currentToken().Src),
+		SourceLoc: left.GetSrc(),
 		Lower:     left,
 		Upper:     parseExpr(p, bp),
 	}
@@ -102,128 +96,139 @@ func parseBinaryExpr(p *Parser, left ast.Expression, _ BindingPower) ast.Express
 	right := parseExpr(p, op_bp-1)
 
 	return ast.BinaryExpression{
-		SourceLoc: ast.SourceLoc(p.currentToken().Src),
+		SourceLoc: ast.SourceLoc(operatorToken.Src),
 		Left:      left,
 		Operator:  operatorToken,
 		Right:     right,
 	}
 }
 func parsePrimaryExpr(p *Parser) ast.Expression {
+	start := ast.SourceLoc(p.currentToken().Src)
 	switch p.currentTokenKind() {
 	case lexer.NUMBER:
 		number, _ := strconv.ParseFloat(p.move().Value, 64)
 		return ast.NumberExpression{
-			SourceLoc: ast.SourceLoc(p.currentToken().Src),
+			SourceLoc: start,
 			Value:     number,
 		}
 	case lexer.STRING:
-		str := p.move().Value
-		unescaped, err := strconv.Unquote(str)
+		tk := p.move()
+		unescaped, err := strconv.Unquote(tk.Value)
 		if err != nil {
-			panic(fmt.Sprintf("unexpected str format %s", str))
+			p.errorf(tk, "invalid string literal %s", tk.Value)
 		}
 		return ast.StringExpression{
-			SourceLoc: ast.SourceLoc(p.currentToken().Src),
+			SourceLoc: start,
 			Value:     unescaped,
 		}
 	case lexer.IDENTIFIER:
 		return ast.SymbolExpression{
-			SourceLoc: ast.SourceLoc(p.currentToken().Src),
+			SourceLoc: start,
 			Value:     p.move().Value,
 		}
 	default:
-		panic(fmt.Sprintf("Cannot create primary_expr from %s\n", lexer.TokenKindString(p.currentTokenKind())))
+		p.errorf(p.currentToken(), "expected expression, found %s", describe(p.currentToken()))
+		return nil
 	}
 }
 
 func parseMemberExpr(p *Parser, left ast.Expression, bp BindingPower) ast.Expression {
-	isComputed := p.move().Kind == lexer.OPEN_BRACKET
+	open := p.move()
+	isComputed := open.Kind == lexer.OPEN_BRACKET
+	// a.b is located at b, a[i] at a
+	property := ast.SourceLoc(p.currentToken().Src)
 
 	if isComputed {
 		rhsList := make([]ast.Expression, 0)
-		rhsList = append(rhsList, parseExpr(p, bp))
-		for {
-			if p.currentTokenKind() == lexer.CLOSE_BRACKET {
-				break
-			}
-			p.expect(lexer.COMMA)
-			rhsList = append(rhsList, parseExpr(p, bp))
+		rhsList = append(rhsList, parseExpr(p, assignment))
+		for p.currentTokenKind() == lexer.COMMA {
+			p.move()
+			rhsList = append(rhsList, parseExpr(p, assignment))
 		}
-		p.expect(lexer.CLOSE_BRACKET)
+		p.expectClose(lexer.CLOSE_BRACKET, "index", open)
 		return ast.ComputedExpression{
-			SourceLoc: ast.SourceLoc(p.currentToken().Src),
+			SourceLoc: left.GetSrc(),
 			Member:    left,
 			Indices:   rhsList,
 		}
 	}
 
 	return ast.MemberExpression{
-		SourceLoc: ast.SourceLoc(p.currentToken().Src),
+		SourceLoc: property,
 		Member:    left,
 		Property:  p.expect(lexer.IDENTIFIER).Value,
 	}
 }
 
 func parseArrayLiteralExpr(p *Parser) ast.Expression {
-	p.expect(lexer.OPEN_BRACKET)
+	start := ast.SourceLoc(p.currentToken().Src)
+	open := p.expect(lexer.OPEN_BRACKET)
 	arrayContents := make([]ast.Expression, 0)
 
 	for p.hasTokens() && p.currentTokenKind() != lexer.CLOSE_BRACKET {
 		arrayContents = append(arrayContents, parseExpr(p, logical))
 
-		if !p.currentToken().IsOneOfMany(lexer.EOF, lexer.CLOSE_BRACKET) {
-			p.expect(lexer.COMMA)
+		if !p.currentToken().IsOneOfMany(lexer.EOF, lexer.CLOSE_BRACKET, lexer.COMMA) {
+			p.expectClose(lexer.CLOSE_BRACKET, "array literal", open)
+		}
+		if p.currentTokenKind() == lexer.COMMA {
+			p.move()
 		}
 	}
 
-	p.expect(lexer.CLOSE_BRACKET)
+	p.expectClose(lexer.CLOSE_BRACKET, "array literal", open)
 
 	return ast.ListExpression{
-		SourceLoc: ast.SourceLoc(p.currentToken().Src),
+		SourceLoc: start,
 		Constants: arrayContents,
 	}
 }
 
 func parseGroupingExpr(p *Parser) ast.Expression {
-	p.expect(lexer.OPEN_PAREN)
+	open := p.expect(lexer.OPEN_PAREN)
 	expr := parseExpr(p, default_bp)
-	p.expect(lexer.CLOSE_PAREN)
+	p.expectClose(lexer.CLOSE_PAREN, "parenthesized expression", open)
 	return expr
 }
 
 func parseNullExpr(p *Parser) ast.Expression {
+	start := ast.SourceLoc(p.currentToken().Src)
 	p.expect(lexer.NULL)
 	return ast.NullExpression{
-		SourceLoc: ast.SourceLoc(p.currentToken().Src),
+		SourceLoc: start,
 	}
 }
 
 func parseCallExpr(p *Parser, left ast.Expression, bp BindingPower) ast.Expression {
-	p.move()
+	open := p.move()
 	arguments := make([]ast.Expression, 0)
 
 	for p.hasTokens() && p.currentTokenKind() != lexer.CLOSE_PAREN {
 		arguments = append(arguments, parseExpr(p, assignment))
 
-		if !p.currentToken().IsOneOfMany(lexer.EOF, lexer.CLOSE_PAREN) {
-			p.expect(lexer.COMMA)
+		if !p.currentToken().IsOneOfMany(lexer.EOF, lexer.CLOSE_PAREN, lexer.COMMA) {
+			p.expectClose(lexer.CLOSE_PAREN, "call", open)
+		}
+		if p.currentTokenKind() == lexer.COMMA {
+			p.move()
 		}
 	}
 
-	p.expect(lexer.CLOSE_PAREN)
+	p.expectClose(lexer.CLOSE_PAREN, "call", open)
 	return ast.CallExpression{
-		SourceLoc: ast.SourceLoc(p.currentToken().Src),
+		SourceLoc: left.GetSrc(),
 		Method:    left,
 		Arguments: arguments,
 	}
 }
 
 func parseFuncExpr(p *Parser) ast.Expression {
+	start := ast.SourceLoc(p.currentToken().Src)
 	p.expect(lexer.FN)
 	functionParams, returnType, functionBody := parseFnParamsAndBody(p)
 
 	return ast.FunctionExpression{
-		SourceLoc:  ast.SourceLoc(p.currentToken().Src),
+		SourceLoc:  start,
 		Parameters: functionParams,
 		ReturnType: returnType,
 		Body:       functionBody,
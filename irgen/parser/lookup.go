@@ -22,6 +22,27 @@ const (
 	primary
 )
 
+// Binding powers for printers of expressions, see Precedence.
+const (
+	// AssignmentPower is the binding power call arguments, conditions and
+	// values of declarations are parsed with.
+	AssignmentPower = assignment
+	// ListPower is the binding power list literal elements are parsed with.
+	ListPower = logical
+	// UnaryPower is the binding power operands of prefix operators are
+	// parsed with.
+	UnaryPower = unary
+)
+
+// Precedence returns the binding power of kind following an expression, as
+// the parser applies it: an operator with a higher one than the expression
+// is parsed with extends it. Printers use it to decide where an expression
+// needs parentheses to parse back the same.
+func Precedence(kind lexer.TokenKind) BindingPower {
+	ensureTables()
+	return bp_table[kind]
+}
+
 type statement_handler func(p *Parser) ast.Statement
 type nudHandler func(p *Parser) ast.Expression
 type ledHandler func(p *Parser, left ast.Expression, bp BindingPower) ast.Expression
@@ -110,11 +131,15 @@ func BuildTokensTable() {
 	nud(lexer.OPEN_PAREN, parseGroupingExpr)
 	nud(lexer.FN, parseFuncExpr)
 	nud(lexer.NEW, func(p *Parser) ast.Expression {
-		p.move()
-		classInstantiation := parseExpr(p, default_bp)
+		newToken := p.move()
+		classInstantiation, ok := parseExpr(p, default_bp).(ast.CallExpression)
+		if !ok {
+			p.errorf(newToken, "expected constructor call after new, e.g. new Point(1, 2)")
+		}
 
 		return ast.NewExpression{
-			Instantiation: ast.ExpectExpr[ast.CallExpression](classInstantiation),
+			SourceLoc:     ast.SourceLoc(newToken.Src),
+			Instantiation: classInstantiation,
 		}
 	})
 
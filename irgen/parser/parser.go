@@ -1,48 +1,101 @@
 package parser
 
 import (
+	"io"
+	"sync"
+
 	"github.com/nagarajRPoojari/picasso/irgen/ast"
+	errorsx "github.com/nagarajRPoojari/picasso/irgen/error"
 	"github.com/nagarajRPoojari/picasso/irgen/lexer"
 )
 
 type Parser struct {
 	tokens []lexer.Token
 	pos    int
+
+	// diags receives the syntax errors.
+	diags *errorsx.Collector
+
+	// reachedEOF is set once an error has been reported at the end of the
+	// file.
+	reachedEOF bool
+}
+
+// buildTables fills the lookup tables once, as files may be parsed
+// concurrently.
+var buildTables sync.Once
+
+func ensureTables() {
+	buildTables.Do(func() {
+		BuildTokensTable()
+		BuildTypeTokensTable()
+	})
 }
 
-func createParser(tokens []lexer.Token) *Parser {
-	BuildTokensTable()
-	BuildTypeTokensTable()
+func createParser(diags *errorsx.Collector, tokens []lexer.Token) *Parser {
+	ensureTables()
 
 	p := &Parser{
 		tokens: tokens,
 		pos:    0,
+		diags:  diags,
 	}
 
 	return p
 }
 
-func ParseAll(path string) ast.BlockStatement {
-	tokens := lexer.Tokenize(path)
-	p := createParser(tokens)
-	body := make([]ast.Statement, 0)
+// ParseAll parses every statement in the file at path. Syntax errors are
+// recorded in diags and the statements containing them are replaced by
+// ast.BadStatement, so that all errors of a file are reported.
+func ParseAll(diags *errorsx.Collector, path string) (tree ast.BlockStatement) {
+	return parseAll(diags, lexer.Tokenize, path)
+}
+
+// ParseAllFrom is ParseAll for a file read from r. path only names the file
+// in diagnostics.
+func ParseAllFrom(diags *errorsx.Collector, path string, r io.Reader) ast.BlockStatement {
+	return parseAll(diags, readerTokenizer(r), path)
+}
+
+func parseAll(diags *errorsx.Collector, tokenize tokenizer, path string) (tree ast.BlockStatement) {
+	defer diags.Recover()
+
+	tokens := tokenize(diags, path)
+	p := createParser(diags, tokens)
+	tree.Body = make([]ast.Statement, 0)
 
 	for p.hasTokens() {
-		body = append(body, parseStmt(p))
+		tree.Body = append(tree.Body, parseStmtOrRecover(p))
 	}
 
-	return ast.BlockStatement{
-		Body: body,
-	}
+	return tree
 }
 
-func ParseImports(filePath string) ast.BlockStatement {
-	tokens := lexer.Tokenize(filePath)
-	p := createParser(tokens)
-	body := make([]ast.Statement, 0)
+// ParseTokens is ParseAll for tokens already read, e.g. by tools that need
+// the comments the lexer keeps with them.
+func ParseTokens(diags *errorsx.Collector, path string, tokens []lexer.Token) ast.BlockStatement {
+	return parseAll(diags, func(*errorsx.Collector, string) []lexer.Token { return tokens }, path)
+}
+
+// ParseImports parses the imports at the top of the file at path.
+func ParseImports(diags *errorsx.Collector, filePath string) (tree ast.BlockStatement) {
+	return parseImports(diags, lexer.Tokenize, filePath)
+}
+
+// ParseImportsFrom is ParseImports for a file read from r.
+func ParseImportsFrom(diags *errorsx.Collector, path string, r io.Reader) ast.BlockStatement {
+	return parseImports(diags, readerTokenizer(r), path)
+}
+
+func parseImports(diags *errorsx.Collector, tokenize tokenizer, filePath string) (tree ast.BlockStatement) {
+	defer diags.Recover()
+
+	tokens := tokenize(diags, filePath)
+	p := createParser(diags, tokens)
+	tree.Body = make([]ast.Statement, 0)
 
 	for p.hasTokens() {
-		stmt := parseStmt(p)
+		stmt := parseStmtOrRecover(p)
 		// as soon as I see non-import statement then stop parsing
 		// @todo: ideally don't accept source string, it could be too big
 		// I should read incrementely
@@ -50,10 +103,79 @@ func ParseImports(filePath string) ast.BlockStatement {
 		if _, ok := stmt.(ast.ImportStatement); !ok {
 			break
 		}
-		body = append(body, stmt)
+		tree.Body = append(tree.Body, stmt)
 	}
 
-	return ast.BlockStatement{
-		Body: body,
+	return tree
+}
+
+type tokenizer func(diags *errorsx.Collector, path string) []lexer.Token
+
+func readerTokenizer(r io.Reader) tokenizer {
+	return func(diags *errorsx.Collector, path string) []lexer.Token {
+		return lexer.TokenizeReader(diags, path, r)
+	}
+}
+
+// parseStmtOrRecover parses a statement. On a syntax error, which has already
+// been recorded, it skips to the start of the next statement and returns an
+// ast.BadStatement in place of the broken one.
+func parseStmtOrRecover(p *Parser) (stmt ast.Statement) {
+	start := p.pos
+	startToken := p.currentToken()
+
+	defer func() {
+		if r := recover(); r != nil {
+			if !p.diags.Caught(r) {
+				panic(r)
+			}
+			// always make progress, e.g. past a stray '}'
+			if p.pos == start {
+				p.move()
+			}
+			p.synchronize()
+			stmt = ast.BadStatement{SourceLoc: ast.SourceLoc(startToken.Src)}
+		}
+	}()
+
+	return parseStmt(p)
+}
+
+// synchronize skips tokens up to a point where parsing can resume: after a
+// ';', before a '}' closing the current block, or before a keyword starting a
+// statement. Blocks opened while skipping are skipped as a whole, so a broken
+// function signature drops its body rather than parsing it as top level code.
+func (p *Parser) synchronize() {
+	depth := 0
+	for p.hasTokens() {
+		switch kind := p.currentTokenKind(); {
+		case kind == lexer.OPEN_CURLY:
+			depth++
+
+		case kind == lexer.CLOSE_CURLY:
+			if depth == 0 {
+				return
+			}
+			depth--
+			if depth == 0 {
+				p.move()
+				return
+			}
+
+		case kind == lexer.SEMI_COLON && depth == 0:
+			p.move()
+			return
+
+		case depth == 0 && isStatementKeyword(kind):
+			return
+		}
+		p.move()
 	}
 }
+
+// isStatementKeyword reports whether kind starts a statement. A '{' starts
+// one too but is handled by synchronize itself.
+func isStatementKeyword(kind lexer.TokenKind) bool {
+	_, ok := statement_table[kind]
+	return ok && kind != lexer.OPEN_CURLY
+}
@@ -5,11 +5,19 @@ import (
 	"strings"
 
 	"github.com/nagarajRPoojari/picasso/irgen/ast"
-	errorsx "github.com/nagarajRPoojari/picasso/irgen/error"
 	"github.com/nagarajRPoojari/picasso/irgen/lexer"
 )
 
 func parseStmt(p *Parser) ast.Statement {
+	// blocks stop at their '}', so this one closes nothing
+	if p.currentTokenKind() == lexer.CLOSE_CURLY {
+		p.errorf(p.currentToken(), "unexpected '}' without a matching '{'")
+	}
+
+	if p.atTestFunc() {
+		return parseTestFunc(p)
+	}
+
 	stmt_fn, exists := statement_table[p.currentTokenKind()]
 
 	if exists {
@@ -20,6 +28,7 @@ func parseStmt(p *Parser) ast.Statement {
 }
 
 func parseExpressionStmt(p *Parser) ast.ExpressionStatement {
+	start := ast.SourceLoc(p.currentToken().Src)
 	// Parse first expression - for single assignments this will consume the whole thing
 	// For multi-assignments, we need to detect the pattern
 	firstExpr := parseExpr(p, default_bp)
@@ -29,7 +38,7 @@ func parseExpressionStmt(p *Parser) ast.ExpressionStatement {
 		// This is a single assignment that was already parsed
 		p.expect(lexer.SEMI_COLON)
 		return ast.ExpressionStatement{
-			SourceLoc:  ast.SourceLoc(p.currentToken().Src),
+			SourceLoc:  start,
 			Expression: assignExpr,
 		}
 	}
@@ -46,7 +55,7 @@ func parseExpressionStmt(p *Parser) ast.ExpressionStatement {
 
 		// Now we must have '=' for multi-assignment
 		if p.currentTokenKind() == lexer.ASSIGNMENT {
-			p.move() // consume '='
+			operator := p.move() // consume '='
 
 			// Parse RHS values
 			assignedValues := []ast.Expression{}
@@ -61,9 +70,10 @@ func parseExpressionStmt(p *Parser) ast.ExpressionStatement {
 
 			// Create multi-assignment expression
 			return ast.ExpressionStatement{
-				SourceLoc: ast.SourceLoc(p.currentToken().Src),
+				SourceLoc: start,
 				Expression: ast.AssignmentExpression{
-					SourceLoc:      ast.SourceLoc(p.currentToken().Src),
+					SourceLoc:      start,
+					Operator:       operator,
 					Assignees:      assignees,
 					AssignedValues: assignedValues,
 				},
@@ -74,27 +84,34 @@ func parseExpressionStmt(p *Parser) ast.ExpressionStatement {
 	// Regular expression statement (function call, etc.)
 	p.expect(lexer.SEMI_COLON)
 	return ast.ExpressionStatement{
-		SourceLoc:  ast.SourceLoc(p.currentToken().Src),
+		SourceLoc:  start,
 		Expression: firstExpr,
 	}
 }
 
 func parseBlockStmt(p *Parser) ast.Statement {
-	p.expect(lexer.OPEN_CURLY)
+	start := ast.SourceLoc(p.currentToken().Src)
+	open := p.expect(lexer.OPEN_CURLY)
 	body := []ast.Statement{}
 
 	for p.hasTokens() && p.currentTokenKind() != lexer.CLOSE_CURLY {
-		body = append(body, parseStmt(p))
+		body = append(body, parseStmtOrRecover(p))
 	}
 
-	p.expect(lexer.CLOSE_CURLY)
+	p.expectClose(lexer.CLOSE_CURLY, "block", open)
+	for _, stmt := range body {
+		if fn, ok := stmt.(ast.FunctionDefinitionStatement); ok && fn.IsTest {
+			p.report(fn.SourceLoc, "test functions must be declared at the top level")
+		}
+	}
 	return ast.BlockStatement{
-		SourceLoc: ast.SourceLoc(p.currentToken().Src),
+		SourceLoc: start,
 		Body:      body,
 	}
 }
 
 func parseVarDeclStmt(p *Parser) ast.Statement {
+	start := ast.SourceLoc(p.currentToken().Src)
 	p.expect(lexer.SAY)
 
 	var isInternal bool
@@ -111,18 +128,15 @@ func parseVarDeclStmt(p *Parser) ast.Statement {
 
 	// Parse first identifier
 	identifiers := []string{}
+	nameLocs := []ast.SourceLoc{}
 	explicitTypes := []ast.Type{}
 
 	symbolName := p.currentToken()
 	if p.currentTokenKind() != lexer.IDENTIFIER {
-		errorsx.PanicParserError(
-			"unexpected keyword in variable declaration",
-			p.currentToken().Src.FilePath,
-			p.currentToken().Src.Line,
-			p.currentToken().Src.Col,
-		)
+		p.errorf(symbolName, "expected variable name, found %s", describe(symbolName))
 	}
 	identifiers = append(identifiers, symbolName.Value)
+	nameLocs = append(nameLocs, ast.SourceLoc(symbolName.Src))
 	p.move()
 
 	// Check for multiple declarations: say a: int, b: int
@@ -145,8 +159,9 @@ func parseVarDeclStmt(p *Parser) ast.Statement {
 		for p.currentTokenKind() == lexer.COMMA {
 			p.move() // consume comma
 
-			nextName := p.expect(lexer.IDENTIFIER).Value
-			identifiers = append(identifiers, nextName)
+			nextName := p.expect(lexer.IDENTIFIER)
+			identifiers = append(identifiers, nextName.Value)
+			nameLocs = append(nameLocs, ast.SourceLoc(nextName.Src))
 
 			if p.currentTokenKind() == lexer.COLON {
 				p.move()
@@ -181,7 +196,7 @@ func parseVarDeclStmt(p *Parser) ast.Statement {
 			assignmentValues = append(assignmentValues, parseExpr(p, assignment))
 		}
 	} else if len(explicitTypes) == 0 || explicitTypes[0] == nil {
-		panic("Missing explicit type for variable declaration.")
+		p.errorf(p.currentToken(), "variable %s needs a type or an initial value", symbolName.Value)
 	}
 
 	p.expect(lexer.SEMI_COLON)
@@ -189,12 +204,7 @@ func parseVarDeclStmt(p *Parser) ast.Statement {
 	// Check for reserved keywords
 	for _, id := range identifiers {
 		if _, ok := reserved_keywords[id]; ok {
-			errorsx.PanicParserError(
-				"use of reserved keyword",
-				p.currentToken().Src.FilePath,
-				p.currentToken().Src.Line,
-				p.currentToken().Src.Col,
-			)
+			p.errorf(symbolName, "%s is a reserved keyword and cannot be used as a variable name", id)
 		}
 	}
 
@@ -210,8 +220,9 @@ func parseVarDeclStmt(p *Parser) ast.Statement {
 		}
 
 		return ast.VariableDeclarationStatement{
-			SourceLoc:     ast.SourceLoc(p.currentToken().Src),
+			SourceLoc:     start,
 			Identifier:    identifiers[0],
+			NameLoc:       nameLocs[0],
 			AssignedValue: assignmentValue,
 			ExplicitType:  explicitType,
 			IsStatic:      isStatic,
@@ -221,8 +232,9 @@ func parseVarDeclStmt(p *Parser) ast.Statement {
 
 	// Multiple variable declarations
 	return ast.VariableDeclarationStatement{
-		SourceLoc:      ast.SourceLoc(p.currentToken().Src),
+		SourceLoc:      start,
 		Identifiers:    identifiers,
+		NameLocs:       nameLocs,
 		ExplicitTypes:  explicitTypes,
 		AssignedValues: assignmentValues,
 		IsStatic:       isStatic,
@@ -233,9 +245,9 @@ func parseVarDeclStmt(p *Parser) ast.Statement {
 func parseFnParamsAndBody(p *Parser) ([]ast.Parameter, ast.Type, []ast.Statement) {
 	functionParams := make([]ast.Parameter, 0)
 
-	p.expect(lexer.OPEN_PAREN)
+	open := p.expect(lexer.OPEN_PAREN)
 	for p.hasTokens() && p.currentTokenKind() != lexer.CLOSE_PAREN {
-		paramName := p.expect(lexer.IDENTIFIER).Value
+		paramName := p.expect(lexer.IDENTIFIER)
 		p.expect(lexer.COLON)
 		var atomic bool
 		if p.currentTokenKind() == lexer.ATOMIC {
@@ -248,8 +260,9 @@ func parseFnParamsAndBody(p *Parser) ([]ast.Parameter, ast.Type, []ast.Statement
 			paramType.SetAtomic()
 		}
 		functionParams = append(functionParams, ast.Parameter{
-			Name: paramName,
-			Type: paramType,
+			Name:    paramName.Value,
+			Type:    paramType,
+			NameLoc: ast.SourceLoc(paramName.Src),
 		})
 
 		if !p.currentToken().IsOneOfMany(lexer.CLOSE_PAREN, lexer.EOF) {
@@ -257,7 +270,7 @@ func parseFnParamsAndBody(p *Parser) ([]ast.Parameter, ast.Type, []ast.Statement
 		}
 	}
 
-	p.expect(lexer.CLOSE_PAREN)
+	p.expectClose(lexer.CLOSE_PAREN, "parameter list", open)
 	var returnType ast.Type
 
 	if p.currentTokenKind() == lexer.COLON {
@@ -271,6 +284,7 @@ func parseFnParamsAndBody(p *Parser) ([]ast.Parameter, ast.Type, []ast.Statement
 }
 
 func parseFuncDeclaration(p *Parser) ast.Statement {
+	start := ast.SourceLoc(p.currentToken().Src)
 	p.move()
 	startToken := p.move()
 	var isStatic bool
@@ -289,27 +303,17 @@ func parseFuncDeclaration(p *Parser) ast.Statement {
 		if startToken.Kind == lexer.IDENTIFIER {
 			functionName = startToken.Value
 		} else {
-			errorsx.PanicParserError(
-				"unexpected keyword after fn",
-				p.currentToken().Src.FilePath,
-				p.currentToken().Src.Line,
-				p.currentToken().Src.Col,
-			)
+			p.errorf(startToken, "expected function name after fn, found %s", describe(startToken))
 		}
 	}
 	functionParams, returnType, functionBody := parseFnParamsAndBody(p)
 
 	if _, ok := reserved_keywords[functionName]; ok {
-		errorsx.PanicParserError(
-			"use of reserved keyword",
-			p.currentToken().Src.FilePath,
-			p.currentToken().Src.Line,
-			p.currentToken().Src.Col,
-		)
+		p.errorf(startToken, "%s is a reserved keyword and cannot be used as a function name", functionName)
 	}
 
 	return ast.FunctionDefinitionStatement{
-		SourceLoc:  ast.SourceLoc(p.currentToken().Src),
+		SourceLoc:  start,
 		Parameters: functionParams,
 		ReturnType: returnType,
 		Body:       functionBody,
@@ -320,6 +324,38 @@ func parseFuncDeclaration(p *Parser) ast.Statement {
 	}
 }
 
+// TEST starts a test function, test fn name() { ... }. It is a keyword only
+// in front of fn, so that test remains usable as a name.
+const TEST = "test"
+
+// atTestFunc reports whether the current token starts a test function.
+func (p *Parser) atTestFunc() bool {
+	if tk := p.currentToken(); tk.Kind != lexer.IDENTIFIER || tk.Value != TEST {
+		return false
+	}
+	return p.pos+1 < len(p.tokens) && p.tokens[p.pos+1].Kind == lexer.FN
+}
+
+// parseTestFunc parses a test function, run by irgen test. Tests live in
+// *_test.pic files and take no parameters.
+func parseTestFunc(p *Parser) ast.Statement {
+	testToken := p.move()
+	if !strings.HasSuffix(testToken.Src.FilePath, "_test.pic") {
+		p.errorf(testToken, "test functions are only allowed in _test.pic files")
+	}
+	fnToken := p.currentToken()
+	fn := parseFuncDeclaration(p).(ast.FunctionDefinitionStatement)
+	if fn.IsStatic || fn.IsInternal {
+		p.errorf(fnToken, "test function %s cannot be static or internal", fn.Name)
+	}
+	if len(fn.Parameters) != 0 || fn.ReturnType != nil {
+		p.errorf(fnToken, "test function %s must have no parameters and no return type", fn.Name)
+	}
+	fn.SourceLoc = ast.SourceLoc(testToken.Src)
+	fn.IsTest = true
+	return fn
+}
+
 func funcHash(params []ast.Parameter, ret ast.Type) uint32 {
 	var s = ""
 	if ret != nil {
@@ -335,6 +371,7 @@ func funcHash(params []ast.Parameter, ret ast.Type) uint32 {
 }
 
 func parseIfStmt(p *Parser) ast.Statement {
+	start := ast.SourceLoc(p.currentToken().Src)
 	p.move()
 	condition := parseExpr(p, assignment)
 	consequent := parseBlockStmt(p)
@@ -351,7 +388,7 @@ func parseIfStmt(p *Parser) ast.Statement {
 	}
 
 	return ast.IfStatement{
-		SourceLoc:  ast.SourceLoc(p.currentToken().Src),
+		SourceLoc:  start,
 		Condition:  condition,
 		Consequent: consequent,
 		Alternate:  alternate,
@@ -359,14 +396,19 @@ func parseIfStmt(p *Parser) ast.Statement {
 }
 
 func parseImportStmt(p *Parser) ast.Statement {
+	start := ast.SourceLoc(p.currentToken().Src)
 	p.move()
 	var importAlias string
-	importName := strings.ReplaceAll(p.expect(lexer.STRING).Value, "/", ".")
+	path := p.expect(lexer.STRING)
+	nameLoc := ast.SourceLoc(path.Src)
+	importName := strings.ReplaceAll(path.Value, "/", ".")
 	importName = importName[1 : len(importName)-1]
 
 	if p.currentTokenKind() == lexer.AS {
 		p.move()
-		importAlias = p.expect(lexer.IDENTIFIER).Value
+		alias := p.expect(lexer.IDENTIFIER)
+		importAlias = alias.Value
+		nameLoc = ast.SourceLoc(alias.Src)
 	} else {
 		paths := strings.Split(importName, ".")
 		importAlias = paths[len(paths)-1]
@@ -374,20 +416,23 @@ func parseImportStmt(p *Parser) ast.Statement {
 
 	p.expect(lexer.SEMI_COLON)
 	return ast.ImportStatement{
-		SourceLoc: ast.SourceLoc(p.currentToken().Src),
+		SourceLoc: start,
 		Name:      importName,
 		Alias:     importAlias,
+		NameLoc:   nameLoc,
 	}
 }
 
 func parseForeachStmt(p *Parser) ast.Statement {
+	start := ast.SourceLoc(p.currentToken().Src)
 	p.move()
 	valueName := p.expect(lexer.IDENTIFIER).Value
 
 	var index bool
+	var indexName string
 	if p.currentTokenKind() == lexer.COMMA {
 		p.expect(lexer.COMMA)
-		p.expect(lexer.IDENTIFIER)
+		indexName = p.expect(lexer.IDENTIFIER).Value
 		index = true
 	}
 
@@ -396,27 +441,30 @@ func parseForeachStmt(p *Parser) ast.Statement {
 	body := ast.ExpectStmt[ast.BlockStatement](parseBlockStmt(p)).Body
 
 	return ast.ForeachStatement{
-		SourceLoc: ast.SourceLoc(p.currentToken().Src),
+		SourceLoc: start,
 		Value:     valueName,
 		Index:     index,
+		IndexName: indexName,
 		Iterable:  iterable,
 		Body:      body,
 	}
 }
 
 func parseWhileStmt(p *Parser) ast.Statement {
+	start := ast.SourceLoc(p.currentToken().Src)
 	p.move()
 	condition := parseExpr(p, assignment)
 	body := ast.ExpectStmt[ast.BlockStatement](parseBlockStmt(p)).Body
 
 	return ast.WhileStatement{
-		SourceLoc: ast.SourceLoc(p.currentToken().Src),
+		SourceLoc: start,
 		Condition: condition,
 		Body:      body,
 	}
 }
 
 func parseClassDeclStmt(p *Parser) ast.Statement {
+	start := ast.SourceLoc(p.currentToken().Src)
 	p.move()
 	var isInternal bool
 	if p.currentTokenKind() == lexer.INTERNAL {
@@ -437,7 +485,7 @@ func parseClassDeclStmt(p *Parser) ast.Statement {
 	classBody := parseBlockStmt(p)
 
 	return ast.ClassDeclarationStatement{
-		SourceLoc:  ast.SourceLoc(p.currentToken().Src),
+		SourceLoc:  start,
 		Name:       className,
 		Body:       ast.ExpectStmt[ast.BlockStatement](classBody).Body,
 		Implements: implements,
@@ -446,31 +494,33 @@ func parseClassDeclStmt(p *Parser) ast.Statement {
 }
 
 func parseInterfaceDeclStmt(p *Parser) ast.Statement {
+	start := ast.SourceLoc(p.currentToken().Src)
 	p.move()
 	interfaceName := p.expect(lexer.IDENTIFIER).Value
 	interfaceBody := parseBlockStmt(p)
 
 	return ast.InterfaceDeclarationStatement{
-		SourceLoc: ast.SourceLoc(p.currentToken().Src),
+		SourceLoc: start,
 		Name:      interfaceName,
 		Body:      ast.ExpectStmt[ast.BlockStatement](interfaceBody).Body,
 	}
 }
 
 func parseFuncReturnStmt(p *Parser) ast.Statement {
+	start := ast.SourceLoc(p.currentToken().Src)
 	p.expect(lexer.RETURN)
 
 	if p.currentTokenKind() == lexer.NULL {
 		p.move()
 		p.expect(lexer.SEMI_COLON)
 		return ast.ReturnStatement{
-			SourceLoc: ast.SourceLoc(p.currentToken().Src),
+			SourceLoc: start,
 		}
 	}
 	if p.currentTokenKind() == lexer.SEMI_COLON {
 		p.move()
 		return ast.ReturnStatement{
-			SourceLoc: ast.SourceLoc(p.currentToken().Src),
+			SourceLoc: start,
 			IsVoid:    true,
 		}
 	}
@@ -489,9 +539,9 @@ func parseFuncReturnStmt(p *Parser) ast.Statement {
 	// If single value, use old format for backward compatibility
 	if len(values) == 1 {
 		return ast.ReturnStatement{
-			SourceLoc: ast.SourceLoc(p.currentToken().Src),
+			SourceLoc: start,
 			Value: ast.ExpressionStatement{
-				SourceLoc:  ast.SourceLoc(p.currentToken().Src),
+				SourceLoc:  start,
 				Expression: values[0],
 			},
 		}
@@ -499,31 +549,33 @@ func parseFuncReturnStmt(p *Parser) ast.Statement {
 
 	// Multiple values
 	return ast.ReturnStatement{
-		SourceLoc: ast.SourceLoc(p.currentToken().Src),
+		SourceLoc: start,
 		Values:    values,
 	}
 }
 
 func parseBreakStmt(p *Parser) ast.Statement {
+	start := ast.SourceLoc(p.currentToken().Src)
 	p.expect(lexer.BREAK)
 	p.expect(lexer.SEMI_COLON)
 
 	return ast.BreakStatement{
-		SourceLoc: ast.SourceLoc(p.currentToken().Src),
+		SourceLoc: start,
 	}
 }
 
 func parseAtomicBlockStmt(p *Parser) ast.Statement {
-	p.expect(lexer.OPEN_ATOMIC)
+	start := ast.SourceLoc(p.currentToken().Src)
+	open := p.expect(lexer.OPEN_ATOMIC)
 	body := []ast.Statement{}
 
 	for p.hasTokens() && p.currentTokenKind() != lexer.CLOSE_ATOMIC {
-		body = append(body, parseStmt(p))
+		body = append(body, parseStmtOrRecover(p))
 	}
 
-	p.expect(lexer.CLOSE_ATOMIC)
+	p.expectClose(lexer.CLOSE_ATOMIC, "atomic block", open)
 	return ast.AtomicBlockStatement{
-		SourceLoc: ast.SourceLoc(p.currentToken().Src),
+		SourceLoc: start,
 		Body:      body,
 	}
 }
@@ -1,7 +1,6 @@
 package parser
 
 import (
-	"fmt"
 	"strings"
 
 	"github.com/nagarajRPoojari/picasso/irgen/ast"
@@ -49,18 +48,21 @@ func BuildTypeTokensTable() {
 
 	// Support for tuple types: (type1, type2, ...)
 	typeNud(lexer.OPEN_PAREN, primary, func(p *Parser) ast.Type {
-		p.move() // consume '('
+		open := p.move() // consume '('
 		types := []ast.Type{}
 
 		for p.hasTokens() && p.currentTokenKind() != lexer.CLOSE_PAREN {
 			types = append(types, parse_type(p, default_bp))
 
 			if p.currentTokenKind() != lexer.CLOSE_PAREN {
-				p.expect(lexer.COMMA)
+				if p.currentTokenKind() != lexer.COMMA {
+					p.expectClose(lexer.CLOSE_PAREN, "tuple type", open)
+				}
+				p.move()
 			}
 		}
 
-		p.expect(lexer.CLOSE_PAREN)
+		p.expectClose(lexer.CLOSE_PAREN, "tuple type", open)
 
 		// If only one type, return it directly (not a tuple)
 		if len(types) == 1 {
@@ -78,7 +80,7 @@ func parse_type(p *Parser, bp BindingPower) ast.Type {
 	nud_fn, exists := typeNudTable[tokenKind]
 
 	if !exists {
-		panic(fmt.Sprintf("type: NUD Handler expected for token %s\n", lexer.TokenKindString(tokenKind)))
+		p.errorf(p.currentToken(), "expected type, found %s", describe(p.currentToken()))
 	}
 
 	var identifierList []string
@@ -102,7 +104,7 @@ func parse_type(p *Parser, bp BindingPower) ast.Type {
 		led_fn, exists := typeLedTable[tokenKind]
 
 		if !exists {
-			panic(fmt.Sprintf("type: LED Handler expected for token %s\n", lexer.TokenKindString(tokenKind)))
+			break
 		}
 
 		left = led_fn(p, left, bp)
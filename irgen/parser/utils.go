@@ -3,20 +3,24 @@ package parser
 import (
 	"fmt"
 
+	"github.com/nagarajRPoojari/picasso/irgen/ast"
 	errorsx "github.com/nagarajRPoojari/picasso/irgen/error"
 	"github.com/nagarajRPoojari/picasso/irgen/lexer"
 )
 
 func (t *Parser) currentToken() lexer.Token {
 	if t.pos >= len(t.tokens) {
-		return t.tokens[t.pos-1]
+		return t.tokens[len(t.tokens)-1]
 	}
 	return t.tokens[t.pos]
 }
 
+// move consumes the current token. The parser never moves past EOF.
 func (t *Parser) move() lexer.Token {
 	tk := t.currentToken()
-	t.pos++
+	if tk.Kind != lexer.EOF {
+		t.pos++
+	}
 	return tk
 }
 
@@ -25,28 +29,89 @@ func (t *Parser) hasTokens() bool {
 }
 
 func (t *Parser) currentTokenKind() lexer.TokenKind {
-	return t.tokens[t.pos].Kind
+	return t.currentToken().Kind
 }
 
-func (t *Parser) expectError(expectedKind lexer.TokenKind, err any) lexer.Token {
-	token := t.currentToken()
-	kind := token.Kind
-
-	if kind != expectedKind {
-		if err == nil {
-			errString := fmt.Sprintf("Expected %s but recieved %s instead\n", lexer.TokenKindString(expectedKind), lexer.TokenKindString(kind))
-			errorsx.PanicParserError(
-				errString,
-				t.currentToken().Src.FilePath,
-				t.currentToken().Src.Line,
-				t.currentToken().Src.Col)
-		}
+// errorf reports a syntax error at tk and unwinds to the enclosing
+// statement, see parseStmtOrRecover.
+func (t *Parser) errorf(tk lexer.Token, format string, args ...any) {
+	if tk.Kind == lexer.EOF {
+		t.reachedEOF = true
 	}
+	errorsx.PanicParserError(fmt.Sprintf(format, args...), tk.Src.FilePath, tk.Src.Line, tk.Src.Col)
+}
 
-	return t.move()
+// report records a syntax error at loc without unwinding, for errors found
+// once a construct has been parsed.
+func (t *Parser) report(loc ast.SourceLoc, format string, args ...any) {
+	t.diags.Add(errorsx.Diagnostic{
+		Phase:   errorsx.PhaseParser,
+		Code:    errorsx.CodeSyntax,
+		Message: fmt.Sprintf(format, args...),
+		Path:    loc.FilePath,
+		Line:    loc.Line,
+		Col:     loc.Col,
+	})
 }
 
 // expect will consume current token
 func (t *Parser) expect(expectedKind lexer.TokenKind) lexer.Token {
-	return t.expectError(expectedKind, nil)
+	token := t.currentToken()
+	if token.Kind == expectedKind || t.skipAtEOF(token) {
+		return t.move()
+	}
+
+	// a ';' missing at the end of a line is reported there and assumed,
+	// so that the statement on the next line is still parsed
+	if expectedKind == lexer.SEMI_COLON && t.pos > 0 {
+		prev := t.tokens[t.pos-1]
+		if prev.Src.Line != token.Src.Line {
+			t.diags.Add(errorsx.Diagnostic{
+				Phase:   errorsx.PhaseParser,
+				Code:    errorsx.CodeSyntax,
+				Message: fmt.Sprintf("expected ';' after %s", describe(prev)),
+				Path:    prev.Src.FilePath,
+				Line:    prev.Src.Line,
+				Col:     prev.Src.Col + len(prev.Value),
+			})
+			return lexer.Token{Kind: lexer.SEMI_COLON, Value: ";", Src: prev.Src}
+		}
+	}
+	t.errorf(token, "expected %s, found %s", quote(expectedKind), describe(token))
+	return token
+}
+
+// expectClose consumes the token closing the construct opened by open,
+// e.g. the ')' of a call.
+func (t *Parser) expectClose(expectedKind lexer.TokenKind, construct string, open lexer.Token) lexer.Token {
+	token := t.currentToken()
+	if token.Kind == expectedKind || t.skipAtEOF(token) {
+		return t.move()
+	}
+	t.errorf(token, "expected %s to close %s started at %d:%d, found %s",
+		quote(expectedKind), construct, open.Src.Line, open.Src.Col, describe(token))
+	return token
+}
+
+// skipAtEOF reports whether a missing token at tk can be ignored because an
+// error has already been reported at the end of the file. Every construct
+// still open there would otherwise report its own missing closer.
+func (t *Parser) skipAtEOF(tk lexer.Token) bool {
+	return tk.Kind == lexer.EOF && t.reachedEOF
+}
+
+// quote spells kind for messages, e.g. ';'.
+func quote(kind lexer.TokenKind) string {
+	return fmt.Sprintf("'%s'", lexer.TokenKindSymbol(kind))
+}
+
+// describe names tk for messages, e.g. ';' or identifier foo.
+func describe(tk lexer.Token) string {
+	switch tk.Kind {
+	case lexer.EOF:
+		return "end of file"
+	case lexer.IDENTIFIER, lexer.NUMBER, lexer.STRING:
+		return fmt.Sprintf("%s %s", lexer.TokenKindString(tk.Kind), tk.Value)
+	}
+	return fmt.Sprintf("'%s'", tk.Value)
 }
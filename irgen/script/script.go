@@ -0,0 +1,177 @@
+// Package script builds and runs a standalone .pic file, e.g. a small tool
+// or the reproduction of a bug report, without a project around it. The
+// file is the start package, whatever its name, and may only import builtin
+// packages. It is compiled into a work directory between the steps of the
+// picasso CLI that picasso build runs around irgen gen: picasso ffi, which
+// generates the IR of the builtin C modules, and picasso link.
+package script
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+
+	"github.com/nagarajRPoojari/picasso/irgen/ast"
+	generator "github.com/nagarajRPoojari/picasso/irgen/codegen"
+	errorsx "github.com/nagarajRPoojari/picasso/irgen/error"
+	"github.com/nagarajRPoojari/picasso/irgen/parser"
+)
+
+// Executable is the path of the program in the work directory once linked.
+var Executable = filepath.Join(generator.BUILD, "a.out")
+
+// Options configures Run.
+type Options struct {
+	Generator generator.Options
+	// Work is the directory the file is built in, kept afterwards. A
+	// temporary directory, removed once done, is used if empty.
+	Work string
+	// FFI generates the IR of the builtin C modules into buildDir/tmp. It
+	// defaults to FFI.
+	FFI func(ctx context.Context, buildDir string) error
+	// Link turns the IR in buildDir into the executable buildDir/a.out.
+	// It defaults to Link.
+	Link func(ctx context.Context, buildDir string) error
+
+	// Stdin, Stdout and Stderr of the program default to those of the
+	// process.
+	Stdin          io.Reader
+	Stdout, Stderr io.Writer
+}
+
+// Compile generates the IR of the file at path into buildDir. Compile
+// errors, e.g. an import of a package that is not builtin, are recorded in
+// opts.Diagnostics and reported as generator.ErrCompile.
+func Compile(path, buildDir string, opts generator.Options) error {
+	if _, err := os.Stat(path); err != nil {
+		return err
+	}
+	if opts.Diagnostics == nil {
+		opts.Diagnostics = errorsx.Diagnostics
+	}
+	diags := opts.Diagnostics
+	tree := parser.ParseAll(diags, path)
+	if diags.Errors() > 0 {
+		return generator.ErrCompile
+	}
+
+	hasStart := false
+	for _, stI := range tree.Body {
+		switch st := stI.(type) {
+		case ast.ImportStatement:
+			if !st.IsBuiltIn() {
+				diags.Add(errorsx.Diagnostic{
+					Phase:   errorsx.PhaseCompilation,
+					Message: fmt.Sprintf("cannot import %s in a single file, only builtin packages; make it a project to import other packages", st.Name),
+					Path:    path,
+					Line:    st.Line,
+					Col:     st.Col,
+				})
+			}
+		case ast.FunctionDefinitionStatement:
+			hasStart = hasStart || st.Name == generator.MAIN
+		}
+	}
+	if diags.Errors() > 0 {
+		return generator.ErrCompile
+	}
+	if !hasStart {
+		return fmt.Errorf("%s: no fn %s(args: []string) to run", path, generator.MAIN)
+	}
+
+	g := generator.NewGeneratorFor(map[string]ast.BlockStatement{generator.MAIN: tree}, buildDir, opts)
+	return g.BuildAll()
+}
+
+// FFI runs picasso ffi on buildDir.
+func FFI(ctx context.Context, buildDir string) error {
+	return runCLI(ctx, "ffi", buildDir)
+}
+
+// Link runs picasso link on buildDir.
+func Link(ctx context.Context, buildDir string) error {
+	return runCLI(ctx, "link", buildDir)
+}
+
+// runCLI runs step of the picasso CLI, $PICASSO or picasso looked up in
+// PATH, on buildDir.
+func runCLI(ctx context.Context, step, buildDir string) error {
+	cli := os.Getenv("PICASSO")
+	if cli == "" {
+		cli = "picasso"
+	}
+	out, err := exec.CommandContext(ctx, cli, step, buildDir).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s %s: %w\n%s", cli, step, err, out)
+	}
+	return nil
+}
+
+// Run builds the file at path and runs it with args, which start gets
+// after the path of the file. It returns the exit status of the program,
+// 128 plus the signal for a program killed by one. The error reports a
+// file that cannot be built or run, not a program that fails.
+func Run(ctx context.Context, path string, args []string, opts Options) (int, error) {
+	work := opts.Work
+	if work == "" {
+		var err error
+		if work, err = os.MkdirTemp("", "picasso-run-"); err != nil {
+			return 0, err
+		}
+		defer os.RemoveAll(work)
+	}
+	// the CLI is run elsewhere
+	work, err := filepath.Abs(work)
+	if err != nil {
+		return 0, err
+	}
+	buildDir := filepath.Join(work, generator.BUILD)
+	if err := os.MkdirAll(filepath.Join(buildDir, "tmp"), 0o755); err != nil {
+		return 0, err
+	}
+
+	ffi, link := opts.FFI, opts.Link
+	if ffi == nil {
+		ffi = FFI
+	}
+	if link == nil {
+		link = Link
+	}
+	if err := ffi(ctx, buildDir); err != nil {
+		return 0, err
+	}
+	if err := Compile(path, buildDir, opts.Generator); err != nil {
+		return 0, err
+	}
+	if err := link(ctx, buildDir); err != nil {
+		return 0, err
+	}
+
+	cmd := exec.CommandContext(ctx, filepath.Join(work, Executable), args...)
+	cmd.Args[0] = path
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = opts.Stdin, opts.Stdout, opts.Stderr
+	if cmd.Stdin == nil {
+		cmd.Stdin = os.Stdin
+	}
+	if cmd.Stdout == nil {
+		cmd.Stdout = os.Stdout
+	}
+	if cmd.Stderr == nil {
+		cmd.Stderr = os.Stderr
+	}
+
+	err = cmd.Run()
+	var exit *exec.ExitError
+	if errors.As(err, &exit) {
+		if ws, ok := exit.Sys().(syscall.WaitStatus); ok && ws.Signaled() {
+			return 128 + int(ws.Signal()), nil
+		}
+		return exit.ExitCode(), nil
+	}
+	return 0, err
+}
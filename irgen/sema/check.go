@@ -0,0 +1,180 @@
+package sema
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/nagarajRPoojari/picasso/irgen/ast"
+	errorutils "github.com/nagarajRPoojari/picasso/irgen/codegen/error"
+	errorsx "github.com/nagarajRPoojari/picasso/irgen/error"
+)
+
+// checker holds the state of a single Check run.
+type checker struct {
+	info *Info
+	// diags receives the errors and warnings.
+	diags *errorsx.Collector
+
+	// imports maps each package to its import aliases. A package can always
+	// refer to itself by its own name.
+	imports map[string]map[string]importEntry
+
+	// typeNames lists all classes and interfaces in a stable order.
+	typeNames []string
+
+	// targets are the packages whose bodies are checked and for which
+	// errors are reported.
+	targets map[string]struct{}
+	// muted discards errors, see mute.
+	muted bool
+
+	// reads holds the local symbols whose value is used, as opposed to
+	// only assigned to.
+	reads map[*Symbol]bool
+	// usedImports holds the import aliases referred to by each package.
+	usedImports map[string]map[string]bool
+
+	// state of the function being checked
+	pkg    string
+	ret    ast.Type
+	scopes *scope
+	loops  []*loop
+}
+
+// Check resolves and type checks the given packages, keyed by package name
+// (e.g. start, os.io). Declarations of every package are collected so that
+// imports resolve, but only the bodies of targets are checked; with no
+// targets every package is. Errors and warnings are added to diags.
+//
+// Check is conservative: an expression whose type cannot be determined,
+// such as the result of a builtin lib call, is accepted everywhere.
+func Check(diags *errorsx.Collector, pkgs map[string]ast.BlockStatement, targets ...string) *Info {
+	c := &checker{
+		info:    newInfo(pkgs),
+		diags:   diags,
+		imports: make(map[string]map[string]importEntry),
+		targets: make(map[string]struct{}),
+
+		reads:       make(map[*Symbol]bool),
+		usedImports: make(map[string]map[string]bool),
+	}
+	if len(targets) == 0 {
+		for name := range pkgs {
+			targets = append(targets, name)
+		}
+	}
+	for _, name := range targets {
+		c.targets[name] = struct{}{}
+	}
+
+	names := c.packageNames()
+	for _, name := range names {
+		c.collectImports(name, pkgs[name])
+	}
+	for _, name := range names {
+		c.collectTypes(name, pkgs[name])
+	}
+	for fq := range c.info.Classes {
+		c.typeNames = append(c.typeNames, fq)
+	}
+	for fq := range c.info.Interfaces {
+		c.typeNames = append(c.typeNames, fq)
+	}
+	sort.Strings(c.typeNames)
+
+	for _, name := range names {
+		c.collectMembers(name, pkgs[name])
+	}
+	for _, name := range names {
+		if c.isTarget(name) {
+			c.checkImplements(name, pkgs[name])
+		}
+	}
+	for _, name := range names {
+		if c.isTarget(name) {
+			c.checkPackage(name, pkgs[name])
+			c.checkImportsUsed(name)
+		}
+	}
+	return c.info
+}
+
+// packageNames returns the package names in a stable order so that
+// diagnostics and suffix matches do not depend on map iteration.
+func (c *checker) packageNames() []string {
+	names := make([]string, 0, len(c.info.Packages))
+	for name := range c.info.Packages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (c *checker) isTarget(pkg string) bool {
+	_, ok := c.targets[pkg]
+	return ok
+}
+
+// errorf records an error at loc.
+func (c *checker) errorf(loc ast.SourceLoc, msg string, args ...any) {
+	c.errorWithNotes(loc, nil, msg, args...)
+}
+
+func (c *checker) errorWithNotes(loc ast.SourceLoc, notes []errorsx.Note, msg string, args ...any) {
+	if c.muted {
+		return
+	}
+	code := errorutils.Code(msg)
+	if len(args) > 0 {
+		msg = fmt.Sprintf(msg, args...)
+	}
+	c.diags.Add(errorsx.Diagnostic{
+		Phase:   errorsx.PhaseSemantic,
+		Code:    code,
+		Message: msg,
+		Path:    loc.FilePath,
+		Line:    loc.Line,
+		Col:     loc.Col,
+		Notes:   notes,
+	})
+}
+
+// libraryPrefix starts the package names of the libraries linked into every
+// project under picasso/.
+const libraryPrefix = "picasso."
+
+// warn records warning w at loc, see errorsx.Collector.Warn. Library code is
+// not the user's to fix, so it is never warned about.
+func (c *checker) warn(w errorsx.Warning, loc ast.SourceLoc, notes []errorsx.Note, format string, args ...any) {
+	if c.muted || strings.HasPrefix(c.pkg, libraryPrefix) {
+		return
+	}
+	c.diags.Warn(w, errorsx.Diagnostic{
+		Phase:   errorsx.PhaseSemantic,
+		Message: fmt.Sprintf(format, args...),
+		Path:    loc.FilePath,
+		Line:    loc.Line,
+		Col:     loc.Col,
+		Notes:   notes,
+	})
+}
+
+// noteAt builds a note pointing at loc.
+func noteAt(loc ast.SourceLoc, msg string, args ...any) errorsx.Note {
+	return errorsx.Note{
+		Message: fmt.Sprintf(msg, args...),
+		Path:    loc.FilePath,
+		Line:    loc.Line,
+		Col:     loc.Col,
+	}
+}
+
+// redeclared reports name as already declared at prev.
+func (c *checker) redeclared(loc ast.SourceLoc, prev ast.SourceLoc, msg string, name string) {
+	var notes []errorsx.Note
+	if prev.FilePath != "" {
+		notes = append(notes, noteAt(prev, errorutils.PreviousDeclaration, name))
+	}
+	c.errorWithNotes(loc, notes, msg, name)
+}
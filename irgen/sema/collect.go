@@ -0,0 +1,349 @@
+package sema
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/nagarajRPoojari/picasso/irgen/ast"
+	errorutils "github.com/nagarajRPoojari/picasso/irgen/codegen/error"
+	errorsx "github.com/nagarajRPoojari/picasso/irgen/error"
+)
+
+// collectImports records the import aliases of pkg.
+func (c *checker) collectImports(pkg string, tree ast.BlockStatement) {
+	aliases := map[string]importEntry{pkg: {kind: userImport, name: pkg}}
+	for _, stI := range tree.Body {
+		st, ok := stI.(ast.ImportStatement)
+		if !ok {
+			continue
+		}
+		switch {
+		case st.IsBuiltIn():
+			aliases[st.Alias] = importEntry{kind: libImport, name: st.EndName(), loc: nameLoc(st.NameLoc, st.SourceLoc)}
+		case st.IsFFI():
+			aliases[st.Alias] = importEntry{kind: ffiImport, name: st.EndName(), loc: nameLoc(st.NameLoc, st.SourceLoc)}
+		default:
+			aliases[st.Alias] = importEntry{kind: userImport, name: st.Name, loc: nameLoc(st.NameLoc, st.SourceLoc)}
+		}
+	}
+	c.imports[pkg] = aliases
+}
+
+// collectTypes registers the classes and interfaces declared in pkg, without
+// their members, so that member types can refer to any of them.
+func (c *checker) collectTypes(pkg string, tree ast.BlockStatement) {
+	defer c.mute(!c.isTarget(pkg))()
+	for _, stI := range tree.Body {
+		switch st := stI.(type) {
+		case ast.ClassDeclarationStatement:
+			fq := fmt.Sprintf("%s.%s", pkg, st.Name)
+			if prev, ok := c.declOf(fq); ok {
+				c.redeclared(st.SourceLoc, prev, errorutils.TypeRedeclaration, fq)
+				continue
+			}
+			c.info.Classes[fq] = &Class{
+				Name:     fq,
+				Package:  pkg,
+				Decl:     st.SourceLoc,
+				Internal: st.IsInternal,
+				Fields:   make(map[string]*Symbol),
+				Methods:  make(map[string]*Method),
+			}
+
+		case ast.InterfaceDeclarationStatement:
+			fq := fmt.Sprintf("%s.%s", pkg, st.Name)
+			if prev, ok := c.declOf(fq); ok {
+				c.redeclared(st.SourceLoc, prev, errorutils.TypeRedeclaration, fq)
+				continue
+			}
+			c.info.Interfaces[fq] = &Interface{
+				Name:    fq,
+				Package: pkg,
+				Decl:    st.SourceLoc,
+				Methods: make(map[string]*Method),
+			}
+		}
+	}
+}
+
+func (c *checker) declOf(fq string) (ast.SourceLoc, bool) {
+	if cls, ok := c.info.Classes[fq]; ok {
+		return cls.Decl, true
+	}
+	if ifs, ok := c.info.Interfaces[fq]; ok {
+		return ifs.Decl, true
+	}
+	return ast.SourceLoc{}, false
+}
+
+// collectMembers resolves the fields and method signatures of the classes
+// and interfaces declared in pkg.
+func (c *checker) collectMembers(pkg string, tree ast.BlockStatement) {
+	defer c.mute(!c.isTarget(pkg))()
+	for _, stI := range tree.Body {
+		switch st := stI.(type) {
+		case ast.ClassDeclarationStatement:
+			cls := c.info.Classes[fmt.Sprintf("%s.%s", pkg, st.Name)]
+			if cls == nil || cls.Decl != st.SourceLoc {
+				continue // redeclared
+			}
+			if st.Implements != "" {
+				fq, ok := c.resolveTypeName(pkg, st.Implements)
+				_, isInterface := c.info.Interfaces[fq]
+				switch {
+				case isInterface:
+					cls.Implements = fq
+				case !ok || fq != "":
+					// a class or a name that does not exist at all
+					c.errorf(st.SourceLoc, errorutils.UnknownInterfaceError, st.Implements)
+				}
+			}
+
+			for _, memberI := range st.Body {
+				switch member := memberI.(type) {
+				case ast.VariableDeclarationStatement:
+					for _, field := range splitDeclaration(member) {
+						c.addField(pkg, cls, field)
+					}
+				case ast.FunctionDefinitionStatement:
+					m := c.method(pkg, cls.Name, member.Name, member.SourceLoc, member.Parameters, member.ReturnType)
+					m.Internal = member.IsInternal
+					m.Static = member.IsStatic
+					if prev, ok := cls.Methods[member.Name]; ok {
+						c.redeclared(member.SourceLoc, prev.Decl, errorutils.MethodRedeclaration, m.Owner+"."+m.Name)
+						continue
+					}
+					cls.Methods[member.Name] = m
+				}
+			}
+
+		case ast.InterfaceDeclarationStatement:
+			ifs := c.info.Interfaces[fmt.Sprintf("%s.%s", pkg, st.Name)]
+			if ifs == nil || ifs.Decl != st.SourceLoc {
+				continue
+			}
+			for _, memberI := range st.Body {
+				var m *Method
+				switch member := memberI.(type) {
+				case ast.FunctionDefinitionStatement:
+					m = c.method(pkg, ifs.Name, member.Name, member.SourceLoc, member.Parameters, member.ReturnType)
+				case ast.FunctionDeclarationStatement:
+					m = c.method(pkg, ifs.Name, member.Name, member.SourceLoc, member.Parameters, member.ReturnType)
+				case ast.VariableDeclarationStatement:
+					c.errorf(member.SourceLoc, errorutils.VarsNotAllowedInInterfaceError, ifs.Name)
+					continue
+				default:
+					continue
+				}
+				if prev, ok := ifs.Methods[m.Name]; ok {
+					c.redeclared(m.Decl, prev.Decl, errorutils.MethodRedeclaration, m.Owner+"."+m.Name)
+					continue
+				}
+				ifs.Methods[m.Name] = m
+			}
+		}
+	}
+}
+
+func (c *checker) addField(pkg string, cls *Class, st ast.VariableDeclarationStatement) {
+	f := &Symbol{
+		Name:     st.Identifier,
+		Kind:     FieldSymbol,
+		Type:     c.resolveType(pkg, st.ExplicitType, st.SourceLoc),
+		Decl:     st.SourceLoc,
+		Owner:    cls.Name,
+		Internal: st.IsInternal,
+	}
+
+	if prev, ok := cls.Fields[f.Name]; ok {
+		c.redeclared(st.SourceLoc, prev.Decl, errorutils.VariableRedeclaration, f.Name)
+		return
+	}
+	cls.Fields[f.Name] = f
+}
+
+func (c *checker) method(pkg, owner, name string, loc ast.SourceLoc, params []ast.Parameter, ret ast.Type) *Method {
+	resolve := func(tp ast.Type) ast.Type {
+		if tp == nil {
+			return nil
+		}
+		return c.resolveType(pkg, tp, loc)
+	}
+
+	m := &Method{
+		Symbol: Symbol{Name: name, Kind: MethodSymbol, Decl: loc, Owner: owner},
+		Return: resolve(ret),
+	}
+	for _, p := range params {
+		m.Params = append(m.Params, &Symbol{
+			Name: p.Name,
+			Kind: ParamSymbol,
+			Type: resolve(p.Type),
+			Decl: nameLoc(p.NameLoc, loc),
+		})
+	}
+	m.Type = m.Return
+	return m
+}
+
+// mute discards errors until the returned func is called if on is set.
+// Declarations of packages that are not checked are collected this way.
+func (c *checker) mute(on bool) func() {
+	prev := c.muted
+	c.muted = c.muted || on
+	return func() { c.muted = prev }
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// splitDeclaration turns a multiple declaration, say a: int, b: int, into
+// one declaration per variable.
+func splitDeclaration(st ast.VariableDeclarationStatement) []ast.VariableDeclarationStatement {
+	if len(st.Identifiers) == 0 {
+		return []ast.VariableDeclarationStatement{st}
+	}
+	out := make([]ast.VariableDeclarationStatement, len(st.Identifiers))
+	for i, id := range st.Identifiers {
+		out[i] = ast.VariableDeclarationStatement{
+			SourceLoc:  st.SourceLoc,
+			Identifier: id,
+			Constant:   st.Constant,
+			IsStatic:   st.IsStatic,
+			IsAtomic:   st.IsAtomic,
+			IsInternal: st.IsInternal,
+		}
+		if i < len(st.NameLocs) {
+			out[i].NameLoc = st.NameLocs[i]
+		}
+		if i < len(st.ExplicitTypes) {
+			out[i].ExplicitType = st.ExplicitTypes[i]
+		}
+	}
+	return out
+}
+
+// nameLoc returns loc, where a name is written, or at if the tree was not
+// parsed and has no such location, e.g. when read from an .exports file.
+func nameLoc(loc, at ast.SourceLoc) ast.SourceLoc {
+	if loc.FilePath == "" && loc.Line == 0 {
+		return at
+	}
+	return loc
+}
+
+// checkImplements verifies that every class of pkg implementing an
+// interface provides all its methods with matching signatures.
+func (c *checker) checkImplements(pkg string, tree ast.BlockStatement) {
+	for _, stI := range tree.Body {
+		st, ok := stI.(ast.ClassDeclarationStatement)
+		if !ok {
+			continue
+		}
+		cls := c.info.Classes[fmt.Sprintf("%s.%s", pkg, st.Name)]
+		if cls == nil || cls.Decl != st.SourceLoc || cls.Implements == "" {
+			continue
+		}
+		ifs := c.info.Interfaces[cls.Implements]
+
+		for _, name := range sortedKeys(ifs.Methods) {
+			want := ifs.Methods[name]
+			got, ok := cls.Methods[name]
+			if !ok {
+				c.errorWithNotes(cls.Decl, []errorsx.Note{noteAt(want.Decl, "%s declared here", ifs.Name+"."+name)},
+					errorutils.UnImplementedInterfaceMethod,
+					fmt.Sprintf("%s.%s must implement interface method %s", cls.Name, name, name))
+				continue
+			}
+			if msg := signatureMismatch(want, got); msg != "" {
+				c.errorWithNotes(got.Decl, []errorsx.Note{noteAt(want.Decl, "%s declared here", ifs.Name+"."+name)},
+					errorutils.UnImplementedInterfaceMethod,
+					fmt.Sprintf("Method signature mismatch: %s.%s %s", cls.Name, name, msg))
+			}
+		}
+	}
+}
+
+// signatureMismatch describes how got differs from want, "" if it does not.
+// Types that could not be resolved are not compared.
+func signatureMismatch(want, got *Method) string {
+	if len(want.Params) != len(got.Params) {
+		return "parameter count differs"
+	}
+	for i := range want.Params {
+		w, g := want.Params[i].Type, got.Params[i].Type
+		if w != nil && g != nil && TypeString(w) != TypeString(g) {
+			return fmt.Sprintf("parameter %d type mismatch (expected %s, got %s)", i, TypeString(w), TypeString(g))
+		}
+	}
+	if want.Return != nil && got.Return != nil && TypeString(want.Return) != TypeString(got.Return) {
+		return fmt.Sprintf("return type mismatch (expected %s, got %s)", TypeString(want.Return), TypeString(got.Return))
+	}
+	return ""
+}
+
+// useImportsIn marks the imports referred to by sts, which are not checked
+// because control never reaches them. Names are matched without resolving
+// them, which is enough for an import not to be reported as unused.
+func (c *checker) useImportsIn(sts []ast.Statement) {
+	var useType func(tp ast.Type)
+	useType = func(tp ast.Type) {
+		switch tp := tp.(type) {
+		case *ast.SymbolType:
+			c.useName(tp.Value)
+		case *ast.ListType:
+			useType(tp.Underlying)
+		case *ast.TupleType:
+			for _, t := range tp.Types {
+				useType(t)
+			}
+		}
+	}
+	for _, st := range sts {
+		ast.Inspect(st, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case ast.SymbolExpression:
+				c.useName(n.Value)
+			case ast.VariableDeclarationStatement:
+				useType(n.ExplicitType)
+				for _, t := range n.ExplicitTypes {
+					useType(t)
+				}
+			}
+			return true
+		})
+	}
+}
+
+// useName marks the import name refers to, either through its alias, e.g.
+// geo or geo.Point, or as a bare class name.
+func (c *checker) useName(name string) {
+	alias, _, qualified := strings.Cut(name, ".")
+	if _, ok := c.imports[c.pkg][alias]; ok {
+		c.useImport(c.pkg, alias)
+		return
+	}
+	if fq := c.matchSuffix(name); !qualified && fq != "" {
+		c.useImportOf(c.pkg, fq)
+	}
+}
+
+// checkImportsUsed warns about imports of pkg that are never referred to.
+func (c *checker) checkImportsUsed(pkg string) {
+	c.pkg = pkg
+	for _, alias := range sortedKeys(c.imports[pkg]) {
+		imp := c.imports[pkg][alias]
+		// a package can always refer to itself
+		if imp.loc.FilePath == "" || c.usedImports[pkg][alias] {
+			continue
+		}
+		c.warn(errorsx.UnusedImport, imp.loc, nil, "%s imported and not used", alias)
+	}
+}
@@ -0,0 +1,381 @@
+package sema
+
+import (
+	"github.com/nagarajRPoojari/picasso/irgen/ast"
+	errorutils "github.com/nagarajRPoojari/picasso/irgen/codegen/error"
+	"github.com/nagarajRPoojari/picasso/irgen/codegen/handlers/constants"
+	"github.com/nagarajRPoojari/picasso/irgen/lexer"
+)
+
+var (
+	booleanType = &ast.SymbolType{Value: "boolean"}
+	float64Type = &ast.SymbolType{Value: "float64"}
+	stringType  = &ast.SymbolType{Value: "string"}
+	nullType    = &ast.SymbolType{Value: "null"}
+)
+
+// expr checks e and returns its type, nil if unknown. Known types are
+// recorded in Info.Types.
+func (c *checker) expr(e ast.Expression) ast.Type {
+	if e == nil {
+		return nil
+	}
+	t := c.exprType(e)
+	if t != nil {
+		c.info.Types[KeyOf(e)] = t
+	}
+	return t
+}
+
+func (c *checker) exprs(list []ast.Expression) []ast.Type {
+	types := make([]ast.Type, len(list))
+	for i, e := range list {
+		types[i] = c.expr(e)
+	}
+	return types
+}
+
+func (c *checker) exprType(e ast.Expression) ast.Type {
+	switch ex := e.(type) {
+	case ast.NumberExpression:
+		// number literals are float64 until converted, like in codegen
+		return float64Type
+
+	case ast.StringExpression:
+		return stringType
+
+	case ast.NullExpression:
+		return nullType
+
+	case ast.SymbolExpression:
+		return c.symbol(ex)
+
+	case ast.MemberExpression:
+		return c.member(ex)
+
+	case ast.ComputedExpression:
+		return c.index(ex)
+
+	case ast.CallExpression:
+		return c.call(ex)
+
+	case ast.NewExpression:
+		return c.newExpr(ex)
+
+	case ast.BinaryExpression:
+		return c.binary(ex)
+
+	case ast.PrefixExpression:
+		t := c.expr(ex.Operand)
+		switch ex.Operator.Kind {
+		case lexer.NOT:
+			return booleanType
+		case lexer.TYPEOF:
+			return nil
+		}
+		return t
+
+	case ast.AssignmentExpression:
+		c.assign(ex)
+
+	case ast.RangeExpression:
+		c.expr(ex.Lower)
+		c.expr(ex.Upper)
+
+	case ast.ListExpression:
+		c.exprs(ex.Constants)
+	}
+	return nil
+}
+
+func (c *checker) symbol(ex ast.SymbolExpression) ast.Type {
+	if sym := c.scopes.lookup(ex.Value); sym != nil {
+		c.info.Uses[KeyOf(ex)] = sym
+		c.reads[sym] = true
+		return sym.Type
+	}
+	// type names are values too, e.g. array.create(int, 10)
+	if c.isTypeName(ex.Value) {
+		return nil
+	}
+	if _, ok := c.imports[c.pkg][ex.Value]; ok {
+		c.useImport(c.pkg, ex.Value)
+		return nil
+	}
+	c.errorf(ex.SourceLoc, errorutils.UnknownVariable, ex.Value)
+	return nil
+}
+
+func (c *checker) isTypeName(name string) bool {
+	return isPrimitive(name) || c.isUserType(name) || c.matchSuffix(name) != ""
+}
+
+// packageMember handles alias.name where alias is an import rather than a
+// variable. It reports whether ex is such an expression.
+func (c *checker) packageMember(ex ast.MemberExpression) bool {
+	sym, ok := ex.Member.(ast.SymbolExpression)
+	if !ok || c.scopes.lookup(sym.Value) != nil {
+		return false
+	}
+	imp, ok := c.imports[c.pkg][sym.Value]
+	if !ok {
+		return false
+	}
+	c.useImport(c.pkg, sym.Value)
+	if imp.kind == userImport {
+		name := sym.Value + "." + ex.Property
+		if _, ok := c.resolveTypeName(c.pkg, name); !ok {
+			c.errorf(ex.SourceLoc, errorutils.UnknownType, name)
+		}
+	}
+	return true
+}
+
+func (c *checker) member(ex ast.MemberExpression) ast.Type {
+	if c.packageMember(ex) {
+		return nil
+	}
+	base := c.expr(ex.Member)
+
+	switch c.info.kindOf(base) {
+	case classKind:
+		cls := c.info.Classes[base.Get()]
+		if f, ok := cls.Fields[ex.Property]; ok {
+			c.checkAccess(ex, cls, f)
+			c.info.Uses[KeyOf(ex)] = f
+			return f.Type
+		}
+		// methods are values too, e.g. thread(this.run)
+		if m, ok := cls.Methods[ex.Property]; ok {
+			c.checkAccess(ex, cls, &m.Symbol)
+			c.info.Uses[KeyOf(ex)] = &m.Symbol
+			return nil
+		}
+		c.errorf(ex.SourceLoc, errorutils.UnknownClassField, ex.Property, cls.Name)
+
+	case interfaceKind:
+		ifs := c.info.Interfaces[base.Get()]
+		if m, ok := ifs.Methods[ex.Property]; ok {
+			c.info.Uses[KeyOf(ex)] = &m.Symbol
+			return nil
+		}
+		c.errorf(ex.SourceLoc, errorutils.UnknownClassField, ex.Property, ifs.Name)
+	}
+	return nil
+}
+
+// checkAccess reports access to an internal member from outside its class.
+// As in codegen, only members reached through this are accessible.
+func (c *checker) checkAccess(ex ast.MemberExpression, cls *Class, sym *Symbol) {
+	if sym.Internal && rootOf(ex) != constants.THIS {
+		c.errorf(ex.SourceLoc, errorutils.FieldNotAccessible, cls.Name, ex.Property)
+	}
+}
+
+// rootOf returns the name a chain of member and index expressions starts
+// from, e.g. this for this.a[0].b.
+func rootOf(e ast.Expression) string {
+	switch ex := e.(type) {
+	case ast.SymbolExpression:
+		return ex.Value
+	case ast.MemberExpression:
+		return rootOf(ex.Member)
+	case ast.ComputedExpression:
+		return rootOf(ex.Member)
+	}
+	return ""
+}
+
+func (c *checker) index(ex ast.ComputedExpression) ast.Type {
+	t := c.expr(ex.Member)
+	c.exprs(ex.Indices)
+
+	for _, idx := range ex.Indices {
+		// slicing keeps the array type
+		if _, ok := idx.(ast.RangeExpression); ok {
+			continue
+		}
+		list, ok := t.(*ast.ListType)
+		if !ok {
+			return nil
+		}
+		t = list.Underlying
+	}
+	return t
+}
+
+func (c *checker) call(ex ast.CallExpression) ast.Type {
+	switch m := ex.Method.(type) {
+	case ast.SymbolExpression:
+		args := c.exprs(ex.Arguments)
+		if m.Value == "thread" {
+			return nil
+		}
+		if c.scopes.lookup(m.Value) == nil && isPrimitive(m.Value) {
+			// explicit cast, e.g. int(x)
+			if len(args) != 1 {
+				c.errorf(ex.SourceLoc, errorutils.ParamsError, m.Value, 1)
+			}
+			return &ast.SymbolType{Value: m.Value}
+		}
+		c.errorf(m.SourceLoc, errorutils.UnknownMethod, m.Value)
+		return nil
+
+	case ast.MemberExpression:
+		if c.packageMember(m) {
+			// lib and ffi functions are not declared in picasso source
+			c.exprs(ex.Arguments)
+			return nil
+		}
+		base := c.expr(m.Member)
+		args := c.exprs(ex.Arguments)
+
+		var method *Method
+		switch c.info.kindOf(base) {
+		case classKind:
+			cls := c.info.Classes[base.Get()]
+			method = cls.Methods[m.Property]
+			if method != nil {
+				c.checkAccess(m, cls, &method.Symbol)
+			}
+		case interfaceKind:
+			method = c.info.Interfaces[base.Get()].Methods[m.Property]
+		default:
+			return nil
+		}
+		if method == nil {
+			c.errorf(m.SourceLoc, errorutils.UnknownMethod, m.Property)
+			return nil
+		}
+		c.info.Uses[KeyOf(m)] = &method.Symbol
+		c.checkArgs(ex, method, args)
+		return method.Return
+	}
+
+	c.expr(ex.Method)
+	c.exprs(ex.Arguments)
+	return nil
+}
+
+// checkArgs checks the arguments of a call to m.
+func (c *checker) checkArgs(ex ast.CallExpression, m *Method, args []ast.Type) {
+	if len(args) != len(m.Params) {
+		c.errorf(ex.SourceLoc, errorutils.ParamsError, m.Owner+"."+m.Name, len(m.Params))
+		return
+	}
+	for i, p := range m.Params {
+		c.expectAssignable(ex.Arguments[i], p.Type, args[i])
+	}
+}
+
+func (c *checker) newExpr(ex ast.NewExpression) ast.Type {
+	inst := ex.Instantiation
+	args := c.exprs(inst.Arguments)
+
+	name := typeName(inst.Method)
+	fq, ok := c.resolveTypeName(c.pkg, name)
+	if !ok {
+		c.errorf(ex.SourceLoc, errorutils.UnknownClass, name)
+		return nil
+	}
+	if _, ok := c.info.Interfaces[fq]; ok {
+		c.errorf(ex.SourceLoc, errorutils.InterfaceInstantiationError, fq)
+		return nil
+	}
+	cls, ok := c.info.Classes[fq]
+	if !ok {
+		if fq != "" {
+			c.errorf(ex.SourceLoc, errorutils.UnknownClass, name)
+		}
+		return nil
+	}
+
+	if cls.Internal && cls.Package != c.pkg {
+		c.errorf(ex.SourceLoc, errorutils.ClassNotAccessible, fq)
+	}
+	// the constructor is the method named after the class
+	if ctor, ok := cls.Methods[simpleName(fq)]; ok {
+		c.checkArgs(inst, ctor, args)
+	}
+	return &ast.SymbolType{Value: fq}
+}
+
+// typeName returns the name written in a type position of an expression,
+// e.g. start.Test for new start.Test().
+func typeName(e ast.Expression) string {
+	switch ex := e.(type) {
+	case ast.SymbolExpression:
+		return ex.Value
+	case ast.MemberExpression:
+		if base := typeName(ex.Member); base != "" {
+			return base + "." + ex.Property
+		}
+	}
+	return ""
+}
+
+func simpleName(fq string) string {
+	for i := len(fq) - 1; i >= 0; i-- {
+		if fq[i] == '.' {
+			return fq[i+1:]
+		}
+	}
+	return fq
+}
+
+func (c *checker) binary(ex ast.BinaryExpression) ast.Type {
+	left := c.expr(ex.Left)
+	c.expr(ex.Right)
+
+	switch ex.Operator.Kind {
+	case lexer.LESS, lexer.LESS_EQUALS, lexer.GREATER, lexer.GREATER_EQUALS,
+		lexer.EQUALS, lexer.NOT_EQUALS, lexer.AND, lexer.OR, lexer.QUESTION:
+		return booleanType
+	case lexer.DOT_DOT:
+		return nil
+	}
+	// arithmetic and bitwise results take the type of the left operand
+	if c.info.kindOf(left) == numericKind {
+		return left
+	}
+	return nil
+}
+
+func (c *checker) assign(ex ast.AssignmentExpression) {
+	if ex.Assignee != nil {
+		to := c.lvalue(ex.Assignee)
+		from := c.expr(ex.AssignedValue)
+		c.expectAssignable(ex.AssignedValue, to, from)
+		return
+	}
+
+	to := make([]ast.Type, len(ex.Assignees))
+	for i, a := range ex.Assignees {
+		to[i] = c.lvalue(a)
+	}
+	values, from := c.unpack(ex.SourceLoc, ex.AssignedValues, c.exprs(ex.AssignedValues), len(to))
+	for i := range values {
+		c.expectAssignable(values[i], to[i], from[i])
+	}
+}
+
+// lvalue checks the target of an assignment. Assigning to a variable does
+// not count as using it.
+func (c *checker) lvalue(e ast.Expression) ast.Type {
+	if ex, ok := e.(ast.SymbolExpression); ok {
+		if sym := c.scopes.lookup(ex.Value); sym != nil {
+			c.info.Uses[KeyOf(ex)] = sym
+			c.info.Types[KeyOf(ex)] = sym.Type
+			return sym.Type
+		}
+	}
+	if ex, ok := e.(ast.ComputedExpression); ok {
+		for _, idx := range ex.Indices {
+			if _, ok := idx.(ast.RangeExpression); ok {
+				c.errorf(ex.SourceLoc, errorutils.SliceAssignmentError)
+				break
+			}
+		}
+	}
+	return c.expr(e)
+}
@@ -0,0 +1,144 @@
+// Package sema implements the semantic analysis phase: it resolves names and
+// computes the type of every expression of a parsed program without
+// generating any IR. The result is an Info describing the typed program,
+// which codegen, the check command and editor tooling consume.
+package sema
+
+import (
+	"reflect"
+
+	"github.com/nagarajRPoojari/picasso/irgen/ast"
+)
+
+// SymbolKind classifies what a name refers to.
+type SymbolKind int
+
+const (
+	VarSymbol SymbolKind = iota
+	ParamSymbol
+	FieldSymbol
+	MethodSymbol
+	ClassSymbol
+	InterfaceSymbol
+	PackageSymbol
+)
+
+// Symbol is a declared name together with its resolved type.
+type Symbol struct {
+	Name string
+	Kind SymbolKind
+	// Type is the resolved type, nil if unknown.
+	Type ast.Type
+	// Decl is where the symbol is declared. Imported packages and builtins
+	// have no declaration site.
+	Decl ast.SourceLoc
+	// Owner is the fully qualified class or interface of fields and methods.
+	Owner    string
+	Internal bool
+}
+
+// Method is a class or interface method.
+type Method struct {
+	Symbol
+	Params []*Symbol
+	// Return is nil for methods returning nothing.
+	Return ast.Type
+	Static bool
+}
+
+// Class is a class declaration with its members resolved.
+type Class struct {
+	// Name is fully qualified, e.g. start.Test.
+	Name    string
+	Package string
+	Decl    ast.SourceLoc
+	// Implements is the fully qualified interface, "" if none.
+	Implements string
+	Internal   bool
+
+	Fields  map[string]*Symbol
+	Methods map[string]*Method
+}
+
+// Interface is an interface declaration.
+type Interface struct {
+	Name    string
+	Package string
+	Decl    ast.SourceLoc
+	Methods map[string]*Method
+}
+
+// NodeKey identifies an AST node. Nodes are values without identity, so
+// they are keyed by their location and concrete type, and by their depth
+// among the nodes of the same location and type nested in their left
+// operand, e.g. 1 for the outer index of a[i][j] and 0 for a[i].
+type NodeKey struct {
+	Loc   ast.SourceLoc
+	Kind  reflect.Type
+	Depth int
+}
+
+// KeyOf returns the key of n.
+func KeyOf(n ast.Node) NodeKey {
+	key := NodeKey{Loc: n.GetSrc(), Kind: reflect.TypeOf(n)}
+	for e := leftOperand(n); e != nil; e = leftOperand(e) {
+		if e.GetSrc() == key.Loc && reflect.TypeOf(e) == key.Kind {
+			key.Depth++
+		}
+	}
+	return key
+}
+
+// leftOperand returns the operand n starts with, nil if it has none.
+func leftOperand(n ast.Node) ast.Expression {
+	switch ex := n.(type) {
+	case ast.ComputedExpression:
+		return ex.Member
+	case ast.MemberExpression:
+		return ex.Member
+	case ast.CallExpression:
+		return ex.Method
+	case ast.BinaryExpression:
+		return ex.Left
+	case ast.AssignmentExpression:
+		return ex.Assignee
+	case ast.RangeExpression:
+		return ex.Lower
+	}
+	return nil
+}
+
+// Info is the typed program produced by Check.
+type Info struct {
+	// Packages maps package names to their parsed trees.
+	Packages map[string]ast.BlockStatement
+
+	// Classes and Interfaces are keyed by fully qualified name.
+	Classes    map[string]*Class
+	Interfaces map[string]*Interface
+
+	// Types holds the type of every expression whose type is known.
+	Types map[NodeKey]ast.Type
+	// Uses maps symbol and member expressions to what they refer to.
+	Uses map[NodeKey]*Symbol
+}
+
+// TypeOf returns the type of e, nil if unknown.
+func (t *Info) TypeOf(e ast.Expression) ast.Type {
+	return t.Types[KeyOf(e)]
+}
+
+// SymbolOf returns the symbol e refers to, nil if it is not a name.
+func (t *Info) SymbolOf(e ast.Expression) *Symbol {
+	return t.Uses[KeyOf(e)]
+}
+
+func newInfo(pkgs map[string]ast.BlockStatement) *Info {
+	return &Info{
+		Packages:   pkgs,
+		Classes:    make(map[string]*Class),
+		Interfaces: make(map[string]*Interface),
+		Types:      make(map[NodeKey]ast.Type),
+		Uses:       make(map[NodeKey]*Symbol),
+	}
+}
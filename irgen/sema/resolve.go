@@ -0,0 +1,254 @@
+package sema
+
+import (
+	"strings"
+
+	"github.com/nagarajRPoojari/picasso/irgen/ast"
+	errorutils "github.com/nagarajRPoojari/picasso/irgen/codegen/error"
+)
+
+// importKind tells what an import alias refers to.
+type importKind int
+
+const (
+	userImport importKind = iota
+	libImport
+	ffiImport
+)
+
+type importEntry struct {
+	kind importKind
+	// name is the package name for user imports and the module name, e.g.
+	// syncio, for lib and ffi imports.
+	name string
+	loc  ast.SourceLoc
+}
+
+// scalar type names understood by codegen without a declaration.
+var numericTypes = map[string]struct{}{
+	"boolean": {}, "bool": {}, "i1": {},
+	"int8": {}, "uint8": {}, "i8": {},
+	"int16": {}, "uint16": {}, "i16": {},
+	"int32": {}, "uint32": {},
+	"int64": {}, "uint64": {}, "i64": {},
+	"int": {}, "uint": {},
+	"float16": {}, "half": {}, "float32": {}, "float": {}, "float64": {}, "double": {},
+}
+
+// builtinTypes are runtime types that exist without a declaration.
+var builtinTypes = map[string]struct{}{
+	"null": {}, "void": {}, "string": {},
+	"array": {}, "mutex": {}, "rwmutex": {}, "waitgroup": {},
+}
+
+func isPrimitive(name string) bool {
+	if _, ok := numericTypes[name]; ok {
+		return true
+	}
+	if _, ok := builtinTypes[name]; ok {
+		return true
+	}
+	return strings.HasPrefix(name, "atomic_")
+}
+
+// kind is the coarse category of a type used for compatibility checks.
+type kind int
+
+const (
+	unknownKind kind = iota
+	numericKind
+	stringKind
+	nullKind
+	arrayKind
+	classKind
+	interfaceKind
+	tupleKind
+)
+
+func (t *Info) kindOf(tp ast.Type) kind {
+	switch tp := tp.(type) {
+	case *ast.SymbolType:
+		if tp.Atomic {
+			return unknownKind
+		}
+		if _, ok := numericTypes[tp.Value]; ok {
+			return numericKind
+		}
+		switch tp.Value {
+		case "string":
+			return stringKind
+		case "null":
+			return nullKind
+		}
+		if _, ok := t.Classes[tp.Value]; ok {
+			return classKind
+		}
+		if _, ok := t.Interfaces[tp.Value]; ok {
+			return interfaceKind
+		}
+	case *ast.ListType:
+		return arrayKind
+	case *ast.TupleType:
+		return tupleKind
+	}
+	return unknownKind
+}
+
+// assignable reports whether a value of type from may be stored in a slot of
+// type to. Like codegen, numbers convert among themselves and pointers
+// (strings, arrays, classes) among themselves, but never one into the other.
+// Unknown types are always assignable.
+func (t *Info) assignable(to, from ast.Type) bool {
+	tk, fk := t.kindOf(to), t.kindOf(from)
+	if tk == unknownKind || fk == unknownKind || tk == tupleKind || fk == tupleKind {
+		return true
+	}
+
+	switch {
+	case tk == numericKind:
+		return fk == numericKind
+	case fk == numericKind:
+		return false
+	case tk == interfaceKind && fk == classKind:
+		return t.Classes[from.Get()].Implements == to.Get()
+	}
+	return true
+}
+
+// TypeString formats a resolved type for messages and tooling, e.g.
+// []start.Point.
+func TypeString(tp ast.Type) string {
+	switch tp := tp.(type) {
+	case nil:
+		return "void"
+	case *ast.ListType:
+		return "[]" + TypeString(tp.Underlying)
+	case *ast.TupleType:
+		parts := make([]string, len(tp.Types))
+		for i, e := range tp.Types {
+			parts[i] = TypeString(e)
+		}
+		return "(" + strings.Join(parts, ", ") + ")"
+	}
+	return tp.Get()
+}
+
+// resolveType maps a type written in pkg to its fully qualified form.
+// It returns nil if the type cannot be resolved; an error is reported only
+// when the name definitely does not exist.
+func (c *checker) resolveType(pkg string, tp ast.Type, loc ast.SourceLoc) ast.Type {
+	switch tp := tp.(type) {
+	case *ast.SymbolType:
+		if tp.Atomic {
+			return &ast.SymbolType{Atomic: true, Value: tp.Value}
+		}
+		fq, ok := c.resolveTypeName(pkg, tp.Value)
+		if !ok {
+			c.errorf(loc, errorutils.UnknownType, tp.Value)
+			return nil
+		}
+		if fq == "" {
+			return nil
+		}
+		return &ast.SymbolType{Value: fq}
+
+	case *ast.ListType:
+		u := c.resolveType(pkg, tp.Underlying, loc)
+		if u == nil {
+			return nil
+		}
+		return &ast.ListType{Atomic: tp.Atomic, Length: tp.Length, Underlying: u}
+
+	case *ast.TupleType:
+		types := make([]ast.Type, len(tp.Types))
+		for i, e := range tp.Types {
+			types[i] = c.resolveType(pkg, e, loc)
+		}
+		return &ast.TupleType{Atomic: tp.Atomic, Types: types}
+	}
+	return nil
+}
+
+// resolveTypeName resolves a type name as written in pkg. It returns the
+// fully qualified name, "" with ok set if the name cannot be checked (e.g. a
+// C struct), and ok unset if the name certainly does not exist.
+func (c *checker) resolveTypeName(pkg string, name string) (fq string, ok bool) {
+	if isPrimitive(name) {
+		return name, true
+	}
+	if c.isUserType(name) {
+		// an alias named like its package already reads fully qualified
+		c.useImportOf(pkg, name)
+		return name, true
+	}
+
+	dot := strings.Index(name, ".")
+	if dot == -1 {
+		// bare class names are matched by suffix, like codegen does
+		if fq := c.matchSuffix(name); fq != "" {
+			c.useImportOf(pkg, fq)
+			return fq, true
+		}
+		return "", false
+	}
+
+	imp, ok := c.imports[pkg][name[:dot]]
+	if ok {
+		c.useImport(pkg, name[:dot])
+	}
+	if !ok || imp.kind != userImport {
+		if fq := c.matchSuffix(name); fq != "" {
+			c.useImportOf(pkg, fq)
+			return fq, true
+		}
+		return "", true
+	}
+
+	fq = imp.name + name[dot:]
+	if c.isUserType(fq) {
+		return fq, true
+	}
+	if _, known := c.info.Packages[imp.name]; !known {
+		return "", true
+	}
+	return "", false
+}
+
+func (c *checker) isUserType(fq string) bool {
+	if _, ok := c.info.Classes[fq]; ok {
+		return true
+	}
+	_, ok := c.info.Interfaces[fq]
+	return ok
+}
+
+// matchSuffix finds the class or interface whose fully qualified name ends
+// with name, e.g. Test for start.Test.
+func (c *checker) matchSuffix(name string) string {
+	suffix := "." + name
+	for _, fq := range c.typeNames {
+		if strings.HasSuffix(fq, suffix) {
+			return fq
+		}
+	}
+	return ""
+}
+
+// useImport marks alias as referred to by pkg.
+func (c *checker) useImport(pkg string, alias string) {
+	if c.usedImports[pkg] == nil {
+		c.usedImports[pkg] = make(map[string]bool)
+	}
+	c.usedImports[pkg][alias] = true
+}
+
+// useImportOf marks the imports of pkg that provide type fq, which was
+// referred to without an alias.
+func (c *checker) useImportOf(pkg string, fq string) {
+	owner := fq[:strings.LastIndex(fq, ".")]
+	for alias, imp := range c.imports[pkg] {
+		if imp.kind == userImport && imp.name == owner {
+			c.useImport(pkg, alias)
+		}
+	}
+}
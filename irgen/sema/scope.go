@@ -0,0 +1,41 @@
+package sema
+
+// scope is a stack of blocks of local symbols. Like codegen, a function
+// has a block for its parameters and another for its body, so locals may
+// shadow parameters; a name may only be declared once per block.
+type scope struct {
+	blocks []map[string]*Symbol
+}
+
+func newScope() *scope {
+	return &scope{}
+}
+
+func (t *scope) push() {
+	t.blocks = append(t.blocks, make(map[string]*Symbol))
+}
+
+func (t *scope) pop() {
+	t.blocks = t.blocks[:len(t.blocks)-1]
+}
+
+// declare adds sym to the innermost block, returning the symbol it clashes
+// with if the name is already declared there.
+func (t *scope) declare(sym *Symbol) (*Symbol, bool) {
+	inner := t.blocks[len(t.blocks)-1]
+	if prev, ok := inner[sym.Name]; ok {
+		return prev, false
+	}
+	inner[sym.Name] = sym
+	return nil, true
+}
+
+// lookup finds name in the innermost block declaring it.
+func (t *scope) lookup(name string) *Symbol {
+	for i := len(t.blocks) - 1; i >= 0; i-- {
+		if sym, ok := t.blocks[i][name]; ok {
+			return sym
+		}
+	}
+	return nil
+}
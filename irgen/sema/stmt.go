@@ -0,0 +1,389 @@
+package sema
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nagarajRPoojari/picasso/irgen/ast"
+	errorutils "github.com/nagarajRPoojari/picasso/irgen/codegen/error"
+	"github.com/nagarajRPoojari/picasso/irgen/codegen/handlers/constants"
+	errorsx "github.com/nagarajRPoojari/picasso/irgen/error"
+)
+
+// checkPackage checks the bodies of all methods, functions and field
+// initializers of pkg.
+func (c *checker) checkPackage(pkg string, tree ast.BlockStatement) {
+	c.pkg = pkg
+	for _, stI := range tree.Body {
+		switch st := stI.(type) {
+		case ast.ClassDeclarationStatement:
+			cls := c.info.Classes[fmt.Sprintf("%s.%s", pkg, st.Name)]
+			if cls == nil || cls.Decl != st.SourceLoc {
+				continue
+			}
+			c.checkFields(cls, st)
+			for _, memberI := range st.Body {
+				member, ok := memberI.(ast.FunctionDefinitionStatement)
+				if !ok {
+					continue
+				}
+				// a redeclared method is reported once, not checked again
+				if m := cls.Methods[member.Name]; m != nil && m.Decl == member.SourceLoc {
+					c.checkFunc(cls, m, &member)
+				}
+			}
+
+		case ast.FunctionDefinitionStatement:
+			c.checkFunc(nil, nil, &st)
+		}
+	}
+}
+
+// checkFields checks field initializers against the field types.
+func (c *checker) checkFields(cls *Class, st ast.ClassDeclarationStatement) {
+	c.enter(cls)
+	defer c.leave()
+
+	for _, memberI := range st.Body {
+		member, ok := memberI.(ast.VariableDeclarationStatement)
+		if !ok {
+			continue
+		}
+		fields := splitDeclaration(member)
+		values := declaredValues(member)
+		for i, v := range values {
+			t := c.expr(v)
+			if i < len(fields) && len(values) == len(fields) {
+				if f := cls.Fields[fields[i].Identifier]; f != nil && f.Decl == member.SourceLoc {
+					c.expectAssignable(v, f.Type, t)
+				}
+			}
+		}
+	}
+}
+
+// checkFunc checks the body of a method of cls, or of a top-level function
+// if cls is nil.
+func (c *checker) checkFunc(cls *Class, m *Method, fn *ast.FunctionDefinitionStatement) {
+	c.enter(cls)
+	defer c.leave()
+
+	if m != nil {
+		c.ret = m.Return
+	} else if fn.ReturnType != nil {
+		c.ret = c.resolveType(c.pkg, fn.ReturnType, fn.SourceLoc)
+	}
+
+	for i, p := range fn.Parameters {
+		sym := &Symbol{Name: p.Name, Kind: ParamSymbol, Decl: nameLoc(p.NameLoc, fn.SourceLoc)}
+		if m != nil {
+			sym.Type = m.Params[i].Type
+		} else {
+			sym.Type = c.resolveType(c.pkg, p.Type, fn.SourceLoc)
+		}
+		// the runtime passes the arguments of start whether it reads them
+		// or not
+		if cls == nil && fn.Name == constants.MAIN {
+			c.reads[sym] = true
+		}
+		c.declare(sym)
+	}
+
+	if !c.block(fn.Body) && fn.ReturnType != nil {
+		c.missingReturn(cls, fn)
+	}
+}
+
+// missingReturn reports fn, a method of cls if not nil, whose body can
+// complete without returning a value.
+func (c *checker) missingReturn(cls *Class, fn *ast.FunctionDefinitionStatement) {
+	what := "function " + fn.Name
+	if cls != nil {
+		what = fmt.Sprintf("method %s.%s", cls.Name[strings.LastIndex(cls.Name, ".")+1:], fn.Name)
+	}
+	var notes []errorsx.Note
+	if n := len(fn.Body); n > 0 {
+		notes = append(notes, noteAt(fn.Body[n-1].GetSrc(), "control can leave this statement without returning"))
+	}
+	c.errorWithNotes(fn.SourceLoc, notes, errorutils.MissingReturn, what)
+}
+
+// enter sets up the state for checking code of cls, with a block holding
+// the parameters and this.
+func (c *checker) enter(cls *Class) {
+	c.ret = nil
+	c.loops = nil
+	c.scopes = newScope()
+	c.scopes.push()
+	if cls != nil {
+		c.scopes.declare(&Symbol{
+			Name: constants.THIS,
+			Kind: ParamSymbol,
+			Type: &ast.SymbolType{Value: cls.Name},
+			Decl: cls.Decl,
+		})
+	}
+}
+
+func (c *checker) leave() {
+	c.closeBlock()
+	c.scopes = nil
+}
+
+// block checks sts in a new scope and reports whether control can never
+// reach its end. Like codegen, statements after a return or break are not
+// checked.
+func (c *checker) block(sts []ast.Statement) bool {
+	c.scopes.push()
+	defer c.closeBlock()
+
+	for i, st := range sts {
+		if c.stmt(st) {
+			if i+1 < len(sts) {
+				c.warn(errorsx.Unreachable, sts[i+1].GetSrc(), nil, "unreachable code")
+				c.useImportsIn(sts[i+1:])
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// closeBlock pops the innermost block, warning about its variables and
+// parameters that are never used.
+func (c *checker) closeBlock() {
+	inner := c.scopes.blocks[len(c.scopes.blocks)-1]
+	for _, name := range sortedKeys(inner) {
+		sym := inner[name]
+		if c.reads[sym] || name == constants.THIS || strings.HasPrefix(name, "_") {
+			continue
+		}
+		switch sym.Kind {
+		case VarSymbol:
+			c.warn(errorsx.UnusedVariable, sym.Decl, nil, "%s declared and not used", name)
+		case ParamSymbol:
+			c.warn(errorsx.UnusedParameter, sym.Decl, nil, "parameter %s is not used", name)
+		}
+	}
+	c.scopes.pop()
+}
+
+// declare adds a local symbol to the innermost block, reporting a clash
+// with one of the same block and warning about one it shadows.
+func (c *checker) declare(sym *Symbol) {
+	outer := c.scopes.lookup(sym.Name)
+	if prev, ok := c.scopes.declare(sym); !ok {
+		c.redeclared(sym.Decl, prev.Decl, errorutils.VariableRedeclaration, sym.Name)
+		return
+	}
+	if outer != nil && outer.Name != constants.THIS {
+		c.warn(errorsx.Shadow, sym.Decl, []errorsx.Note{noteAt(outer.Decl, "%s declared here", sym.Name)},
+			"declaration of %s shadows an outer declaration", sym.Name)
+	}
+}
+
+// stmt checks st and reports whether it terminates its block, i.e. whether
+// control never continues with the next statement.
+func (c *checker) stmt(stI ast.Statement) bool {
+	switch st := stI.(type) {
+	case ast.VariableDeclarationStatement:
+		c.declareVars(st)
+
+	case ast.ExpressionStatement:
+		t := c.expr(st.Expression)
+		switch ex := st.Expression.(type) {
+		case ast.CallExpression:
+			if t != nil {
+				c.warn(errorsx.UnusedResult, st.SourceLoc, nil, "result of %s is not used", typeName(ex.Method))
+			}
+		case ast.AssignmentExpression, ast.NewExpression:
+		default:
+			c.errorf(st.SourceLoc, errorutils.InvalidStatement)
+		}
+
+	case ast.BlockStatement:
+		return c.block(st.Body)
+
+	case ast.AtomicBlockStatement:
+		return c.block(st.Body)
+
+	case ast.IfStatement:
+		c.expr(st.Condition)
+		then := c.stmt(st.Consequent)
+		if st.Alternate == nil {
+			return false
+		}
+		return c.stmt(st.Alternate) && then
+
+	case ast.ForeachStatement:
+		c.expr(st.Iterable)
+		c.scopes.push()
+		index := &Symbol{Name: st.Value, Kind: VarSymbol, Type: &ast.SymbolType{Value: "int"}, Decl: st.SourceLoc}
+		// loops often only count
+		c.reads[index] = true
+		c.declare(index)
+		c.loop(st.Body)
+		c.closeBlock()
+
+	case ast.WhileStatement:
+		c.expr(st.Condition)
+		l := c.loop(st.Body)
+		if !alwaysTrue(st.Condition) {
+			return false
+		}
+		if !l.breaks && !l.returns {
+			c.warn(errorsx.InfiniteLoop, st.SourceLoc, nil, "loop never exits")
+		}
+		return !l.breaks
+
+	case ast.BreakStatement:
+		if len(c.loops) == 0 {
+			c.errorf(st.SourceLoc, errorutils.InvalidBreakStatement)
+		} else {
+			c.loops[len(c.loops)-1].breaks = true
+		}
+		return true
+
+	case ast.ReturnStatement:
+		c.checkReturn(st)
+		for _, l := range c.loops {
+			l.returns = true
+		}
+		return true
+	}
+	return false
+}
+
+// loop records how control leaves a loop being checked.
+type loop struct {
+	breaks  bool
+	returns bool
+}
+
+func (c *checker) loop(body []ast.Statement) *loop {
+	l := &loop{}
+	c.loops = append(c.loops, l)
+	c.block(body)
+	c.loops = c.loops[:len(c.loops)-1]
+	return l
+}
+
+// alwaysTrue reports whether cond is a constant that always holds, e.g. the
+// 1 of while (1).
+func alwaysTrue(cond ast.Expression) bool {
+	n, ok := cond.(ast.NumberExpression)
+	return ok && n.Value != 0
+}
+
+// declaredValues returns the initializers of a single or multiple
+// declaration.
+func declaredValues(st ast.VariableDeclarationStatement) []ast.Expression {
+	if len(st.Identifiers) > 0 {
+		return st.AssignedValues
+	}
+	if st.AssignedValue != nil {
+		return []ast.Expression{st.AssignedValue}
+	}
+	return nil
+}
+
+func (c *checker) declareVars(st ast.VariableDeclarationStatement) {
+	decls := splitDeclaration(st)
+	values := declaredValues(st)
+
+	// initializers are evaluated before the variables come into scope
+	valueTypes := c.exprs(values)
+	if len(decls) > 1 && len(values) > 0 {
+		values, valueTypes = c.unpack(st.SourceLoc, values, valueTypes, len(decls))
+	}
+	pairwise := len(values) == len(decls)
+
+	for i, d := range decls {
+		sym := &Symbol{Name: d.Identifier, Kind: VarSymbol, Decl: nameLoc(d.NameLoc, st.SourceLoc)}
+		if d.ExplicitType != nil {
+			sym.Type = c.resolveType(c.pkg, d.ExplicitType, st.SourceLoc)
+			if pairwise {
+				c.expectAssignable(values[i], sym.Type, valueTypes[i])
+			}
+		} else if pairwise && c.info.kindOf(valueTypes[i]) != tupleKind {
+			sym.Type = valueTypes[i]
+		}
+
+		c.declare(sym)
+	}
+}
+
+// unpack expands tuple values into one value per element, as codegen does
+// when n variables are assigned at once. Each element is attributed to the
+// expression producing it. Nothing is returned if the number of values
+// cannot be known, e.g. when a lib function is called.
+func (c *checker) unpack(loc ast.SourceLoc, values []ast.Expression, types []ast.Type, n int) ([]ast.Expression, []ast.Type) {
+	var exprs []ast.Expression
+	var elems []ast.Type
+	for i, t := range types {
+		switch c.info.kindOf(t) {
+		case unknownKind:
+			return nil, nil
+		case tupleKind:
+			for _, e := range t.(*ast.TupleType).Types {
+				exprs = append(exprs, values[i])
+				elems = append(elems, e)
+			}
+		default:
+			exprs = append(exprs, values[i])
+			elems = append(elems, t)
+		}
+	}
+
+	switch {
+	case len(values) == 1 && len(elems) == 1:
+		c.errorf(loc, errorutils.TupleUnpackFailed, "Cannot assign single non-tuple value to multiple variables")
+		return nil, nil
+	case len(values) == 1 && len(elems) > n:
+		// extra elements of a single tuple are dropped
+		return exprs[:n], elems[:n]
+	case len(elems) != n:
+		c.errorf(loc, errorutils.TupleUnpackFailed, fmt.Sprintf("Assignment count mismatch: %d variables, %d values", n, len(elems)))
+		return nil, nil
+	}
+	return exprs, elems
+}
+
+func (c *checker) checkReturn(st ast.ReturnStatement) {
+	if len(st.Values) > 0 {
+		types := c.exprs(st.Values)
+		if c.ret == nil {
+			return
+		}
+		tuple, ok := c.ret.(*ast.TupleType)
+		if !ok {
+			c.errorf(st.SourceLoc, errorutils.TuplePackFailed, "Multiple return values require tuple return type")
+			return
+		}
+		if len(tuple.Types) != len(st.Values) {
+			c.errorf(st.SourceLoc, errorutils.TuplePackFailed,
+				fmt.Sprintf("expected %d return values, got %d", len(tuple.Types), len(st.Values)))
+			return
+		}
+		for i, v := range st.Values {
+			c.expectAssignable(v, tuple.Types[i], types[i])
+		}
+		return
+	}
+
+	if st.Value.Expression == nil {
+		return
+	}
+	t := c.expr(st.Value.Expression)
+	if c.ret != nil {
+		c.expectAssignable(st.Value.Expression, c.ret, t)
+	}
+}
+
+// expectAssignable reports an error at e if its type from cannot be
+// implicitly converted to to.
+func (c *checker) expectAssignable(e ast.Expression, to, from ast.Type) {
+	if !c.info.assignable(to, from) {
+		c.errorf(e.GetSrc(), errorutils.ImplicitTypeCastError, TypeString(from), TypeString(to))
+	}
+}
@@ -0,0 +1,92 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nagarajRPoojari/picasso/irgen/compiler"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	bceLower = "array index < 0"
+	bceUpper = "array index out of bounds"
+)
+
+// compileBCE compiles body as the body of start, with the array module in
+// scope, and returns the IR of the start module.
+func compileBCE(t *testing.T, body string) string {
+	t.Helper()
+	src := "using \"builtin/array\";\n\nfn start(args: []string) {\n" + body + "}\n"
+	res, diags, err := compiler.Compile(context.Background(), compiler.Options{
+		Sources:  source(src),
+		Warnings: []string{"none"},
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, diags)
+	if res == nil {
+		return ""
+	}
+	return res.Modules["start"].String()
+}
+
+func TestBCEElided(t *testing.T) {
+	cases := map[string]string{
+		"foreach over len": `    say x: []int = array.create(int, 4);
+    foreach i in 0..array.len(x) {
+        say v: int = x[i];
+    }
+`,
+		"constant index": `    say x: []int = array.create(int, 4);
+    say v: int = x[3];
+`,
+		"constant range": `    say x: []int = array.create(int, 4);
+    foreach i in 0..4 {
+        say v: int = x[i];
+    }
+`,
+	}
+	for name, body := range cases {
+		t.Run(name, func(t *testing.T) {
+			ir := compileBCE(t, body)
+			assert.NotContains(t, ir, bceLower)
+			assert.NotContains(t, ir, bceUpper)
+		})
+	}
+}
+
+func TestBCEKept(t *testing.T) {
+	cases := map[string]string{
+		"range past length": `    say x: []int = array.create(int, 4);
+    foreach i in 0..4+1 {
+        say v: int = x[i];
+    }
+`,
+		"constant index past length": `    say x: []int = array.create(int, 4);
+    say v: int = x[4];
+`,
+		"array reassigned in loop": `    say x: []int = array.create(int, 4);
+    foreach i in 0..array.len(x) {
+        say v: int = x[i];
+        x = array.create(int, 1);
+    }
+`,
+		"index reassigned in loop": `    say x: []int = array.create(int, 4);
+    foreach i in 0..array.len(x) {
+        i = i + 1;
+        say v: int = x[i];
+    }
+`,
+		"array reassigned": `    say x: []int = array.create(int, 4);
+    x = array.create(int, 1);
+    say v: int = x[3];
+`,
+	}
+	for name, body := range cases {
+		t.Run(name, func(t *testing.T) {
+			ir := compileBCE(t, body)
+			assert.Contains(t, ir, bceLower)
+			assert.Contains(t, ir, bceUpper)
+		})
+	}
+}
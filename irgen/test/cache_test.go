@@ -0,0 +1,97 @@
+package test
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	generator "github.com/nagarajRPoojari/picasso/irgen/codegen"
+	errorsx "github.com/nagarajRPoojari/picasso/irgen/error"
+	"github.com/nagarajRPoojari/picasso/irgen/manifest"
+	"github.com/stretchr/testify/assert"
+)
+
+const cacheStart = `using "geo/point";
+using "geo/line";
+
+fn start(args: []string) {
+    say p: point.Point = new point.Point(1);
+    say l: line.Line = new line.Line(2);
+    say n: int = p.x + l.n;
+}
+`
+
+const cacheLine = `class Line {
+    say n: int;
+    fn Line(n: int) {
+        this.n = n;
+    }
+}
+`
+
+// buildCached builds the project at dir and returns the packages it built,
+// and the IR of start if it was built.
+func buildCached(t *testing.T, dir string, opts generator.Options) ([]string, string) {
+	t.Helper()
+	g, err := generator.NewGenerator(dir, opts)
+	if !assert.NoError(t, err) {
+		return nil, ""
+	}
+	assert.NoError(t, g.BuildAll())
+	mods := g.Modules()
+	start := ""
+	if m, ok := mods["start"]; ok {
+		start = m.String()
+	}
+	return slices.Sorted(maps.Keys(mods)), start
+}
+
+func TestBuildCache(t *testing.T) {
+	defer errorsx.Diagnostics.Reset()
+	t.Setenv(manifest.IncludeEnv, "")
+
+	dir := writeProject(t, map[string]string{
+		manifest.TOML:   "[module]\nname = \"cached\"\n",
+		"start.pic":     cacheStart,
+		"geo/point.pic": testPoint,
+		"geo/line.pic":  cacheLine,
+	})
+	edit := func(name, old, new string) {
+		path := filepath.Join(dir, name)
+		src, err := os.ReadFile(path)
+		assert.NoError(t, err)
+		assert.NoError(t, os.WriteFile(path, []byte(strings.Replace(string(src), old, new, 1)), 0o644))
+	}
+
+	built, _ := buildCached(t, dir, generator.Options{})
+	assert.Equal(t, []string{"geo.line", "geo.point", "start"}, built)
+	built, _ = buildCached(t, dir, generator.Options{})
+	assert.Empty(t, built)
+
+	// a change confined to a function body rebuilds the package only
+	edit("geo/point.pic", "this.x = x;", "this.x = x + 1;")
+	built, _ = buildCached(t, dir, generator.Options{})
+	assert.Equal(t, []string{"geo.point"}, built)
+
+	// a change of its interface rebuilds its importers too
+	edit("geo/point.pic", "say x: int;", "say x: int;\n    say y: int;")
+	built, _ = buildCached(t, dir, generator.Options{})
+	assert.Equal(t, []string{"geo.point", "start"}, built)
+
+	// other options rebuild everything, and are then cached too
+	built, start := buildCached(t, dir, generator.Options{CheckedArith: true})
+	assert.Equal(t, []string{"geo.line", "geo.point", "start"}, built)
+	assert.Contains(t, start, "llvm.sadd.with.overflow.i64")
+	built, _ = buildCached(t, dir, generator.Options{CheckedArith: true})
+	assert.Empty(t, built)
+
+	// options not changing the IR don't
+	built, _ = buildCached(t, dir, generator.Options{CheckedArith: true, Jobs: 4})
+	assert.Empty(t, built)
+	built, start = buildCached(t, dir, generator.Options{})
+	assert.Equal(t, []string{"geo.line", "geo.point", "start"}, built)
+	assert.NotContains(t, start, "with.overflow")
+}
@@ -0,0 +1,111 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/nagarajRPoojari/picasso/irgen/compiler"
+	"github.com/stretchr/testify/assert"
+)
+
+const checkedSrc = `fn start(args: []string) {
+    say a: int = 7;
+    say b: int = 2;
+    say u: uint = 7;
+    say v: uint = 2;
+    say c: int = a + b;
+    say d: int = a - b;
+    say e: int = a * b;
+    say f: int = a / b;
+    say g: double = a % b;
+    say w: uint = u + v;
+    say x: uint = u - v;
+    say y: uint = u * v;
+    say z: uint = u / v;
+}
+`
+
+// checkedLoc returns where the operator of expr, e.g. a + b, is in
+// checkedSrc, as a trap reports it.
+func checkedLoc(expr string) string {
+	for i, line := range strings.Split(checkedSrc, "\n") {
+		if col := strings.Index(line, expr); col != -1 {
+			return fmt.Sprintf("start.pic:%d:%d", i+1, col+len("a ")+1)
+		}
+	}
+	return ""
+}
+
+func compileChecked(t *testing.T, checked bool) string {
+	t.Helper()
+	res, diags, err := compiler.Compile(context.Background(), compiler.Options{
+		Sources:      source(checkedSrc),
+		CheckedArith: checked,
+		Warnings:     []string{"none"},
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, diags)
+	if res == nil {
+		return ""
+	}
+	return res.Modules["start"].String()
+}
+
+func TestCheckedArith(t *testing.T) {
+	ir := compileChecked(t, true)
+
+	for _, intrinsic := range []string{"sadd", "ssub", "smul", "uadd", "usub", "umul"} {
+		assert.Contains(t, ir, fmt.Sprintf("call { i64, i1 } @llvm.%s.with.overflow.i64(", intrinsic))
+	}
+	// the traps point at the operator
+	for _, expr := range []string{"a + b", "a - b", "a * b", "u + v", "u - v", "u * v"} {
+		assert.Contains(t, ir, "integer overflow at "+checkedLoc(expr), expr)
+	}
+	for _, expr := range []string{"a / b", "a % b", "u / v"} {
+		assert.Contains(t, ir, "division by zero at "+checkedLoc(expr), expr)
+	}
+	// INT64_MIN / -1, for signed division only
+	assert.Equal(t, 1, strings.Count(ir, ", -9223372036854775808"))
+	assert.Contains(t, ir, "integer overflow at "+checkedLoc("a / b"))
+	assert.NotContains(t, ir, "integer overflow at "+checkedLoc("u / v"))
+	assert.NotContains(t, ir, "integer overflow at "+checkedLoc("a % b"))
+}
+
+func TestUncheckedArith(t *testing.T) {
+	ir := compileChecked(t, false)
+
+	assert.NotContains(t, ir, "with.overflow")
+	assert.NotContains(t, ir, "integer overflow at")
+	assert.NotContains(t, ir, "division by zero at")
+	assert.NotContains(t, ir, "-9223372036854775808")
+}
+
+// TestCheckedArithNarrow pins down that narrower integers are checked at 64
+// bits: int8 operands are widened, so 100 + 100 only traps when the result
+// is stored back into an int8.
+func TestCheckedArithNarrow(t *testing.T) {
+	res, diags, err := compiler.Compile(context.Background(), compiler.Options{
+		Sources: source(`fn start(args: []string) {
+    say a: int8 = 100;
+    say b: int8 = 100;
+    say wide: int = a + b;
+    say narrow: int8 = a + b;
+}
+`),
+		CheckedArith: true,
+		Warnings:     []string{"none"},
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, diags)
+	if res == nil {
+		return
+	}
+	ir := res.Modules["start"].String()
+
+	assert.Equal(t, 2, strings.Count(ir, "call { i64, i1 } @llvm.sadd.with.overflow.i64("))
+	assert.NotContains(t, ir, "with.overflow.i8")
+	// the store into narrow is the only int downcast
+	assert.Equal(t, 1, strings.Count(ir, "runtime overflow in int downcast"))
+}
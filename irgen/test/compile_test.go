@@ -0,0 +1,142 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"testing/fstest"
+
+	"github.com/nagarajRPoojari/picasso/irgen/compiler"
+	errorsx "github.com/nagarajRPoojari/picasso/irgen/error"
+	"github.com/stretchr/testify/assert"
+)
+
+func source(src string) fstest.MapFS {
+	return fstest.MapFS{"start.pic": {Data: []byte(src)}}
+}
+
+func TestCompileConcurrently(t *testing.T) {
+	src := `
+class Counter {
+    say n: int;
+
+    fn Counter() {
+        this.n = 0;
+    }
+
+    fn next(): int {
+        this.n = this.n + 1;
+        return this.n;
+    }
+}
+
+fn start(args: []string) {
+    say c: start.Counter = new start.Counter();
+    c.next();
+}
+`
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res, diags, err := compiler.Compile(context.Background(), compiler.Options{Sources: source(src)})
+			assert.NoError(t, err)
+			assert.Empty(t, diags)
+			if assert.NotNil(t, res) {
+				assert.Contains(t, res.Modules["start"].String(), "start.Counter.next")
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// TestCompileDiagnosticsConcurrently checks that compilations running at
+// once, with errors in every phase, each report only their own diagnostics.
+func TestCompileDiagnosticsConcurrently(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			src := fmt.Sprintf(`
+fn start(args: []string) {
+    say x%[1]d: int = missing%[1]d;
+    say y%[1]d: int = "%[1]d";
+}
+`, i)
+			if i%2 == 1 {
+				src = fmt.Sprintf("fn start(args: []string) {\n    say x%d: int = ;\n}\n", i)
+			}
+			_, diags, err := compiler.Compile(context.Background(), compiler.Options{Sources: source(src)})
+			assert.ErrorIs(t, err, compiler.ErrCompile)
+
+			var got []string
+			for _, d := range diags {
+				got = append(got, d.Message)
+			}
+			if i%2 == 1 {
+				assert.Equal(t, []string{"expected expression, found ';'"}, got)
+				return
+			}
+			assert.Equal(t, []string{
+				fmt.Sprintf("x%d declared and not used [-Wunused-variable]", i),
+				fmt.Sprintf("unknown variable missing%d", i),
+				fmt.Sprintf("y%d declared and not used [-Wunused-variable]", i),
+				"failed to implicitly type cast: string to int",
+			}, got)
+		}()
+	}
+	wg.Wait()
+}
+
+func TestCompileReportsDiagnostics(t *testing.T) {
+	src := `
+fn start(args: []string) {
+    say x: int = missing;
+}
+`
+	res, diags, err := compiler.Compile(context.Background(), compiler.Options{
+		Sources:  source(src),
+		Warnings: []string{"none"},
+	})
+	assert.ErrorIs(t, err, compiler.ErrCompile)
+	assert.Nil(t, res)
+	if assert.Len(t, diags, 1) {
+		d := diags[0]
+		assert.Equal(t, "start.pic", d.Path)
+		assert.Equal(t, 3, d.Line)
+		// the end is read from the in-memory source
+		assert.Equal(t, d.Col+len("missing"), d.EndCol)
+		assert.Equal(t, "unknown variable missing", d.Message)
+	}
+	// the process wide collector is left alone
+	assert.Equal(t, 0, errorsx.Diagnostics.Len())
+}
+
+func TestCompileCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _, err := compiler.Compile(ctx, compiler.Options{Sources: source("fn start(args: []string) {}")})
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+// TestCompileVariableNamedLikeType checks that codegen resolves names as the
+// semantic pass does: a variable shadows the type of the same name.
+func TestCompileVariableNamedLikeType(t *testing.T) {
+	src := `
+fn start(args: []string) {
+    say half: int = 3;
+    say y: int = half + 1;
+    y = y + 1;
+}
+`
+	res, diags, err := compiler.Compile(context.Background(), compiler.Options{Sources: source(src)})
+	assert.NoError(t, err)
+	assert.Empty(t, diags)
+	if assert.NotNil(t, res) {
+		// half is read from its variable, not made a zero of type half
+		assert.NotContains(t, res.Modules["start"].String(), "alloca half")
+	}
+}
@@ -0,0 +1,126 @@
+package test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	generator "github.com/nagarajRPoojari/picasso/irgen/codegen"
+	"github.com/nagarajRPoojari/picasso/irgen/deps"
+	errorsx "github.com/nagarajRPoojari/picasso/irgen/error"
+	"github.com/nagarajRPoojari/picasso/irgen/manifest"
+	"github.com/stretchr/testify/assert"
+)
+
+const testPoint = `class Point {
+    say x: int;
+    fn Point(x: int) {
+        this.x = x;
+    }
+}
+`
+
+// writeTarball writes files under a top directory, as in a release tarball.
+func writeTarball(t *testing.T, path string, files map[string]string) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, src := range files {
+		assert.NoError(t, tw.WriteHeader(&tar.Header{Name: "geo-1.0/" + name, Mode: 0o644, Size: int64(len(src))}))
+		_, err := tw.Write([]byte(src))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, tw.Close())
+	assert.NoError(t, gz.Close())
+	assert.NoError(t, os.WriteFile(path, buf.Bytes(), 0o644))
+}
+
+func TestDeps(t *testing.T) {
+	defer errorsx.Diagnostics.Reset()
+	t.Setenv(manifest.IncludeEnv, "")
+
+	root := writeProject(t, map[string]string{
+		"shared/" + manifest.TOML:  "[module]\nname = \"shared\"\n\n[build]\nsources = [\"src\"]\n",
+		"shared/src/geo/point.pic": testPoint,
+		"shared/src/geo/shape.pic": "using \"geo/point\";\n\n" + testShape,
+		"shared/build/stale.pic":   "not vendored\n",
+		"app/" + manifest.TOML:     "[module]\nname = \"app\"\n\n[dependencies.shared]\npath = \"../shared\"\n\n[dependencies.circles]\nurl = \"../geo.tar.gz\"\nversion = \"1.0\"\n",
+		"app/geo/shape.pic":        testShape,
+		"app/start.pic": `using "vendor/shared/geo/shape" as shape;
+using "vendor/circles/geo/point";
+using "geo/shape" as mine;
+
+fn start(args: []string) {
+    say s: shape.Square = new shape.Square(3);
+    say m: mine.Square = new mine.Square(2);
+    say p: point.Point = new point.Point(1);
+    s.area();
+    m.area();
+    m.side = p.x;
+}
+`,
+	})
+	writeTarball(t, filepath.Join(root, "geo.tar.gz"), map[string]string{"geo/point.pic": testPoint})
+	app := filepath.Join(root, "app")
+
+	m, err := manifest.Load(app)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, []manifest.Dependency{
+		{Name: "shared", Path: filepath.Join(root, "shared"), Line: 4},
+		{Name: "circles", URL: "../geo.tar.gz", Version: "1.0", Line: 7},
+	}, m.Deps)
+	assert.EqualError(t, deps.Verify(m), "dependency shared is not in picasso.lock, run irgen deps")
+
+	locked, err := deps.Vendor(m)
+	assert.NoError(t, err)
+	if assert.Len(t, locked, 2) {
+		assert.Equal(t, "../shared", locked[0].Source)
+		assert.Equal(t, "-", locked[0].Version)
+		assert.Equal(t, "1.0", locked[1].Version)
+	}
+	for _, path := range []string{"shared/geo/point.pic", "shared/geo/shape.pic", "circles/geo/point.pic"} {
+		assert.FileExists(t, filepath.Join(app, "vendor", path))
+	}
+	assert.NoFileExists(t, filepath.Join(app, "vendor/shared/build/stale.pic"))
+	lock, err := deps.ReadLock(filepath.Join(app, deps.LockFile))
+	assert.NoError(t, err)
+	assert.Equal(t, locked[1], lock["circles"])
+	assert.NoError(t, deps.Verify(m))
+
+	// packages of the same path in the project and its dependencies are
+	// distinct, and a vendored package imports its siblings as it did
+	g, err := generator.NewGenerator(app, generator.Options{DryRun: true})
+	if assert.NoError(t, err) {
+		assert.NoError(t, g.BuildAll())
+		var names []string
+		for name := range g.Modules() {
+			names = append(names, name)
+		}
+		assert.ElementsMatch(t, []string{"start", "geo.shape", "vendor.shared.geo.shape", "vendor.shared.geo.point", "vendor.circles.geo.point"}, names)
+	}
+
+	// a vendored package edited by hand
+	shape := filepath.Join(app, "vendor/shared/geo/shape.pic")
+	assert.NoError(t, os.WriteFile(shape, []byte(testShape), 0o644))
+	assert.ErrorContains(t, deps.Verify(m), "does not match its checksum in picasso.lock, run irgen deps")
+	_, err = generator.NewGenerator(app, generator.Options{DryRun: true})
+	assert.ErrorContains(t, err, "dependency shared: ")
+
+	// a tarball changed upstream at the same version
+	writeTarball(t, filepath.Join(root, "geo.tar.gz"), map[string]string{"geo/point.pic": testPoint + "\n"})
+	_, err = deps.Vendor(m)
+	assert.ErrorContains(t, err, "dependency circles: checksum mismatch for ../geo.tar.gz at 1.0, picasso.lock has "+lock["circles"].Sum)
+	assert.FileExists(t, filepath.Join(app, "vendor/circles/geo/point.pic"))
+
+	// dropped dependencies are removed from the vendor directory
+	m.Deps = m.Deps[:1]
+	_, err = deps.Vendor(m)
+	assert.NoError(t, err)
+	assert.NoDirExists(t, filepath.Join(app, "vendor/circles"))
+	assert.NoError(t, deps.Verify(m))
+}
@@ -0,0 +1,126 @@
+package test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/nagarajRPoojari/picasso/irgen/compiler"
+	"github.com/stretchr/testify/assert"
+)
+
+// deterministicSrc has classes with enough fields and methods for map
+// iteration order to show in the IR, strings and two packages. Libs are not
+// imported, as the test has no C modules to link them with.
+var deterministicSrc = fstest.MapFS{
+	"shapes.pic": {Data: []byte(`
+interface Shape {
+    fn area(): float64 {
+        return 0.0;
+    }
+    fn name(): string {
+        return "";
+    }
+}
+
+class Rect: shapes.Shape {
+    say w: float64;
+    say h: float64;
+    say label: string;
+    say id: int;
+    say visible: int;
+    say depth: int;
+
+    fn Rect(w: float64, h: float64) {
+        this.w = w;
+        this.h = h;
+        this.label = "rect";
+    }
+
+    fn area(): float64 {
+        return this.w * this.h;
+    }
+
+    fn name(): string {
+        return this.label;
+    }
+
+    fn scale(by: float64): float64 {
+        this.w = this.w * by;
+        this.h = this.h * by;
+        return this.area();
+    }
+}
+`)},
+	"start.pic": {Data: []byte(`
+using "shapes";
+
+class Canvas {
+    say a: shapes.Rect;
+    say b: shapes.Rect;
+    say count: int;
+    say title: string;
+
+    fn Canvas() {
+        this.a = new shapes.Rect(1.0, 2.0);
+        this.b = new shapes.Rect(3.0, 4.0);
+        this.title = "canvas";
+    }
+
+    fn total(): float64 {
+        return this.a.area() + this.b.area();
+    }
+}
+
+fn start(args: []string) {
+    say c: start.Canvas = new start.Canvas();
+    say total: float64 = c.total() + c.a.scale(2.0);
+    say name: string = c.a.name();
+}
+`)},
+}
+
+// build compiles deterministicSrc into a new directory and returns the
+// content of the files written, keyed by name.
+func build(t *testing.T, jobs int) map[string]string {
+	t.Helper()
+	dir := t.TempDir()
+	_, diags, err := compiler.Compile(context.Background(), compiler.Options{
+		Sources:  deterministicSrc,
+		OutDir:   dir,
+		Jobs:     jobs,
+		Warnings: []string{"none"},
+	})
+	if !assert.NoError(t, err) {
+		t.Fatal(diags)
+	}
+
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	files := make(map[string]string)
+	for _, e := range entries {
+		data, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		assert.NoError(t, err)
+		files[e.Name()] = string(data)
+	}
+	return files
+}
+
+func TestBuildIsDeterministic(t *testing.T) {
+	first := build(t, 1)
+	assert.Contains(t, first, "start.ll")
+	assert.Contains(t, first, "shapes.ll")
+
+	for _, jobs := range []int{1, 1, 4, 4} {
+		again := build(t, jobs)
+		assert.Equal(t, len(first), len(again))
+		for name, content := range first {
+			// assert.Equal would print both modules in full
+			if content != again[name] {
+				t.Errorf("%s differs between builds with -j %d", name, jobs)
+			}
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package test
+
+import (
+	"io"
+	"os"
+	"testing"
+	"testing/fstest"
+
+	errorsx "github.com/nagarajRPoojari/picasso/irgen/error"
+	"github.com/stretchr/testify/assert"
+)
+
+const diagSrc = `class Point {
+    say x: int;
+}
+
+fn start(args: []string) {
+    say x: int = 1 + "one";
+    say count: int = 2;
+}
+`
+
+// printDiagnostics prints a type error spanning an expression, with a note,
+// and a warning whose end is read from diagSrc, in format f.
+func printDiagnostics(t *testing.T, f errorsx.Format) string {
+	t.Helper()
+	diags := errorsx.NewCollector()
+	diags.SetSources(fstest.MapFS{"geo/start.pic": {Data: []byte(diagSrc)}})
+	diags.SetFormat(f)
+
+	diags.Add(errorsx.Diagnostic{
+		Phase:    errorsx.PhaseSemantic,
+		Severity: errorsx.SeverityWarning,
+		Code:     "W0001",
+		Message:  "count declared and not used [-Wunused-variable]",
+		Path:     "geo/start.pic",
+		Line:     7,
+		Col:      9,
+	})
+	diags.Add(errorsx.Diagnostic{
+		Phase:   errorsx.PhaseSemantic,
+		Code:    "E0008",
+		Message: "failed to implicitly type cast: string to int",
+		Path:    "geo/start.pic",
+		Line:    6,
+		Col:     18,
+		EndLine: 6,
+		EndCol:  27,
+		Notes: []errorsx.Note{{
+			Message: "x declared here",
+			Path:    "geo/start.pic",
+			Line:    2,
+			Col:     9,
+		}},
+	})
+
+	r, w, err := os.Pipe()
+	if !assert.NoError(t, err) {
+		return ""
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	diags.Print()
+	os.Stdout = stdout
+	w.Close()
+	out, err := io.ReadAll(r)
+	assert.NoError(t, err)
+	return string(out)
+}
+
+func TestDiagnosticsJSON(t *testing.T) {
+	assert.Equal(t, wantJSON, printDiagnostics(t, errorsx.FormatJSON))
+}
+
+func TestDiagnosticsSARIF(t *testing.T) {
+	assert.Equal(t, wantSARIF, printDiagnostics(t, errorsx.FormatSARIF))
+}
+
+// The end of the error is the range given, the ends of the note and the
+// warning span the token they point at.
+const wantJSON = `{
+  "diagnostics": [
+    {
+      "severity": "error",
+      "code": "E0008",
+      "phase": "Semantic",
+      "message": "failed to implicitly type cast: string to int",
+      "file": "geo/start.pic",
+      "line": 6,
+      "col": 18,
+      "endLine": 6,
+      "endCol": 27,
+      "related": [
+        {
+          "message": "x declared here",
+          "file": "geo/start.pic",
+          "line": 2,
+          "col": 9,
+          "endLine": 2,
+          "endCol": 10
+        }
+      ]
+    },
+    {
+      "severity": "warning",
+      "code": "W0001",
+      "phase": "Semantic",
+      "message": "count declared and not used [-Wunused-variable]",
+      "file": "geo/start.pic",
+      "line": 7,
+      "col": 9,
+      "endLine": 7,
+      "endCol": 14
+    }
+  ]
+}
+`
+
+const wantSARIF = `{
+  "$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+  "version": "2.1.0",
+  "runs": [
+    {
+      "tool": {
+        "driver": {
+          "name": "irgen",
+          "rules": [
+            {
+              "id": "E0008"
+            },
+            {
+              "id": "W0001"
+            }
+          ]
+        }
+      },
+      "results": [
+        {
+          "ruleId": "E0008",
+          "level": "error",
+          "message": {
+            "text": "failed to implicitly type cast: string to int"
+          },
+          "locations": [
+            {
+              "physicalLocation": {
+                "artifactLocation": {
+                  "uri": "geo/start.pic"
+                },
+                "region": {
+                  "startLine": 6,
+                  "startColumn": 18,
+                  "endLine": 6,
+                  "endColumn": 27
+                }
+              }
+            }
+          ],
+          "relatedLocations": [
+            {
+              "id": 0,
+              "message": {
+                "text": "x declared here"
+              },
+              "physicalLocation": {
+                "artifactLocation": {
+                  "uri": "geo/start.pic"
+                },
+                "region": {
+                  "startLine": 2,
+                  "startColumn": 9,
+                  "endLine": 2,
+                  "endColumn": 10
+                }
+              }
+            }
+          ]
+        },
+        {
+          "ruleId": "W0001",
+          "level": "warning",
+          "message": {
+            "text": "count declared and not used [-Wunused-variable]"
+          },
+          "locations": [
+            {
+              "physicalLocation": {
+                "artifactLocation": {
+                  "uri": "geo/start.pic"
+                },
+                "region": {
+                  "startLine": 7,
+                  "startColumn": 9,
+                  "endLine": 7,
+                  "endColumn": 14
+                }
+              }
+            }
+          ]
+        }
+      ]
+    }
+  ]
+}
+`
@@ -0,0 +1,155 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nagarajRPoojari/picasso/irgen/doc"
+	errorsx "github.com/nagarajRPoojari/picasso/irgen/error"
+	"github.com/stretchr/testify/assert"
+)
+
+const docShapes = `// Package shapes has geometric shapes.
+//
+// All of them have an area.
+
+using "builtin/syncio";
+
+// Shape is a closed figure.
+interface Shape {
+    // area returns the area.
+    fn area(): float64 {}
+}
+
+// Square is a square.
+class Square: Shape {
+    say side: float64; // not a doc comment
+
+    // internal state
+    say internal n: int;
+    // count of squares
+    say static count: int, other: int;
+
+    // Square makes a square
+    // of given side.
+    fn Square(side: float64) {
+        this.side = side;
+    }
+
+    fn area(): float64 {
+        return this.side * this.side;
+    }
+
+    // hidden
+    fn internal secret() {}
+}
+
+class internal Hidden {}
+`
+
+const docCircle = `using "shapes" as s;
+
+// Circle is round.
+class Circle: s.Shape {
+    fn Circle() {}
+    fn static unit(): []float64 {}
+}
+`
+
+func TestDocParse(t *testing.T) {
+	p, err := doc.Parse(errorsx.NewCollector(), "shapes", "shapes.pic", []byte(docShapes))
+	assert.NoError(t, err)
+	assert.Equal(t, "Package shapes has geometric shapes.\n\nAll of them have an area.", p.Doc)
+
+	if assert.Len(t, p.Interfaces, 1) && assert.Len(t, p.Interfaces[0].Methods, 1) {
+		ifs := p.Interfaces[0]
+		assert.Equal(t, "Shape is a closed figure.", ifs.Doc)
+		assert.Equal(t, 8, ifs.Line)
+		assert.Equal(t, &doc.Member{Name: "area", Decl: "fn area(): float64", Doc: "area returns the area.", Line: 10}, ifs.Methods[0])
+	}
+
+	// internal declarations are left out
+	if assert.Len(t, p.Classes, 1) {
+		cls := p.Classes[0]
+		assert.Equal(t, "Square is a square.", cls.Doc)
+		assert.Equal(t, "Shape", cls.Implements)
+		assert.Equal(t, []*doc.Member{
+			{Name: "side", Decl: "say side: float64", Line: 15},
+			{Name: "count", Decl: "say static count: int", Doc: "count of squares", Line: 20},
+			{Name: "other", Decl: "say static other: int", Doc: "count of squares", Line: 20},
+		}, cls.Fields)
+		assert.Equal(t, []*doc.Member{
+			{Name: "Square", Decl: "fn Square(side: float64)", Doc: "Square makes a square\nof given side.", Line: 24},
+		}, cls.Constructors)
+		assert.Equal(t, []*doc.Member{
+			{Name: "area", Decl: "fn area(): float64", Line: 28},
+		}, cls.Methods)
+	}
+}
+
+func TestDocPackageDocIsNotDeclDoc(t *testing.T) {
+	p, err := doc.Parse(errorsx.NewCollector(), "a", "a.pic", []byte("// A is a class.\nclass A {}\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, "", p.Doc)
+	if assert.Len(t, p.Classes, 1) {
+		assert.Equal(t, "A is a class.", p.Classes[0].Doc)
+	}
+}
+
+func TestDocSyntaxError(t *testing.T) {
+	_, err := doc.Parse(errorsx.NewCollector(), "a", "a.pic", []byte("class A {"))
+	assert.ErrorIs(t, err, doc.ErrSyntax)
+}
+
+func TestDocWrite(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src")
+	assert.NoError(t, os.MkdirAll(filepath.Join(src, "geo"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(src, "shapes.pic"), []byte(docShapes), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(src, "geo", "circle.pic"), []byte(docCircle), 0o644))
+
+	shapes, err := doc.Parse(errorsx.NewCollector(), "shapes", filepath.Join(src, "shapes.pic"), []byte(docShapes))
+	assert.NoError(t, err)
+	circle, err := doc.Parse(errorsx.NewCollector(), "geo.circle", filepath.Join(src, "geo", "circle.pic"), []byte(docCircle))
+	assert.NoError(t, err)
+
+	doc.Link([]*doc.Package{shapes, circle})
+	assert.Equal(t, "shapes.Shape", shapes.Classes[0].Implements)
+	assert.Equal(t, "shapes.Shape", circle.Classes[0].Implements)
+	assert.Equal(t, []string{"geo.circle.Circle", "shapes.Square"}, shapes.Interfaces[0].Implementers)
+
+	out := filepath.Join(dir, "out")
+	assert.NoError(t, doc.Write(out, []*doc.Package{shapes, circle}))
+
+	read := func(name string) string {
+		b, err := os.ReadFile(filepath.Join(out, name))
+		assert.NoError(t, err)
+		return string(b)
+	}
+	md := read("shapes.md")
+	for _, want := range []string{
+		"# Package shapes\n\nPackage shapes has geometric shapes.\n\nAll of them have an area.\n",
+		"- [geo.circle.Circle](geo.circle.md#Circle)\n",
+		"Implements [shapes.Shape](shapes.md#Shape).\n",
+		"```picasso\nfn area(): float64\n```\n\narea returns the area.\n\n[source](../src/shapes.pic#L10)\n",
+		"- `say static count: int`: count of squares ([source](../src/shapes.pic#L20))\n",
+	} {
+		assert.Contains(t, md, want)
+	}
+	assert.NotContains(t, md, "secret")
+	assert.NotContains(t, md, "Hidden")
+
+	html := read("geo.circle.html")
+	for _, want := range []string{
+		`<p>Implements <a href="shapes.html#Shape">shapes.Shape</a>.</p>`,
+		`<pre><code>fn static unit(): []float64</code></pre>`,
+		`<a class="source" href="../src/geo/circle.pic#L4">source</a>`,
+	} {
+		assert.Contains(t, html, want)
+	}
+
+	assert.Equal(t, "# Packages\n\n- [geo.circle](geo.circle.md)\n- [shapes](shapes.md): Package shapes has geometric shapes.\n", read("index.md"))
+	assert.True(t, strings.Contains(read("index.html"), `<a href="shapes.html">shapes</a>`))
+}
@@ -0,0 +1,109 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/nagarajRPoojari/picasso/irgen/ast"
+	"github.com/nagarajRPoojari/picasso/irgen/codegen/pipeline"
+	"github.com/nagarajRPoojari/picasso/irgen/compiler"
+	errorsx "github.com/nagarajRPoojari/picasso/irgen/error"
+	"github.com/nagarajRPoojari/picasso/irgen/lexer"
+	"github.com/nagarajRPoojari/picasso/irgen/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+const dumpSrc = `fn start(args: []string) {
+    say x: int = 1 + 2;
+}
+`
+
+func TestDumpTokens(t *testing.T) {
+	tokens := lexer.TokenizeReader(errorsx.NewCollector(), "a.pic", strings.NewReader("say x: int = 1;"))
+	var got []string
+	for _, tk := range tokens {
+		got = append(got, tk.String())
+	}
+	assert.Equal(t, []string{"say()", "identifier(x)", "colon()", "identifier(int)", "assignment()",
+		"number(1)", "semi_colon()", "eof()"}, got)
+	assert.Equal(t, lexer.SourceLoc{FilePath: "a.pic", Line: 1, Col: 5}, tokens[1].Src)
+}
+
+func TestDumpAST(t *testing.T) {
+	diags := errorsx.NewCollector()
+	tree := parser.ParseAllFrom(diags, "a.pic", strings.NewReader(dumpSrc))
+	assert.Equal(t, 0, diags.Errors())
+
+	var text bytes.Buffer
+	assert.NoError(t, ast.Fprint(&text, tree))
+	for _, want := range []string{
+		"BlockStatement\n  Body: [1]\n    0: FunctionDefinitionStatement @ a.pic:1:1\n",
+		"          ExplicitType: SymbolType\n            Value: \"int\"\n",
+		"AssignedValue: BinaryExpression @ a.pic:2:20\n",
+		"Operator: plus()\n",
+	} {
+		assert.Contains(t, text.String(), want)
+	}
+
+	doc, err := json.Marshal(ast.JSON(tree))
+	assert.NoError(t, err)
+	var got struct {
+		Node string
+		Body []struct {
+			Node string
+			Name string
+			Loc  struct {
+				File      string
+				Line, Col int
+			}
+		}
+	}
+	assert.NoError(t, json.Unmarshal(doc, &got))
+	assert.Equal(t, "BlockStatement", got.Node)
+	if assert.Len(t, got.Body, 1) {
+		assert.Equal(t, "FunctionDefinitionStatement", got.Body[0].Node)
+		assert.Equal(t, "start", got.Body[0].Name)
+		assert.Equal(t, "a.pic", got.Body[0].Loc.File)
+		assert.Equal(t, 1, got.Body[0].Loc.Line)
+	}
+}
+
+func TestDumpStopAfter(t *testing.T) {
+	_, err := pipeline.ParseStep("link")
+	assert.ErrorContains(t, err, `unknown pipeline step "link", want one of register, declare, define, optimize`)
+
+	files := map[string]string{
+		"start.pic": `using "geo/shape" as shape;
+
+fn start(args: []string) {
+    say s: shape.Square = new shape.Square(3);
+    s.area();
+}
+`,
+		"geo/shape.pic": testShape,
+	}
+	compile := func(last pipeline.Step) map[string]string {
+		res, _, err := compiler.Compile(context.Background(), compiler.Options{Sources: os.DirFS(writeProject(t, files)), StopAfter: last})
+		assert.NoError(t, err)
+		out := make(map[string]string)
+		if res != nil {
+			for name, m := range res.Modules {
+				out[name] = m.String()
+			}
+		}
+		return out
+	}
+
+	declared := compile(pipeline.StepDeclare)
+	assert.Contains(t, declared["geo.shape"], "declare ")
+	assert.NotContains(t, declared["geo.shape"], "define ")
+	assert.NotContains(t, declared["start"], "define ")
+
+	all := compile("")
+	assert.Contains(t, all["geo.shape"], "define ")
+	assert.Contains(t, all["start"], "define ")
+}
@@ -0,0 +1,83 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nagarajRPoojari/picasso/irgen/codegen/exports"
+	errorsx "github.com/nagarajRPoojari/picasso/irgen/error"
+	"github.com/nagarajRPoojari/picasso/irgen/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+const exportsSrc = `using "builtin/syncio";
+
+interface Shape {
+    fn area(): float64 {
+        return 0.0;
+    }
+}
+
+class Square: lib.Shape {
+    say internal side: float64;
+    say tags: []string, count: int;
+
+    fn Square(side: float64) {
+        this.side = side;
+    }
+
+    fn area(): float64 {
+        return this.side * this.side;
+    }
+
+    fn split(): (float64, float64) {
+        return this.side, this.side;
+    }
+}
+`
+
+func exportsOf(t *testing.T, src string) *exports.Package {
+	t.Helper()
+	return exports.FromAST("lib", parser.ParseAllFrom(errorsx.NewCollector(), "lib.pic", strings.NewReader(src)))
+}
+
+func TestExportsRoundTrip(t *testing.T) {
+	p := exportsOf(t, exportsSrc)
+	path := exports.Path(t.TempDir(), "lib")
+	assert.NoError(t, exports.Write(path, p))
+
+	read, err := exports.Read(path)
+	assert.NoError(t, err)
+	assert.Equal(t, p, read)
+
+	tree, err := read.AST()
+	assert.NoError(t, err)
+	assert.Equal(t, p, exports.FromAST("lib", tree))
+
+	var out strings.Builder
+	assert.NoError(t, exports.Fprint(&out, p))
+	assert.Contains(t, out.String(), "class Square: lib.Shape {")
+	assert.Contains(t, out.String(), "say internal side: float64;")
+	assert.Contains(t, out.String(), "fn split(): (float64, float64);")
+}
+
+func TestExportsHash(t *testing.T) {
+	hash := exportsOf(t, exportsSrc).Hash()
+
+	// moving code and changing bodies keeps the interface
+	moved := "\n\n" + strings.Replace(exportsSrc, "this.side * this.side", "this.side", 1)
+	assert.Equal(t, hash, exportsOf(t, moved).Hash())
+
+	changed := strings.Replace(exportsSrc, "fn split()", "fn split(n: int)", 1)
+	assert.NotEqual(t, hash, exportsOf(t, changed).Hash())
+}
+
+func TestExportsVersionMismatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lib.exports")
+	assert.NoError(t, os.WriteFile(path, []byte(`{"version": 0, "name": "lib"}`), 0o644))
+
+	_, err := exports.Read(path)
+	assert.ErrorIs(t, err, exports.ErrVersion)
+}
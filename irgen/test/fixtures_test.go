@@ -0,0 +1,73 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nagarajRPoojari/picasso/irgen/compiler"
+	"github.com/stretchr/testify/assert"
+)
+
+// compileFixture compiles the e2e project at dir, relative to the e2e
+// directory, and returns its diagnostics as "code line:col message", with
+// notes on lines of their own. The project must fail to compile.
+func compileFixture(t *testing.T, dir string) []string {
+	t.Helper()
+	_, diags, err := compiler.Compile(context.Background(), compiler.Options{
+		Sources: os.DirFS(filepath.Join("../../e2e", dir)),
+	})
+	assert.ErrorIs(t, err, compiler.ErrCompile)
+
+	var got []string
+	for _, d := range diags {
+		assert.Equal(t, "start.pic", d.Path)
+		got = append(got, fmt.Sprintf("%s %d:%d %s", d.Code, d.Line, d.Col, d.Message))
+		for _, n := range d.Notes {
+			got = append(got, fmt.Sprintf("  note %d:%d %s", n.Line, n.Col, n.Message))
+		}
+	}
+	return got
+}
+
+// TestMultipleErrorsFixture checks that every error of a file is reported
+// in one run, not only the first.
+func TestMultipleErrorsFixture(t *testing.T) {
+	assert.Equal(t, []string{
+		"W0001 7:13 x declared and not used [-Wunused-variable]",
+		"E0018 7:22 unknown variable undefinedVar",
+		"E0018 13:22 unknown variable otherUndefined",
+		"E0008 20:35 failed to implicitly type cast: string to int",
+	}, compileFixture(t, "functions/multiple_errors_fail"))
+}
+
+// TestSyntaxErrorsFixture checks that the parser recovers after a syntax
+// error and reports the next ones of the file.
+func TestSyntaxErrorsFixture(t *testing.T) {
+	assert.Equal(t, []string{
+		"E1001 7:27 expected ';' after number 1",
+		"E1001 8:39 expected ')' to close call started at 8:22, found ';'",
+		"E1001 13:22 expected expression, found ';'",
+	}, compileFixture(t, "functions/syntax_errors_fail"))
+}
+
+// TestMissingReturnFixture checks that both ways of leaving a method
+// without returning are reported, with a note at the statement to blame.
+func TestMissingReturnFixture(t *testing.T) {
+	assert.Equal(t, []string{
+		"E0037 7:5 missing return at end of method Sign.of",
+		"  note 8:9 control can leave this statement without returning",
+		"E0037 16:5 missing return at end of method Sign.first",
+		"  note 17:9 control can leave this statement without returning",
+	}, compileFixture(t, "functions/missing_return_fail"))
+}
+
+// TestUnknownTypeFixture checks that an unknown field type is reported by
+// the semantic pass, before any IR is generated.
+func TestUnknownTypeFixture(t *testing.T) {
+	assert.Equal(t, []string{
+		"E0036 5:5 unknown type start.Point",
+	}, compileFixture(t, "types/composites/classes/unknown_type_fail"))
+}
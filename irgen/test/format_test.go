@@ -0,0 +1,206 @@
+package test
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/nagarajRPoojari/picasso/irgen/ast"
+	errorsx "github.com/nagarajRPoojari/picasso/irgen/error"
+	"github.com/nagarajRPoojari/picasso/irgen/format"
+	"github.com/nagarajRPoojari/picasso/irgen/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{
+			name: "layout",
+			src: `class A{say x:int;say y :int ;
+fn A(x:int,y:int){this.x=x;this.y=y;}
+
+
+fn sum():int{return this.x+this.y;}}`,
+			want: `class A {
+    say x: int;
+    say y: int;
+    fn A(x: int, y: int) {
+        this.x = x;
+        this.y = y;
+    }
+
+    fn sum(): int {
+        return this.x + this.y;
+    }
+}
+`,
+		},
+		{
+			name: "parentheses",
+			src: `fn start(args: []string) {
+    if (a > 0) { a = (a + 1) * 2; }
+    b = (a - b) - c;
+    b = a - (b - c);
+    c = -(-a);
+    d = [1, (a && b)];
+    e = (new A(1)).x;
+    foreach i, j in (0..n) {}
+}`,
+			want: `fn start(args: []string) {
+    if a > 0 {
+        a = (a + 1) * 2;
+    }
+    b = (a - b) - c;
+    b = a - b - c;
+    c = - -a;
+    d = [1, (a && b)];
+    e = (new A(1)).x;
+    foreach i, j in 0..n {}
+}
+`,
+		},
+		{
+			name: "comments",
+			src: `// package doc
+
+using "builtin/syncio";
+// arrays
+using "builtin/array" as arr;
+
+fn start(args: []string) { // entry
+    // first
+    a(); // one
+    bb(); // two
+
+
+    // last
+}
+// end`,
+			want: `// package doc
+
+// arrays
+using "builtin/array" as arr;
+using "builtin/syncio";
+
+fn start(args: []string) { // entry
+    // first
+    a();  // one
+    bb(); // two
+
+    // last
+}
+// end
+`,
+		},
+		{
+			name: "literals and operators",
+			src:  `fn f() { say a: int, b: float64 = 1, 2.50; a += 1; a, b = b, a; say s: string = "a\tb"; return; }`,
+			want: `fn f() {
+    say a: int, b: float64 = 1, 2.50;
+    a += 1;
+    a, b = b, a;
+    say s: string = "a\tb";
+    return;
+}
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := format.Source(errorsx.NewCollector(), "test.pic", []byte(tt.src))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+}
+
+func TestFormatSyntaxError(t *testing.T) {
+	_, err := format.Source(errorsx.NewCollector(), "test.pic", []byte("fn f( {"))
+	assert.ErrorIs(t, err, format.ErrSyntax)
+}
+
+// TestFormatE2E formats every e2e source that parses and checks that the
+// result parses to the same tree and is formatted already.
+func TestFormatE2E(t *testing.T) {
+	err := filepath.WalkDir("../../e2e", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(path, ".pic") {
+			return err
+		}
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		diags := errorsx.NewCollector()
+		got, err := format.Source(diags, path, src)
+		if err != nil {
+			// the negative tests have syntax errors
+			assert.ErrorIs(t, err, format.ErrSyntax, path)
+			return nil
+		}
+		again, err := format.Source(diags, path, got)
+		assert.NoError(t, err, path)
+		assert.Equal(t, string(got), string(again), path)
+
+		// imports are sorted
+		want := parser.ParseAllFrom(diags, path, strings.NewReader(string(src)))
+		imports := 0
+		for imports < len(want.Body) {
+			if _, ok := want.Body[imports].(ast.ImportStatement); !ok {
+				break
+			}
+			imports++
+		}
+		slices.SortStableFunc(want.Body[:imports], func(a, b ast.Statement) int {
+			return strings.Compare(a.(ast.ImportStatement).Name, b.(ast.ImportStatement).Name)
+		})
+		tree := parser.ParseAllFrom(diags, path, strings.NewReader(string(got)))
+		assert.Equal(t, shape(reflect.ValueOf(want)), shape(reflect.ValueOf(tree)), path)
+		return nil
+	})
+	assert.NoError(t, err)
+}
+
+// shape prints v without source positions, to compare trees parsed from
+// source laid out differently.
+func shape(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.Interface, reflect.Pointer:
+		if v.IsNil() {
+			return "nil"
+		}
+		return shape(v.Elem())
+	case reflect.Struct:
+		if v.Type() == reflect.TypeOf(ast.SourceLoc{}) {
+			return ""
+		}
+		var b strings.Builder
+		b.WriteString(v.Type().Name() + "{")
+		for i := 0; i < v.NumField(); i++ {
+			switch v.Type().Field(i).Name {
+			case "SourceLoc", "Src", "Comments":
+				continue
+			}
+			b.WriteString(v.Type().Field(i).Name + ":" + shape(v.Field(i)) + " ")
+		}
+		return b.String() + "}"
+	case reflect.Slice:
+		var b strings.Builder
+		b.WriteString("[")
+		for i := 0; i < v.Len(); i++ {
+			b.WriteString(shape(v.Index(i)) + " ")
+		}
+		return b.String() + "]"
+	}
+	return fmt.Sprint(v.Interface())
+}
@@ -0,0 +1,124 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/nagarajRPoojari/picasso/irgen/ast"
+	"github.com/nagarajRPoojari/picasso/irgen/codegen/tools"
+	"github.com/nagarajRPoojari/picasso/irgen/compiler"
+	errorsx "github.com/nagarajRPoojari/picasso/irgen/error"
+	"github.com/nagarajRPoojari/picasso/irgen/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+func graphOf(t *testing.T, files map[string]string) *tools.ImportGraph {
+	diags := errorsx.NewCollector()
+	pkgs := make(map[string]ast.BlockStatement)
+	for name, src := range files {
+		pkgs[name] = parser.ParseAllFrom(diags, name+".pic", strings.NewReader(src))
+	}
+	assert.Equal(t, 0, diags.Errors())
+	return tools.NewImportGraph(pkgs)
+}
+
+func TestImportGraph(t *testing.T) {
+	g := graphOf(t, map[string]string{
+		"start":       "using \"builtin/syncio\";\nusing \"geo/shape\" as shape;\nusing \"c/ffi/coo\";\n",
+		"geo.shape":   "using \"geo/point\";\nusing \"picasso/os\";\n",
+		"geo.point":   "using \"builtin/syncio\";\n",
+		"old":         "using \"geo/point\" as p;\n",
+		"old_test":    "using \"old\";\n",
+		"picasso.io":  "class IO {}\n",
+		"picasso.os":  "using \"builtin/os\";\n",
+		"picasso.net": "using \"picasso/io\";\n",
+	})
+
+	kinds := make(map[string]tools.NodeKind)
+	for _, n := range g.Nodes() {
+		kinds[n.Name] = n.Kind
+	}
+	assert.Equal(t, map[string]tools.NodeKind{
+		"start":          tools.KindPackage,
+		"geo.shape":      tools.KindPackage,
+		"geo.point":      tools.KindPackage,
+		"old":            tools.KindPackage,
+		"old_test":       tools.KindPackage,
+		"picasso.os":     tools.KindLib,
+		"builtin.syncio": tools.KindBuiltin,
+		"builtin.os":     tools.KindBuiltin,
+		"c.ffi.coo":      tools.KindFFI,
+	}, kinds)
+
+	assert.Equal(t, []string{"old"}, g.Unused("start"))
+	assert.Equal(t, []tools.FanIn{
+		{Name: "builtin.syncio", Importers: 5},
+		{Name: "geo.point", Importers: 4},
+	}, g.TopFanIn(2))
+	assert.Nil(t, g.Cycle())
+
+	r := g.Report("start", 1)
+	var dot bytes.Buffer
+	assert.NoError(t, r.WriteDOT(&dot))
+	for _, want := range []string{
+		"  // unused: old\n",
+		"  // fan-in: builtin.syncio (5)\n",
+		`  "c.ffi.coo" [shape=hexagon];`,
+		`  "old" [shape=box, color=gray, fontcolor=gray];`,
+		`  "old" -> "geo.point" [label="p"];`,
+		`  "start" -> "geo.shape";`,
+	} {
+		assert.Contains(t, dot.String(), want)
+	}
+
+	var doc bytes.Buffer
+	assert.NoError(t, r.WriteJSON(&doc))
+	var got tools.Report
+	assert.NoError(t, json.Unmarshal(doc.Bytes(), &got))
+	assert.Equal(t, r.Nodes, got.Nodes)
+	assert.Nil(t, got.Cycle)
+}
+
+func TestImportGraphCycle(t *testing.T) {
+	g := graphOf(t, map[string]string{
+		"start": "using \"a\";\n",
+		"a":     "using \"b\";\n",
+		"b":     "using \"c\";\n",
+		"c":     "using \"a\";\n",
+	})
+	cycle := g.Cycle()
+	assert.Equal(t, "a -> b -> c -> a", tools.CyclePath(cycle))
+	if assert.Len(t, cycle, 3) {
+		assert.Equal(t, ast.SourceLoc{FilePath: "c.pic", Line: 1, Col: 1}, cycle[2].Loc)
+	}
+
+	var dot bytes.Buffer
+	assert.NoError(t, g.Report("start", 5).WriteDOT(&dot))
+	assert.Contains(t, dot.String(), "  // cycle: a -> b -> c -> a\n")
+	assert.Contains(t, dot.String(), `  "c" -> "a" [color=red];`)
+	assert.Contains(t, dot.String(), "  \"start\" -> \"a\";\n")
+
+	// the build reports the same path, with the imports making it
+	dir := writeProject(t, map[string]string{
+		"start.pic": "using \"a\";\nfn start(args: []string) {}\n",
+		"a.pic":     "using \"b\";\n",
+		"b.pic":     "using \"a\";\n",
+	})
+	_, diags, err := compiler.Compile(context.Background(), compiler.Options{Sources: os.DirFS(dir)})
+	assert.ErrorIs(t, err, compiler.ErrCompile)
+	var msgs []string
+	for _, d := range diags {
+		if d.Code == "E0038" {
+			msgs = append(msgs, d.Message)
+			if assert.Len(t, d.Notes, 2) {
+				assert.Equal(t, "a imports b here", d.Notes[0].Message)
+				assert.Equal(t, "b.pic", d.Notes[1].Path)
+			}
+		}
+	}
+	assert.Equal(t, []string{"cyclic dependency detected: a -> b -> a"}, msgs)
+}
@@ -0,0 +1,439 @@
+package test
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	errorsx "github.com/nagarajRPoojari/picasso/irgen/error"
+	"github.com/nagarajRPoojari/picasso/irgen/lsp"
+	"github.com/nagarajRPoojari/picasso/irgen/manifest"
+	"github.com/stretchr/testify/assert"
+)
+
+// lspClient drives a language server over pipes like an editor does.
+type lspClient struct {
+	t    *testing.T
+	in   io.WriteCloser
+	msgs chan lspMessage
+	done chan error
+	id   int
+	// notifications received while waiting for responses
+	notes []lspMessage
+}
+
+type lspMessage struct {
+	ID     *int            `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code int `json:"code"`
+	} `json:"error"`
+}
+
+func startLSP(t *testing.T, root string) *lspClient {
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	c := &lspClient{t: t, in: inW, msgs: make(chan lspMessage, 64), done: make(chan error, 1)}
+	go func() {
+		err := lsp.NewServer("").Serve(inR, outW)
+		outW.Close()
+		c.done <- err
+	}()
+	// the server blocks writing until its output is read
+	go func() {
+		defer close(c.msgs)
+		out := bufio.NewReader(outR)
+		for {
+			msg, err := readLSPMessage(out)
+			if err != nil {
+				return
+			}
+			c.msgs <- msg
+		}
+	}()
+
+	var res struct {
+		Capabilities struct {
+			DefinitionProvider bool `json:"definitionProvider"`
+		} `json:"capabilities"`
+	}
+	c.request("initialize", map[string]any{"rootUri": fileURI(root)}, &res)
+	assert.True(t, res.Capabilities.DefinitionProvider)
+	c.notify("initialized", map[string]any{})
+	return c
+}
+
+func fileURI(p string) string {
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(p)}).String()
+}
+
+func (c *lspClient) send(msg map[string]any) {
+	msg["jsonrpc"] = "2.0"
+	body, err := json.Marshal(msg)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	fmt.Fprintf(c.in, "Content-Length: %d\r\n\r\n%s", len(body), body)
+}
+
+func readLSPMessage(r *bufio.Reader) (lspMessage, error) {
+	var msg lspMessage
+	header, err := textproto.NewReader(r).ReadMIMEHeader()
+	if err != nil {
+		return msg, err
+	}
+	n, err := strconv.Atoi(header.Get("Content-Length"))
+	if err != nil {
+		return msg, err
+	}
+	body := make([]byte, n)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return msg, err
+	}
+	return msg, json.Unmarshal(body, &msg)
+}
+
+func (c *lspClient) read() lspMessage {
+	msg, ok := <-c.msgs
+	if !ok {
+		c.t.Fatal("server output closed")
+	}
+	return msg
+}
+
+func (c *lspClient) notify(method string, params any) {
+	c.send(map[string]any{"method": method, "params": params})
+}
+
+// request sends a request and decodes the result of its response into
+// result, keeping the notifications received meanwhile.
+func (c *lspClient) request(method string, params any, result any) {
+	c.id++
+	c.send(map[string]any{"id": c.id, "method": method, "params": params})
+	for {
+		msg := c.read()
+		if msg.ID == nil {
+			c.notes = append(c.notes, msg)
+			continue
+		}
+		assert.Equal(c.t, c.id, *msg.ID)
+		assert.Nil(c.t, msg.Error, method)
+		if result != nil {
+			assert.NoError(c.t, json.Unmarshal(msg.Result, result), method)
+		}
+		return
+	}
+}
+
+// diagnostics returns the messages of the diagnostics last published for
+// uri, after the server has handled everything sent so far.
+func (c *lspClient) diagnostics(uri string) []string {
+	// a round trip, after which every notification sent before is handled
+	c.request("textDocument/hover", lspPosition{uri, 0, 0}.params(), nil)
+	msgs := []string{}
+	for _, note := range c.notes {
+		var params struct {
+			URI         string `json:"uri"`
+			Diagnostics []struct {
+				Message string `json:"message"`
+				Range   struct {
+					Start struct{ Line, Character int }
+				} `json:"range"`
+			} `json:"diagnostics"`
+		}
+		if note.Method != "textDocument/publishDiagnostics" || json.Unmarshal(note.Params, &params) != nil || params.URI != uri {
+			continue
+		}
+		msgs = msgs[:0]
+		for _, d := range params.Diagnostics {
+			msgs = append(msgs, fmt.Sprintf("%d:%d %s", d.Range.Start.Line, d.Range.Start.Character, d.Message))
+		}
+	}
+	return msgs
+}
+
+func (c *lspClient) exit() {
+	c.request("shutdown", nil, nil)
+	c.notify("exit", nil)
+	assert.NoError(c.t, <-c.done)
+}
+
+type lspPosition struct {
+	URI       string
+	Line, Col int
+}
+
+func (p lspPosition) params() map[string]any {
+	return map[string]any{
+		"textDocument": map[string]any{"uri": p.URI},
+		"position":     map[string]any{"line": p.Line, "character": p.Col},
+	}
+}
+
+const lspStart = `using "builtin/syncio";
+using "shapes" as sh;
+
+fn start(args: []string) {
+    say p: sh.Point = new sh.Point(1, 2);
+    say n: int = p.sum();
+    syncio.printf("%d\n", n + p.x);
+}
+`
+
+const lspShapes = `class Point {
+    say x: int;
+    say internal y: int;
+
+    fn Point(x: int, y: int) {
+        this.x = x;
+        this.y = y;
+    }
+
+    fn sum(): int {
+        return this.x + this.y;
+    }
+}
+`
+
+func lspProject(t *testing.T) (root, start, shapes string) {
+	root = t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(root, "start.pic"), []byte(lspStart), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(root, "shapes.pic"), []byte(lspShapes), 0o644))
+	return root, fileURI(filepath.Join(root, "start.pic")), fileURI(filepath.Join(root, "shapes.pic"))
+}
+
+func TestLSPDiagnostics(t *testing.T) {
+	root, start, _ := lspProject(t)
+	c := startLSP(t, root)
+	defer c.exit()
+
+	c.notify("textDocument/didOpen", map[string]any{
+		"textDocument": map[string]any{"uri": start, "version": 1, "text": lspStart},
+	})
+	assert.Empty(t, c.diagnostics(start))
+
+	// a type error, then a syntax error, then fixed
+	typed := strings.Replace(lspStart, "say n: int = p.sum();", "say n: string = p.sum();", 1)
+	c.notify("textDocument/didChange", map[string]any{
+		"textDocument":   map[string]any{"uri": start, "version": 2},
+		"contentChanges": []map[string]any{{"text": typed}},
+	})
+	diags := c.diagnostics(start)
+	if assert.Len(t, diags, 1) {
+		assert.True(t, strings.HasPrefix(diags[0], "5:"), diags[0])
+	}
+
+	broken := strings.Replace(lspStart, "say n: int = p.sum();", "say n: int = ;", 1)
+	c.notify("textDocument/didChange", map[string]any{
+		"textDocument":   map[string]any{"uri": start, "version": 3},
+		"contentChanges": []map[string]any{{"text": broken}},
+	})
+	diags = c.diagnostics(start)
+	if assert.NotEmpty(t, diags) {
+		assert.Equal(t, "5:17 expected expression, found ';'", diags[0])
+	}
+
+	c.notify("textDocument/didChange", map[string]any{
+		"textDocument":   map[string]any{"uri": start, "version": 4},
+		"contentChanges": []map[string]any{{"text": lspStart}},
+	})
+	assert.Empty(t, c.diagnostics(start))
+	// the process wide collector is left alone
+	assert.Equal(t, 0, errorsx.Diagnostics.Len())
+}
+
+// TestLSPManifest checks that packages are named and looked up by the
+// roots of the manifest: src/start.pic is package start, and the libs are
+// found in the include paths.
+func TestLSPManifest(t *testing.T) {
+	t.Setenv(manifest.IncludeEnv, "")
+	libs := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(libs, "util"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(libs, "util", "num.pic"), []byte("class Num {\n    say v: int;\n\n    fn Num(v: int) {\n        this.v = v;\n    }\n}\n"), 0o644))
+
+	text := strings.Replace(lspStart, `using "shapes" as sh;`, "using \"geo/shapes\" as sh;\nusing \"util/num\";", 1)
+	text = strings.Replace(text, "n + p.x", "n + k.v", 1)
+	text = strings.Replace(text, "    syncio.printf", "    say k: num.Num = new num.Num(p.x);\n    syncio.printf", 1)
+	root := writeProject(t, map[string]string{
+		manifest.TOML:        fmt.Sprintf("[module]\nname = \"shapes\"\nentry = \"start\"\n\n[build]\nsources = [\"src\"]\ninclude = [%q]\n", libs),
+		"src/start.pic":      text,
+		"src/geo/shapes.pic": lspShapes,
+		"shapes.pic":         "this is not in a source root\n",
+	})
+	start := fileURI(filepath.Join(root, "src", "start.pic"))
+	c := startLSP(t, root)
+	defer c.exit()
+
+	c.notify("textDocument/didOpen", map[string]any{
+		"textDocument": map[string]any{"uri": start, "version": 1, "text": text},
+	})
+	assert.Empty(t, c.diagnostics(start))
+
+	var loc *struct {
+		URI string `json:"uri"`
+	}
+	c.request("textDocument/definition", lspPosition{start, 5, 15}.params(), &loc)
+	if assert.NotNil(t, loc) {
+		assert.Equal(t, fileURI(filepath.Join(root, "src", "geo", "shapes.pic")), loc.URI)
+	}
+	c.request("textDocument/definition", lspPosition{start, 7, 16}.params(), &loc)
+	if assert.NotNil(t, loc) {
+		assert.Equal(t, fileURI(filepath.Join(libs, "util", "num.pic")), loc.URI)
+	}
+
+	var hover *struct {
+		Contents struct{ Value string } `json:"contents"`
+	}
+	c.request("textDocument/hover", lspPosition{start, 5, 15}.params(), &hover)
+	if assert.NotNil(t, hover) {
+		assert.Equal(t, "```picasso\nclass geo.shapes.Point\n```", hover.Contents.Value)
+	}
+}
+
+func TestLSPDefinitionAndHover(t *testing.T) {
+	root, start, shapes := lspProject(t)
+	c := startLSP(t, root)
+	defer c.exit()
+	c.notify("textDocument/didOpen", map[string]any{
+		"textDocument": map[string]any{"uri": start, "version": 1, "text": lspStart},
+	})
+
+	type location struct {
+		URI   string `json:"uri"`
+		Range struct {
+			Start struct{ Line, Character int }
+		} `json:"range"`
+	}
+	tests := []struct {
+		name  string
+		at    lspPosition
+		want  string // uri:line:col of the definition, "" for none
+		hover string
+	}{
+		{"class in type", lspPosition{start, 4, 15}, shapes + ":0:0", "class shapes.Point"},
+		{"class in new", lspPosition{start, 4, 31}, shapes + ":0:0", "class shapes.Point"},
+		{"package alias", lspPosition{start, 4, 12}, shapes + ":0:0", "package shapes"},
+		{"import path", lspPosition{start, 1, 9}, shapes + ":0:0", "package shapes"},
+		{"method", lspPosition{start, 5, 20}, shapes + ":9:4", "fn shapes.Point.sum(): int"},
+		{"field", lspPosition{start, 6, 32}, shapes + ":1:4", "say shapes.Point.x: int"},
+		// locals are found at their name rather than at say
+		{"local", lspPosition{start, 6, 26}, start + ":5:8", "say n: int"},
+		{"builtin module", lspPosition{start, 6, 6}, "", "builtin module syncio"},
+		{"keyword", lspPosition{start, 3, 1}, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var loc *location
+			c.request("textDocument/definition", tt.at.params(), &loc)
+			if tt.want == "" {
+				assert.Nil(t, loc)
+			} else if assert.NotNil(t, loc) {
+				assert.Equal(t, tt.want, fmt.Sprintf("%s:%d:%d", loc.URI, loc.Range.Start.Line, loc.Range.Start.Character))
+			}
+
+			var hover *struct {
+				Contents struct{ Value string } `json:"contents"`
+			}
+			c.request("textDocument/hover", tt.at.params(), &hover)
+			if tt.hover == "" {
+				assert.Nil(t, hover)
+			} else if assert.NotNil(t, hover) {
+				assert.Equal(t, "```picasso\n"+tt.hover+"\n```", hover.Contents.Value)
+			}
+		})
+	}
+}
+
+func TestLSPCompletion(t *testing.T) {
+	root, start, shapes := lspProject(t)
+	c := startLSP(t, root)
+	defer c.exit()
+
+	tests := []struct {
+		name string
+		uri  string
+		text string
+		at   lspPosition
+		want []string
+	}{
+		{
+			name: "class members",
+			uri:  start,
+			text: strings.Replace(lspStart, "say n: int = p.sum();", "say n: int = p.", 1),
+			at:   lspPosition{start, 5, 19},
+			want: []string{"sum", "x"},
+		},
+		{
+			name: "partial member",
+			uri:  start,
+			text: strings.Replace(lspStart, "say n: int = p.sum();", "say n: int = p.su;", 1),
+			at:   lspPosition{start, 5, 21},
+			want: []string{"sum", "x"},
+		},
+		{
+			name: "internal members through this",
+			uri:  shapes,
+			text: strings.Replace(lspShapes, "return this.x + this.y;", "return this.", 1),
+			at:   lspPosition{shapes, 10, 20},
+			want: []string{"sum", "x", "y"},
+		},
+		{
+			name: "package types",
+			uri:  start,
+			text: strings.Replace(lspStart, "say p: sh.Point", "say p: sh.", 1),
+			at:   lspPosition{start, 4, 14},
+			want: []string{"Point"},
+		},
+		{
+			name: "builtin module",
+			uri:  start,
+			text: strings.Replace(lspStart, "syncio.printf", "syncio.", 1),
+			at:   lspPosition{start, 6, 11},
+			want: []string{"printf", "scanf"},
+		},
+		{
+			name: "no dot",
+			uri:  start,
+			text: lspStart,
+			at:   lspPosition{start, 5, 9},
+			want: []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c.notify("textDocument/didOpen", map[string]any{
+				"textDocument": map[string]any{"uri": tt.uri, "version": 1, "text": tt.text},
+			})
+			var list struct {
+				Items []struct{ Label string } `json:"items"`
+			}
+			c.request("textDocument/completion", tt.at.params(), &list)
+			labels := []string{}
+			for _, item := range list.Items {
+				labels = append(labels, item.Label)
+			}
+			sort.Strings(labels)
+			assert.Equal(t, tt.want, labels)
+			c.notify("textDocument/didClose", map[string]any{"textDocument": map[string]any{"uri": tt.uri}})
+		})
+	}
+}
+
+func TestLSPExitWithoutShutdown(t *testing.T) {
+	inR, inW := io.Pipe()
+	done := make(chan error, 1)
+	go func() { done <- lsp.NewServer("").Serve(inR, io.Discard) }()
+	fmt.Fprintf(inW, "Content-Length: %d\r\n\r\n%s", len(`{"jsonrpc":"2.0","method":"exit"}`), `{"jsonrpc":"2.0","method":"exit"}`)
+	assert.ErrorIs(t, <-done, lsp.ErrNoShutdown)
+}
@@ -0,0 +1,149 @@
+package test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	generator "github.com/nagarajRPoojari/picasso/irgen/codegen"
+	errorsx "github.com/nagarajRPoojari/picasso/irgen/error"
+	"github.com/nagarajRPoojari/picasso/irgen/manifest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestManifestParse(t *testing.T) {
+	dir := writeProject(t, map[string]string{
+		"src/app/main.pic":   "",
+		"c/ffi/coo/coo.c":    "",
+		"vendor/geo/geo.pic": "",
+	})
+
+	toml := `# shapes
+[module]
+name = "shapes"
+entry = "app/main"   # the package of fn start
+
+[build]
+sources = ["src"]
+include = ["vendor", ]
+ffi = "c/ffi"
+output = "out"
+`
+	ini := `; shapes
+[module]
+name = shapes
+entry = app.main
+
+[build]
+sources = src
+include = vendor,
+ffi = c/ffi
+output = out
+`
+	for name, src := range map[string]string{manifest.TOML: toml, manifest.INI: ini} {
+		path := filepath.Join(dir, name)
+		m, err := manifest.Parse(path, strings.NewReader(src))
+		if !assert.NoError(t, err, name) {
+			continue
+		}
+		assert.Equal(t, &manifest.Manifest{
+			Path:    path,
+			Dir:     dir,
+			Module:  "shapes",
+			Entry:   "app.main",
+			Sources: []string{filepath.Join(dir, "src")},
+			Include: []string{filepath.Join(dir, "vendor")},
+			FFI:     []string{filepath.Join(dir, "c/ffi")},
+			Output:  filepath.Join(dir, "out"),
+		}, m, name)
+	}
+
+	m, err := manifest.Parse(filepath.Join(dir, manifest.TOML), strings.NewReader("[module]\nname = \"shapes\"\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, manifest.DefaultEntry, m.Entry)
+	assert.Equal(t, []string{dir}, m.Roots())
+	assert.Equal(t, filepath.Join(dir, manifest.DefaultOutput), m.Output)
+}
+
+func TestManifestErrors(t *testing.T) {
+	dir := writeProject(t, map[string]string{"start.pic": ""})
+	path := filepath.Join(dir, manifest.TOML)
+
+	for src, want := range map[string]string{
+		"[build]\noutput = \"out\"\n":                                          path + ": missing module name, declare it with name = \"...\" in [module]",
+		"[module]\nname = \"my-app\"\n":                                        path + ":2: invalid module name \"my-app\", want letters, digits and _",
+		"[module]\nname = app\n":                                               path + ":2: value of name: expected a quoted string, found app",
+		"[module]\nname = \"app\"\nname = \"b\"\n":                             path + ":3: duplicate key name",
+		"[module]\nname = \"app\"\n[deps]\n":                                   path + ":3: unknown section [deps], want [module], [build] or [dependencies.NAME]",
+		"[module]\nname = \"app\"\nmain = \"x\"\n":                             path + ":3: unknown key main in [module], want name or entry",
+		"[module]\nname = \"app\"\n\n[build]\nsources = [\"src\"]\n":           path + ":5: sources directory src does not exist",
+		"name = \"app\"\n":                                                     path + ":1: key name outside of a section",
+		"[module]\nname = \"app\"\n[dependencies.geo]\nversion = \"1.0\"\n":    path + ":3: dependency geo needs a path or a url",
+		"[module]\nname = \"app\"\n[dependencies.geo]\nurl = \"geo.tar.gz\"\n": path + ":3: dependency geo needs the version of its url",
+		"[module]\nname = \"app\"\n[dependencies.geo]\npath = \"geo\"\n":       path + ":3: dependency geo: directory geo does not exist",
+		"[module\n": path + ":1: unterminated section header [module",
+	} {
+		_, err := manifest.Parse(path, strings.NewReader(src))
+		var merr *manifest.Error
+		if assert.True(t, errors.As(err, &merr), src) {
+			assert.Equal(t, want, err.Error())
+		}
+	}
+
+	assert.NoError(t, os.WriteFile(path, []byte("[module]\nname = \"app\"\n"), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, manifest.INI), []byte("[module]\nname = app\n"), 0o644))
+	_, err := manifest.Find(dir)
+	assert.ErrorContains(t, err, "both picasso.toml and picasso.ini, keep one")
+}
+
+func TestManifestLegacy(t *testing.T) {
+	dir := writeProject(t, map[string]string{"start.pic": "", "c/ffi/coo/coo.c": ""})
+	libs := t.TempDir()
+
+	t.Setenv(manifest.IncludeEnv, "")
+	_, err := manifest.Load(dir)
+	assert.ErrorIs(t, err, manifest.ErrNotFound)
+
+	t.Setenv(manifest.IncludeEnv, libs)
+	m, err := manifest.Load(dir)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "", m.Path)
+		assert.Equal(t, manifest.DefaultEntry, m.Entry)
+		assert.Equal(t, []string{dir, libs}, m.Roots())
+		assert.Equal(t, []string{filepath.Join(dir, "c/ffi")}, m.FFI)
+		assert.Equal(t, filepath.Join(dir, "build"), m.Output)
+	}
+}
+
+func TestManifestBuild(t *testing.T) {
+	defer errorsx.Diagnostics.Reset()
+	t.Setenv(manifest.IncludeEnv, "")
+
+	dir := writeProject(t, map[string]string{
+		manifest.TOML: "[module]\nname = \"shapes\"\nentry = \"app/main\"\n\n[build]\nsources = [\"src\"]\noutput = \"out\"\n",
+		"src/app/main.pic": "using \"geo/shape\" as shape;\n" +
+			"fn start(args: []string) {\n    say s: shape.Square = new shape.Square(3);\n    s.area();\n}\n",
+		"src/geo/shape.pic": testShape,
+		"start.pic":         "this is not in a source root\n",
+	})
+
+	g, err := generator.NewGenerator(dir, generator.Options{DryRun: true})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NoError(t, g.BuildAll())
+	assert.Equal(t, 0, errorsx.Diagnostics.Errors())
+	var names []string
+	for name := range g.Modules() {
+		names = append(names, name)
+	}
+	assert.ElementsMatch(t, []string{"app.main", "geo.shape"}, names)
+
+	m, err := manifest.Load(dir)
+	assert.NoError(t, err)
+	m.Entry = "app.other"
+	_, err = generator.NewGeneratorFromManifest(m, generator.Options{DryRun: true})
+	assert.EqualError(t, err, "entry package app.other not found in "+filepath.Join(dir, "src"))
+}
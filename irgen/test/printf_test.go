@@ -0,0 +1,97 @@
+package test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nagarajRPoojari/picasso/irgen/compiler"
+	"github.com/stretchr/testify/assert"
+)
+
+// compilePrintf compiles call at the end of a start declaring the variables
+// of formatVars.
+func compilePrintf(t *testing.T, call string) (*compiler.Result, []compiler.Diagnostic, error) {
+	out := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(out, "tmp"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(out, "tmp", "syncio.ll"),
+		[]byte("declare i32 @__public__syncio_printf(i8*, ...)\ndeclare i32 @__public__syncio_scanf(i8*, ...)\n"), 0o644))
+
+	src := formatVars + "    " + call + "\n}\n"
+	return compiler.Compile(context.Background(), compiler.Options{
+		Sources:  source(src),
+		OutDir:   out,
+		Warnings: []string{"none"},
+	})
+}
+
+const formatVars = `using "builtin/syncio";
+
+fn start(args: []string) {
+    say n: int = 3;
+    say b: int8 = 2;
+    say f: float32 = 1.5;
+    say d: float64 = 2.5;
+    say s: string = "x";
+`
+
+func TestPrintfFormat(t *testing.T) {
+	res, diags, err := compilePrintf(t, `syncio.printf("%d %5.*f %hhd %-8s %p %% %lu %.2f\n", n, n, d, b, s, s, n, f);`)
+	assert.NoError(t, err)
+	assert.Empty(t, diags)
+	if assert.NotNil(t, res) {
+		ir := res.Modules["start"].String()
+		// small ints are passed as int and floats as double
+		assert.Contains(t, ir, "sext i8")
+		assert.Contains(t, ir, "fpext float")
+	}
+
+	// not checked when the format is not a literal
+	_, diags, err = compilePrintf(t, `syncio.printf(s, n);`)
+	assert.NoError(t, err)
+	assert.Empty(t, diags)
+}
+
+func TestPrintfFormatErrors(t *testing.T) {
+	tests := []struct {
+		call, at, msg string
+		// note is the verb the argument is for, if any
+		note string
+	}{
+		{`syncio.printf("%d and %s\n", s, n);`, "s, n", "%d wants an integer, got string", "%d"},
+		{`syncio.printf("%s\n", n);`, "n)", "%s wants a string, got int64", "%s"},
+		{`syncio.printf("%f\n", n);`, "n)", "%f wants a float, got int64", "%f"},
+		{`syncio.printf("%*d\n", d, n);`, "d, n", "%*d wants an integer width or precision, got float64", "%*d"},
+		{`syncio.printf("%d %d\n", n);`, `"%d %d`, "%d has no argument, the format takes 2", ""},
+		{`syncio.printf("%d\n", n, s);`, "s)", "extra argument, the format takes 1 but got 2", ""},
+		{`syncio.printf("%q\n", n);`, `"%q`, "unknown verb %q", ""},
+		{`syncio.printf("100%");`, `"100`, "% at the end of the format has no verb", ""},
+		{`syncio.printf("%Lf\n", d);`, `"%Lf`, "%Lf: long double is not supported", ""},
+		{`syncio.scanf("%d", n);`, "n)", "%d wants an int32 or uint32, got int64, use %ld for it", "%d"},
+		{`syncio.scanf("%f", d);`, "d)", "%f wants a float32, got float64, use %lf for it", "%f"},
+		{`syncio.scanf("%*d %s", n);`, "n)", "%s wants a string, got int64", "%s"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.call, func(t *testing.T) {
+			res, diags, err := compilePrintf(t, tt.call)
+			assert.ErrorIs(t, err, compiler.ErrCompile)
+			assert.Nil(t, res)
+			if !assert.Len(t, diags, 1) {
+				return
+			}
+			d := diags[0]
+			assert.Equal(t, "E0039", d.Code)
+			assert.Equal(t, "format mismatch in syncio."+tt.call[len("syncio."):strings.Index(tt.call, "(")]+": "+tt.msg, d.Message)
+			assert.Equal(t, strings.Count(formatVars, "\n")+1, d.Line)
+			assert.Equal(t, len("    ")+strings.Index(tt.call, tt.at)+1, d.Col)
+			if tt.note == "" {
+				assert.Empty(t, d.Notes)
+			} else if assert.Len(t, d.Notes, 1) {
+				assert.Equal(t, tt.note+" is in this format", d.Notes[0].Message)
+				assert.Equal(t, len("    ")+strings.Index(tt.call, `"`)+1, d.Notes[0].Col)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	generator "github.com/nagarajRPoojari/picasso/irgen/codegen"
+	errorsx "github.com/nagarajRPoojari/picasso/irgen/error"
+	"github.com/nagarajRPoojari/picasso/irgen/script"
+	"github.com/stretchr/testify/assert"
+)
+
+const scriptHello = `using "builtin/syncio";
+
+fn start(args: []string) {
+    syncio.printf("hello\n");
+}
+`
+
+// fakeFFI declares what the IR of the builtin syncio module would define.
+func fakeFFI(ctx context.Context, buildDir string) error {
+	return os.WriteFile(filepath.Join(buildDir, "tmp", "syncio.ll"),
+		[]byte("declare i32 @__public__syncio_printf(i8*, ...)\n"), 0o644)
+}
+
+// fakeLink links a program printing its arguments and exiting with 7.
+func fakeLink(ctx context.Context, buildDir string) error {
+	if _, err := os.Stat(filepath.Join(buildDir, generator.MAIN+".ll")); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(buildDir, "a.out"), []byte("#!/bin/sh\necho \"$*\"\nexit 7\n"), 0o755)
+}
+
+func TestScriptRun(t *testing.T) {
+	defer errorsx.Diagnostics.Reset()
+
+	dir := writeProject(t, map[string]string{"hello.pic": scriptHello})
+	path := filepath.Join(dir, "hello.pic")
+	var out bytes.Buffer
+	code, err := script.Run(context.Background(), path, []string{"-n", "2"}, script.Options{
+		Work:   filepath.Join(dir, "work"),
+		FFI:    fakeFFI,
+		Link:   fakeLink,
+		Stdout: &out,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 7, code)
+	assert.Equal(t, "-n 2\n", out.String())
+	assert.FileExists(t, filepath.Join(dir, "work", script.Executable))
+}
+
+func TestScriptErrors(t *testing.T) {
+	defer errorsx.Diagnostics.Reset()
+
+	dir := writeProject(t, map[string]string{
+		"bad.pic":     "using \"builtin/syncio\";\nusing \"geo/shape\" as shape;\n\nfn start(args: []string) {\n}\n",
+		"nostart.pic": "fn main() {\n}\n",
+	})
+	opts := script.Options{FFI: fakeFFI, Link: fakeLink}
+
+	_, err := script.Run(context.Background(), filepath.Join(dir, "bad.pic"), nil, opts)
+	assert.ErrorIs(t, err, generator.ErrCompile)
+	if assert.Len(t, errorsx.Diagnostics.Sorted(), 1) {
+		d := errorsx.Diagnostics.Sorted()[0]
+		assert.Contains(t, d.Message, "cannot import geo.shape in a single file")
+		assert.Equal(t, 2, d.Line)
+	}
+	errorsx.Diagnostics.Reset()
+
+	_, err = script.Run(context.Background(), filepath.Join(dir, "nostart.pic"), nil, opts)
+	assert.ErrorContains(t, err, "no fn start(args: []string) to run")
+
+	_, err = script.Run(context.Background(), filepath.Join(dir, "missing.pic"), nil, opts)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -0,0 +1,39 @@
+package test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nagarajRPoojari/picasso/irgen/ast"
+	errorsx "github.com/nagarajRPoojari/picasso/irgen/error"
+	"github.com/nagarajRPoojari/picasso/irgen/parser"
+	"github.com/nagarajRPoojari/picasso/irgen/sema"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSemaNestedKeys checks that expressions nested in their left operand
+// at the same location, e.g. m[1] in m[1][0], have types of their own.
+func TestSemaNestedKeys(t *testing.T) {
+	src := `using "builtin/array";
+
+fn start(args: []string) {
+    say m: [][]int = array.create(int, 3, 2);
+    say x: int = m[1][0];
+    x = x + 1;
+}
+`
+	diags := errorsx.NewCollector()
+	tree := parser.ParseAllFrom(diags, "start.pic", strings.NewReader(src))
+	info := sema.Check(diags, map[string]ast.BlockStatement{"start": tree}, "start")
+	assert.Equal(t, 0, diags.Errors())
+
+	body := tree.Body[1].(ast.FunctionDefinitionStatement).Body
+	outer := body[1].(ast.VariableDeclarationStatement).AssignedValue.(ast.ComputedExpression)
+	inner := outer.Member.(ast.ComputedExpression)
+	assert.Equal(t, outer.SourceLoc, inner.SourceLoc)
+	assert.NotEqual(t, sema.KeyOf(outer), sema.KeyOf(inner))
+	if assert.NotNil(t, info.TypeOf(outer)) && assert.NotNil(t, info.TypeOf(inner)) {
+		assert.Equal(t, "int", info.TypeOf(outer).Get())
+		assert.Equal(t, "array", info.TypeOf(inner).Get())
+	}
+}
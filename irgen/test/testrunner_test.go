@@ -0,0 +1,297 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nagarajRPoojari/picasso/irgen/ast"
+	"github.com/nagarajRPoojari/picasso/irgen/compiler"
+	errorsx "github.com/nagarajRPoojari/picasso/irgen/error"
+	"github.com/nagarajRPoojari/picasso/irgen/manifest"
+	"github.com/nagarajRPoojari/picasso/irgen/parser"
+	"github.com/nagarajRPoojari/picasso/irgen/testrunner"
+	"github.com/stretchr/testify/assert"
+)
+
+const testShape = `class Square {
+    say side: int;
+    fn Square(side: int) {
+        this.side = side;
+    }
+    fn area(): int {
+        return this.side * this.side;
+    }
+}
+`
+
+const testShapeTest = `using "builtin/assert";
+using "geo/shape" as shape;
+
+// area of a square
+test fn area() {
+    say s: shape.Square = new shape.Square(3);
+    assert.equal(s.area(), 9);
+    assert.notNull(s);
+}
+
+test fn names() {
+    say test: string = "a";
+    assert.equal(test, "a");
+    if (test == null) {
+        assert.fails("no name");
+    }
+}
+`
+
+// writeProject writes files, keyed by path relative to the project, to a
+// new project directory.
+func writeProject(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, src := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		assert.NoError(t, os.WriteFile(path, []byte(src), 0o644))
+	}
+	return dir
+}
+
+func TestTestFuncParse(t *testing.T) {
+	diags := errorsx.NewCollector()
+	tree := parser.ParseAllFrom(diags, "shape_test.pic", strings.NewReader(testShapeTest))
+	assert.Equal(t, 0, diags.Errors())
+	var tests []string
+	for _, st := range tree.Body {
+		if fn, ok := st.(ast.FunctionDefinitionStatement); ok && fn.IsTest {
+			tests = append(tests, fn.Name)
+			assert.Equal(t, 1, fn.Col)
+		}
+	}
+	assert.Equal(t, []string{"area", "names"}, tests)
+
+	for _, tc := range []struct{ path, src, msg string }{
+		{"a.pic", "test fn a() {}", "test functions are only allowed in _test.pic files"},
+		{"a_test.pic", "class A { test fn a() {} }", "test functions must be declared at the top level"},
+		{"a_test.pic", "test fn a(x: int) {}", "test function a must have no parameters and no return type"},
+		{"a_test.pic", "test fn static a() {}", "test function a cannot be static or internal"},
+	} {
+		diags := errorsx.NewCollector()
+		parser.ParseAllFrom(diags, tc.path, strings.NewReader(tc.src))
+		if d := diags.Sorted(); assert.Len(t, d, 1, tc.src) {
+			assert.Equal(t, tc.msg, d[0].Message)
+		}
+	}
+}
+
+func TestTestRunnerGenerate(t *testing.T) {
+	dir := writeProject(t, map[string]string{
+		"start.pic":          "fn start(args: []string) {}\n",
+		"geo/shape.pic":      testShape,
+		"geo/shape_test.pic": testShapeTest,
+	})
+
+	collector := errorsx.NewCollector()
+	tests, err := testrunner.Discover(collector, dir)
+	assert.NoError(t, err)
+	assert.Equal(t, []testrunner.Test{
+		{Package: "geo.shape_test", Name: "area", Path: filepath.Join(dir, "geo", "shape_test.pic"), Line: 5},
+		{Package: "geo.shape_test", Name: "names", Path: filepath.Join(dir, "geo", "shape_test.pic"), Line: 11},
+	}, tests)
+
+	work := t.TempDir()
+	assert.NoError(t, testrunner.Generate(collector, dir, work, tests))
+
+	rewritten, err := os.ReadFile(filepath.Join(work, "geo", "shape_test.pic"))
+	assert.NoError(t, err)
+	// tests keep their lines
+	assert.Equal(t, strings.Count(testShapeTest, "\n"), bytes.Count(rewritten, []byte("\n")))
+	assert.Contains(t, string(rewritten), "// area of a square\nclass __test_area { fn __test_area() {} fn run() {\n")
+	assert.Contains(t, string(rewritten), "    assert.notNull(s);\n} }\n")
+
+	entry, err := os.ReadFile(filepath.Join(work, "start.pic"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(entry), `using "geo/shape_test" as pkg0;`)
+	assert.Contains(t, string(entry), `if (strings.compare(name, "geo.shape_test.names") == 0) {`)
+
+	// the runner compiles, with the string functions of the C lib
+	out := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(out, "tmp"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(out, "tmp", "strings.ll"), []byte(`%struct.__public__string_t = type { i8*, i64 }
+
+declare i32 @__public__strings_compare(%struct.__public__string_t*, %struct.__public__string_t*)
+`), 0o644))
+	res, diags, err := compiler.Compile(context.Background(), compiler.Options{Sources: os.DirFS(work), OutDir: out})
+	assert.NoError(t, err)
+	assert.Empty(t, diags)
+	if assert.NotNil(t, res) {
+		ir := res.Modules["geo.shape_test"].String()
+		assert.Contains(t, ir, "call void @__public__assert_equal_float(")
+		assert.Contains(t, ir, "call void @__public__assert_equal_string(")
+		assert.Contains(t, ir, "call void @__public__assert_not_null(")
+		assert.Contains(t, ir, "call void @__public__assert_fails(")
+		assert.Contains(t, res.Modules["start"].String(), "call i32 @__public__strings_compare(")
+	}
+}
+
+func TestTestRunnerGenerateRejectsStartImport(t *testing.T) {
+	dir := writeProject(t, map[string]string{
+		"start.pic":   "class A {}\nfn start(args: []string) {}\n",
+		"a_test.pic":  "using \"start\";\n\ntest fn a() {}\n",
+		"build/x.pic": "not parsed",
+	})
+	diags := errorsx.NewCollector()
+	tests, err := testrunner.Discover(diags, dir)
+	assert.NoError(t, err)
+	err = testrunner.Generate(diags, dir, t.TempDir(), tests)
+	assert.ErrorContains(t, err, "test files cannot import start")
+}
+
+// TestTestRunnerGenerateManifest checks that tests are named by their path
+// in the source root and that the runner replaces the entry package of the
+// manifest.
+func TestTestRunnerGenerateManifest(t *testing.T) {
+	t.Setenv(manifest.IncludeEnv, "")
+	dir := writeProject(t, map[string]string{
+		manifest.TOML:            "[module]\nname = \"shapes\"\nentry = \"app/main\"\n\n[build]\nsources = [\"src\"]\noutput = \"out\"\n",
+		"src/app/main.pic":       "fn start(args: []string) {}\n",
+		"src/geo/shape.pic":      testShape,
+		"src/geo/shape_test.pic": testShapeTest,
+		"out/geo/old_test.pic":   "not parsed",
+	})
+
+	diags := errorsx.NewCollector()
+	tests, err := testrunner.Discover(diags, dir)
+	assert.NoError(t, err)
+	if assert.Len(t, tests, 2) {
+		assert.Equal(t, "geo.shape_test.area", tests[0].FullName())
+	}
+
+	work := t.TempDir()
+	assert.NoError(t, testrunner.Generate(diags, dir, work, tests))
+	assert.FileExists(t, filepath.Join(work, manifest.TOML))
+	assert.NoFileExists(t, filepath.Join(work, "start.pic"))
+	entry, err := os.ReadFile(filepath.Join(work, "src", "app", "main.pic"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(entry), `using "geo/shape_test" as pkg0;`)
+	assert.Contains(t, string(entry), `if (strings.compare(name, "geo.shape_test.area") == 0) {`)
+
+	m, err := manifest.Load(dir)
+	if assert.NoError(t, err) {
+		path, err := testrunner.EntryPath(m)
+		assert.NoError(t, err)
+		assert.Equal(t, filepath.Join("src", "app", "main.pic"), path)
+	}
+
+	// the entry package cannot be imported by tests
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "src", "a_test.pic"), []byte("using \"app/main\";\n\ntest fn a() {}\n"), 0o644))
+	tests, err = testrunner.Discover(diags, dir)
+	assert.NoError(t, err)
+	err = testrunner.Generate(diags, dir, t.TempDir(), tests)
+	assert.ErrorContains(t, err, "test files cannot import app.main")
+}
+
+// fakeBuild "builds" a runner which passes the tests named in pass, sleeps
+// in the tests named slow and fails the others.
+func fakeBuild(pass, slow string) func(context.Context, string) error {
+	return func(_ context.Context, dir string) error {
+		script := "#!/bin/sh\ncase \"$1\" in\n" +
+			"  " + pass + ") exit 0;;\n" +
+			"  " + slow + ") exec sleep 5;;\n" +
+			"esac\necho \"===== assertion failed: assert.equal: got 1, want 2\" >&2\nexit 1\n"
+		path := filepath.Join(dir, testrunner.Executable)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+		return os.WriteFile(path, []byte(script), 0o755)
+	}
+}
+
+func TestTestRunnerRun(t *testing.T) {
+	dir := writeProject(t, map[string]string{
+		"start.pic": "fn start(args: []string) {}\n",
+		"a_test.pic": `test fn ok() {}
+test fn bad() {}
+test fn slow() {}
+`,
+	})
+
+	r := &testrunner.Runner{Build: fakeBuild("a_test.ok", "a_test.slow"), Timeout: 200 * time.Millisecond}
+	results, err := r.Run(context.Background(), dir)
+	assert.NoError(t, err)
+	if assert.Len(t, results, 3) {
+		assert.Equal(t, "a_test.ok", results[0].FullName())
+		assert.True(t, results[0].Passed)
+
+		assert.False(t, results[1].Passed)
+		assert.Equal(t, "exit status 1", results[1].Err)
+		assert.Equal(t, "===== assertion failed: assert.equal: got 1, want 2\n", results[1].Output)
+
+		assert.False(t, results[2].Passed)
+		assert.Equal(t, "timed out after 200ms", results[2].Err)
+		assert.Less(t, results[2].Elapsed, 5*time.Second)
+	}
+	assert.Equal(t, 2, testrunner.Failed(results))
+
+	r.Filter = regexp.MustCompile(`\.ok$`)
+	results, err = r.Run(context.Background(), dir)
+	assert.NoError(t, err)
+	if assert.Len(t, results, 1) {
+		assert.True(t, results[0].Passed)
+	}
+
+	r.Filter = regexp.MustCompile(`none`)
+	results, err = r.Run(context.Background(), dir)
+	assert.NoError(t, err)
+	assert.Empty(t, results)
+}
+
+func TestTestRunnerReports(t *testing.T) {
+	results := []testrunner.Result{
+		{Test: testrunner.Test{Package: "a_test", Name: "ok", Path: "a_test.pic", Line: 1}, Passed: true, Elapsed: 1500 * time.Microsecond},
+		{Test: testrunner.Test{Package: "b_test", Name: "bad", Path: "b_test.pic", Line: 3}, Elapsed: 2 * time.Millisecond,
+			Err: "exit status 1", Output: "===== assertion failed: assert.fails: <no>\n"},
+	}
+
+	var text, tap, junit bytes.Buffer
+	assert.NoError(t, testrunner.Write(&text, testrunner.FormatText, results))
+	assert.Equal(t, `--- PASS: a_test.ok (0.00s)
+--- FAIL: b_test.bad (0.00s)
+    b_test.pic:3: exit status 1
+    ===== assertion failed: assert.fails: <no>
+FAIL: 1 of 2 test(s) failed
+`, text.String())
+
+	assert.NoError(t, testrunner.Write(&tap, testrunner.FormatTAP, results))
+	assert.Equal(t, `TAP version 13
+1..2
+ok 1 - a_test.ok
+  ---
+  duration_ms: 1.500
+  ...
+not ok 2 - b_test.bad
+  ---
+  duration_ms: 2.000
+  at: b_test.pic:3
+  message: "exit status 1"
+  output: |
+    ===== assertion failed: assert.fails: <no>
+  ...
+`, tap.String())
+
+	assert.NoError(t, testrunner.Write(&junit, testrunner.FormatJUnit, results))
+	for _, want := range []string{
+		`<testsuites tests="2" failures="1" time="0.004">`,
+		`<testsuite name="a_test" tests="1" failures="0" time="0.002">`,
+		`<testcase name="ok" classname="a_test" file="a_test.pic" line="1" time="0.002"></testcase>`,
+		`<failure message="exit status 1">===== assertion failed: assert.fails: &lt;no&gt;&#xA;</failure>`,
+	} {
+		assert.Contains(t, junit.String(), want)
+	}
+
+	assert.Error(t, testrunner.Write(&text, "xml", results))
+}
@@ -0,0 +1,183 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"testing/fstest"
+
+	"github.com/nagarajRPoojari/picasso/irgen/compiler"
+	errorsx "github.com/nagarajRPoojari/picasso/irgen/error"
+	"github.com/stretchr/testify/assert"
+)
+
+// warnSrc triggers every warning once. point is only used after an endless
+// loop and pt is never used.
+const warnSrc = `using "geo/point";
+using "geo/point" as pt;
+
+class Counter {
+    say n: int;
+
+    fn Counter() {
+        this.n = 0;
+    }
+
+    fn next(): int {
+        this.n = this.n + 1;
+        return this.n;
+    }
+}
+
+fn start(args: []string) {
+    say unused: int = 1;
+    say c: start.Counter = new start.Counter();
+    c.next();
+}
+
+fn spin(n: int, _: int) {
+    while (1) {
+    }
+    say p: point.Point = new point.Point(1);
+}
+
+fn shadow(): int {
+    say y: int = 1;
+    if (y > 0) {
+        say y: int = 2;
+        return y;
+    }
+    return y;
+}
+`
+
+// compileWarnings compiles warnSrc with the given -W flags and returns its
+// diagnostics as "code line:col severity" for easy comparison.
+func compileWarnings(t *testing.T, flags ...string) ([]string, error) {
+	t.Helper()
+	_, diags, err := compiler.Compile(context.Background(), compiler.Options{
+		Sources: fstest.MapFS{
+			"start.pic":     {Data: []byte(warnSrc)},
+			"geo/point.pic": {Data: []byte(testPoint)},
+		},
+		Warnings: flags,
+	})
+	var got []string
+	for _, d := range diags {
+		got = append(got, fmt.Sprintf("%s %d:%d %s", d.Code, d.Line, d.Col, d.Severity))
+	}
+	return got, err
+}
+
+func TestWarnings(t *testing.T) {
+	// carets point at the names declared, not at say or using
+	unusedImport := "W0003 2:22 warning"
+	unusedVariable := "W0001 18:9 warning"
+	infiniteLoop := "W0007 24:5 warning"
+	unreachable := "W0005 26:5 warning"
+	unusedParameter := "W0002 23:9 warning"
+	shadow := "W0004 32:13 warning"
+	unusedResult := "W0006 20:5 warning"
+
+	tests := []struct {
+		name  string
+		flags []string
+		want  []string
+	}{
+		{
+			name: "default",
+			want: []string{unusedImport, unusedVariable, infiniteLoop, unreachable},
+		},
+		{
+			// start's args and _ are never reported
+			name:  "all",
+			flags: []string{"all"},
+			want:  []string{unusedImport, unusedVariable, unusedResult, unusedParameter, infiniteLoop, unreachable, shadow},
+		},
+		{
+			name:  "none",
+			flags: []string{"none"},
+		},
+		{
+			name:  "enable one",
+			flags: []string{"none", "shadow"},
+			want:  []string{shadow},
+		},
+		{
+			name:  "disable one",
+			flags: []string{"no-unused-variable", "no-infinite-loop"},
+			want:  []string{unusedImport, unreachable},
+		},
+		{
+			name:  "later flags win",
+			flags: []string{"no-shadow", "all", "no-unused-result", "no-shadow"},
+			want:  []string{unusedImport, unusedVariable, unusedParameter, infiniteLoop, unreachable},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := compileWarnings(t, tt.flags...)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestWarningsAsErrors(t *testing.T) {
+	got, err := compileWarnings(t, "error=unused-variable")
+	assert.ErrorIs(t, err, compiler.ErrCompile)
+	assert.Equal(t, []string{"W0003 2:22 warning", "W0001 18:9 error", "W0007 24:5 warning", "W0005 26:5 warning"}, got)
+
+	// error=NAME also enables NAME
+	got, err = compileWarnings(t, "none", "error=shadow")
+	assert.ErrorIs(t, err, compiler.ErrCompile)
+	assert.Equal(t, []string{"W0004 32:13 error"}, got)
+
+	got, err = compileWarnings(t, "error", "no-error=unused-import", "no-error=unused-variable", "no-error=infinite-loop", "no-error=unreachable")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"W0003 2:22 warning", "W0001 18:9 warning", "W0007 24:5 warning", "W0005 26:5 warning"}, got)
+
+	// whatever the order
+	got, err = compileWarnings(t, "none", "shadow", "no-error=shadow", "error")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"W0004 32:13 warning"}, got)
+
+	got, err = compileWarnings(t, "error", "no-unused-import", "no-unused-variable")
+	assert.ErrorIs(t, err, compiler.ErrCompile)
+	assert.Equal(t, []string{"W0007 24:5 error", "W0005 26:5 error"}, got)
+}
+
+// ignoreSrc declares unused variables a to g; the ones on lines 2, 5, 6
+// and 8 are suppressed.
+const ignoreSrc = `fn start(args: []string) {
+    say a: int = 1; // picasso:ignore unused-variable
+    say b: int = 1; // picasso:ignore shadow
+    // picasso:ignore
+    say c: int = 1;
+    say d: string = "http://example.com"; // picasso:ignore unused-variable
+    say e: string = "// picasso:ignore";
+    say f: int = 1; // picasso:ignore shadow, unused-variable
+    say g: int = 1;
+}
+`
+
+func TestWarningsIgnore(t *testing.T) {
+	_, diags, err := compiler.Compile(context.Background(), compiler.Options{
+		Sources: source(ignoreSrc),
+	})
+	assert.NoError(t, err)
+	var lines []int
+	for _, d := range diags {
+		lines = append(lines, d.Line)
+	}
+	// a directive after code only covers its own line, so g is reported
+	assert.Equal(t, []int{3, 7, 9}, lines)
+}
+
+func TestWarningFlagErrors(t *testing.T) {
+	for _, flag := range []string{"unused", "no-unused", "error=unused", "no-error=unused"} {
+		_, err := compileWarnings(t, flag)
+		assert.ErrorContains(t, err, `unknown warning "unused"`, flag)
+	}
+	assert.Equal(t, []string{"infinite-loop", "shadow", "unreachable", "unused-import", "unused-parameter", "unused-result", "unused-variable"}, errorsx.WarningNames())
+}
@@ -0,0 +1,170 @@
+// Package testrunner runs the tests of a Picasso project, see irgen test.
+//
+// Tests are functions declared test fn name() { ... } at the top level of
+// *_test.pic files, checking results with the builtin/assert module. The
+// runner copies the project to a work directory, turns every test into a
+// class with a run method, replaces its entry package by one calling the
+// test named by its first argument and builds the project once. Each test then runs in a
+// process of its own, so that a failed assertion, which ends the process,
+// or a crash fails that test only.
+package testrunner
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/nagarajRPoojari/picasso/irgen/ast"
+	errorsx "github.com/nagarajRPoojari/picasso/irgen/error"
+	"github.com/nagarajRPoojari/picasso/irgen/lexer"
+	"github.com/nagarajRPoojari/picasso/irgen/manifest"
+	"github.com/nagarajRPoojari/picasso/irgen/parser"
+)
+
+// Suffix ends the names of test files.
+const Suffix = "_test.pic"
+
+// ErrSyntax is returned for test files that do not parse; the syntax errors
+// are recorded in the collector given.
+var ErrSyntax = errors.New("syntax errors")
+
+// Test is a test function.
+type Test struct {
+	// Package is the package of the test file, e.g. geo.shape_test.
+	Package string
+	Name    string
+	// Path is the test file.
+	Path string
+	Line int
+}
+
+// FullName names t on the command line of the runner and in reports, e.g.
+// geo.shape_test.area.
+func (t Test) FullName() string {
+	return t.Package + "." + t.Name
+}
+
+// file is a parsed test file.
+type file struct {
+	path string
+	// rel is the path relative to the project directory.
+	rel    string
+	src    []byte
+	tokens []lexer.Token
+	tree   ast.BlockStatement
+	tests  []Test
+}
+
+// Discover returns the tests of the source roots of the project in dir,
+// sorted by file and line. Syntax errors are recorded in diags.
+func Discover(diags *errorsx.Collector, dir string) ([]Test, error) {
+	m, err := load(dir)
+	if err != nil {
+		return nil, err
+	}
+	files, err := parseFiles(diags, m)
+	if err != nil {
+		return nil, err
+	}
+	var tests []Test
+	for _, f := range files {
+		tests = append(tests, f.tests...)
+	}
+	return tests, nil
+}
+
+// load returns the manifest of the project in dir. A project without one
+// is laid out as manifest.Legacy describes, the libs not being needed to
+// find its tests.
+func load(dir string) (*manifest.Manifest, error) {
+	m, err := manifest.Load(dir)
+	if errors.Is(err, manifest.ErrNotFound) {
+		return manifest.Legacy(dir)
+	}
+	return m, err
+}
+
+func parseFiles(diags *errorsx.Collector, m *manifest.Manifest) ([]*file, error) {
+	var files []*file
+	syntax := false
+	for _, root := range m.Sources {
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			switch {
+			case err != nil:
+				return err
+			case d.IsDir():
+				// roots nested in one another are walked once
+				if path != root && (skipDir(m, path) || slices.Contains(m.Sources, path)) {
+					return filepath.SkipDir
+				}
+				return nil
+			case !strings.HasSuffix(path, Suffix):
+				return nil
+			}
+
+			rel, err := filepath.Rel(m.Dir, path)
+			if err != nil {
+				return err
+			}
+			pkg, err := filepath.Rel(root, path)
+			if err != nil {
+				return err
+			}
+			f, err := parseFile(diags, path, filepath.ToSlash(rel), filepath.ToSlash(pkg))
+			if errors.Is(err, ErrSyntax) {
+				syntax = true
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			files = append(files, f)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if syntax {
+		return nil, ErrSyntax
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].rel < files[j].rel })
+	return files, nil
+}
+
+// skipDir reports whether the directory at path in the project of m holds
+// no sources: the build output and hidden directories.
+func skipDir(m *manifest.Manifest, path string) bool {
+	return path == m.Output || strings.HasPrefix(filepath.Base(path), ".")
+}
+
+// parseFile parses the test file at path, rel in the project directory and
+// pkgPath in its source root.
+func parseFile(diags *errorsx.Collector, path, rel, pkgPath string) (*file, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	errs := diags.Errors()
+	tokens := lexer.TokenizeReader(diags, path, bytes.NewReader(src))
+	tree := parser.ParseTokens(diags, path, tokens)
+	if diags.Errors() > errs {
+		return nil, fmt.Errorf("%s: %w", path, ErrSyntax)
+	}
+
+	f := &file{path: path, rel: rel, src: src, tokens: tokens, tree: tree}
+	pkg := strings.ReplaceAll(strings.TrimSuffix(pkgPath, ".pic"), "/", ".")
+	for _, stI := range tree.Body {
+		if st, ok := stI.(ast.FunctionDefinitionStatement); ok && st.IsTest {
+			f.tests = append(f.tests, Test{Package: pkg, Name: st.Name, Path: path, Line: st.Line})
+		}
+	}
+	return f, nil
+}
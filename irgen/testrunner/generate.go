@@ -0,0 +1,230 @@
+package testrunner
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/nagarajRPoojari/picasso/irgen/ast"
+	errorsx "github.com/nagarajRPoojari/picasso/irgen/error"
+	"github.com/nagarajRPoojari/picasso/irgen/lexer"
+	"github.com/nagarajRPoojari/picasso/irgen/manifest"
+)
+
+// Generate writes the test runner of the project in dir to work: a copy of
+// the project whose test files declare their tests as classes and whose
+// entry package, see EntryPath, runs the test named by its first argument.
+// Only tests are dispatched to, the other functions of test files are left
+// out as the compiler only builds start. Syntax errors are recorded in
+// diags.
+func Generate(diags *errorsx.Collector, dir, work string, tests []Test) error {
+	m, err := load(dir)
+	if err != nil {
+		return err
+	}
+	files, err := parseFiles(diags, m)
+	if err != nil {
+		return err
+	}
+	entryPath, err := EntryPath(m)
+	if err != nil {
+		return err
+	}
+	if err := copyProject(m, work); err != nil {
+		return err
+	}
+
+	for _, f := range files {
+		for _, stI := range f.tree.Body {
+			if st, ok := stI.(ast.ImportStatement); ok && st.Name == m.Entry {
+				return fmt.Errorf("%s:%d: test files cannot import %s, which the runner replaces", f.path, st.Line, st.Name)
+			}
+		}
+		src, err := f.rewrite()
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(work, filepath.FromSlash(f.rel)), src, 0o644); err != nil {
+			return err
+		}
+	}
+	return os.WriteFile(filepath.Join(work, entryPath), entry(tests), 0o644)
+}
+
+// EntryPath returns the path, relative to the project directory, of the
+// file of the entry package of the project of m, which the runner replaces:
+// the first one found in the source roots, or the place it would have in
+// the first root.
+func EntryPath(m *manifest.Manifest) (string, error) {
+	if len(m.Sources) == 0 {
+		return "", fmt.Errorf("%s: no source root", m.Dir)
+	}
+	name := filepath.FromSlash(strings.ReplaceAll(m.Entry, ".", "/")) + ".pic"
+	root := m.Sources[0]
+	for _, r := range m.Sources {
+		if _, err := os.Stat(filepath.Join(r, name)); err == nil {
+			root = r
+			break
+		}
+	}
+	rel, err := filepath.Rel(m.Dir, filepath.Join(root, name))
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("source root %s is outside the project %s", root, m.Dir)
+	}
+	return rel, nil
+}
+
+// ClassName is the class holding test name in the rewritten test file.
+func ClassName(name string) string {
+	return "__test_" + name
+}
+
+// rewrite turns every test of f into a class with a constructor and a run
+// method holding the body of the test, e.g.
+//
+//	test fn area() { ... }
+//
+// becomes
+//
+//	class __test_area { fn __test_area() {} fn run() { ... } }
+//
+// The class stays on the lines of the test, so that errors and stack traces
+// point into the test file.
+func (f *file) rewrite() ([]byte, error) {
+	lines := lineOffsets(f.src)
+	offset := func(tk lexer.Token) int {
+		return lines[tk.Src.Line-1] + tk.Src.Col - 1
+	}
+
+	at := make(map[lexer.SourceLoc]int, len(f.tokens))
+	for i, tk := range f.tokens {
+		at[tk.Src] = i
+	}
+
+	var out bytes.Buffer
+	last := 0
+	for _, stI := range f.tree.Body {
+		st, ok := stI.(ast.FunctionDefinitionStatement)
+		if !ok || !st.IsTest {
+			continue
+		}
+		i, ok := at[lexer.SourceLoc(st.SourceLoc)]
+		if !ok || i+2 >= len(f.tokens) {
+			return nil, fmt.Errorf("%s:%d: test %s not found in tokens", f.path, st.Line, st.Name)
+		}
+		// test fn name
+		name := f.tokens[i+2]
+		end := closingCurly(f.tokens, i)
+		if end == -1 {
+			return nil, fmt.Errorf("%s:%d: body of test %s not found", f.path, st.Line, st.Name)
+		}
+
+		out.Write(f.src[last:offset(f.tokens[i])])
+		cls := ClassName(st.Name)
+		fmt.Fprintf(&out, "class %s { fn %s() {} fn run", cls, cls)
+		stop := offset(f.tokens[end]) + 1
+		out.Write(f.src[offset(name)+len(name.Value) : stop])
+		out.WriteString(" }")
+		last = stop
+	}
+	out.Write(f.src[last:])
+	return out.Bytes(), nil
+}
+
+// closingCurly returns the index of the '}' closing the first block opened
+// after tokens[start], -1 if there is none.
+func closingCurly(tokens []lexer.Token, start int) int {
+	depth := 0
+	for i := start; i < len(tokens); i++ {
+		switch tokens[i].Kind {
+		case lexer.OPEN_CURLY:
+			depth++
+		case lexer.CLOSE_CURLY:
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// lineOffsets returns the offset of the start of every line of src.
+func lineOffsets(src []byte) []int {
+	lines := []int{0}
+	for i, b := range src {
+		if b == '\n' {
+			lines = append(lines, i+1)
+		}
+	}
+	return lines
+}
+
+// entry returns the source of the runner start.pic, running the test whose
+// full name is its first argument.
+func entry(tests []Test) []byte {
+	var b strings.Builder
+	b.WriteString("// Code generated by irgen test. DO NOT EDIT.\n\n")
+	b.WriteString("using \"builtin/strings\";\n")
+
+	aliases := make(map[string]string)
+	for _, t := range tests {
+		if _, ok := aliases[t.Package]; ok {
+			continue
+		}
+		aliases[t.Package] = fmt.Sprintf("pkg%d", len(aliases))
+		fmt.Fprintf(&b, "using %q as %s;\n", strings.ReplaceAll(t.Package, ".", "/"), aliases[t.Package])
+	}
+
+	b.WriteString("\nfn start(args: []string) {\n")
+	b.WriteString("    say name: string = args[1];\n")
+	for i, t := range tests {
+		cls := aliases[t.Package] + "." + ClassName(t.Name)
+		fmt.Fprintf(&b, "    if (strings.compare(name, %q) == 0) {\n", t.FullName())
+		fmt.Fprintf(&b, "        say t%d: %s = new %s();\n", i, cls, cls)
+		fmt.Fprintf(&b, "        t%d.run();\n", i)
+		b.WriteString("    }\n")
+	}
+	b.WriteString("}\n")
+	return []byte(b.String())
+}
+
+// copyProject copies the project of m to work, but its build output and
+// hidden directories.
+func copyProject(m *manifest.Manifest, work string) error {
+	dir := m.Dir
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		dst := filepath.Join(work, rel)
+
+		switch {
+		case d.IsDir():
+			if path != dir && skipDir(m, path) {
+				return filepath.SkipDir
+			}
+			return os.MkdirAll(dst, 0o755)
+		case d.Type()&fs.ModeSymlink != 0:
+			target, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(target, dst)
+		case !d.Type().IsRegular():
+			return nil
+		}
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(dst, src, 0o644)
+	})
+}
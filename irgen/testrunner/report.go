@@ -0,0 +1,182 @@
+package testrunner
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Report formats.
+const (
+	FormatText  = "text"
+	FormatTAP   = "tap"
+	FormatJUnit = "junit"
+)
+
+// Formats lists the report formats.
+var Formats = []string{FormatText, FormatTAP, FormatJUnit}
+
+// Failed returns the number of failed tests in results.
+func Failed(results []Result) int {
+	n := 0
+	for _, res := range results {
+		if !res.Passed {
+			n++
+		}
+	}
+	return n
+}
+
+// Write reports results to w in format, one of Formats.
+func Write(w io.Writer, format string, results []Result) error {
+	switch format {
+	case FormatText:
+		return WriteText(w, results)
+	case FormatTAP:
+		return WriteTAP(w, results)
+	case FormatJUnit:
+		return WriteJUnit(w, results)
+	}
+	return fmt.Errorf("unknown report format %q, want one of %s", format, strings.Join(Formats, ", "))
+}
+
+// WriteText reports results like go test -v, with the output of failed
+// tests.
+func WriteText(w io.Writer, results []Result) error {
+	var b strings.Builder
+	for _, res := range results {
+		status := "PASS"
+		if !res.Passed {
+			status = "FAIL"
+		}
+		fmt.Fprintf(&b, "--- %s: %s (%.2fs)\n", status, res.FullName(), res.Elapsed.Seconds())
+		if !res.Passed {
+			fmt.Fprintf(&b, "    %s:%d: %s\n", res.Path, res.Line, res.Err)
+			for _, line := range lines(res.Output) {
+				fmt.Fprintf(&b, "    %s\n", line)
+			}
+		}
+	}
+
+	failed := Failed(results)
+	if failed > 0 {
+		fmt.Fprintf(&b, "FAIL: %d of %d test(s) failed\n", failed, len(results))
+	} else {
+		fmt.Fprintf(&b, "ok: %d test(s) passed\n", len(results))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteTAP reports results in the Test Anything Protocol, version 13. The
+// time and output of each test are in its YAML block.
+func WriteTAP(w io.Writer, results []Result) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "TAP version 13\n1..%d\n", len(results))
+	for i, res := range results {
+		status := "ok"
+		if !res.Passed {
+			status = "not ok"
+		}
+		fmt.Fprintf(&b, "%s %d - %s\n", status, i+1, res.FullName())
+		b.WriteString("  ---\n")
+		fmt.Fprintf(&b, "  duration_ms: %.3f\n", float64(res.Elapsed)/float64(time.Millisecond))
+		if !res.Passed {
+			fmt.Fprintf(&b, "  at: %s:%d\n", res.Path, res.Line)
+			fmt.Fprintf(&b, "  message: %q\n", res.Err)
+			if out := lines(res.Output); len(out) > 0 {
+				b.WriteString("  output: |\n")
+				for _, line := range out {
+					fmt.Fprintf(&b, "    %s\n", line)
+				}
+			}
+		}
+		b.WriteString("  ...\n")
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// The JUnit XML elements, as read by CI servers.
+type (
+	junitSuites struct {
+		XMLName xml.Name     `xml:"testsuites"`
+		Tests   int          `xml:"tests,attr"`
+		Fails   int          `xml:"failures,attr"`
+		Time    string       `xml:"time,attr"`
+		Suites  []junitSuite `xml:"testsuite"`
+	}
+	junitSuite struct {
+		Name  string      `xml:"name,attr"`
+		Tests int         `xml:"tests,attr"`
+		Fails int         `xml:"failures,attr"`
+		Time  string      `xml:"time,attr"`
+		Cases []junitCase `xml:"testcase"`
+	}
+	junitCase struct {
+		Name      string        `xml:"name,attr"`
+		Classname string        `xml:"classname,attr"`
+		File      string        `xml:"file,attr"`
+		Line      int           `xml:"line,attr"`
+		Time      string        `xml:"time,attr"`
+		Failure   *junitFailure `xml:"failure,omitempty"`
+	}
+	junitFailure struct {
+		Message string `xml:"message,attr"`
+		Output  string `xml:",chardata"`
+	}
+)
+
+// WriteJUnit reports results as JUnit XML, a test suite per package.
+func WriteJUnit(w io.Writer, results []Result) error {
+	seconds := func(d time.Duration) string { return fmt.Sprintf("%.3f", d.Seconds()) }
+
+	doc := junitSuites{Tests: len(results), Fails: Failed(results)}
+	var total time.Duration
+	suites := make(map[string]int)
+	var times []time.Duration
+	for _, res := range results {
+		i, ok := suites[res.Package]
+		if !ok {
+			i = len(doc.Suites)
+			suites[res.Package] = i
+			doc.Suites = append(doc.Suites, junitSuite{Name: res.Package})
+			times = append(times, 0)
+		}
+		suite := &doc.Suites[i]
+
+		c := junitCase{Name: res.Name, Classname: res.Package, File: res.Path, Line: res.Line, Time: seconds(res.Elapsed)}
+		if !res.Passed {
+			c.Failure = &junitFailure{Message: res.Err, Output: res.Output}
+			suite.Fails++
+		}
+		suite.Cases = append(suite.Cases, c)
+		suite.Tests++
+		times[i] += res.Elapsed
+		suite.Time = seconds(times[i])
+		total += res.Elapsed
+	}
+	doc.Time = seconds(total)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// lines splits output into lines, without the trailing empty one.
+func lines(output string) []string {
+	output = strings.TrimRight(output, "\n")
+	if output == "" {
+		return nil
+	}
+	return strings.Split(output, "\n")
+}
@@ -0,0 +1,140 @@
+package testrunner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	generator "github.com/nagarajRPoojari/picasso/irgen/codegen"
+	errorsx "github.com/nagarajRPoojari/picasso/irgen/error"
+)
+
+// Executable is the path of the runner in the work directory once built.
+var Executable = filepath.Join(generator.BUILD, "a.out")
+
+// waitDelay bounds the wait for the output of a test once it has been
+// killed.
+const waitDelay = time.Second
+
+// Result is the outcome of a test.
+type Result struct {
+	Test
+	Passed  bool
+	Elapsed time.Duration
+	// Output is what the test wrote to stdout and stderr, with the failed
+	// assertion and its stack trace.
+	Output string
+	// Err says why the test failed, e.g. exit status 1, "" if it passed.
+	Err string
+}
+
+// Runner runs the tests of a project.
+type Runner struct {
+	// Build builds the project in dir, leaving the executable at
+	// Executable in dir. It defaults to Build.
+	Build func(ctx context.Context, dir string) error
+	// Filter selects the tests to run by full name; all tests run if nil.
+	Filter *regexp.Regexp
+	// Timeout bounds the time of each test; 0 means no limit.
+	Timeout time.Duration
+	// Work is the work directory the runner is generated in. A temporary
+	// directory, removed once done, is used if empty.
+	Work string
+	// Diagnostics receives the syntax errors of the test files; nil for
+	// errorsx.Diagnostics.
+	Diagnostics *errorsx.Collector
+}
+
+// Build runs picasso build, the compile and link step of the picasso CLI,
+// in dir. The CLI is $PICASSO, or picasso looked up in PATH.
+func Build(ctx context.Context, dir string) error {
+	cli := os.Getenv("PICASSO")
+	if cli == "" {
+		cli = "picasso"
+	}
+	out, err := exec.CommandContext(ctx, cli, "build", dir).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s build: %w\n%s", cli, err, out)
+	}
+	return nil
+}
+
+// Run builds the runner of the project in dir and runs every test selected
+// by the filter in a process of its own, in order. The error reports a
+// project that cannot be built, not failed tests.
+func (r *Runner) Run(ctx context.Context, dir string) ([]Result, error) {
+	diags := r.Diagnostics
+	if diags == nil {
+		diags = errorsx.Diagnostics
+	}
+	all, err := Discover(diags, dir)
+	if err != nil {
+		return nil, err
+	}
+	var tests []Test
+	for _, t := range all {
+		if r.Filter == nil || r.Filter.MatchString(t.FullName()) {
+			tests = append(tests, t)
+		}
+	}
+	if len(tests) == 0 {
+		return nil, nil
+	}
+
+	work := r.Work
+	if work == "" {
+		if work, err = os.MkdirTemp("", "picasso-test-"); err != nil {
+			return nil, err
+		}
+		defer os.RemoveAll(work)
+	}
+	if err := Generate(diags, dir, work, tests); err != nil {
+		return nil, err
+	}
+	build := r.Build
+	if build == nil {
+		build = Build
+	}
+	if err := build(ctx, work); err != nil {
+		return nil, err
+	}
+
+	results := make([]Result, 0, len(tests))
+	for _, t := range tests {
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+		results = append(results, r.run(ctx, work, t))
+	}
+	return results, nil
+}
+
+func (r *Runner) run(ctx context.Context, work string, t Test) Result {
+	if r.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.Timeout)
+		defer cancel()
+	}
+
+	cmd := exec.CommandContext(ctx, filepath.Join(work, Executable), t.FullName())
+	cmd.Dir = work
+	// children of a killed test may keep its output open
+	cmd.WaitDelay = waitDelay
+	start := time.Now()
+	out, err := cmd.CombinedOutput()
+	res := Result{Test: t, Elapsed: time.Since(start), Output: string(out), Passed: err == nil}
+
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		res.Passed = false
+		res.Err = fmt.Sprintf("timed out after %s", r.Timeout)
+	case err != nil:
+		res.Err = err.Error()
+	}
+	return res
+}
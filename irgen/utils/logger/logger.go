@@ -6,6 +6,8 @@ import (
 	"os"
 	"sync"
 	"time"
+
+	"github.com/fatih/color"
 )
 
 type LogLevel int
@@ -103,7 +105,8 @@ func New() *Logger {
 		out:        os.Stdout,
 		errOut:     os.Stderr,
 		timeFormat: "2006-01-02 15:04:05",
-		useColor:   true,
+		// no escape codes in files and pipes, e.g. CI logs
+		useColor: !color.NoColor,
 	}
 }
 
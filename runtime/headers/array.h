@@ -11,6 +11,7 @@ typedef struct {
     int64_t rank;  
     int64_t capacity;  
     int32_t elem_size;
+    void* base;         /* owning array for slice views, NULL if data is owned */
 } __public__array_t;
 
 /**
@@ -45,6 +46,22 @@ __public__array_t* __public__get_subarray(__public__array_t* arr, int64_t index)
  */
 void __public__set_subarray(__public__array_t* arr, int64_t index, __public__array_t* sub_arr);
 
+/**
+ * @brief Create a view over arr[lo..hi) sharing arr's backing storage
+ * @param arr The source array
+ * @param lo Inclusive lower bound along the first dimension
+ * @param hi Exclusive upper bound along the first dimension
+ * @return New array header whose data points into arr's storage
+ */
+__public__array_t* __public__slice_array(__public__array_t* arr, int64_t lo, int64_t hi);
+
+/**
+ * @brief Deep copy an array (or a view) into freshly owned storage
+ * @param arr The array to copy
+ * @return New array with its own backing storage
+ */
+__public__array_t* __public__copy_array(__public__array_t* arr);
+
 /**
  * @brief utility func to print array information
  * @param arr array struct instance
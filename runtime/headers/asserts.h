@@ -0,0 +1,66 @@
+#ifndef ASSERTS_H
+#define ASSERTS_H
+#include <stdint.h>
+#include "str.h"
+
+/*
+ * Checks behind the builtin/assert module. A failed check prints what was
+ * expected and the stack trace, then ends the process with status 1, which
+ * irgen test reports as a failed test.
+ */
+
+/**
+ * @brief Check that two signed integers are equal
+ * @param got Value under test
+ * @param want Expected value
+ */
+void __public__assert_equal_int(int64_t got, int64_t want);
+
+/**
+ * @brief Check that two unsigned integers are equal
+ * @param got Value under test
+ * @param want Expected value
+ */
+void __public__assert_equal_uint(uint64_t got, uint64_t want);
+
+/**
+ * @brief Check that two floats are equal
+ * @param got Value under test
+ * @param want Expected value
+ */
+void __public__assert_equal_float(double got, double want);
+
+/**
+ * @brief Check that two booleans are equal
+ * @param got Value under test, 0 or 1
+ * @param want Expected value, 0 or 1
+ */
+void __public__assert_equal_bool(int32_t got, int32_t want);
+
+/**
+ * @brief Check that two strings have the same bytes
+ * @param got Value under test, may be NULL
+ * @param want Expected value, may be NULL
+ */
+void __public__assert_equal_string(__public__string_t* got, __public__string_t* want);
+
+/**
+ * @brief Check that two objects or arrays are the same
+ * @param got Value under test
+ * @param want Expected value
+ */
+void __public__assert_equal_ptr(void* got, void* want);
+
+/**
+ * @brief Check that an object, array or string is not null
+ * @param v Value under test
+ */
+void __public__assert_not_null(void* v);
+
+/**
+ * @brief Fail unconditionally
+ * @param msg Reason of the failure, may be NULL
+ */
+void __public__assert_fails(__public__string_t* msg);
+
+#endif
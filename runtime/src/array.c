@@ -52,6 +52,7 @@ static __public__array_t* __alloc_array_recursive(int32_t elem_size, int32_t ran
     arr->length = count;
     arr->rank = rank;
     arr->elem_size = elem_size;
+    arr->base = NULL;
     
     memset(arr->data, 0, data_size);
     
@@ -116,8 +117,12 @@ void __public__extend_array(__public__array_t* arr, int32_t unused) {
     assert(new_cap * arr->elem_size != 0);
     memcpy(data, arr->data, (arr->length - 1) * arr->elem_size);
     
-    release(__arena__, arr->data);
+    /* views share storage with their owner: copy-on-grow, never release */
+    if (arr->base == NULL) {
+        release(__arena__, arr->data);
+    }
     arr->data = data;
+    arr->base = NULL;
 }
 
 int64_t __public__len(__public__array_t* arr) {
@@ -164,6 +169,91 @@ void __public__set_subarray(__public__array_t* arr, int64_t index, __public__arr
     sub_arrays[index] = sub_arr;
 }
 
+/**
+ * @brief Create a view over arr[lo..hi) sharing arr's backing storage
+ * @param arr The source array
+ * @param lo Inclusive lower bound along the first dimension
+ * @param hi Exclusive upper bound along the first dimension
+ * @return New array header whose data points into arr's storage
+ *
+ * Writes through the view are visible in arr. The view's capacity equals its
+ * length, so appending to it reallocates instead of clobbering arr.
+ */
+__public__array_t* __public__slice_array(__public__array_t* arr, int64_t lo, int64_t hi) {
+    if (arr == NULL) {
+        __public__runtime_error("===== array is NULL in slice_array");
+    }
+    if (lo < 0 || lo > hi || hi > arr->length) {
+        __public__runtime_error("===== slice bounds out of range");
+    }
+
+    size_t shape_size = (size_t)arr->rank * sizeof(int64_t);
+    __public__array_t* view = (__public__array_t*)allocate(__arena__, sizeof(__public__array_t) + shape_size);
+
+    size_t stride = arr->rank > 1 ? sizeof(__public__array_t*) : (size_t)arr->elem_size;
+    view->data = arr->data + (size_t)lo * stride;
+
+    if (arr->rank > 0) {
+        view->shape = (int64_t*)(view + 1);
+        memcpy(view->shape, arr->shape, shape_size);
+        view->shape[0] = hi - lo;
+    } else {
+        view->shape = NULL;
+    }
+
+    view->length = hi - lo;
+    view->capacity = hi - lo;
+    view->rank = arr->rank;
+    view->elem_size = arr->elem_size;
+
+    /* keep the root owner reachable so the collector never frees the storage */
+    view->base = arr->base != NULL ? arr->base : (void*)arr;
+    return view;
+}
+
+/**
+ * @brief Deep copy an array (or a view) into freshly owned storage
+ * @param arr The array to copy
+ * @return New array with its own backing storage
+ */
+__public__array_t* __public__copy_array(__public__array_t* arr) {
+    if (arr == NULL) {
+        return NULL;
+    }
+
+    size_t shape_size = (size_t)arr->rank * sizeof(int64_t);
+    __public__array_t* out = (__public__array_t*)allocate(__arena__, sizeof(__public__array_t) + shape_size);
+
+    size_t stride = arr->rank > 1 ? sizeof(__public__array_t*) : (size_t)arr->elem_size;
+    int64_t count = arr->length > 0 ? arr->length : 1;
+    out->data = (char*)allocate(__arena__, (size_t)count * stride);
+
+    if (arr->rank > 0) {
+        out->shape = (int64_t*)(out + 1);
+        memcpy(out->shape, arr->shape, shape_size);
+        out->shape[0] = arr->length;
+    } else {
+        out->shape = NULL;
+    }
+
+    out->length = arr->length;
+    out->capacity = arr->length;
+    out->rank = arr->rank;
+    out->elem_size = arr->elem_size;
+    out->base = NULL;
+
+    if (arr->rank > 1) {
+        __public__array_t** src = (__public__array_t**)arr->data;
+        __public__array_t** dst = (__public__array_t**)out->data;
+        for (int64_t i = 0; i < arr->length; i++) {
+            dst[i] = __public__copy_array(src[i]);
+        }
+    } else {
+        memcpy(out->data, arr->data, (size_t)arr->length * stride);
+    }
+    return out;
+}
+
 /** @deprecated */
 void __public__debug_array_info(__public__array_t* arr) {
     if (arr == NULL) {
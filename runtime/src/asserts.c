@@ -0,0 +1,78 @@
+#include "platform.h"
+
+#include <stdarg.h>
+#include <stdio.h>
+#include <string.h>
+
+#include "asserts.h"
+#include "sigerr.h"
+
+/* longest failure message, longer strings are cut */
+#define ASSERT_MSG_MAX 1024
+
+/**
+ * @brief Report a failed check and exit
+ * @param fmt printf style format of the message
+ */
+static void assert_failed(const char* fmt, ...) {
+    char msg[ASSERT_MSG_MAX];
+    int n = snprintf(msg, sizeof(msg), "===== assertion failed: ");
+
+    va_list ap;
+    va_start(ap, fmt);
+    vsnprintf(msg + n, sizeof(msg) - n, fmt, ap);
+    va_end(ap);
+
+    __public__runtime_error(msg);
+}
+
+void __public__assert_equal_int(int64_t got, int64_t want) {
+    if (got != want)
+        assert_failed("assert.equal: got %lld, want %lld", (long long)got, (long long)want);
+}
+
+void __public__assert_equal_uint(uint64_t got, uint64_t want) {
+    if (got != want)
+        assert_failed("assert.equal: got %llu, want %llu", (unsigned long long)got, (unsigned long long)want);
+}
+
+void __public__assert_equal_float(double got, double want) {
+    if (got != want)
+        assert_failed("assert.equal: got %g, want %g", got, want);
+}
+
+void __public__assert_equal_bool(int32_t got, int32_t want) {
+    if (!got != !want)
+        assert_failed("assert.equal: got %s, want %s", got ? "true" : "false", want ? "true" : "false");
+}
+
+void __public__assert_equal_string(__public__string_t* got, __public__string_t* want) {
+    if (got == want)
+        return;
+    if (got == NULL || want == NULL) {
+        assert_failed("assert.equal: got %s, want %s", got ? "a string" : "null", want ? "a string" : "null");
+        return;
+    }
+    if (got->size == want->size && memcmp(got->data, want->data, got->size) == 0)
+        return;
+    assert_failed("assert.equal: got \"%.*s\", want \"%.*s\"",
+        (int)got->size, got->data, (int)want->size, want->data);
+}
+
+void __public__assert_equal_ptr(void* got, void* want) {
+    if (got != want)
+        assert_failed("assert.equal: got %p, want %p", got, want);
+}
+
+void __public__assert_not_null(void* v) {
+    if (v == NULL)
+        assert_failed("assert.notNull: got null");
+}
+
+void __public__assert_fails(__public__string_t* msg) {
+    if (msg == NULL || msg->data == NULL) {
+        assert_failed("assert.fails");
+        return;
+    }
+    assert_failed("assert.fails: %.*s", (int)msg->size, msg->data);
+}
@@ -48,6 +48,7 @@ static __public__array_t* __alloc_array_recursive(int32_t elem_size, int32_t ran
     arr->length = count;
     arr->rank = rank;
     arr->elem_size = elem_size;
+    arr->base = NULL;
     
     memset(arr->data, 0, data_size);
     
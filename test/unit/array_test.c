@@ -180,6 +180,59 @@ void test_alloc_array_different_types(void) {
     }
 }
 
+void test_slice_array_shares_storage(void) {
+    __public__array_t* arr = __public__alloc_array(sizeof(int), 1, (int64_t)8);
+    int* data = (int*)arr->data;
+    for (int i = 0; i < 8; i++) {
+        data[i] = i;
+    }
+
+    __public__array_t* view = __public__slice_array(arr, 2, 5);
+    TEST_ASSERT_NOT_NULL(view);
+    TEST_ASSERT_EQUAL_INT64(3, view->length);
+    TEST_ASSERT_EQUAL_INT64(3, view->shape[0]);
+    TEST_ASSERT_EQUAL_PTR(arr, view->base);
+    TEST_ASSERT_EQUAL_INT(2, ((int*)view->data)[0]);
+
+    /* writes through the view land in the source */
+    ((int*)view->data)[0] = 42;
+    TEST_ASSERT_EQUAL_INT(42, data[2]);
+
+    /* a view of a view still points at the root owner */
+    __public__array_t* inner = __public__slice_array(view, 1, 3);
+    TEST_ASSERT_EQUAL_PTR(arr, inner->base);
+    TEST_ASSERT_EQUAL_INT(3, ((int*)inner->data)[0]);
+}
+
+void test_extend_slice_does_not_clobber_source(void) {
+    __public__array_t* arr = __public__alloc_array(sizeof(int), 1, (int64_t)4);
+    __public__array_t* view = __public__slice_array(arr, 0, 2);
+
+    __public__extend_array(view, 0);
+    ((int*)view->data)[2] = 7;
+
+    TEST_ASSERT_EQUAL_INT64(3, view->length);
+    TEST_ASSERT_NULL(view->base);
+    TEST_ASSERT_EQUAL_INT(0, ((int*)arr->data)[2]);
+}
+
+void test_copy_array_is_independent(void) {
+    __public__array_t* arr = __public__alloc_array(sizeof(int), 2, (int64_t)2, (int64_t)3);
+    __public__array_t* row = __public__get_subarray(arr, 1);
+    ((int*)row->data)[0] = 5;
+
+    __public__array_t* dup = __public__copy_array(arr);
+    TEST_ASSERT_NULL(dup->base);
+    TEST_ASSERT_EQUAL_INT64(2, dup->length);
+
+    __public__array_t* dup_row = __public__get_subarray(dup, 1);
+    TEST_ASSERT_TRUE(dup_row != row);
+    TEST_ASSERT_EQUAL_INT(5, ((int*)dup_row->data)[0]);
+
+    ((int*)dup_row->data)[0] = 9;
+    TEST_ASSERT_EQUAL_INT(5, ((int*)row->data)[0]);
+}
+
 int main(void) {
     UNITY_BEGIN();
     __global__arena__ = gc_create_global_arena();
@@ -193,6 +246,9 @@ int main(void) {
     RUN_TEST(test_alloc_array_write_read);
     RUN_TEST(test_alloc_array_boundary_sizes);
     RUN_TEST(test_alloc_array_different_types);
+    RUN_TEST(test_slice_array_shares_storage);
+    RUN_TEST(test_extend_slice_does_not_clobber_source);
+    RUN_TEST(test_copy_array_is_independent);
 
     return UNITY_END();
 }
\ No newline at end of file